@@ -4,14 +4,15 @@ import "time"
 
 // Achievement status constants
 const (
-	AchievementStatusDraft     = "draft"
-	AchievementStatusSubmitted = "submitted"
-	AchievementStatusVerified  = "verified"
-	AchievementStatusRejected  = "rejected"
-	AchievementStatusDeleted   = "deleted" // Added "deleted" status for soft delete
+	AchievementStatusDraft             = "draft"
+	AchievementStatusSubmitted         = "submitted"
+	AchievementStatusRevisionRequested = "revision_requested" // Dikembalikan dosen ke mahasiswa untuk diperbaiki
+	AchievementStatusVerified          = "verified"
+	AchievementStatusRejected          = "rejected"
+	AchievementStatusDeleted           = "deleted" // Added "deleted" status for soft delete
 )
 
-// Achievement type constants
+// Achievement type constants untuk tipe bawaan; daftar lengkap ada di katalog achievement_types
 const (
 	AchievementTypeAcademic      = "academic"
 	AchievementTypeCompetition   = "competition"
@@ -31,6 +32,8 @@ type Achievement struct {
 	Details         map[string]interface{} `bson:"details" json:"details"` // Dynamic fields
 	Tags            []string               `bson:"tags" json:"tags"`
 	Points          int                    `bson:"points" json:"points"`
+	SchemaVersion   int                    `bson:"schema_version,omitempty" json:"schema_version"` // Versi schema Details saat terakhir divalidasi, 0 untuk data lama
+	SearchTerms     []string               `bson:"search_terms,omitempty" json:"-"`                // Kata dasar untuk text index, lihat AchievementSearchTerms
 	CreatedAt       time.Time              `bson:"created_at" json:"created_at"`
 	UpdatedAt       time.Time              `bson:"updated_at" json:"updated_at"`
 }
@@ -46,7 +49,17 @@ type AchievementReference struct {
 	VerifiedAt         *time.Time `db:"verified_at" json:"verified_at"`
 	VerifiedBy         *string    `db:"verified_by" json:"verified_by"`
 	RejectionNote      *string    `db:"rejection_note" json:"rejection_note"`
-	DeletedAt          *time.Time `db:"deleted_at" json:"deleted_at"` // Added deleted_at field
+	ResubmissionCount  int        `db:"resubmission_count" json:"resubmission_count"` // Berapa kali prestasi ditolak lalu dibuka kembali
+	Version            int        `db:"version" json:"version"`                       // Naik setiap kali isi atau status prestasi berubah, dipakai sebagai ETag
+	PossibleDuplicate  bool       `db:"possible_duplicate" json:"possible_duplicate"` // Ditandai saat submit jika mirip prestasi lain
+	TeamID             *string    `db:"team_id" json:"team_id"`                       // Sama untuk semua anggota prestasi tim, nil untuk prestasi individu
+	TeamRole           *string    `db:"team_role" json:"team_role"`                   // leader atau member
+	Points             *int       `db:"points" json:"points"`                         // Poin yang diberikan ke anggota ini, nil = pakai poin dokumen
+	SLARemindedAt      *time.Time `db:"sla_reminded_at" json:"sla_reminded_at"`       // Waktu dosen wali diingatkan karena verifikasi melewati SLA
+	EscalatedAt        *time.Time `db:"escalated_at" json:"escalated_at"`             // Waktu prestasi dieskalasi ke reviewer departemen
+	ValidUntil         *time.Time `db:"valid_until" json:"valid_until"`               // Tanggal terakhir prestasi berlaku, nil = berlaku selamanya
+	ExpiryRemindedAt   *time.Time `db:"expiry_reminded_at" json:"expiry_reminded_at"` // Waktu mahasiswa diingatkan masa berlaku akan habis
+	DeletedAt          *time.Time `db:"deleted_at" json:"deleted_at"`                 // Added deleted_at field
 	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt          time.Time  `db:"updated_at" json:"updated_at"`
 }
@@ -54,20 +67,47 @@ type AchievementReference struct {
 // AchievementWithReference combines MongoDB data with PostgreSQL reference
 type AchievementWithReference struct {
 	Achievement
-	Status        string     `json:"status"`
-	SubmittedAt   *time.Time `json:"submitted_at"`
-	VerifiedAt    *time.Time `json:"verified_at"`
-	VerifiedBy    *string    `json:"verified_by"`
-	RejectionNote *string    `json:"rejection_note"`
+	ReferenceID       string     `json:"reference_id"` // ID achievement_references, dipakai di URL endpoint prestasi
+	Status            string     `json:"status"`
+	SubmittedAt       *time.Time `json:"submitted_at"`
+	VerifiedAt        *time.Time `json:"verified_at"`
+	VerifiedBy        *string    `json:"verified_by"`
+	RejectionNote     *string    `json:"rejection_note"`
+	ResubmissionCount int        `json:"resubmission_count"`
+	Version           int        `json:"version"`             // Sama dengan header ETag, kirim kembali lewat If-Match saat mengubah prestasi
+	PossibleDuplicate bool       `json:"possible_duplicate"`  // Penanda untuk verifikator bahwa prestasi ini mirip prestasi lain
+	TeamID            *string    `json:"team_id,omitempty"`   // Terisi jika prestasi tim
+	TeamRole          *string    `json:"team_role,omitempty"` // Peran pemilik reference ini dalam tim
+	SLARemindedAt     *time.Time `json:"sla_reminded_at,omitempty"`
+	EscalatedAt       *time.Time `json:"escalated_at,omitempty"` // Reviewer departemen boleh memverifikasi setelah eskalasi
+	ValidUntil        *time.Time `json:"valid_until,omitempty"`  // Tanggal terakhir prestasi berlaku (format tanggal, tanpa jam)
+	Expired           bool       `json:"expired"`                // Masa berlaku sudah lewat, dihitung saat data dibaca
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`   // Hanya terisi untuk prestasi di trash
+
+	RevisionRequests  []*AchievementRevisionRequest `json:"revision_requests,omitempty"`  // Hanya diisi di detail prestasi
+	ApprovalProgress  *ApprovalProgress             `json:"approval_progress,omitempty"`  // Hanya diisi di detail prestasi
+	PointsSuggestion  *PointsSuggestion             `json:"points_suggestion,omitempty"`  // Rekomendasi poin dari rubrik, hanya di detail prestasi untuk verifikator
+	Duplicates        []*DuplicateMatch             `json:"duplicates,omitempty"`         // Prestasi lain yang kemungkinan sama, diisi saat create/submit dan di detail
+	TeamMembers       []*TeamMember                 `json:"team_members,omitempty"`       // Anggota tim, diisi di detail prestasi dan laporan
+	EvidenceChecklist *EvidenceChecklist            `json:"evidence_checklist,omitempty"` // Syarat bukti tipe prestasi, hanya di detail prestasi
+}
+
+// PurgeCandidate adalah reference di trash yang sudah melewati masa simpan dan siap dihapus permanen
+type PurgeCandidate struct {
+	ReferenceID string    `db:"id"`
+	DeletedAt   time.Time `db:"deleted_at"`
+	TeamID      *string   `db:"team_id"`
 }
 
 // CreateAchievementRequest adalah request untuk membuat prestasi baru
 type CreateAchievementRequest struct {
-	AchievementType string                 `json:"achievement_type"` // Tipe prestasi: academic, competition, organization, publication, certification, other
+	AchievementType string                 `json:"achievement_type"` // Kode tipe prestasi dari katalog achievement_types
 	Title           string                 `json:"title"`            // Judul prestasi
 	Description     string                 `json:"description"`      // Deskripsi prestasi
 	Details         map[string]interface{} `json:"details"`          // Detail dinamis berdasarkan tipe
 	Tags            []string               `json:"tags"`             // Tag/kategori prestasi
+	TeamMembers     []TeamMemberRequest    `json:"team_members"`     // Opsional, anggota tim selain pembuat (pembuat otomatis menjadi leader)
+	ValidUntil      *string                `json:"valid_until"`      // Opsional, YYYY-MM-DD. Untuk sertifikasi default dari details.expiry_date
 	// Points field removed - points will be assigned by lecturer during verification
 }
 
@@ -78,10 +118,44 @@ type UpdateAchievementRequest struct {
 	Description     *string                 `json:"description"`
 	Details         *map[string]interface{} `json:"details"`
 	Tags            *[]string               `json:"tags"`
+	ValidUntil      *string                 `json:"valid_until"` // YYYY-MM-DD, string kosong menghapus masa berlaku
 	// Points field removed - points cannot be updated by students
 }
 
 // VerifyAchievementRequest adalah request untuk verifikasi prestasi dengan poin
 type VerifyAchievementRequest struct {
-	Points int `json:"points" validate:"required,min=0"` // Poin yang diberikan dosen saat verifikasi
+	Points        int    `json:"points" validate:"required,min=0"` // Poin yang diberikan dosen saat verifikasi
+	Justification string `json:"justification"`                    // Wajib jika poin menyimpang dari rubrik melebihi toleransi
+}
+
+// AdjustPointsRequest adalah request koreksi poin prestasi yang sudah diverifikasi
+type AdjustPointsRequest struct {
+	Points *int   `json:"points"` // Poin baru, wajib kecuali revoke
+	Revoke bool   `json:"revoke"` // Cabut poin (menjadi 0)
+	Reason string `json:"reason"` // Wajib, alasan koreksi dicatat di history
+}
+
+// BulkReviewItem adalah satu prestasi dalam batch verifikasi/penolakan
+type BulkReviewItem struct {
+	AchievementID string `json:"achievement_id"`
+	Action        string `json:"action"`         // verify atau reject
+	Points        *int   `json:"points"`         // Wajib untuk verify
+	Version       *int   `json:"version"`        // Opsional, sama seperti If-Match pada endpoint satuan
+	Justification string `json:"justification"`  // Wajib jika poin menyimpang dari rubrik melebihi toleransi
+	RejectionNote string `json:"rejection_note"` // Wajib untuk reject
+}
+
+// BulkReviewRequest adalah request verifikasi/penolakan banyak prestasi sekaligus
+type BulkReviewRequest struct {
+	Items []BulkReviewItem `json:"items"`
+}
+
+// BulkReviewResult adalah hasil pemrosesan satu item batch; item lain tetap diproses jika item ini gagal
+type BulkReviewResult struct {
+	AchievementID string      `json:"achievement_id"`
+	Action        string      `json:"action"`
+	Success       bool        `json:"success"`
+	StatusCode    int         `json:"status_code"` // Status HTTP yang akan dikembalikan endpoint satuan
+	Message       string      `json:"message"`
+	Data          interface{} `json:"data,omitempty"`
 }
@@ -10,6 +10,7 @@ type AchievementAttachment struct {
 	FilePath      string    `db:"file_path" json:"file_path"`
 	FileSize      int64     `db:"file_size" json:"file_size"`
 	FileType      string    `db:"file_type" json:"file_type"`
+	ContentHash   string    `db:"content_hash" json:"content_hash"` // SHA-256 isi file, dipakai untuk deteksi duplikat
 	UploadedBy    string    `db:"uploaded_by" json:"uploaded_by"`
 	UploadedAt    time.Time `db:"uploaded_at" json:"uploaded_at"`
 }
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// AchievementComment adalah komentar diskusi antara mahasiswa dan dosen pada satu prestasi
+type AchievementComment struct {
+	ID            string                `db:"id" json:"id"`
+	AchievementID string                `db:"achievement_id" json:"achievement_id"`
+	ParentID      *string               `db:"parent_id" json:"parent_id"` // Kosong untuk komentar utama, terisi untuk balasan
+	AuthorID      string                `db:"author_id" json:"author_id"`
+	AuthorName    string                `db:"author_name" json:"author_name"`
+	Body          string                `db:"body" json:"body"`
+	Mentions      []string              `db:"mentions" json:"mentions"`           // User ID yang di-mention lewat @username
+	AttachmentID  *string               `db:"attachment_id" json:"attachment_id"` // Lampiran prestasi yang sedang dibahas
+	CreatedAt     time.Time             `db:"created_at" json:"created_at"`
+	EditedAt      *time.Time            `db:"edited_at" json:"edited_at"`
+	DeletedAt     *time.Time            `db:"deleted_at" json:"deleted_at"` // Komentar terhapus tetap ada agar balasannya tidak yatim
+	Replies       []*AchievementComment `json:"replies,omitempty"`
+}
+
+// CreateCommentRequest adalah request untuk menulis komentar atau balasan
+type CreateCommentRequest struct {
+	Body         string  `json:"body"`
+	ParentID     *string `json:"parent_id"`
+	AttachmentID *string `json:"attachment_id"`
+}
+
+// UpdateCommentRequest adalah request untuk mengedit isi komentar
+type UpdateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// Timeline entry types
+const (
+	TimelineEntryHistory = "history"
+	TimelineEntryComment = "comment"
+)
+
+// TimelineEntry adalah satu kejadian pada timeline prestasi: perubahan status atau komentar
+type TimelineEntry struct {
+	Type      string              `json:"type"`
+	Timestamp time.Time           `json:"timestamp"`
+	History   *AchievementHistory `json:"history,omitempty"`
+	Comment   *AchievementComment `json:"comment,omitempty"`
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Alasan sebuah prestasi dianggap duplikat
+const (
+	DuplicateReasonTitle      = "similar_title" // Judul (setelah dinormalisasi) mirip dan tipe sama
+	DuplicateReasonEventDate  = "same_event_date"
+	DuplicateReasonAttachment = "same_attachment" // Isi file lampiran identik (hash SHA-256 sama)
+)
+
+// DuplicateTitleThreshold adalah batas kemiripan judul (0-1) agar dianggap duplikat
+const DuplicateTitleThreshold = 0.8
+
+// eventDateKeys adalah field Details yang dipakai sebagai tanggal kegiatan, sesuai urutan prioritas
+var eventDateKeys = []string{"event_date", "date", "issued_date", "period_start"}
+
+// DuplicateMatch adalah prestasi lain yang kemungkinan sama dengan prestasi yang sedang dibuat/disubmit
+type DuplicateMatch struct {
+	AchievementID string   `json:"achievement_id"`
+	StudentID     string   `json:"student_id"`
+	Title         string   `json:"title"`
+	Status        string   `json:"status"`
+	Reasons       []string `json:"reasons"`
+	Link          string   `json:"link"`
+}
+
+// NewDuplicateMatch membuat DuplicateMatch dengan link ke detail prestasi
+func NewDuplicateMatch(achievement *AchievementWithReference, reasons []string) *DuplicateMatch {
+	return &DuplicateMatch{
+		AchievementID: achievement.ReferenceID,
+		StudentID:     achievement.StudentID,
+		Title:         achievement.Title,
+		Status:        achievement.Status,
+		Reasons:       reasons,
+		Link:          "/api/v1/achievements/" + achievement.ReferenceID,
+	}
+}
+
+// NormalizeTitle menyamakan huruf besar/kecil, tanda baca, dan spasi agar judul bisa dibandingkan
+func NormalizeTitle(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// TitleSimilarity menghitung kemiripan dua judul dengan Jaccard index atas kata-kata judul yang sudah dinormalisasi
+func TitleSimilarity(a, b string) float64 {
+	wordsA := strings.Fields(NormalizeTitle(a))
+	wordsB := strings.Fields(NormalizeTitle(b))
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	setA := make(map[string]bool, len(wordsA))
+	for _, w := range wordsA {
+		setA[w] = true
+	}
+	union := len(setA)
+	intersection := 0
+	seenB := make(map[string]bool, len(wordsB))
+	for _, w := range wordsB {
+		if seenB[w] {
+			continue
+		}
+		seenB[w] = true
+		if setA[w] {
+			intersection++
+		} else {
+			union++
+		}
+	}
+	return float64(intersection) / float64(union)
+}
+
+// EventDate mengambil tanggal kegiatan dari Details; string kosong jika tidak ada
+func EventDate(details map[string]interface{}) string {
+	for _, key := range eventDateKeys {
+		if value, ok := details[key]; ok && value != nil {
+			if date := strings.TrimSpace(fmt.Sprint(value)); date != "" {
+				return date
+			}
+		}
+	}
+	return ""
+}
+
+// DuplicateReasons membandingkan isi dua prestasi. Judul mirip dengan tipe sama dianggap duplikat
+// kecuali keduanya punya tanggal kegiatan yang berbeda. Lampiran identik dicek terpisah lewat hash.
+func DuplicateReasons(a, b *Achievement) []string {
+	if a.AchievementType != b.AchievementType || TitleSimilarity(a.Title, b.Title) < DuplicateTitleThreshold {
+		return nil
+	}
+
+	dateA, dateB := EventDate(a.Details), EventDate(b.Details)
+	if dateA != "" && dateB != "" {
+		if dateA != dateB {
+			return nil
+		}
+		return []string{DuplicateReasonTitle, DuplicateReasonEventDate}
+	}
+	return []string{DuplicateReasonTitle}
+}
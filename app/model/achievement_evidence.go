@@ -0,0 +1,160 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Kode item checklist bukti prestasi
+const (
+	EvidenceItemAttachments = "attachments" // Jumlah minimal lampiran
+	EvidenceItemFileTypes   = "file_types"  // Semua lampiran bertipe yang diizinkan
+	EvidenceItemDetails     = "details"     // Satu field Details yang wajib terisi
+)
+
+// EvidenceRules adalah aturan bukti per tipe prestasi yang harus dipenuhi sebelum prestasi disubmit
+type EvidenceRules struct {
+	MinAttachments   int      `json:"min_attachments"`
+	AllowedFileTypes []string `json:"allowed_file_types"` // Ekstensi file tanpa titik, misal: pdf, jpg. Kosong = semua tipe
+	RequiredDetails  []string `json:"required_details"`   // Field Details yang wajib terisi saat submit (boleh kosong saat draft)
+}
+
+// Normalize merapikan aturan: ekstensi huruf kecil tanpa titik, field tanpa spasi, tanpa duplikat
+func (r EvidenceRules) Normalize() EvidenceRules {
+	normalized := EvidenceRules{MinAttachments: r.MinAttachments}
+	seen := make(map[string]bool)
+	for _, fileType := range r.AllowedFileTypes {
+		fileType = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(fileType), "."))
+		if fileType != "" && !seen["type:"+fileType] {
+			seen["type:"+fileType] = true
+			normalized.AllowedFileTypes = append(normalized.AllowedFileTypes, fileType)
+		}
+	}
+	for _, field := range r.RequiredDetails {
+		field = strings.TrimSpace(field)
+		if field != "" && !seen["field:"+field] {
+			seen["field:"+field] = true
+			normalized.RequiredDetails = append(normalized.RequiredDetails, field)
+		}
+	}
+	return normalized
+}
+
+// IsFileTypeAllowed mengecek ekstensi nama file terhadap AllowedFileTypes
+func (r EvidenceRules) IsFileTypeAllowed(fileName string) bool {
+	if len(r.AllowedFileTypes) == 0 {
+		return true
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	for _, allowed := range r.AllowedFileTypes {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveEvidenceRules mengembalikan aturan bukti tipe prestasi; requires_attachment berarti minimal satu lampiran
+func (d *AchievementTypeDefinition) EffectiveEvidenceRules() EvidenceRules {
+	rules := d.EvidenceRules.Normalize()
+	if d.RequiresAttachment && rules.MinAttachments < 1 {
+		rules.MinAttachments = 1
+	}
+	return rules
+}
+
+// EvidenceChecklistItem adalah satu syarat bukti beserta status pemenuhannya
+type EvidenceChecklistItem struct {
+	Code      string `json:"code"`
+	Field     string `json:"field,omitempty"` // Hanya untuk item details
+	Label     string `json:"label"`
+	Satisfied bool   `json:"satisfied"`
+	Detail    string `json:"detail,omitempty"` // Keterangan progres, misal: 1 dari 2 lampiran
+}
+
+// EvidenceChecklist adalah daftar syarat bukti prestasi; Complete bernilai true jika semua terpenuhi
+type EvidenceChecklist struct {
+	Complete bool                     `json:"complete"`
+	Items    []*EvidenceChecklistItem `json:"items"`
+}
+
+// Missing mengembalikan item yang belum terpenuhi
+func (c *EvidenceChecklist) Missing() []*EvidenceChecklistItem {
+	var missing []*EvidenceChecklistItem
+	for _, item := range c.Items {
+		if !item.Satisfied {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
+
+// BuildEvidenceChecklist menilai Details dan lampiran prestasi terhadap aturan bukti. Lampiran yang
+// tipenya tidak diizinkan tidak dihitung ke jumlah minimal.
+func BuildEvidenceChecklist(rules EvidenceRules, details map[string]interface{}, attachments []*AchievementAttachment) *EvidenceChecklist {
+	checklist := &EvidenceChecklist{Items: []*EvidenceChecklistItem{}}
+
+	allowed := 0
+	var rejected []string
+	for _, attachment := range attachments {
+		if rules.IsFileTypeAllowed(attachment.FileName) {
+			allowed++
+		} else {
+			rejected = append(rejected, attachment.FileName)
+		}
+	}
+
+	if rules.MinAttachments > 0 {
+		label := fmt.Sprintf("Minimal %d lampiran", rules.MinAttachments)
+		if len(rules.AllowedFileTypes) > 0 {
+			label += " (" + strings.Join(rules.AllowedFileTypes, ", ") + ")"
+		}
+		checklist.Items = append(checklist.Items, &EvidenceChecklistItem{
+			Code:      EvidenceItemAttachments,
+			Label:     label,
+			Satisfied: allowed >= rules.MinAttachments,
+			Detail:    fmt.Sprintf("%d dari %d lampiran", allowed, rules.MinAttachments),
+		})
+	}
+
+	if len(rules.AllowedFileTypes) > 0 && len(attachments) > 0 {
+		item := &EvidenceChecklistItem{
+			Code:      EvidenceItemFileTypes,
+			Label:     "Lampiran harus bertipe " + strings.Join(rules.AllowedFileTypes, ", "),
+			Satisfied: len(rejected) == 0,
+		}
+		if len(rejected) > 0 {
+			item.Detail = "tipe tidak diizinkan: " + strings.Join(rejected, ", ")
+		}
+		checklist.Items = append(checklist.Items, item)
+	}
+
+	for _, field := range rules.RequiredDetails {
+		checklist.Items = append(checklist.Items, &EvidenceChecklistItem{
+			Code:      EvidenceItemDetails,
+			Field:     field,
+			Label:     "Details " + field + " wajib diisi",
+			Satisfied: hasDetailsValue(details[field]),
+		})
+	}
+
+	checklist.Complete = len(checklist.Missing()) == 0
+	return checklist
+}
+
+// hasDetailsValue mengecek apakah nilai Details terisi (bukan nil, string kosong, atau list/objek kosong)
+func hasDetailsValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// ValidUntilLayout adalah format tanggal masa berlaku prestasi
+const ValidUntilLayout = "2006-01-02"
+
+// ExpiringAchievement adalah prestasi terverifikasi yang masa berlakunya segera habis
+type ExpiringAchievement struct {
+	AchievementID string    `json:"achievement_id"` // ID achievement_references
+	Title         string    `json:"title"`
+	StudentID     string    `json:"student_id"`
+	ValidUntil    time.Time `json:"valid_until"`
+}
+
+// ParseValidUntil membaca tanggal masa berlaku dengan format YYYY-MM-DD
+func ParseValidUntil(value string) (time.Time, error) {
+	return time.Parse(ValidUntilLayout, value)
+}
+
+// IsValidityExpired mengecek apakah masa berlaku sudah lewat pada waktu now. Prestasi masih berlaku
+// sepanjang hari terakhirnya.
+func IsValidityExpired(validUntil *time.Time, now time.Time) bool {
+	if validUntil == nil {
+		return false
+	}
+	lastDay := time.Date(validUntil.Year(), validUntil.Month(), validUntil.Day(), 0, 0, 0, 0, now.Location())
+	return !now.Before(lastDay.AddDate(0, 0, 1))
+}
+
+// IsExpired mengecek apakah masa berlaku prestasi sudah lewat pada waktu now
+func (a *AchievementWithReference) IsExpired(now time.Time) bool {
+	return IsValidityExpired(a.ValidUntil, now)
+}
+
+// DaysLeft menghitung sisa hari masa berlaku sampai hari terakhirnya, 0 jika hari ini adalah hari terakhir
+func (e *ExpiringAchievement) DaysLeft(now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastDay := time.Date(e.ValidUntil.Year(), e.ValidUntil.Month(), e.ValidUntil.Day(), 0, 0, 0, 0, time.UTC)
+	return int(lastDay.Sub(today).Hours() / 24)
+}
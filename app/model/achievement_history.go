@@ -4,12 +4,17 @@ import "time"
 
 // AchievementHistory menyimpan riwayat perubahan status achievement
 type AchievementHistory struct {
-	ID            string    `db:"id" json:"id"`
-	AchievementID string    `db:"achievement_id" json:"achievement_id"`
-	OldStatus     string    `db:"previous_status" json:"previous_status"`
-	NewStatus     string    `db:"new_status" json:"new_status"`
-	ChangedBy     string    `db:"changed_by" json:"changed_by"`
-	ChangedByName string    `db:"changed_by_name" json:"changed_by_name"`
-	Note          *string   `db:"notes" json:"notes"`
-	CreatedAt     time.Time `db:"changed_at" json:"changed_at"`
+	ID             string    `db:"id" json:"id"`
+	AchievementID  string    `db:"achievement_id" json:"achievement_id"`
+	Action         string    `db:"action" json:"action"` // Aksi workflow yang memicu perubahan, misal: submit, verify
+	OldStatus      string    `db:"previous_status" json:"previous_status"`
+	NewStatus      string    `db:"new_status" json:"new_status"`
+	ChangedBy      string    `db:"changed_by" json:"changed_by"`
+	ChangedByName  string    `db:"changed_by_name" json:"changed_by_name"`
+	OnBehalfOf     *string   `db:"on_behalf_of" json:"on_behalf_of"` // User ID dosen wali asli jika aksi dilakukan lewat pelimpahan
+	OnBehalfOfName *string   `db:"on_behalf_of_name" json:"on_behalf_of_name"`
+	Note           *string   `db:"notes" json:"notes"`
+	OldPoints      *int      `db:"old_points" json:"old_points,omitempty"` // Hanya terisi untuk koreksi poin
+	NewPoints      *int      `db:"new_points" json:"new_points,omitempty"`
+	CreatedAt      time.Time `db:"changed_at" json:"changed_at"`
 }
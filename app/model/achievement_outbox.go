@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Operasi outbox untuk dokumen prestasi di MongoDB. Setiap operasi idempoten sehingga aman diulang
+// jika proses berhenti setelah MongoDB ditulis tetapi sebelum entri outbox ditandai selesai.
+const (
+	OutboxOperationUpsert = "upsert" // Ganti seluruh dokumen, buat jika belum ada
+	OutboxOperationUpdate = "update" // $set sebagian field dokumen
+	OutboxOperationDelete = "delete" // Hapus dokumen; dokumen yang sudah tidak ada dianggap berhasil
+)
+
+// AchievementOutboxEntry adalah perubahan dokumen prestasi di MongoDB yang dicatat di PostgreSQL dalam
+// transaksi yang sama dengan perubahan reference. Entri diterapkan berurutan per dokumen lalu dihapus.
+type AchievementOutboxEntry struct {
+	ID                 int64     `db:"id" json:"id"`
+	MongoAchievementID string    `db:"mongo_achievement_id" json:"mongo_achievement_id"`
+	Operation          string    `db:"operation" json:"operation"`
+	Payload            []byte    `db:"payload" json:"-"` // Dokumen atau field $set dalam format BSON
+	Attempts           int       `db:"attempts" json:"attempts"`
+	LastError          *string   `db:"last_error" json:"last_error"`
+	NextAttemptAt      time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,447 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Content-Type yang diterima PATCH /achievements/:id
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7396
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902
+)
+
+// ErrReadOnlyField dikembalikan jika patch menyentuh field yang tidak boleh diubah mahasiswa
+var ErrReadOnlyField = errors.New("field tidak bisa diubah lewat patch")
+
+// ErrPatchTestFailed dikembalikan jika operasi test JSON Patch tidak cocok dengan isi prestasi
+var ErrPatchTestFailed = errors.New("operasi test gagal")
+
+// patchableAchievementFields adalah field prestasi yang boleh diubah lewat patch (sama dengan UpdateAchievementRequest)
+var patchableAchievementFields = map[string]bool{
+	"achievement_type": true,
+	"title":            true,
+	"description":      true,
+	"details":          true,
+	"tags":             true,
+	"valid_until":      true,
+}
+
+// JSONPatchOperation adalah satu operasi RFC 6902
+type JSONPatchOperation struct {
+	Op    string      `json:"op"` // add, remove, replace, move, copy, test
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"` // Untuk move dan copy
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PatchableAchievement mengubah field prestasi yang bisa diedit menjadi dokumen JSON generik tempat patch diterapkan
+func PatchableAchievement(achievement *AchievementWithReference) (map[string]interface{}, error) {
+	var validUntil interface{}
+	if achievement.ValidUntil != nil {
+		validUntil = achievement.ValidUntil.Format(ValidUntilLayout)
+	}
+	raw, err := json.Marshal(map[string]interface{}{
+		"achievement_type": achievement.AchievementType,
+		"title":            achievement.Title,
+		"description":      achievement.Description,
+		"details":          achievement.Details,
+		"tags":             achievement.Tags,
+		"valid_until":      validUntil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Round-trip JSON menyamakan tipe nilai (angka float64, list []interface{}) dengan isi patch
+	var document map[string]interface{}
+	err = json.Unmarshal(raw, &document)
+	return document, err
+}
+
+// PatchedAchievementRequest membandingkan dokumen hasil patch dengan dokumen asal dan mengembalikan
+// UpdateAchievementRequest yang hanya berisi field yang berubah. Field yang dihapus dianggap kosong.
+func PatchedAchievementRequest(original, patched map[string]interface{}) (*UpdateAchievementRequest, error) {
+	for field := range patched {
+		if err := checkPatchableField(field); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields struct {
+		AchievementType *string                 `json:"achievement_type"`
+		Title           *string                 `json:"title"`
+		Description     *string                 `json:"description"`
+		Details         *map[string]interface{} `json:"details"`
+		Tags            *[]string               `json:"tags"`
+		ValidUntil      *string                 `json:"valid_until"`
+	}
+	raw, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("hasil patch tidak sesuai format prestasi: %w", err)
+	}
+
+	req := &UpdateAchievementRequest{}
+	changed := func(field string) bool {
+		return !reflect.DeepEqual(original[field], patched[field])
+	}
+	if changed("achievement_type") {
+		if fields.AchievementType == nil || *fields.AchievementType == "" {
+			return nil, errors.New("achievement_type tidak boleh dihapus")
+		}
+		req.AchievementType = fields.AchievementType
+	}
+	if changed("title") {
+		req.Title = stringOrEmpty(fields.Title)
+	}
+	if changed("description") {
+		req.Description = stringOrEmpty(fields.Description)
+	}
+	if changed("details") {
+		details := map[string]interface{}{}
+		if fields.Details != nil {
+			details = *fields.Details
+		}
+		req.Details = &details
+	}
+	if changed("tags") {
+		tags := []string{}
+		if fields.Tags != nil {
+			tags = *fields.Tags
+		}
+		req.Tags = &tags
+	}
+	if changed("valid_until") {
+		// String kosong menghapus masa berlaku, sama seperti di PUT
+		req.ValidUntil = stringOrEmpty(fields.ValidUntil)
+		if *req.ValidUntil != "" {
+			if _, err := time.Parse(ValidUntilLayout, *req.ValidUntil); err != nil {
+				return nil, errors.New("valid_until harus berformat YYYY-MM-DD")
+			}
+		}
+	}
+	return req, nil
+}
+
+func stringOrEmpty(value *string) *string {
+	if value == nil {
+		empty := ""
+		return &empty
+	}
+	return value
+}
+
+// checkPatchableField memastikan field level atas boleh diubah; points dibedakan agar pesannya jelas
+func checkPatchableField(field string) error {
+	if field == "points" {
+		return fmt.Errorf("%w: points hanya diberikan dosen saat verifikasi", ErrReadOnlyField)
+	}
+	if !patchableAchievementFields[field] {
+		return fmt.Errorf("%w: %s", ErrReadOnlyField, field)
+	}
+	return nil
+}
+
+// ApplyMergePatch menerapkan JSON Merge Patch (RFC 7396): null menghapus field, objek digabung
+// secara rekursif, dan nilai lain (termasuk array) menggantikan nilai lama
+func ApplyMergePatch(document map[string]interface{}, patch map[string]interface{}) (map[string]interface{}, error) {
+	for field := range patch {
+		if err := checkPatchableField(field); err != nil {
+			return nil, err
+		}
+	}
+	return mergePatchObject(document, patch), nil
+}
+
+func mergePatchObject(target map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = map[string]interface{}{}
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]interface{}); ok {
+			targetObject, _ := target[key].(map[string]interface{})
+			target[key] = mergePatchObject(targetObject, patchObject)
+			continue
+		}
+		target[key] = value
+	}
+	return target
+}
+
+// ApplyJSONPatch menerapkan operasi JSON Patch (RFC 6902) secara berurutan. Jika satu operasi gagal
+// (termasuk test), seluruh patch dibatalkan karena perubahan hanya disimpan setelah semua operasi berhasil.
+func ApplyJSONPatch(document map[string]interface{}, operations []JSONPatchOperation) (map[string]interface{}, error) {
+	var root interface{} = document
+	for i, operation := range operations {
+		var err error
+		root, err = applyJSONPatchOperation(root, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operasi ke-%d (%s %s): %w", i+1, operation.Op, operation.Path, err)
+		}
+	}
+	result, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("hasil patch harus berupa objek")
+	}
+	return result, nil
+}
+
+func applyJSONPatchOperation(root interface{}, operation JSONPatchOperation) (interface{}, error) {
+	path, err := parseJSONPointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+	if operation.Op != "test" {
+		if err := checkPatchPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	switch operation.Op {
+	case "add":
+		return jsonPointerSet(root, path, cloneJSONValue(operation.Value), true)
+	case "replace":
+		return jsonPointerSet(root, path, cloneJSONValue(operation.Value), false)
+	case "remove":
+		root, _, err = jsonPointerRemove(root, path)
+		return root, err
+	case "move", "copy":
+		from, err := parseJSONPointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPatchPath(from); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if operation.Op == "move" {
+			if isJSONPointerPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("tidak bisa memindahkan nilai ke dalam dirinya sendiri")
+			}
+			root, value, err = jsonPointerRemove(root, from)
+		} else {
+			value, err = jsonPointerGet(root, from)
+			value = cloneJSONValue(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerSet(root, path, value, true)
+	case "test":
+		value, err := jsonPointerGet(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, cloneJSONValue(operation.Value)) {
+			return nil, fmt.Errorf("%w: nilai tidak sama dengan yang diuji", ErrPatchTestFailed)
+		}
+		return root, nil
+	default:
+		return nil, fmt.Errorf("op %q tidak dikenal", operation.Op)
+	}
+}
+
+// checkPatchPath menolak operasi pada dokumen utuh atau field yang tidak bisa diedit
+func checkPatchPath(path []string) error {
+	if len(path) == 0 {
+		return errors.New("path tidak boleh menunjuk seluruh dokumen")
+	}
+	return checkPatchableField(path[0])
+}
+
+// parseJSONPointer memecah JSON Pointer (RFC 6901) menjadi token, "" berarti seluruh dokumen
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q harus diawali /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isJSONPointerPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonPointerGet(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("field %s tidak ada", token)
+			}
+			node = value
+		case []interface{}:
+			index, err := jsonArrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("%s tidak bisa ditelusuri", token)
+		}
+	}
+	return node, nil
+}
+
+// jsonPointerSet mengisi nilai di path. insert=true mengikuti semantik add (array disisipkan, field
+// baru dibuat), insert=false mengikuti replace (nilai harus sudah ada). Mengembalikan node baru.
+func jsonPointerSet(node interface{}, path []string, value interface{}, insert bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			if _, ok := container[token]; !ok && !insert {
+				return nil, fmt.Errorf("field %s tidak ada", token)
+			}
+			container[token] = value
+			return container, nil
+		}
+		child, ok := container[token]
+		if !ok {
+			return nil, fmt.Errorf("field %s tidak ada", token)
+		}
+		updated, err := jsonPointerSet(child, path[1:], value, insert)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = updated
+		return container, nil
+	case []interface{}:
+		if len(path) == 1 && insert {
+			index, err := jsonArrayIndex(token, len(container), true)
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+		index, err := jsonArrayIndex(token, len(container), false)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 1 {
+			container[index] = value
+			return container, nil
+		}
+		updated, err := jsonPointerSet(container[index], path[1:], value, insert)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = updated
+		return container, nil
+	default:
+		return nil, fmt.Errorf("%s tidak bisa ditelusuri", token)
+	}
+}
+
+// jsonPointerRemove menghapus nilai di path dan mengembalikan node baru beserta nilai yang dihapus
+func jsonPointerRemove(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("path tidak boleh menunjuk seluruh dokumen")
+	}
+	token := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("field %s tidak ada", token)
+		}
+		if len(path) == 1 {
+			delete(container, token)
+			return container, child, nil
+		}
+		updated, removed, err := jsonPointerRemove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = updated
+		return container, removed, nil
+	case []interface{}:
+		index, err := jsonArrayIndex(token, len(container), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(path) == 1 {
+			removed := container[index]
+			return append(container[:index:index], container[index+1:]...), removed, nil
+		}
+		updated, removed, err := jsonPointerRemove(container[index], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[index] = updated
+		return container, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("%s tidak bisa ditelusuri", token)
+	}
+}
+
+// jsonArrayIndex mengubah token menjadi indeks array; "-" (akhir array) hanya berlaku untuk add
+func jsonArrayIndex(token string, length int, insert bool) (int, error) {
+	if token == "-" && insert {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("indeks array %q tidak valid", token)
+	}
+	limit := length - 1
+	if insert {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("indeks array %d di luar jangkauan", index)
+	}
+	return index, nil
+}
+
+// cloneJSONValue menyalin nilai JSON agar operasi berikutnya tidak mengubah nilai yang sama di dua tempat
+func cloneJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		cloned := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			cloned[key] = cloneJSONValue(item)
+		}
+		return cloned
+	case []interface{}:
+		cloned := make([]interface{}, len(v))
+		for i, item := range v {
+			cloned[i] = cloneJSONValue(item)
+		}
+		return cloned
+	default:
+		return v
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+// Jenis ketidaksesuaian antara achievement_references di PostgreSQL dan dokumen prestasi di MongoDB
+const (
+	ReconcileOrphanDocument    = "orphan_document"    // Dokumen MongoDB tanpa reference
+	ReconcileDanglingReference = "dangling_reference" // Reference yang mongo_achievement_id-nya tidak ditemukan
+	ReconcileTitleDrift        = "title_drift"        // achievement_title berbeda dengan title di MongoDB
+	ReconcileStudentMismatch   = "student_mismatch"   // student_id dokumen berbeda dengan pemilik reference
+)
+
+// Tindakan perbaikan untuk tiap jenis ketidaksesuaian
+const (
+	ReconcileActionDeleteDocument = "delete_document"         // Dokumen yatim dihapus lewat outbox
+	ReconcileActionDeleteRef      = "delete_reference"        // Reference beserta history dan lampirannya dihapus
+	ReconcileActionUpdateTitle    = "update_reference_title"  // achievement_title disamakan dengan title di MongoDB
+	ReconcileActionUpdateStudent  = "update_document_student" // student_id dokumen disamakan dengan reference
+)
+
+// ReconcileReference adalah data reference yang dibandingkan saat rekonsiliasi
+type ReconcileReference struct {
+	ReferenceID        string
+	StudentID          string
+	MongoAchievementID string
+	Title              string
+	Status             string
+	TeamRole           *string
+}
+
+// ReconcileDocument adalah data dokumen MongoDB yang dibandingkan saat rekonsiliasi
+type ReconcileDocument struct {
+	MongoAchievementID string
+	StudentID          string
+	Title              string
+}
+
+// ReconcileMismatch adalah satu ketidaksesuaian yang ditemukan beserta tindakan perbaikannya
+type ReconcileMismatch struct {
+	Kind               string `json:"kind"`
+	ReferenceID        string `json:"reference_id,omitempty"`
+	MongoAchievementID string `json:"mongo_achievement_id"`
+	Expected           string `json:"expected,omitempty"` // Nilai menurut sumber yang dipercaya
+	Actual             string `json:"actual,omitempty"`   // Nilai yang tersimpan di sisi lain
+	Action             string `json:"action"`
+	Repaired           bool   `json:"repaired"`
+	Error              string `json:"error,omitempty"` // Alasan perbaikan gagal
+}
+
+// ReconcileReport adalah hasil rekonsiliasi PostgreSQL dan MongoDB
+type ReconcileReport struct {
+	Repair            bool                 `json:"repair"`
+	DryRun            bool                 `json:"dry_run"`
+	ScannedReferences int                  `json:"scanned_references"`
+	ScannedDocuments  int                  `json:"scanned_documents"`
+	PendingSync       int                  `json:"pending_sync"` // Dokumen yang masih punya entri outbox, dilewati karena belum selesai ditulis
+	Mismatches        []*ReconcileMismatch `json:"mismatches"`
+	Summary           map[string]int       `json:"summary"` // Jumlah ketidaksesuaian per jenis
+	Repaired          int                  `json:"repaired"`
+	Failed            int                  `json:"failed"`
+}
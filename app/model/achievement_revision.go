@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// RevisionCommentFields adalah field prestasi yang boleh diberi komentar revisi.
+// Komentar untuk field dinamis memakai prefix "details.", misal: details.competition_level
+var RevisionCommentFields = []string{"achievement_type", "title", "description", "details", "tags", "attachments"}
+
+// AchievementRevisionComment adalah komentar dosen untuk satu field prestasi
+type AchievementRevisionComment struct {
+	Field   string `json:"field"`   // Nama field, misal: title atau details.rank
+	Comment string `json:"comment"` // Apa yang perlu diperbaiki mahasiswa
+}
+
+// AchievementRevisionRequest menyimpan satu putaran permintaan revisi dari dosen
+type AchievementRevisionRequest struct {
+	ID              string                       `db:"id" json:"id"`
+	AchievementID   string                       `db:"achievement_id" json:"achievement_id"`
+	Round           int                          `db:"round" json:"round"`
+	Comments        []AchievementRevisionComment `db:"comments" json:"comments"`
+	RequestedBy     string                       `db:"requested_by" json:"requested_by"`
+	RequestedByName string                       `db:"requested_by_name" json:"requested_by_name"`
+	CreatedAt       time.Time                    `db:"created_at" json:"created_at"`
+	ResolvedAt      *time.Time                   `db:"resolved_at" json:"resolved_at"` // Diisi saat mahasiswa submit ulang
+}
+
+// RequestRevisionRequest adalah request dosen untuk meminta revisi prestasi
+type RequestRevisionRequest struct {
+	Comments []AchievementRevisionComment `json:"comments"`
+}
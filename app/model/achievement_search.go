@@ -0,0 +1,411 @@
+package model
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchHighlight adalah potongan teks field yang cocok dengan kata pencarian. Snippet sudah di-escape
+// sebagai HTML dan kata yang cocok dibungkus <mark>.
+type SearchHighlight struct {
+	Field   string `json:"field"` // title, description, tags, atau details.<key>
+	Snippet string `json:"snippet"`
+}
+
+// AchievementSearchResult adalah prestasi hasil pencarian beserta skor relevansinya
+type AchievementSearchResult struct {
+	AchievementWithReference
+	Score      float64            `json:"score"`
+	Highlights []*SearchHighlight `json:"highlights"`
+}
+
+// minStemLength adalah panjang minimal kata dasar agar imbuhan boleh dilepas
+const minStemLength = 3
+
+// maxPrefixLayers adalah jumlah awalan bertumpuk yang dilepas, misalnya di-per-juang-kan
+const maxPrefixLayers = 3
+
+// snippetLength adalah panjang maksimal snippet highlight (dalam rune)
+const snippetLength = 160
+
+// searchStopwords adalah kata umum yang tidak dipakai sebagai kata pencarian karena text index memakai
+// default_language none sehingga MongoDB tidak membuang stopword
+var searchStopwords = map[string]bool{
+	"yang": true, "dan": true, "di": true, "ke": true, "dari": true, "untuk": true, "dengan": true,
+	"pada": true, "dalam": true, "atau": true, "ini": true, "itu": true, "the": true, "of": true, "and": true, "in": true,
+}
+
+var (
+	particleSuffixes   = []string{"lah", "kah", "tah", "pun"}
+	possessiveSuffixes = []string{"nya", "ku", "mu"}
+	derivationSuffixes = []string{"kan", "an", "i"}
+)
+
+// SearchTokens memecah teks menjadi kata-kata huruf kecil tanpa tanda baca
+func SearchTokens(text string) []string {
+	return strings.Fields(NormalizeTitle(text))
+}
+
+// IndonesianStems mengembalikan kata itu sendiri beserta kandidat kata dasarnya dengan melepas akhiran
+// (partikel, kata ganti milik, -kan/-an/-i) lalu awalan (di-, ke-, se-, ber-, ter-, per-, me-, pe-) beserta
+// peluluhan bunyinya. Tanpa kamus kata dasar, awalan yang ambigu (misalnya meng- + vokal) menghasilkan
+// beberapa kandidat; kata yang sama di dokumen dan di query selalu menghasilkan kandidat yang sama.
+func IndonesianStems(word string) []string {
+	word = strings.ToLower(word)
+	seen := map[string]bool{word: true}
+	stems := []string{word}
+	add := func(stem string) bool {
+		if seen[stem] {
+			return false
+		}
+		seen[stem] = true
+		stems = append(stems, stem)
+		return true
+	}
+
+	for _, base := range stripSuffixes(word) {
+		add(base)
+		frontier := []string{base}
+		for layer := 0; layer < maxPrefixLayers && len(frontier) > 0; layer++ {
+			var next []string
+			for _, candidate := range frontier {
+				for _, stem := range removePrefix(candidate, layer == 0) {
+					if add(stem) {
+						next = append(next, stem)
+					}
+				}
+			}
+			frontier = next
+		}
+	}
+	return stems
+}
+
+// stripSuffixes melepas partikel, kata ganti milik, lalu akhiran turunan secara berurutan dan
+// mengembalikan kata beserta setiap bentuk antaranya
+func stripSuffixes(word string) []string {
+	forms := []string{word}
+	current := word
+	for _, group := range [][]string{particleSuffixes, possessiveSuffixes, derivationSuffixes} {
+		for _, suffix := range group {
+			if !strings.HasSuffix(current, suffix) {
+				continue
+			}
+			base := strings.TrimSuffix(current, suffix)
+			// -i setelah s biasanya bagian kata dasar (prestasi, kompetisi)
+			if suffix == "i" && strings.HasSuffix(base, "s") {
+				continue
+			}
+			if utf8.RuneCountInString(base) < minStemLength {
+				continue
+			}
+			current = base
+			forms = append(forms, current)
+			break
+		}
+	}
+	return forms
+}
+
+// removePrefix melepas satu lapis awalan dan mengembalikan kandidat kata dasarnya. di-, ke-, dan se-
+// hanya dilepas pada lapis pertama karena tidak muncul di tengah tumpukan awalan.
+func removePrefix(word string, first bool) []string {
+	var stems []string
+	keep := func(stem string) {
+		if utf8.RuneCountInString(stem) >= minStemLength {
+			stems = append(stems, stem)
+		}
+	}
+
+	if first {
+		for _, prefix := range []string{"di", "ke", "se"} {
+			if strings.HasPrefix(word, prefix) {
+				keep(word[len(prefix):])
+			}
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(word, "belajar"), strings.HasPrefix(word, "pelajar"):
+		keep(word[3:])
+	case strings.HasPrefix(word, "ber"), strings.HasPrefix(word, "ter"), strings.HasPrefix(word, "per"):
+		keep(word[3:])
+	case strings.HasPrefix(word, "me"), strings.HasPrefix(word, "pe"):
+		for _, stem := range nasalStems(word[2:], word[0] == 'p') {
+			keep(stem)
+		}
+	}
+	return stems
+}
+
+// nasalStems mengembalikan kandidat kata dasar setelah awalan me-/pe- dengan peluluhan bunyi:
+// meng-/peng- (ikut, kirim), meny-/peny- (s), mem-/pem- (p), men-/pen- (t)
+func nasalStems(rest string, pe bool) []string {
+	if rest == "" {
+		return nil
+	}
+	switch {
+	case strings.HasPrefix(rest, "ng") && len(rest) > 2:
+		after := rest[2:]
+		if isVowel(after[0]) {
+			return []string{after, "k" + after}
+		}
+		if strings.ContainsRune("ghk", rune(after[0])) {
+			return []string{after}
+		}
+	case strings.HasPrefix(rest, "ny") && len(rest) > 2 && isVowel(rest[2]):
+		return []string{"s" + rest[2:]}
+	case rest[0] == 'm' && len(rest) > 1:
+		after := rest[1:]
+		// memper-: awalan per- setelah me- tidak meluluh
+		if strings.ContainsRune("bfv", rune(after[0])) || strings.HasPrefix(after, "per") {
+			return []string{after}
+		}
+		if isVowel(after[0]) {
+			return []string{"p" + after, rest}
+		}
+	case rest[0] == 'n' && len(rest) > 1:
+		after := rest[1:]
+		if strings.ContainsRune("cdjzs", rune(after[0])) {
+			return []string{after}
+		}
+		if isVowel(after[0]) {
+			return []string{"t" + after, rest}
+		}
+	case strings.ContainsRune("lrwy", rune(rest[0])):
+		return []string{rest}
+	case pe && !isVowel(rest[0]):
+		// pe- tanpa peluluhan: pelatih, pekerja, peserta
+		return []string{rest}
+	}
+	return nil
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aiueo", b) >= 0
+}
+
+// AchievementSearchTerms mengembalikan kata dari nilai teks Details serta kata dasar dari title, description,
+// tags, dan Details yang berbeda dari kata aslinya. Disimpan di dokumen MongoDB sebagai search_terms karena
+// text index tidak mencakup isi Details, dan agar "perlombaan" cocok dengan "lomba" meskipun MongoDB tidak
+// punya stemmer bahasa Indonesia.
+func AchievementSearchTerms(achievement *Achievement) []string {
+	var tokens []string
+	var terms []string
+	present := make(map[string]bool)
+	for _, field := range searchableFields(achievement) {
+		fromDetails := strings.HasPrefix(field.name, "details")
+		for _, token := range SearchTokens(field.text) {
+			if searchStopwords[token] {
+				present[token] = true
+				continue
+			}
+			if fromDetails && !present[token] {
+				terms = append(terms, token)
+			}
+			present[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	// Kata yang sudah muncul apa adanya di dokumen tidak perlu diulang
+	for _, token := range tokens {
+		for _, stem := range IndonesianStems(token)[1:] {
+			if !present[stem] {
+				present[stem] = true
+				terms = append(terms, stem)
+			}
+		}
+	}
+	return terms
+}
+
+// TextSearchQuery menerjemahkan input pengguna menjadi string $search MongoDB. Setiap kata ditambah kandidat
+// kata dasarnya, frasa dalam tanda kutip dan kata berawalan "-" (pengecualian) diteruskan apa adanya.
+// terms berisi kata yang dipakai untuk highlight; kosong jika input tidak punya kata yang dicari.
+func TextSearchQuery(input string) (string, []string) {
+	var parts []string
+	var terms []string
+	seenParts := make(map[string]bool)
+	seenTerms := make(map[string]bool)
+	addTerm := func(term string) {
+		if !seenTerms[term] {
+			seenTerms[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	rest := input
+	for {
+		open := strings.IndexByte(rest, '"')
+		if open < 0 {
+			break
+		}
+		closing := strings.IndexByte(rest[open+1:], '"')
+		if closing < 0 {
+			break
+		}
+		phrase := strings.Join(SearchTokens(rest[open+1:open+1+closing]), " ")
+		if phrase != "" {
+			parts = append(parts, `"`+phrase+`"`)
+			for _, token := range strings.Fields(phrase) {
+				addTerm(token)
+			}
+		}
+		rest = rest[:open] + " " + rest[open+1+closing+1:]
+	}
+
+	for _, field := range strings.Fields(rest) {
+		if strings.HasPrefix(field, "-") {
+			for _, token := range SearchTokens(field) {
+				parts = append(parts, "-"+token)
+			}
+			continue
+		}
+		for _, token := range SearchTokens(field) {
+			if searchStopwords[token] {
+				continue
+			}
+			for _, stem := range IndonesianStems(token) {
+				addTerm(stem)
+				if !seenParts[stem] {
+					seenParts[stem] = true
+					parts = append(parts, stem)
+				}
+			}
+		}
+	}
+
+	return strings.Join(parts, " "), terms
+}
+
+// searchableField adalah teks satu field prestasi yang ikut dicari
+type searchableField struct {
+	name string
+	text string
+}
+
+// searchableFields mengumpulkan field teks prestasi; nilai Details diurutkan berdasarkan key dan hanya
+// string, map, serta slice hasil decode JSON yang dibaca
+func searchableFields(achievement *Achievement) []searchableField {
+	fields := []searchableField{
+		{name: "title", text: achievement.Title},
+		{name: "description", text: achievement.Description},
+		{name: "tags", text: strings.Join(achievement.Tags, ", ")},
+	}
+	return append(fields, detailFields("details", achievement.Details)...)
+}
+
+func detailFields(path string, value interface{}) []searchableField {
+	switch v := value.(type) {
+	case string:
+		return []searchableField{{name: path, text: v}}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var fields []searchableField
+		for _, key := range keys {
+			fields = append(fields, detailFields(path+"."+key, v[key])...)
+		}
+		return fields
+	case []interface{}:
+		var fields []searchableField
+		for _, item := range v {
+			fields = append(fields, detailFields(path, item)...)
+		}
+		return fields
+	}
+	return nil
+}
+
+// HighlightAchievement membuat highlight untuk setiap field yang mengandung salah satu terms. Kata di
+// teks dianggap cocok jika kata itu atau salah satu kata dasarnya ada di terms.
+func HighlightAchievement(achievement *Achievement, terms []string) []*SearchHighlight {
+	termSet := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		termSet[term] = true
+	}
+
+	highlights := []*SearchHighlight{}
+	for _, field := range searchableFields(achievement) {
+		if snippet, ok := HighlightText(field.text, termSet); ok {
+			highlights = append(highlights, &SearchHighlight{Field: field.name, Snippet: snippet})
+		}
+	}
+	return highlights
+}
+
+// HighlightText meng-escape text sebagai HTML dan membungkus kata yang cocok dengan <mark>. Teks yang lebih
+// panjang dari snippetLength dipotong di sekitar kata cocok pertama. ok false jika tidak ada kata yang cocok.
+func HighlightText(text string, terms map[string]bool) (string, bool) {
+	runes := []rune(text)
+
+	// Cari rentang setiap kata (huruf/angka berurutan) yang cocok
+	type span struct{ start, end int }
+	var matches []span
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		for _, stem := range IndonesianStems(string(runes[i:j])) {
+			if terms[stem] {
+				matches = append(matches, span{i, j})
+				break
+			}
+		}
+		i = j
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	start, end := 0, len(runes)
+	if len(runes) > snippetLength {
+		start = max(0, matches[0].start-snippetLength/4)
+		end = min(len(runes), start+snippetLength)
+		start = max(0, end-snippetLength)
+		// Jangan memotong di tengah kata
+		for start > 0 && isWordRune(runes[start-1]) && start < matches[0].start {
+			start++
+		}
+		for end < len(runes) && end > 0 && isWordRune(runes[end-1]) && isWordRune(runes[end]) {
+			end--
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	cursor := start
+	for _, match := range matches {
+		if match.start < start || match.end > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[cursor:match.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[match.start:match.end])))
+		b.WriteString("</mark>")
+		cursor = match.end
+	}
+	b.WriteString(html.EscapeString(string(runes[cursor:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return strings.TrimSpace(b.String()), true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Aksi history yang dicatat oleh scheduler SLA verifikasi
+const (
+	AchievementActionSLAReminder = "sla_reminder"
+	AchievementActionEscalate    = "escalate"
+)
+
+// OverdueSubmission adalah prestasi berstatus submitted yang belum diverifikasi melewati SLA
+type OverdueSubmission struct {
+	AchievementID     string     `json:"achievement_id"`
+	Title             string     `json:"title"`
+	StudentID         string     `json:"student_id"`
+	StudentNumber     string     `json:"nim"`
+	StudentName       string     `json:"student_name"`
+	AdvisorID         string     `json:"advisor_id"`
+	AdvisorUserID     string     `json:"advisor_user_id"`
+	AdvisorName       string     `json:"advisor_name"`
+	AdvisorDepartment string     `json:"advisor_department"`
+	SubmittedAt       time.Time  `json:"submitted_at"`
+	AgeDays           int        `json:"age_days"` // Lama menunggu verifikasi dalam hari penuh
+	SLARemindedAt     *time.Time `json:"sla_reminded_at"`
+	EscalatedAt       *time.Time `json:"escalated_at"`
+}
+
+// SetAge mengisi AgeDays berdasarkan waktu submit
+func (o *OverdueSubmission) SetAge(now time.Time) {
+	o.AgeDays = int(now.Sub(o.SubmittedAt) / (24 * time.Hour))
+}
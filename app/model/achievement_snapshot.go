@@ -0,0 +1,147 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Jenis perubahan field pada diff antar versi prestasi
+const (
+	FieldChangeAdded    = "added"
+	FieldChangeRemoved  = "removed"
+	FieldChangeModified = "modified"
+)
+
+// AchievementSnapshotContent adalah isi prestasi yang disalin dari MongoDB saat disubmit
+type AchievementSnapshotContent struct {
+	AchievementType string                 `json:"achievement_type"`
+	Title           string                 `json:"title"`
+	Description     string                 `json:"description"`
+	Details         map[string]interface{} `json:"details"`
+	Tags            []string               `json:"tags"`
+	SchemaVersion   int                    `json:"schema_version"`
+}
+
+// AchievementSnapshot adalah satu versi isi prestasi. Version dimulai dari 1 dan naik setiap submit.
+type AchievementSnapshot struct {
+	ID            string                     `json:"id"`
+	AchievementID string                     `json:"achievement_id"`
+	Version       int                        `json:"version"`
+	Content       AchievementSnapshotContent `json:"content"`
+	CreatedBy     string                     `json:"created_by"`
+	CreatedAt     time.Time                  `json:"created_at"`
+}
+
+// FieldChange adalah perubahan satu field antara dua versi. Field Details memakai prefix "details.",
+// sama seperti komentar revisi.
+type FieldChange struct {
+	Field   string      `json:"field"`
+	Change  string      `json:"change"` // added, removed, atau modified
+	Old     interface{} `json:"old"`
+	New     interface{} `json:"new"`
+	Added   []string    `json:"added,omitempty"`   // Khusus tags: tag yang ditambahkan
+	Removed []string    `json:"removed,omitempty"` // Khusus tags: tag yang dihapus
+}
+
+// AchievementVersionDiff adalah hasil perbandingan dua versi isi prestasi
+type AchievementVersionDiff struct {
+	From    int            `json:"from"`
+	To      int            `json:"to"`
+	Changes []*FieldChange `json:"changes"`
+}
+
+// NewAchievementSnapshotContent menyalin isi prestasi yang dapat diubah mahasiswa
+func NewAchievementSnapshotContent(achievement *Achievement) AchievementSnapshotContent {
+	details := make(map[string]interface{}, len(achievement.Details))
+	for key, value := range achievement.Details {
+		details[key] = value
+	}
+	return AchievementSnapshotContent{
+		AchievementType: achievement.AchievementType,
+		Title:           achievement.Title,
+		Description:     achievement.Description,
+		Details:         details,
+		Tags:            append([]string{}, achievement.Tags...),
+		SchemaVersion:   achievement.SchemaVersion,
+	}
+}
+
+// DiffSnapshotContent membandingkan dua versi isi prestasi per field. Details dibandingkan per key
+// dan tags dibandingkan sebagai himpunan, sehingga urutan tag tidak dianggap perubahan.
+func DiffSnapshotContent(from, to AchievementSnapshotContent) []*FieldChange {
+	changes := []*FieldChange{}
+	compare := func(field string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			changes = append(changes, &FieldChange{Field: field, Change: FieldChangeModified, Old: old, New: new})
+		}
+	}
+
+	compare("achievement_type", from.AchievementType, to.AchievementType)
+	compare("title", from.Title, to.Title)
+	compare("description", from.Description, to.Description)
+
+	added, removed := diffTags(from.Tags, to.Tags)
+	if len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, &FieldChange{
+			Field:   "tags",
+			Change:  FieldChangeModified,
+			Old:     from.Tags,
+			New:     to.Tags,
+			Added:   added,
+			Removed: removed,
+		})
+	}
+
+	keys := make(map[string]bool)
+	for key := range from.Details {
+		keys[key] = true
+	}
+	for key := range to.Details {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, key := range sortedKeys {
+		old, inFrom := from.Details[key]
+		new, inTo := to.Details[key]
+		field := "details." + key
+		switch {
+		case !inFrom:
+			changes = append(changes, &FieldChange{Field: field, Change: FieldChangeAdded, New: new})
+		case !inTo:
+			changes = append(changes, &FieldChange{Field: field, Change: FieldChangeRemoved, Old: old})
+		default:
+			compare(field, old, new)
+		}
+	}
+
+	compare("schema_version", from.SchemaVersion, to.SchemaVersion)
+	return changes
+}
+
+// diffTags mengembalikan tag yang ditambahkan dan dihapus, sesuai urutan kemunculannya
+func diffTags(from, to []string) (added, removed []string) {
+	inFrom := make(map[string]bool, len(from))
+	for _, tag := range from {
+		inFrom[tag] = true
+	}
+	inTo := make(map[string]bool, len(to))
+	for _, tag := range to {
+		if !inFrom[tag] && !inTo[tag] {
+			added = append(added, tag)
+		}
+		inTo[tag] = true
+	}
+	for _, tag := range from {
+		if !inTo[tag] {
+			removed = append(removed, tag)
+			inTo[tag] = true
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,31 @@
+package model
+
+// Peran anggota dalam prestasi tim
+const (
+	TeamRoleLeader = "leader" // Pembuat prestasi tim, satu-satunya yang boleh mengubah isi prestasi
+	TeamRoleMember = "member"
+)
+
+// MaxTeamMembers adalah jumlah maksimal anggota (termasuk ketua) dalam satu prestasi tim
+const MaxTeamMembers = 20
+
+// TeamMemberRequest adalah anggota tim yang didaftarkan saat membuat prestasi tim
+type TeamMemberRequest struct {
+	StudentID string `json:"student_id"` // NIM anggota
+	Role      string `json:"role"`       // leader atau member, default member
+}
+
+// TeamMember adalah anggota prestasi tim beserta status verifikasi reference miliknya
+type TeamMember struct {
+	ReferenceID   string `json:"reference_id"`
+	StudentID     string `json:"student_id"` // ID student (UUID)
+	StudentNumber string `json:"nim"`
+	FullName      string `json:"full_name"`
+	Role          string `json:"role"`
+	Status        string `json:"status"` // Status verifikasi milik anggota ini, diverifikasi oleh dosen walinya sendiri
+}
+
+// IsValidTeamRole mengecek apakah peran anggota tim valid
+func IsValidTeamRole(role string) bool {
+	return role == TeamRoleLeader || role == TeamRoleMember
+}
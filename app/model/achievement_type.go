@@ -0,0 +1,59 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// AchievementTypeDefinition adalah satu tipe prestasi di katalog yang dikelola admin
+type AchievementTypeDefinition struct {
+	Code               string        `db:"code" json:"code"` // Dipakai sebagai nilai achievement_type, misal: competition
+	NameID             string        `db:"name_id" json:"name_id"`
+	NameEN             string        `db:"name_en" json:"name_en"`
+	Description        string        `db:"description" json:"description"`
+	IsActive           bool          `db:"is_active" json:"is_active"`                     // Tipe yang dipensiunkan tidak bisa dipakai untuk prestasi baru
+	RequiresAttachment bool          `db:"requires_attachment" json:"requires_attachment"` // Sama dengan evidence_rules.min_attachments minimal 1
+	EvidenceRules      EvidenceRules `db:"evidence_rules" json:"evidence_rules"`           // Syarat bukti yang dicek saat submit
+	CreatedAt          time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// CreateAchievementTypeRequest adalah request admin untuk menambah tipe prestasi
+type CreateAchievementTypeRequest struct {
+	Code               string        `json:"code"`
+	NameID             string        `json:"name_id"`
+	NameEN             string        `json:"name_en"`
+	Description        string        `json:"description"`
+	RequiresAttachment bool          `json:"requires_attachment"`
+	EvidenceRules      EvidenceRules `json:"evidence_rules"`
+}
+
+// UpdateAchievementTypeRequest adalah request admin untuk mengubah tipe prestasi
+type UpdateAchievementTypeRequest struct {
+	NameID             *string        `json:"name_id"`
+	NameEN             *string        `json:"name_en"`
+	Description        *string        `json:"description"`
+	IsActive           *bool          `json:"is_active"`
+	RequiresAttachment *bool          `json:"requires_attachment"`
+	EvidenceRules      *EvidenceRules `json:"evidence_rules"` // Menggantikan seluruh aturan bukti jika dikirim
+}
+
+// achievementTypeCodePattern membatasi kode tipe ke huruf kecil, angka, dan underscore
+var achievementTypeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// IsValidAchievementTypeCode mengecek format kode tipe prestasi baru
+func IsValidAchievementTypeCode(code string) bool {
+	return achievementTypeCodePattern.MatchString(code)
+}
+
+// DefaultAchievementTypes mengembalikan tipe prestasi bawaan yang di-seed ke katalog
+func DefaultAchievementTypes() []*AchievementTypeDefinition {
+	return []*AchievementTypeDefinition{
+		{Code: AchievementTypeAcademic, NameID: "Akademik", NameEN: "Academic", IsActive: true},
+		{Code: AchievementTypeCompetition, NameID: "Kompetisi", NameEN: "Competition", IsActive: true},
+		{Code: AchievementTypeOrganization, NameID: "Organisasi", NameEN: "Organization", IsActive: true},
+		{Code: AchievementTypePublication, NameID: "Publikasi", NameEN: "Publication", IsActive: true},
+		{Code: AchievementTypeCertification, NameID: "Sertifikasi", NameEN: "Certification", IsActive: true},
+		{Code: AchievementTypeOther, NameID: "Lainnya", NameEN: "Other", IsActive: true},
+	}
+}
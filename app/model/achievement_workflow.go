@@ -0,0 +1,149 @@
+package model
+
+// Achievement workflow action constants
+const (
+	AchievementActionSubmit          = "submit"
+	AchievementActionVerify          = "verify"
+	AchievementActionApproveStage    = "approve_stage"
+	AchievementActionReject          = "reject"
+	AchievementActionRequestRevision = "request_revision"
+	AchievementActionReopen          = "reopen"
+	AchievementActionDelete          = "delete"
+	AchievementActionRestore         = "restore"       // Dibentuk saat runtime karena status tujuan diambil dari history
+	AchievementActionAdjustPoints    = "adjust_points" // Koreksi poin prestasi terverifikasi, status tidak berubah
+	AchievementActionRevokePoints    = "revoke_points" // Pencabutan poin (menjadi 0), status tidak berubah
+)
+
+// AchievementTransition mendefinisikan satu perpindahan status prestasi yang diizinkan
+type AchievementTransition struct {
+	Action     string   `json:"action"`     // Nama aksi, misal: submit, verify, reject
+	From       []string `json:"from"`       // Status asal yang boleh menjalankan aksi ini
+	To         string   `json:"to"`         // Status tujuan setelah aksi dijalankan
+	Permission string   `json:"permission"` // Permission yang wajib dimiliki user
+	Hooks      []string `json:"hooks"`      // Nama hook yang dijalankan sebelum status diubah
+}
+
+// AchievementTransitionEffects adalah perubahan yang menyertai satu transisi status. Semuanya disimpan dalam
+// transaksi yang sama dengan perubahan status, sehingga tidak ada yang tersimpan jika transisi ditolak karena
+// status atau versi prestasi sudah berubah.
+type AchievementTransitionEffects struct {
+	History                 *AchievementHistory
+	Snapshot                *AchievementSnapshot
+	RevisionRequest         *AchievementRevisionRequest
+	ResolveRevisionRequests bool
+	Approval                *AchievementApproval
+	ResetApprovals          bool
+	DocumentFields          map[string]interface{} // Field dokumen MongoDB yang diubah lewat outbox, misal points
+}
+
+// AchievementWorkflow adalah definisi state machine status prestasi
+type AchievementWorkflow struct {
+	Editable    []string                `json:"editable"` // Status yang isinya masih boleh diedit mahasiswa
+	Transitions []AchievementTransition `json:"transitions"`
+}
+
+// TransitionAchievementRequest adalah request untuk menjalankan aksi workflow secara generik
+type TransitionAchievementRequest struct {
+	Note          string `json:"note"`           // Catatan tambahan untuk history
+	Points        *int   `json:"points"`         // Wajib jika transisi menjalankan hook assign_points
+	Justification string `json:"justification"`  // Wajib jika poin menyimpang dari rubrik (hook check_points_rubric)
+	RejectionNote string `json:"rejection_note"` // Wajib jika transisi menjalankan hook set_rejection_note
+
+	Comments []AchievementRevisionComment `json:"comments"` // Wajib jika transisi menjalankan hook record_revision_request
+}
+
+// DefaultAchievementWorkflow mengembalikan alur bawaan draft -> submitted -> verified/rejected,
+// dengan kemungkinan dikembalikan ke mahasiswa lewat revision_requested
+func DefaultAchievementWorkflow() *AchievementWorkflow {
+	return &AchievementWorkflow{
+		Editable: []string{AchievementStatusDraft, AchievementStatusRevisionRequested},
+		Transitions: []AchievementTransition{
+			{
+				Action:     AchievementActionSubmit,
+				From:       []string{AchievementStatusDraft, AchievementStatusRevisionRequested},
+				To:         AchievementStatusSubmitted,
+				Permission: "achievement:submit",
+				Hooks:      []string{"validate_details", "check_evidence", "flag_duplicates", "snapshot_content", "stamp_submitted", "resolve_revision_requests", "reset_approvals"},
+			},
+			{
+				// Tahap perantara pada approval chain: status tetap submitted sampai tahap terakhir
+				Action:     AchievementActionApproveStage,
+				From:       []string{AchievementStatusSubmitted},
+				To:         AchievementStatusSubmitted,
+				Permission: "achievement:verify",
+				Hooks:      []string{"check_points_rubric", "record_stage_approval"},
+			},
+			{
+				Action:     AchievementActionVerify,
+				From:       []string{AchievementStatusSubmitted},
+				To:         AchievementStatusVerified,
+				Permission: "achievement:verify",
+				Hooks:      []string{"check_points_rubric", "record_stage_approval", "assign_points", "stamp_verifier"},
+			},
+			{
+				Action:     AchievementActionReject,
+				From:       []string{AchievementStatusSubmitted},
+				To:         AchievementStatusRejected,
+				Permission: "achievement:verify",
+				Hooks:      []string{"stamp_verifier", "set_rejection_note"},
+			},
+			{
+				Action:     AchievementActionRequestRevision,
+				From:       []string{AchievementStatusSubmitted},
+				To:         AchievementStatusRevisionRequested,
+				Permission: "achievement:verify",
+				Hooks:      []string{"record_revision_request"},
+			},
+			{
+				Action:     AchievementActionReopen,
+				From:       []string{AchievementStatusRejected},
+				To:         AchievementStatusDraft,
+				Permission: "achievement:update",
+				Hooks:      []string{"count_resubmission"},
+			},
+			{
+				Action:     AchievementActionDelete,
+				From:       []string{AchievementStatusDraft},
+				To:         AchievementStatusDeleted,
+				Permission: "achievement:delete",
+				Hooks:      []string{"stamp_deleted"},
+			},
+		},
+	}
+}
+
+// FindTransition mencari transisi untuk aksi tertentu dari status saat ini
+func (w *AchievementWorkflow) FindTransition(action, fromStatus string) (*AchievementTransition, bool) {
+	for i := range w.Transitions {
+		t := &w.Transitions[i]
+		if t.Action != action {
+			continue
+		}
+		for _, from := range t.From {
+			if from == fromStatus {
+				return t, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// HasAction mengecek apakah aksi terdaftar di workflow, dari status apa pun
+func (w *AchievementWorkflow) HasAction(action string) bool {
+	for _, t := range w.Transitions {
+		if t.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable mengecek apakah prestasi dengan status tertentu masih boleh diedit
+func (w *AchievementWorkflow) IsEditable(status string) bool {
+	for _, s := range w.Editable {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// ApprovalChain mendefinisikan tahapan persetujuan untuk prestasi bernilai tinggi
+type ApprovalChain struct {
+	ID              string          `db:"id" json:"id"`
+	Name            string          `db:"name" json:"name"`
+	AchievementType *string         `db:"achievement_type" json:"achievement_type"` // Kosong berarti berlaku untuk semua tipe
+	MinPoints       int             `db:"min_points" json:"min_points"`             // Berlaku jika poin yang diusulkan >= nilai ini
+	IsActive        bool            `db:"is_active" json:"is_active"`
+	Stages          []ApprovalStage `json:"stages"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+}
+
+// ApprovalStage adalah satu tahap persetujuan dalam approval chain
+type ApprovalStage struct {
+	ID              string   `db:"id" json:"id"`
+	ChainID         string   `db:"chain_id" json:"chain_id"`
+	StageOrder      int      `db:"stage_order" json:"stage_order"` // Dimulai dari 1
+	Name            string   `db:"name" json:"name"`
+	ApproverRole    string   `db:"approver_role" json:"approver_role"`         // Role yang boleh menyetujui tahap ini
+	ApproverUserIDs []string `db:"approver_user_ids" json:"approver_user_ids"` // Kelompok user tertentu, misal anggota komite
+}
+
+// AchievementApproval mencatat persetujuan satu tahap untuk satu prestasi
+type AchievementApproval struct {
+	ID             string    `db:"id" json:"id"`
+	AchievementID  string    `db:"achievement_id" json:"achievement_id"`
+	ChainID        string    `db:"chain_id" json:"chain_id"`
+	StageOrder     int       `db:"stage_order" json:"stage_order"`
+	StageName      string    `db:"stage_name" json:"stage_name"`
+	VerifiedBy     string    `db:"verified_by" json:"verified_by"`
+	VerifiedByName string    `db:"verified_by_name" json:"verified_by_name"`
+	OnBehalfOf     *string   `db:"on_behalf_of" json:"on_behalf_of"`
+	ProposedPoints int       `db:"proposed_points" json:"proposed_points"`
+	VerifiedAt     time.Time `db:"verified_at" json:"verified_at"`
+}
+
+// ApprovalProgress menampilkan posisi prestasi di dalam approval chain
+type ApprovalProgress struct {
+	Chain        *ApprovalChain         `json:"chain"`
+	Approvals    []*AchievementApproval `json:"approvals"`
+	CurrentStage int                    `json:"current_stage"` // 0 jika semua tahap sudah disetujui
+}
+
+// CreateApprovalChainRequest adalah request admin untuk membuat approval chain
+type CreateApprovalChainRequest struct {
+	Name            string                       `json:"name"`
+	AchievementType *string                      `json:"achievement_type"`
+	MinPoints       int                          `json:"min_points"`
+	Stages          []CreateApprovalStageRequest `json:"stages"`
+}
+
+// CreateApprovalStageRequest adalah data satu tahap pada CreateApprovalChainRequest
+type CreateApprovalStageRequest struct {
+	Name            string   `json:"name"`
+	ApproverRole    string   `json:"approver_role"`
+	ApproverUserIDs []string `json:"approver_user_ids"`
+}
+
+// Matches mengecek apakah chain berlaku untuk tipe prestasi dan poin yang diusulkan
+func (c *ApprovalChain) Matches(achievementType string, points int) bool {
+	if !c.IsActive {
+		return false
+	}
+	if c.AchievementType != nil && *c.AchievementType != achievementType {
+		return false
+	}
+	return points >= c.MinPoints
+}
+
+// HasApprover mengecek apakah user termasuk kelompok approver tahap ini
+func (s *ApprovalStage) HasApprover(userID string) bool {
+	for _, id := range s.ApproverUserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanApprove mengecek apakah user dengan role tertentu boleh menyetujui tahap ini
+func (s *ApprovalStage) CanApprove(userID, role string) bool {
+	return s.HasApprover(userID) || (s.ApproverRole != "" && s.ApproverRole == role)
+}
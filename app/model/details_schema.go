@@ -0,0 +1,225 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DetailsSchema adalah subset JSON Schema untuk memvalidasi Achievement.Details
+type DetailsSchema struct {
+	Type                 string                    `json:"type,omitempty"` // object, string, integer, number, boolean, array
+	Title                string                    `json:"title,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Properties           map[string]*DetailsSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *bool                     `json:"additionalProperties,omitempty"` // Default true agar field tambahan tetap diterima
+	Items                *DetailsSchema            `json:"items,omitempty"`
+	Enum                 []interface{}             `json:"enum,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Format               string                    `json:"format,omitempty"` // date (YYYY-MM-DD) atau uri
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+}
+
+// AchievementTypeSchema adalah satu versi schema Details untuk tipe prestasi
+type AchievementTypeSchema struct {
+	AchievementType string         `db:"achievement_type" json:"achievement_type"`
+	Version         int            `db:"version" json:"version"`
+	Schema          *DetailsSchema `db:"schema" json:"schema"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+}
+
+// FieldError adalah kesalahan validasi pada satu field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Check memastikan schema sendiri valid: tipe dikenal dan pattern bisa dikompilasi
+func (s *DetailsSchema) Check() error {
+	switch s.Type {
+	case "", "object", "string", "integer", "number", "boolean", "array":
+	default:
+		return fmt.Errorf("tipe schema tidak dikenal: %s", s.Type)
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("pattern tidak valid: %w", err)
+		}
+	}
+	for name, property := range s.Properties {
+		if property == nil {
+			return fmt.Errorf("property %s tidak boleh kosong", name)
+		}
+		if err := property.Check(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if s.Items != nil {
+		return s.Items.Check()
+	}
+	return nil
+}
+
+// ValidateDetails memvalidasi Details prestasi dan mengembalikan error per field (path diawali "details.")
+func (s *DetailsSchema) ValidateDetails(details map[string]interface{}) []FieldError {
+	var value interface{} = details
+	if details == nil {
+		value = map[string]interface{}{}
+	}
+	return s.validate("details", value)
+}
+
+func (s *DetailsSchema) validate(path string, value interface{}) []FieldError {
+	if value == nil {
+		return []FieldError{{Field: path, Message: "tidak boleh kosong"}}
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []FieldError{{Field: path, Message: "harus berupa object"}}
+		}
+		return s.validateObject(path, object)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []FieldError{{Field: path, Message: "harus berupa array"}}
+		}
+		var errs []FieldError
+		if s.Items != nil {
+			for i, item := range items {
+				errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+		return errs
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []FieldError{{Field: path, Message: "harus berupa teks"}}
+		}
+		if msg := s.validateString(str); msg != "" {
+			return []FieldError{{Field: path, Message: msg}}
+		}
+	case "integer", "number":
+		number, ok := toFloat(value)
+		if !ok || (s.Type == "integer" && number != float64(int64(number))) {
+			return []FieldError{{Field: path, Message: "harus berupa " + map[string]string{"integer": "bilangan bulat", "number": "angka"}[s.Type]}}
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			return []FieldError{{Field: path, Message: fmt.Sprintf("minimal %v", *s.Minimum)}}
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			return []FieldError{{Field: path, Message: fmt.Sprintf("maksimal %v", *s.Maximum)}}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []FieldError{{Field: path, Message: "harus berupa true/false"}}
+		}
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return []FieldError{{Field: path, Message: "harus salah satu dari: " + s.enumString()}}
+	}
+	return nil
+}
+
+func (s *DetailsSchema) validateObject(path string, object map[string]interface{}) []FieldError {
+	var errs []FieldError
+	for _, name := range s.Required {
+		if value, ok := object[name]; !ok || value == nil || value == "" {
+			errs = append(errs, FieldError{Field: path + "." + name, Message: "wajib diisi"})
+		}
+	}
+
+	// Urutkan key agar urutan error stabil
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := object[key]
+		property, known := s.Properties[key]
+		if !known {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				errs = append(errs, FieldError{Field: path + "." + key, Message: "field tidak dikenal"})
+			}
+			continue
+		}
+		// Field opsional yang dikosongkan tidak divalidasi lebih lanjut
+		if value == nil || value == "" {
+			continue
+		}
+		errs = append(errs, property.validate(path+"."+key, value)...)
+	}
+	return errs
+}
+
+func (s *DetailsSchema) validateString(str string) string {
+	length := len([]rune(str))
+	if s.MinLength != nil && length < *s.MinLength {
+		return fmt.Sprintf("minimal %d karakter", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return fmt.Sprintf("maksimal %d karakter", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+			return "format tidak sesuai"
+		}
+	}
+	switch s.Format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", str); err != nil {
+			return "harus berupa tanggal dengan format YYYY-MM-DD"
+		}
+	case "uri":
+		if u, err := url.ParseRequestURI(str); err != nil || u.Scheme == "" || u.Host == "" {
+			return "harus berupa URL yang valid"
+		}
+	}
+	return ""
+}
+
+func (s *DetailsSchema) inEnum(value interface{}) bool {
+	for _, option := range s.Enum {
+		if fmt.Sprint(option) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DetailsSchema) enumString() string {
+	options := make([]string, len(s.Enum))
+	for i, option := range s.Enum {
+		options[i] = fmt.Sprint(option)
+	}
+	return strings.Join(options, ", ")
+}
+
+// toFloat menerima angka dari JSON (float64) maupun dari kode Go (int)
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
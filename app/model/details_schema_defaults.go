@@ -0,0 +1,84 @@
+package model
+
+import "encoding/json"
+
+// defaultDetailsSchemas adalah schema Details versi 1 untuk setiap tipe prestasi bawaan
+var defaultDetailsSchemas = map[string]string{
+	AchievementTypeAcademic: `{
+		"type": "object",
+		"properties": {
+			"institution": {"type": "string", "title": "Institusi pemberi"},
+			"level": {"type": "string", "title": "Tingkat", "enum": ["international", "national", "regional", "local"]},
+			"date": {"type": "string", "title": "Tanggal", "format": "date"}
+		}
+	}`,
+	AchievementTypeCompetition: `{
+		"type": "object",
+		"required": ["competition_name", "level", "rank"],
+		"properties": {
+			"competition_name": {"type": "string", "title": "Nama kompetisi", "minLength": 3},
+			"organizer": {"type": "string", "title": "Penyelenggara"},
+			"level": {"type": "string", "title": "Tingkat", "enum": ["international", "national", "regional", "local"]},
+			"rank": {"type": "integer", "title": "Peringkat", "minimum": 1},
+			"participants": {"type": "integer", "title": "Jumlah peserta", "minimum": 1},
+			"date": {"type": "string", "title": "Tanggal", "format": "date"}
+		}
+	}`,
+	AchievementTypeOrganization: `{
+		"type": "object",
+		"required": ["organization_name", "role"],
+		"properties": {
+			"organization_name": {"type": "string", "title": "Nama organisasi"},
+			"role": {"type": "string", "title": "Jabatan"},
+			"level": {"type": "string", "title": "Tingkat", "enum": ["international", "national", "regional", "local"]},
+			"period_start": {"type": "string", "title": "Mulai menjabat", "format": "date"},
+			"period_end": {"type": "string", "title": "Selesai menjabat", "format": "date"}
+		}
+	}`,
+	AchievementTypePublication: `{
+		"type": "object",
+		"required": ["venue", "doi"],
+		"properties": {
+			"venue": {"type": "string", "title": "Jurnal/konferensi"},
+			"doi": {"type": "string", "title": "DOI", "pattern": "^10\\.\\d{4,9}/\\S+$"},
+			"publication_type": {"type": "string", "title": "Jenis publikasi", "enum": ["journal", "conference", "book"]},
+			"indexing": {"type": "string", "title": "Indeksasi", "enum": ["scopus", "wos", "sinta", "other", "none"]},
+			"authors": {"type": "array", "title": "Penulis", "items": {"type": "string"}},
+			"url": {"type": "string", "title": "Tautan", "format": "uri"}
+		}
+	}`,
+	AchievementTypeCertification: `{
+		"type": "object",
+		"required": ["certification_name", "issuer"],
+		"properties": {
+			"certification_name": {"type": "string", "title": "Nama sertifikasi"},
+			"issuer": {"type": "string", "title": "Lembaga penerbit"},
+			"certificate_number": {"type": "string", "title": "Nomor sertifikat"},
+			"issued_date": {"type": "string", "title": "Tanggal terbit", "format": "date"},
+			"expiry_date": {"type": "string", "title": "Tanggal kedaluwarsa", "format": "date"}
+		}
+	}`,
+	AchievementTypeOther: `{
+		"type": "object"
+	}`,
+}
+
+// DefaultDetailsSchema mengembalikan schema Details bawaan (versi 1) untuk tipe prestasi,
+// atau nil jika tipe tidak memiliki schema bawaan
+func DefaultDetailsSchema(achievementType string) *AchievementTypeSchema {
+	raw, ok := defaultDetailsSchemas[achievementType]
+	if !ok {
+		return nil
+	}
+
+	var schema DetailsSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		panic("schema bawaan " + achievementType + " tidak valid: " + err.Error())
+	}
+
+	return &AchievementTypeSchema{
+		AchievementType: achievementType,
+		Version:         1,
+		Schema:          &schema,
+	}
+}
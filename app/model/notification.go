@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Notification type constants
+const (
+	NotificationTypeMention       = "mention"
+	NotificationTypeCommentReply  = "comment_reply"
+	NotificationTypeSLAReminder   = "sla_reminder"   // Prestasi bimbingan menunggu verifikasi melewati SLA
+	NotificationTypeSLAEscalated  = "sla_escalation" // Prestasi dieskalasi ke reviewer departemen
+	NotificationTypeExpiryWarning = "expiry_warning" // Masa berlaku prestasi (misalnya sertifikasi) segera habis
+)
+
+// Notification adalah pemberitahuan untuk user, misalnya saat di-mention pada komentar
+type Notification struct {
+	ID            string    `db:"id" json:"id"`
+	UserID        string    `db:"user_id" json:"user_id"`
+	Type          string    `db:"type" json:"type"`
+	Message       string    `db:"message" json:"message"`
+	AchievementID *string   `db:"achievement_id" json:"achievement_id"`
+	IsRead        bool      `db:"is_read" json:"is_read"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
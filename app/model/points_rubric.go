@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PointsRubricRule memetakan tipe prestasi dan atribut Details ke poin yang direkomendasikan
+type PointsRubricRule struct {
+	ID              string            `db:"id" json:"id"`
+	AchievementType string            `db:"achievement_type" json:"achievement_type"`
+	Conditions      map[string]string `db:"conditions" json:"conditions"` // Atribut Details yang harus cocok, misal: {"level": "national", "rank": "1"}
+	Points          int               `db:"points" json:"points"`
+	Description     string            `db:"description" json:"description"`
+	IsActive        bool              `db:"is_active" json:"is_active"`
+	CreatedAt       time.Time         `db:"created_at" json:"created_at"`
+}
+
+// PointsSuggestion adalah rekomendasi poin dari rubrik untuk satu prestasi
+type PointsSuggestion struct {
+	RuleID      string            `json:"rule_id"`
+	Points      int               `json:"points"`
+	Tolerance   int               `json:"tolerance"` // Selisih poin yang masih boleh tanpa justifikasi
+	Description string            `json:"description"`
+	Matched     map[string]string `json:"matched"`
+}
+
+// CreatePointsRubricRuleRequest adalah request admin untuk menambah aturan rubrik poin
+type CreatePointsRubricRuleRequest struct {
+	AchievementType string            `json:"achievement_type"`
+	Conditions      map[string]string `json:"conditions"`
+	Points          int               `json:"points"`
+	Description     string            `json:"description"`
+}
+
+// Matches mengecek apakah aturan berlaku untuk prestasi; nilai atribut dibandingkan tanpa membedakan huruf besar/kecil
+func (r *PointsRubricRule) Matches(achievement *Achievement) bool {
+	if !r.IsActive || r.AchievementType != achievement.AchievementType {
+		return false
+	}
+	for key, expected := range r.Conditions {
+		value, ok := achievement.Details[key]
+		if !ok || value == nil {
+			return false
+		}
+		if !strings.EqualFold(strings.TrimSpace(fmt.Sprint(value)), strings.TrimSpace(expected)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Deviates mengecek apakah poin yang diberikan berada di luar toleransi rubrik
+func (s *PointsSuggestion) Deviates(points int) bool {
+	diff := points - s.Points
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > s.Tolerance
+}
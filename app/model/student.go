@@ -50,4 +50,5 @@ type StudentStats struct {
 	Name              string `json:"name"`
 	TotalAchievements int    `json:"total_achievements"`
 	TotalPoints       int    `json:"total_points"`
+	TeamAchievements  int    `json:"team_achievements"` // Bagian dari TotalAchievements yang merupakan prestasi tim
 }
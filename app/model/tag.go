@@ -0,0 +1,194 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tag adalah tag kanonik di katalog. Prestasi menyimpan Name; alias dan variasi penulisan lain
+// dinormalisasi ke Name saat prestasi dibuat atau diubah.
+type Tag struct {
+	Slug      string    `db:"slug" json:"slug"` // Kunci tetap, tidak berubah meskipun nama diganti
+	Name      string    `db:"name" json:"name"` // Nama kanonik yang disimpan di prestasi
+	Aliases   []string  `json:"aliases"`        // Sinonim, singkatan, atau terjemahan yang dipetakan ke tag ini
+	Usage     int       `json:"usage"`          // Jumlah prestasi yang memakai tag, diisi di daftar dan autocomplete
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateTagRequest adalah request untuk menambah tag ke katalog
+type CreateTagRequest struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+// UpdateTagRequest adalah request untuk mengganti nama atau alias tag; aliases menggantikan seluruh alias lama
+type UpdateTagRequest struct {
+	Name    *string   `json:"name"`
+	Aliases *[]string `json:"aliases"`
+}
+
+// MergeTagsRequest menggabungkan tag lain ke tag tujuan. Sources boleh berisi slug tag di katalog maupun
+// tag bebas yang belum ada di katalog; semuanya menjadi alias tag tujuan.
+type MergeTagsRequest struct {
+	Sources []string `json:"sources"`
+}
+
+// TagMergeResult adalah hasil penggabungan tag
+type TagMergeResult struct {
+	Tag                 *Tag     `json:"tag"`
+	MergedTags          []string `json:"merged_tags"`          // Slug tag katalog yang dihapus karena digabung
+	UpdatedAchievements int      `json:"updated_achievements"` // Jumlah prestasi yang tag-nya ditulis ulang
+}
+
+// TagStat adalah statistik prestasi untuk satu tag
+type TagStat struct {
+	Tag            string         `json:"tag"`
+	Slug           string         `json:"slug,omitempty"` // Kosong jika tag belum ada di katalog
+	Total          int            `json:"total"`
+	ByStatus       map[string]int `json:"by_status"`
+	VerifiedPoints int            `json:"verified_points"`
+}
+
+// Add menambahkan statistik lain (misalnya variasi penulisan tag yang sama) ke statistik ini
+func (s *TagStat) Add(other *TagStat) {
+	if s.ByStatus == nil {
+		s.ByStatus = map[string]int{}
+	}
+	s.Total += other.Total
+	s.VerifiedPoints += other.VerifiedPoints
+	for status, count := range other.ByStatus {
+		s.ByStatus[status] += count
+	}
+}
+
+var (
+	tagSpaces      = regexp.MustCompile(`\s+`)
+	tagSlugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// CleanTag merapikan penulisan tag: spasi dan tanda # di awal/akhir dihapus, spasi ganda disatukan
+func CleanTag(tag string) string {
+	return strings.Trim(tagSpaces.ReplaceAllString(strings.TrimSpace(tag), " "), " #")
+}
+
+// TagKey adalah bentuk pembanding tag: huruf kecil tanpa perbedaan spasi, sehingga "AI", "ai" dan " Ai " sama
+func TagKey(tag string) string {
+	return strings.ToLower(CleanTag(tag))
+}
+
+// TagSlug membuat slug dari nama tag, misal "Artificial Intelligence" menjadi "artificial-intelligence"
+func TagSlug(name string) string {
+	return strings.Trim(tagSlugInvalid.ReplaceAllString(TagKey(name), "-"), "-")
+}
+
+// TagIndex memetakan nama, slug, dan alias ke tag kanonik di katalog
+type TagIndex struct {
+	tags  []*Tag
+	byKey map[string]*Tag
+}
+
+// NewTagIndex membangun index dari katalog tag
+func NewTagIndex(tags []*Tag) *TagIndex {
+	index := &TagIndex{tags: tags, byKey: make(map[string]*Tag)}
+	for _, tag := range tags {
+		index.byKey[TagKey(tag.Name)] = tag
+		index.byKey[TagKey(tag.Slug)] = tag
+		for _, alias := range tag.Aliases {
+			index.byKey[TagKey(alias)] = tag
+		}
+	}
+	return index
+}
+
+// Resolve mencari tag kanonik untuk satu tag; nil jika tag tidak ada di katalog
+func (i *TagIndex) Resolve(tag string) *Tag {
+	return i.byKey[TagKey(tag)]
+}
+
+// Normalize mengganti setiap tag dengan nama kanoniknya, merapikan tag yang belum ada di katalog, dan
+// menghapus duplikat (tanpa membedakan huruf besar/kecil) dengan tetap menjaga urutan
+func (i *TagIndex) Normalize(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		name := CleanTag(tag)
+		if canonical := i.Resolve(name); canonical != nil {
+			name = canonical.Name
+		}
+		if name == "" || seen[TagKey(name)] {
+			continue
+		}
+		seen[TagKey(name)] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// TagSuggestion adalah hasil autocomplete tag
+type TagSuggestion struct {
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	MatchedBy string `json:"matched_by,omitempty"` // Alias yang cocok dengan query, kosong jika yang cocok nama tag
+	Usage     int    `json:"usage"`
+}
+
+// Suggest mencari tag yang nama atau aliasnya mengandung query. Hasil diurutkan: cocok persis, awalan,
+// lalu mengandung query; di dalam tiap kelompok tag yang paling sering dipakai didahulukan.
+func (i *TagIndex) Suggest(query string, limit int) []*TagSuggestion {
+	key := TagKey(query)
+	type ranked struct {
+		suggestion *TagSuggestion
+		rank       int
+	}
+	var matches []ranked
+	for _, tag := range i.tags {
+		bestRank, matchedBy := -1, ""
+		candidates := append([]string{tag.Name}, tag.Aliases...)
+		for n, candidate := range candidates {
+			candidateKey := TagKey(candidate)
+			rank := -1
+			switch {
+			case key == "" || candidateKey == key:
+				rank = 0
+			case strings.HasPrefix(candidateKey, key):
+				rank = 1
+			case strings.Contains(candidateKey, key):
+				rank = 2
+			}
+			if rank >= 0 && (bestRank < 0 || rank < bestRank) {
+				bestRank, matchedBy = rank, ""
+				if n > 0 {
+					matchedBy = candidate
+				}
+			}
+		}
+		if bestRank >= 0 {
+			matches = append(matches, ranked{
+				suggestion: &TagSuggestion{Slug: tag.Slug, Name: tag.Name, MatchedBy: matchedBy, Usage: tag.Usage},
+				rank:       bestRank,
+			})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].rank != matches[b].rank {
+			return matches[a].rank < matches[b].rank
+		}
+		if matches[a].suggestion.Usage != matches[b].suggestion.Usage {
+			return matches[a].suggestion.Usage > matches[b].suggestion.Usage
+		}
+		return matches[a].suggestion.Name < matches[b].suggestion.Name
+	})
+
+	suggestions := []*TagSuggestion{}
+	for _, match := range matches {
+		if limit > 0 && len(suggestions) == limit {
+			break
+		}
+		suggestions = append(suggestions, match.suggestion)
+	}
+	return suggestions
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// VerificationDelegation menyimpan pelimpahan wewenang verifikasi dari dosen wali ke dosen lain
+type VerificationDelegation struct {
+	ID          string     `db:"id" json:"id"`
+	DelegatorID string     `db:"delegator_id" json:"delegator_id"` // Lecturer ID dosen wali yang melimpahkan
+	DelegateID  string     `db:"delegate_id" json:"delegate_id"`   // Lecturer ID dosen penerima pelimpahan
+	StartDate   time.Time  `db:"start_date" json:"start_date"`     // Tanggal mulai berlaku (inklusif)
+	EndDate     time.Time  `db:"end_date" json:"end_date"`         // Tanggal akhir berlaku (inklusif)
+	Reason      string     `db:"reason" json:"reason"`
+	CreatedBy   string     `db:"created_by" json:"created_by"` // User ID pembuat pelimpahan
+	RevokedAt   *time.Time `db:"revoked_at" json:"revoked_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// IsActiveAt mengecek apakah pelimpahan berlaku pada waktu tertentu
+func (d *VerificationDelegation) IsActiveAt(t time.Time) bool {
+	if d.RevokedAt != nil {
+		return false
+	}
+	return !t.Before(d.StartDate) && t.Before(d.EndDate.AddDate(0, 0, 1))
+}
+
+// CreateDelegationRequest adalah request untuk membuat pelimpahan wewenang verifikasi
+type CreateDelegationRequest struct {
+	DelegateID string `json:"delegate_id"` // Lecturer ID dosen penerima pelimpahan
+	StartDate  string `json:"start_date"`  // Format YYYY-MM-DD
+	EndDate    string `json:"end_date"`    // Format YYYY-MM-DD
+	Reason     string `json:"reason"`
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"uas_be/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// documentBatchSize adalah jumlah _id maksimal dalam satu query $in, agar statistik atas puluhan ribu
+// prestasi tidak menghasilkan satu query yang terlalu besar
+const documentBatchSize = 1000
+
+// AchievementDocumentFinder membaca banyak dokumen prestasi sekaligus
+type AchievementDocumentFinder interface {
+	// FindAchievementDocuments mengambil dokumen berdasarkan ID MongoDB (hex) dengan filter tambahan opsional.
+	// Dokumen yang tidak ditemukan, tidak cocok dengan filter, atau ID-nya tidak valid tidak ada di hasil.
+	FindAchievementDocuments(mongoIDs []string, filter bson.M) (map[string]model.Achievement, error)
+}
+
+// JoinAchievementDocuments menggabungkan reference dengan dokumen MongoDB-nya dalam satu kali baca per
+// batch, dengan urutan sama seperti refs. Reference yang dokumennya tidak ditemukan dilewati.
+func JoinAchievementDocuments(finder AchievementDocumentFinder, refs []*model.AchievementReference, filter bson.M) ([]*model.AchievementWithReference, error) {
+	mongoIDs := make([]string, len(refs))
+	for i, ref := range refs {
+		mongoIDs[i] = ref.MongoAchievementID
+	}
+
+	documents, err := finder.FindAchievementDocuments(mongoIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*model.AchievementWithReference
+	for _, ref := range refs {
+		achievement, ok := documents[ref.MongoAchievementID]
+		if !ok {
+			continue
+		}
+		results = append(results, newAchievementWithReference(ref, achievement))
+	}
+	return results, nil
+}
+
+// scanReferences membaca semua baris achievement_references; baris yang gagal dibaca dilewati
+func scanReferences(rows *sql.Rows) ([]*model.AchievementReference, error) {
+	var refs []*model.AchievementReference
+	for rows.Next() {
+		ref, err := scanReference(rows)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+func (s *mongoDocumentStore) FindAchievementDocuments(mongoIDs []string, filter bson.M) (map[string]model.Achievement, error) {
+	if s.collection == nil {
+		return nil, errors.New("MongoDB belum terhubung")
+	}
+	ctx := context.Background()
+
+	// ID dibuat unik karena anggota prestasi tim berbagi satu dokumen
+	seen := make(map[string]bool, len(mongoIDs))
+	objIDs := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, mongoID := range mongoIDs {
+		if seen[mongoID] {
+			continue
+		}
+		seen[mongoID] = true
+		if objID, err := primitive.ObjectIDFromHex(mongoID); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+
+	documents := make(map[string]model.Achievement, len(objIDs))
+	for start := 0; start < len(objIDs); start += documentBatchSize {
+		query := bson.M{"_id": bson.M{"$in": objIDs[start:min(start+documentBatchSize, len(objIDs))]}}
+		for key, value := range filter {
+			query[key] = value
+		}
+
+		cursor, err := s.collection.Find(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for cursor.Next(ctx) {
+			objID, ok := cursor.Current.Lookup("_id").ObjectIDOK()
+			if !ok {
+				continue
+			}
+			var achievement model.Achievement
+			if err := cursor.Decode(&achievement); err != nil {
+				continue
+			}
+			documents[objID.Hex()] = achievement
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return documents, nil
+}
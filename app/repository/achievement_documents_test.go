@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"uas_be/app/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// documentFixture membuat n reference beserta dokumennya; setiap dokumen ke-7 hilang dari MongoDB
+func documentFixture(tb testing.TB, n int) (*MockAchievementDocumentStore, []*model.AchievementReference) {
+	documents := NewMockAchievementDocumentStore()
+	refs := make([]*model.AchievementReference, n)
+	for i := range refs {
+		mongoID := primitive.NewObjectID().Hex()
+		refs[i] = &model.AchievementReference{ID: fmt.Sprintf("ref-%d", i), StudentID: "student", MongoAchievementID: mongoID}
+		if i%7 == 6 {
+			continue
+		}
+		achievementType := "academic"
+		if i%2 == 1 {
+			achievementType = "competition"
+		}
+		payload, err := bson.Marshal(&model.Achievement{AchievementType: achievementType, Title: refs[i].ID, Points: i})
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if err := documents.ReplaceAchievementDocument(mongoID, payload); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return documents, refs
+}
+
+// TestJoinAchievementDocuments_SingleRoundTrip tests a page of references is joined with one query, in reference order, skipping missing documents
+func TestJoinAchievementDocuments_SingleRoundTrip(t *testing.T) {
+	// Arrange
+	documents, refs := documentFixture(t, 100)
+	// Anggota prestasi tim berbagi dokumen yang sama
+	refs = append(refs, &model.AchievementReference{ID: "team-member", StudentID: "member", MongoAchievementID: refs[0].MongoAchievementID})
+
+	// Act
+	results, err := JoinAchievementDocuments(documents, refs, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, documents.RoundTrips)
+	assert.Len(t, results, 100-100/7+1)
+	previous := -1
+	for _, result := range results[:len(results)-1] {
+		assert.Equal(t, result.ReferenceID, result.Title)
+		assert.Greater(t, result.Points, previous)
+		previous = result.Points
+	}
+	member := results[len(results)-1]
+	assert.Equal(t, "team-member", member.ReferenceID)
+	assert.Equal(t, "member", member.StudentID)
+	assert.Equal(t, "ref-0", member.Title)
+}
+
+// TestJoinAchievementDocuments_Filter tests the extra MongoDB filter (achievement type) is applied to the batch
+func TestJoinAchievementDocuments_Filter(t *testing.T) {
+	// Arrange
+	documents, refs := documentFixture(t, 20)
+
+	// Act
+	results, err := JoinAchievementDocuments(documents, refs, bson.M{"achievement_type": "competition"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, documents.RoundTrips)
+	assert.NotEmpty(t, results)
+	for _, result := range results {
+		assert.Equal(t, "competition", result.AchievementType)
+	}
+}
+
+// benchmarkDocumentLatency mensimulasikan jeda jaringan satu query ke MongoDB
+const benchmarkDocumentLatency = 20 * time.Microsecond
+
+// BenchmarkAchievementDocuments_PerRow mengukur pola lama: satu FindOne per baris PostgreSQL
+func BenchmarkAchievementDocuments_PerRow(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("rows=%d", n), func(b *testing.B) {
+			documents, refs := documentFixture(b, n)
+			documents.RoundTripLatency = benchmarkDocumentLatency
+			documents.RoundTrips = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var results []*model.AchievementWithReference
+				for _, ref := range refs {
+					found, _ := documents.FindAchievementDocuments([]string{ref.MongoAchievementID}, nil)
+					if achievement, ok := found[ref.MongoAchievementID]; ok {
+						results = append(results, &model.AchievementWithReference{Achievement: achievement, ReferenceID: ref.ID})
+					}
+				}
+				_ = results
+			}
+			b.ReportMetric(float64(documents.RoundTrips)/float64(b.N), "roundtrips/op")
+		})
+	}
+}
+
+// fixedRowsConnector adalah driver database/sql palsu yang menjawab query ke-n dengan results[n] dan query
+// sesudahnya dengan hasil terakhir, sehingga method repository bisa dijalankan tanpa PostgreSQL
+type fixedRowsConnector struct {
+	results []fixedResult
+	queries int
+}
+
+// fixedResult adalah baris hasil satu query beserta nama kolomnya
+type fixedResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (c *fixedRowsConnector) Connect(context.Context) (driver.Conn, error) { return c.Open("") }
+func (c *fixedRowsConnector) Driver() driver.Driver                        { return c }
+func (c *fixedRowsConnector) Open(string) (driver.Conn, error)             { return &fixedRowsConn{c}, nil }
+
+type fixedRowsConn struct{ connector *fixedRowsConnector }
+
+func (c *fixedRowsConn) Prepare(string) (driver.Stmt, error) { return &fixedRowsStmt{c.connector}, nil }
+func (c *fixedRowsConn) Close() error                        { return nil }
+func (c *fixedRowsConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transaksi tidak didukung")
+}
+
+type fixedRowsStmt struct{ connector *fixedRowsConnector }
+
+func (s *fixedRowsStmt) Close() error  { return nil }
+func (s *fixedRowsStmt) NumInput() int { return -1 }
+func (s *fixedRowsStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("penulisan tidak didukung")
+}
+func (s *fixedRowsStmt) Query([]driver.Value) (driver.Rows, error) {
+	c := s.connector
+	result := c.results[min(c.queries, len(c.results)-1)]
+	c.queries++
+	return &fixedRows{result: result}, nil
+}
+
+type fixedRows struct {
+	result fixedResult
+	next   int
+}
+
+func (r *fixedRows) Columns() []string { return r.result.columns }
+func (r *fixedRows) Close() error      { return nil }
+func (r *fixedRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+// fixedRowsRepository membuat repository achievement yang query PostgreSQL-nya dijawab berurutan oleh results
+// dan membaca, mencari, serta menulis dokumen lewat outbox ke documents
+func fixedRowsRepository(documents *MockAchievementDocumentStore, results ...fixedResult) *achievementRepositoryImpl {
+	return &achievementRepositoryImpl{
+		db:        sql.OpenDB(&fixedRowsConnector{results: results}),
+		documents: documents,
+		searcher:  documents,
+		outbox:    NewAchievementOutbox(NewMockAchievementOutboxStore(), documents),
+	}
+}
+
+// referenceResult mengubah refs menjadi hasil query achievement_references dengan kolom yang sama seperti
+// referenceColumns. Kolom yang tidak diisi di sini bernilai NULL, sehingga kolom nullable baru tidak perlu
+// ditambahkan; kolom yang diisi tetapi tidak ada lagi di referenceColumns membuat test gagal.
+func referenceResult(tb testing.TB, refs []*model.AchievementReference) fixedResult {
+	columns := strings.Split(referenceColumns, ",")
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		columns[i] = strings.TrimSpace(column)
+		index[columns[i]] = i
+	}
+
+	now := time.Now()
+	rows := make([][]driver.Value, len(refs))
+	for i, ref := range refs {
+		values := map[string]driver.Value{
+			"id":                   ref.ID,
+			"student_id":           ref.StudentID,
+			"mongo_achievement_id": ref.MongoAchievementID,
+			"achievement_title":    ref.ID,
+			"status":               model.AchievementStatusVerified,
+			"resubmission_count":   int64(0),
+			"version":              int64(1),
+			"possible_duplicate":   false,
+			"created_at":           now,
+			"updated_at":           now,
+		}
+		rows[i] = make([]driver.Value, len(columns))
+		for column, value := range values {
+			position, ok := index[column]
+			if !ok {
+				tb.Fatalf("kolom %s tidak ada di referenceColumns", column)
+			}
+			rows[i][position] = value
+		}
+	}
+	return fixedResult{columns: columns, rows: rows}
+}
+
+// TestGetAchievementsByStudentID_BatchedRoundTrips tests the repository listing reads documents in batches of at most 1000 IDs
+func TestGetAchievementsByStudentID_BatchedRoundTrips(t *testing.T) {
+	// Arrange
+	documents, refs := documentFixture(t, 2500)
+	repo := fixedRowsRepository(documents, referenceResult(t, refs))
+
+	// Act
+	results, err := repo.GetAchievementsByStudentID("student")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, documents.RoundTrips)
+	assert.Len(t, results, 2500-2500/7)
+}
+
+// BenchmarkGetAchievementsByStudentID mengukur daftar prestasi lewat repository: dokumen dibaca per batch documentBatchSize
+func BenchmarkGetAchievementsByStudentID(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("rows=%d", n), func(b *testing.B) {
+			documents, refs := documentFixture(b, n)
+			repo := fixedRowsRepository(documents, referenceResult(b, refs))
+			documents.RoundTripLatency = benchmarkDocumentLatency
+			documents.RoundTrips = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				results, err := repo.GetAchievementsByStudentID("student")
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(results) != n-n/7 {
+					b.Fatalf("expected %d achievements, got %d", n-n/7, len(results))
+				}
+			}
+			b.ReportMetric(float64(documents.RoundTrips)/float64(b.N), "roundtrips/op")
+		})
+	}
+}
+
+// BenchmarkGetTopStudents mengukur statistik top students lewat repository untuk reference lama yang poinnya masih di dokumen
+func BenchmarkGetTopStudents(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("rows=%d", n), func(b *testing.B) {
+			documents, refs := documentFixture(b, n)
+			rows := make([][]driver.Value, len(refs))
+			for i, ref := range refs {
+				studentID := fmt.Sprintf("student-%d", i%50)
+				rows[i] = []driver.Value{studentID, studentID, "Mahasiswa", ref.MongoAchievementID, false, nil}
+			}
+			columns := []string{"student_id", "student_number", "full_name", "mongo_achievement_id", "is_team", "points"}
+			repo := fixedRowsRepository(documents, fixedResult{columns, rows})
+			documents.RoundTripLatency = benchmarkDocumentLatency
+			documents.RoundTrips = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetTopStudents(10, true); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(documents.RoundTrips)/float64(b.N), "roundtrips/op")
+		})
+	}
+}
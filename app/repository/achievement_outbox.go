@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"uas_be/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AchievementOutboxStore menyimpan entri outbox yang belum diterapkan ke MongoDB
+type AchievementOutboxStore interface {
+	// DueOutboxDocuments mengambil ID dokumen MongoDB yang entri pending tertuanya dijadwalkan <= now
+	DueOutboxDocuments(now time.Time, limit int) ([]string, error)
+	// WithOutboxLock menjalankan fn dengan entri satu dokumen terkunci sehingga hanya satu proses yang
+	// menerapkannya. Perubahan lewat tx disimpan jika fn tidak mengembalikan error.
+	WithOutboxLock(mongoID string, fn func(tx AchievementOutboxTx) error) error
+	// HasPendingOutbox memeriksa apakah dokumen masih memiliki entri yang belum diterapkan
+	HasPendingOutbox(mongoID string) (bool, error)
+}
+
+// AchievementOutboxTx adalah operasi outbox di dalam WithOutboxLock
+type AchievementOutboxTx interface {
+	// PendingOutboxEntries mengambil entri pending satu dokumen, urut sesuai waktu dicatat
+	PendingOutboxEntries(mongoID string) ([]*model.AchievementOutboxEntry, error)
+	// CompleteOutboxEntry menghapus entri yang sudah diterapkan
+	CompleteOutboxEntry(id int64) error
+	// FailOutboxEntry mencatat percobaan yang gagal dan jadwal percobaan berikutnya
+	FailOutboxEntry(id int64, lastError string, nextAttemptAt time.Time) error
+}
+
+// AchievementDocumentStore menulis dokumen prestasi di MongoDB. Setiap operasi harus idempoten.
+type AchievementDocumentStore interface {
+	ReplaceAchievementDocument(mongoID string, document []byte) error
+	UpdateAchievementDocument(mongoID string, fields []byte) error
+	DeleteAchievementDocument(mongoID string) error
+}
+
+const (
+	outboxRetryBaseDelay = 30 * time.Second
+	outboxRetryMaxDelay  = time.Hour
+)
+
+// outboxRetryDelay menghitung jeda sebelum percobaan berikutnya: 30 detik, lalu dua kali lipat sampai maksimal satu jam
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBaseDelay
+	for i := 1; i < attempts && delay < outboxRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > outboxRetryMaxDelay {
+		delay = outboxRetryMaxDelay
+	}
+	return delay
+}
+
+// AchievementOutbox menerapkan entri outbox ke MongoDB. PostgreSQL menjadi titik commit: perubahan dianggap
+// tersimpan begitu reference dan entri outbox di-commit, lalu MongoDB dibuat menyusul sampai keduanya sama.
+type AchievementOutbox struct {
+	store     AchievementOutboxStore
+	documents AchievementDocumentStore
+	now       func() time.Time
+}
+
+// NewAchievementOutbox membuat pemroses outbox dari penyimpanan entri dan penyimpanan dokumen
+func NewAchievementOutbox(store AchievementOutboxStore, documents AchievementDocumentStore) *AchievementOutbox {
+	return &AchievementOutbox{store: store, documents: documents, now: time.Now}
+}
+
+// SetClock mengganti sumber waktu, dipakai untuk menguji jadwal percobaan ulang
+func (o *AchievementOutbox) SetClock(now func() time.Time) {
+	o.now = now
+}
+
+// Flush menerapkan semua entri pending satu dokumen secara berurutan. Entri yang gagal dicatat dengan
+// jadwal percobaan berikutnya dan entri setelahnya tidak dijalankan, sehingga perubahan lama tidak
+// pernah menimpa perubahan yang lebih baru.
+func (o *AchievementOutbox) Flush(mongoID string) error {
+	var applyErr error
+	err := o.store.WithOutboxLock(mongoID, func(tx AchievementOutboxTx) error {
+		entries, err := tx.PendingOutboxEntries(mongoID)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if applyErr = o.apply(entry); applyErr != nil {
+				next := o.now().Add(outboxRetryDelay(entry.Attempts + 1))
+				return tx.FailOutboxEntry(entry.ID, applyErr.Error(), next)
+			}
+			if err := tx.CompleteOutboxEntry(entry.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return applyErr
+}
+
+// FlushPending menerapkan entri pending satu dokumen jika ada, dipakai sebelum membaca dokumen agar
+// perubahan yang sudah di-commit di PostgreSQL tidak terbaca lama atau hilang. Pengecekan tanpa lock
+// membuat pembacaan biasa tidak perlu membuka transaksi.
+func (o *AchievementOutbox) FlushPending(mongoID string) error {
+	pending, err := o.store.HasPendingOutbox(mongoID)
+	if err != nil || !pending {
+		return err
+	}
+	return o.Flush(mongoID)
+}
+
+// Process menerapkan entri pending yang sudah jatuh tempo untuk maksimal limit dokumen. Mengembalikan
+// jumlah dokumen yang berhasil disamakan dan error terakhir jika ada dokumen yang masih gagal.
+func (o *AchievementOutbox) Process(limit int) (int, error) {
+	mongoIDs, err := o.store.DueOutboxDocuments(o.now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	var lastErr error
+	for _, mongoID := range mongoIDs {
+		if err := o.Flush(mongoID); err != nil {
+			lastErr = fmt.Errorf("dokumen %s: %w", mongoID, err)
+			continue
+		}
+		synced++
+	}
+	return synced, lastErr
+}
+
+func (o *AchievementOutbox) apply(entry *model.AchievementOutboxEntry) error {
+	switch entry.Operation {
+	case model.OutboxOperationUpsert:
+		return o.documents.ReplaceAchievementDocument(entry.MongoAchievementID, entry.Payload)
+	case model.OutboxOperationUpdate:
+		return o.documents.UpdateAchievementDocument(entry.MongoAchievementID, entry.Payload)
+	case model.OutboxOperationDelete:
+		return o.documents.DeleteAchievementDocument(entry.MongoAchievementID)
+	default:
+		return fmt.Errorf("operasi outbox %q tidak dikenal", entry.Operation)
+	}
+}
+
+// enqueueAchievementOutbox mencatat perubahan dokumen MongoDB di dalam transaksi PostgreSQL pemanggil
+func enqueueAchievementOutbox(tx *sql.Tx, mongoID, operation string, payload []byte) error {
+	_, err := tx.Exec(`
+		INSERT INTO achievement_outbox (mongo_achievement_id, operation, payload, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+	`, mongoID, operation, payload)
+	return err
+}
+
+// postgresOutboxStore menyimpan entri outbox di tabel achievement_outbox
+type postgresOutboxStore struct {
+	db *sql.DB
+}
+
+func (s *postgresOutboxStore) DueOutboxDocuments(now time.Time, limit int) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT mongo_achievement_id FROM (
+			SELECT DISTINCT ON (mongo_achievement_id) mongo_achievement_id, id, next_attempt_at
+			FROM achievement_outbox
+			ORDER BY mongo_achievement_id, id
+		) head
+		WHERE next_attempt_at <= $1
+		ORDER BY id
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mongoIDs []string
+	for rows.Next() {
+		var mongoID string
+		if err := rows.Scan(&mongoID); err != nil {
+			return nil, err
+		}
+		mongoIDs = append(mongoIDs, mongoID)
+	}
+	return mongoIDs, rows.Err()
+}
+
+func (s *postgresOutboxStore) HasPendingOutbox(mongoID string) (bool, error) {
+	var pending bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM achievement_outbox WHERE mongo_achievement_id = $1)`, mongoID).Scan(&pending)
+	return pending, err
+}
+
+func (s *postgresOutboxStore) WithOutboxLock(mongoID string, fn func(tx AchievementOutboxTx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Advisory lock dilepas otomatis saat transaksi selesai
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, mongoID); err != nil {
+		return err
+	}
+	if err := fn(&postgresOutboxTx{tx: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type postgresOutboxTx struct {
+	tx *sql.Tx
+}
+
+func (t *postgresOutboxTx) PendingOutboxEntries(mongoID string) ([]*model.AchievementOutboxEntry, error) {
+	rows, err := t.tx.Query(`
+		SELECT id, mongo_achievement_id, operation, payload, attempts, last_error, next_attempt_at, created_at
+		FROM achievement_outbox
+		WHERE mongo_achievement_id = $1
+		ORDER BY id
+	`, mongoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.AchievementOutboxEntry
+	for rows.Next() {
+		entry := &model.AchievementOutboxEntry{}
+		err := rows.Scan(&entry.ID, &entry.MongoAchievementID, &entry.Operation, &entry.Payload,
+			&entry.Attempts, &entry.LastError, &entry.NextAttemptAt, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (t *postgresOutboxTx) CompleteOutboxEntry(id int64) error {
+	_, err := t.tx.Exec(`DELETE FROM achievement_outbox WHERE id = $1`, id)
+	return err
+}
+
+func (t *postgresOutboxTx) FailOutboxEntry(id int64, lastError string, nextAttemptAt time.Time) error {
+	_, err := t.tx.Exec(`
+		UPDATE achievement_outbox
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		WHERE id = $3
+	`, lastError, nextAttemptAt, id)
+	return err
+}
+
+// mongoDocumentStore menulis dokumen prestasi ke koleksi achievements
+type mongoDocumentStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoDocumentStore) objectID(mongoID string) (primitive.ObjectID, error) {
+	if s.collection == nil {
+		return primitive.NilObjectID, errors.New("MongoDB belum terhubung")
+	}
+	return primitive.ObjectIDFromHex(mongoID)
+}
+
+func (s *mongoDocumentStore) ReplaceAchievementDocument(mongoID string, document []byte) error {
+	objID, err := s.objectID(mongoID)
+	if err != nil {
+		return err
+	}
+	_, err = s.collection.ReplaceOne(context.Background(), bson.M{"_id": objID}, bson.Raw(document), options.Replace().SetUpsert(true))
+	return err
+}
+
+// UpdateAchievementDocument tidak membuat dokumen baru; dokumen yang sudah dihapus dilewati
+func (s *mongoDocumentStore) UpdateAchievementDocument(mongoID string, fields []byte) error {
+	objID, err := s.objectID(mongoID)
+	if err != nil {
+		return err
+	}
+	_, err = s.collection.UpdateOne(context.Background(), bson.M{"_id": objID}, bson.M{"$set": bson.Raw(fields)})
+	return err
+}
+
+func (s *mongoDocumentStore) DeleteAchievementDocument(mongoID string) error {
+	objID, err := s.objectID(mongoID)
+	if err != nil {
+		return err
+	}
+	_, err = s.collection.DeleteOne(context.Background(), bson.M{"_id": objID})
+	return err
+}
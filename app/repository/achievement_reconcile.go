@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"uas_be/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrReconcileStale dikembalikan jika data sudah berubah sejak dipindai sehingga perbaikan tidak lagi tepat
+var ErrReconcileStale = errors.New("data sudah berubah sejak dipindai, jalankan ulang rekonsiliasi")
+
+// GetReconcileReferences mengambil semua reference, termasuk yang ada di trash
+func (r *achievementRepositoryImpl) GetReconcileReferences() ([]*model.ReconcileReference, error) {
+	rows, err := r.db.Query(`
+		SELECT id, student_id, mongo_achievement_id, achievement_title, status, team_role
+		FROM achievement_references
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var references []*model.ReconcileReference
+	for rows.Next() {
+		ref := &model.ReconcileReference{}
+		if err := rows.Scan(&ref.ReferenceID, &ref.StudentID, &ref.MongoAchievementID, &ref.Title, &ref.Status, &ref.TeamRole); err != nil {
+			return nil, err
+		}
+		references = append(references, ref)
+	}
+	return references, rows.Err()
+}
+
+// GetReconcileDocuments mengambil _id, student_id, dan title semua dokumen prestasi di MongoDB
+func (r *achievementRepositoryImpl) GetReconcileDocuments() ([]*model.ReconcileDocument, error) {
+	ctx := context.Background()
+	if r.mongoCollection == nil {
+		return nil, errors.New("MongoDB belum terhubung")
+	}
+
+	projection := options.Find().SetProjection(bson.M{"_id": 1, "student_id": 1, "title": 1})
+	cursor, err := r.mongoCollection.Find(ctx, bson.M{}, projection)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []*model.ReconcileDocument
+	for cursor.Next(ctx) {
+		var raw struct {
+			ID        interface{} `bson:"_id"`
+			StudentID string      `bson:"student_id"`
+			Title     string      `bson:"title"`
+		}
+		if err := cursor.Decode(&raw); err != nil {
+			return nil, err
+		}
+		document := &model.ReconcileDocument{StudentID: raw.StudentID, Title: raw.Title}
+		// Reference selalu menyimpan ObjectID dalam bentuk hex; _id lain tetap dilaporkan sebagai dokumen yatim
+		switch id := raw.ID.(type) {
+		case primitive.ObjectID:
+			document.MongoAchievementID = id.Hex()
+		case string:
+			document.MongoAchievementID = id
+		}
+		documents = append(documents, document)
+	}
+	return documents, cursor.Err()
+}
+
+// GetPendingOutboxDocuments mengambil ID dokumen MongoDB yang masih punya entri outbox
+func (r *achievementRepositoryImpl) GetPendingOutboxDocuments() ([]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT mongo_achievement_id FROM achievement_outbox`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mongoIDs []string
+	for rows.Next() {
+		var mongoID string
+		if err := rows.Scan(&mongoID); err != nil {
+			return nil, err
+		}
+		mongoIDs = append(mongoIDs, mongoID)
+	}
+	return mongoIDs, rows.Err()
+}
+
+// UpdateReferenceTitle menyamakan achievement_title dengan title di MongoDB tanpa menaikkan versi,
+// karena isi prestasi tidak berubah
+func (r *achievementRepositoryImpl) UpdateReferenceTitle(referenceID, title string) error {
+	result, err := r.db.Exec(`
+		UPDATE achievement_references SET achievement_title = $1 WHERE id = $2
+	`, title, referenceID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrReconcileStale
+	}
+	return nil
+}
+
+// SetAchievementDocumentStudent menyamakan student_id dokumen MongoDB dengan pemilik reference lewat outbox
+func (r *achievementRepositoryImpl) SetAchievementDocumentStudent(mongoID, studentID string) error {
+	fields, err := bson.Marshal(bson.M{"student_id": studentID})
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := enqueueAchievementOutbox(tx, mongoID, model.OutboxOperationUpdate, fields); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return r.outbox.Flush(mongoID)
+}
+
+// DeleteOrphanDocument menghapus dokumen MongoDB yang tidak punya reference lewat outbox. Dokumen yang
+// ternyata sudah dipakai reference tidak dihapus.
+func (r *achievementRepositoryImpl) DeleteOrphanDocument(mongoID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var referenced bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM achievement_references WHERE mongo_achievement_id = $1)
+	`, mongoID).Scan(&referenced)
+	if err != nil {
+		return err
+	}
+	if referenced {
+		return ErrReconcileStale
+	}
+
+	if err := enqueueAchievementOutbox(tx, mongoID, model.OutboxOperationDelete, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return r.outbox.Flush(mongoID)
+}
+
+// DeleteDanglingReference menghapus reference yang dokumen MongoDB-nya sudah tidak ada, beserta history,
+// lampiran, revisi, approval, dan komentarnya. Reference yang dokumennya masih menunggu outbox tidak dihapus.
+func (r *achievementRepositoryImpl) DeleteDanglingReference(referenceID string) error {
+	var mongoID string
+	err := r.db.QueryRow(`SELECT mongo_achievement_id FROM achievement_references WHERE id = $1`, referenceID).Scan(&mongoID)
+	if err != nil {
+		return err
+	}
+
+	// Dokumen yang _id-nya bukan ObjectID tidak mungkin ditemukan oleh handler lain, sehingga tetap dianggap hilang
+	if mongoObjID, err := primitive.ObjectIDFromHex(mongoID); err == nil && r.mongoCollection != nil {
+		count, err := r.mongoCollection.CountDocuments(context.Background(), bson.M{"_id": mongoObjID})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrReconcileStale
+		}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM achievement_history WHERE achievement_id = $1`, referenceID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM achievement_attachments WHERE achievement_id = $1`, referenceID); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`
+		DELETE FROM achievement_references ar
+		WHERE ar.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM achievement_outbox o WHERE o.mongo_achievement_id = ar.mongo_achievement_id)
+	`, referenceID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrReconcileStale
+	}
+	return tx.Commit()
+}
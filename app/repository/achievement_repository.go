@@ -3,7 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -11,6 +15,7 @@ import (
 	"uas_be/database"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -24,26 +29,226 @@ type AchievementRepository interface {
 	GetAchievementsByStatus(status string) ([]*model.AchievementWithReference, error)
 	GetAllAchievements(page, pageSize int) ([]*model.AchievementWithReference, int, error)
 	GetAchievementsWithFilters(page, pageSize int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.AchievementWithReference, int, error)
-	UpdateAchievement(referenceID string, achievement *model.Achievement) error
-	SubmitAchievementForVerification(id string) error
-	VerifyAchievement(id string, verifiedBy string) error
-	RejectAchievement(id string, verifiedBy string, rejectionNote string) error
-	DeleteAchievement(id string) error
+	// UpdateAchievement menyimpan isi prestasi jika versinya masih expectedVersion (0 = tanpa pengecekan)
+	// lalu menaikkan versi. Mengembalikan ErrAchievementVersionConflict jika prestasi sudah diubah.
+	UpdateAchievement(referenceID string, achievement *model.Achievement, expectedVersion int) error
+	// GetDeletedAchievements mengambil prestasi di trash; studentID kosong berarti semua mahasiswa
+	GetDeletedAchievements(studentID string) ([]*model.AchievementWithReference, error)
+	// GetPurgeCandidates mengambil reference di trash yang dihapus sebelum deletedBefore tanpa membaca MongoDB,
+	// sehingga reference yang dokumennya sudah hilang tetap ikut dihapus permanen
+	GetPurgeCandidates(deletedBefore time.Time) ([]*model.PurgeCandidate, error)
+	// PurgeAchievement menghapus permanen prestasi berstatus deleted beserta dokumen MongoDB, history, dan attachment
+	PurgeAchievement(referenceID string) error
+	// TransitionAchievementStatus memindahkan status dari fromStatus ke toStatus sekaligus mengisi
+	// kolom tambahan (submitted_at, verified_by, dll). Mengembalikan ErrAchievementStatusConflict
+	// jika status sudah berubah sejak dibaca, atau ErrAchievementVersionConflict jika status sama tetapi
+	// isi prestasi sudah diubah (expectedVersion 0 = tanpa pengecekan versi). effects (boleh nil) disimpan
+	// dalam transaksi yang sama dan ikut dibatalkan jika transisi ditolak.
+	TransitionAchievementStatus(id, fromStatus, toStatus string, expectedVersion int, fields map[string]interface{}, effects *model.AchievementTransitionEffects) error
 
 	CreateAchievementHistory(history *model.AchievementHistory) error
 	GetAchievementHistory(achievementID string) ([]*model.AchievementHistory, error)
 	CreateAttachment(attachment *model.AchievementAttachment) error
+	// GetAttachmentsByAchievementID mengambil attachment prestasi, termasuk yang diunggah anggota tim lain
 	GetAttachmentsByAchievementID(achievementID string) ([]*model.AchievementAttachment, error)
-
-	GetAchievementStatsByPeriod(startDate, endDate time.Time, role, userID string) (map[string]interface{}, error)
+	// CreateTeamAchievement menyimpan satu dokumen MongoDB dan satu reference per anggota tim,
+	// lalu mengembalikan reference milik ketua. ReferenceID tiap anggota diisi setelah berhasil.
+	CreateTeamAchievement(achievement *model.Achievement, members []*model.TeamMember) (*model.AchievementWithReference, error)
+	GetTeamMembers(teamID string) ([]*model.TeamMember, error)
+	// FindAttachmentsByHash mengambil attachment prestasi mana pun yang isinya sama dengan salah satu hash
+	FindAttachmentsByHash(hashes []string) ([]*model.AchievementAttachment, error)
+	// GetOverdueSubmissions mengambil prestasi berstatus submitted yang disubmit sebelum submittedBefore, dari yang terlama
+	GetOverdueSubmissions(submittedBefore time.Time) ([]*model.OverdueSubmission, error)
+	// SetValidUntil mengatur masa berlaku untuk semua reference yang berbagi isi prestasi (anggota tim) dan
+	// mereset pengingat kedaluwarsa. Versi prestasi tidak dinaikkan.
+	SetValidUntil(referenceID string, validUntil *time.Time) error
+	// GetExpiringAchievements mengambil prestasi terverifikasi yang masih berlaku sampai paling lambat
+	// validUntilBefore dan belum diingatkan
+	GetExpiringAchievements(validUntilBefore time.Time) ([]*model.ExpiringAchievement, error)
+	// MarkExpiryReminded mencatat pengingat kedaluwarsa; false jika sudah tercatat
+	MarkExpiryReminded(referenceID string, at time.Time) (bool, error)
+	// GetDuplicateCandidates mengambil prestasi bertipe sama yang belum dihapus; studentID kosong berarti semua mahasiswa
+	GetDuplicateCandidates(studentID, achievementType string) ([]*model.AchievementWithReference, error)
+
+	// CreateAchievementSnapshot menyimpan isi prestasi sebagai versi berikutnya dan mengisi snapshot.Version
+	CreateAchievementSnapshot(snapshot *model.AchievementSnapshot) error
+	// GetAchievementSnapshots mengambil semua versi isi prestasi, dari yang terlama
+	GetAchievementSnapshots(achievementID string) ([]*model.AchievementSnapshot, error)
+
+	CreateRevisionRequest(request *model.AchievementRevisionRequest) error
+	GetRevisionRequests(achievementID string) ([]*model.AchievementRevisionRequest, error)
+	// ResolveRevisionRequests menandai semua permintaan revisi yang masih terbuka sebagai selesai
+	ResolveRevisionRequests(achievementID string) error
+
+	GetApprovalChains(activeOnly bool) ([]*model.ApprovalChain, error)
+	GetApprovalChainByID(id string) (*model.ApprovalChain, error)
+	CreateApprovalChain(chain *model.ApprovalChain) error
+	DeactivateApprovalChain(id string) error
+	CreateAchievementApproval(approval *model.AchievementApproval) error
+	GetAchievementApprovals(achievementID string) ([]*model.AchievementApproval, error)
+	// ResetAchievementApprovals menghapus persetujuan tahap sebelumnya saat prestasi disubmit ulang
+	ResetAchievementApprovals(achievementID string) error
+
+	GetPointsRubricRules(activeOnly bool) ([]*model.PointsRubricRule, error)
+	CreatePointsRubricRule(rule *model.PointsRubricRule) error
+	DeactivatePointsRubricRule(id string) error
+
+	GetAchievementTypes(activeOnly bool) ([]*model.AchievementTypeDefinition, error)
+	GetAchievementType(code string) (*model.AchievementTypeDefinition, error)
+	CreateAchievementType(achievementType *model.AchievementTypeDefinition) error
+	UpdateAchievementType(achievementType *model.AchievementTypeDefinition) error
+
+	// GetAchievementTypeSchema mengambil schema Details versi tertentu; version 0 berarti versi terbaru
+	GetAchievementTypeSchema(achievementType string, version int) (*model.AchievementTypeSchema, error)
+	// CreateAchievementTypeSchema menyimpan schema sebagai versi berikutnya (minimal schema.Version) lalu mengisi
+	// schema.Version. Mengembalikan ErrSchemaVersionConflict jika versi yang sama diterbitkan bersamaan.
+	CreateAchievementTypeSchema(schema *model.AchievementTypeSchema) error
+
+	// GetTags mengambil katalog tag beserta aliasnya, urut nama
+	GetTags() ([]*model.Tag, error)
+	GetTag(slug string) (*model.Tag, error)
+	CreateTag(tag *model.Tag) error
+	// UpdateTag mengganti nama dan seluruh alias tag
+	UpdateTag(tag *model.Tag) error
+	DeleteTag(slug string) error
+	// MergeTags menghapus tag sourceSlugs lalu menyimpan target (yang aliasnya sudah ditambah) dalam satu transaksi
+	MergeTags(target *model.Tag, sourceSlugs []string) error
+	// RenameAchievementTags mengganti tag prestasi yang cocok dengan salah satu variants (tanpa membedakan
+	// huruf besar/kecil) menjadi name, lalu menghapus duplikat dan menaikkan versi prestasi yang berubah.
+	// Mengembalikan jumlah prestasi yang berubah.
+	RenameAchievementTags(variants []string, name string) (int, error)
+	// GetTagUsage menghitung jumlah dokumen prestasi per tag persis seperti yang tersimpan
+	GetTagUsage() (map[string]int, error)
+	// GetAchievementStatsByTag menghitung prestasi per tag persis seperti yang tersimpan; includeExpired
+	// menentukan apakah poin prestasi yang masa berlakunya sudah lewat tetap dihitung
+	GetAchievementStatsByTag(role, userID string, includeExpired bool) (map[string]*model.TagStat, error)
+
+	// includeExpired menentukan apakah poin prestasi yang masa berlakunya sudah lewat tetap dihitung
+	GetAchievementStatsByPeriod(startDate, endDate time.Time, role, userID string, includeExpired bool) (map[string]interface{}, error)
 	GetAchievementStatsByType(role, userID string) (map[string]interface{}, error)
-	GetTopStudents(limit int) ([]*model.StudentStats, error)
+	GetTopStudents(limit int, includeExpired bool) ([]*model.StudentStats, error)
+
+	// ProcessAchievementOutbox menerapkan ulang perubahan dokumen MongoDB yang tertunda untuk maksimal limit
+	// dokumen. Mengembalikan jumlah dokumen yang berhasil disamakan.
+	ProcessAchievementOutbox(limit int) (int, error)
+
+	// GetReconcileReferences dan GetReconcileDocuments mengambil data kedua sisi untuk rekonsiliasi
+	GetReconcileReferences() ([]*model.ReconcileReference, error)
+	GetReconcileDocuments() ([]*model.ReconcileDocument, error)
+	// GetPendingOutboxDocuments mengambil ID dokumen MongoDB yang perubahannya belum selesai diterapkan
+	GetPendingOutboxDocuments() ([]string, error)
+	UpdateReferenceTitle(referenceID, title string) error
+	SetAchievementDocumentStudent(mongoID, studentID string) error
+	DeleteOrphanDocument(mongoID string) error
+	DeleteDanglingReference(referenceID string) error
+
+	// SearchAchievements mencari prestasi dengan text index MongoDB, dibatasi filter daftar prestasi
+	// (status, tanggal, cakupan mahasiswa) dari PostgreSQL. Hasil diurutkan berdasarkan skor relevansi.
+	SearchAchievements(search string, filters map[string]interface{}, page, pageSize int) ([]*model.AchievementSearchResult, int, error)
+	// BackfillSearchTerms mengisi search_terms dokumen yang dibuat sebelum field itu ada. Mengembalikan jumlah
+	// dokumen yang diisi, atau yang perlu diisi jika dryRun.
+	BackfillSearchTerms(dryRun bool) (int, error)
+}
+
+// ErrAchievementStatusConflict dikembalikan jika status prestasi sudah berubah sebelum transisi disimpan
+var ErrAchievementStatusConflict = errors.New("status prestasi sudah berubah, silakan muat ulang data")
+
+// ErrAchievementVersionConflict dikembalikan jika versi prestasi tidak sama dengan versi yang dibaca client (If-Match)
+var ErrAchievementVersionConflict = errors.New("prestasi sudah diubah oleh pengguna lain, silakan muat ulang data")
+
+// ErrSchemaVersionConflict dikembalikan jika dua schema untuk tipe yang sama diterbitkan bersamaan
+var ErrSchemaVersionConflict = errors.New("schema tipe ini sedang diterbitkan oleh pengguna lain, silakan coba lagi")
+
+// ErrAchievementDocumentPending dikembalikan saat perubahan dokumen MongoDB yang sudah di-commit belum bisa diterapkan
+var ErrAchievementDocumentPending = errors.New("data prestasi sedang disinkronkan, silakan coba lagi")
+
+// transitionColumns adalah kolom achievement_references yang boleh diisi oleh hook transisi status
+var transitionColumns = map[string]bool{
+	"submitted_at":   true,
+	"verified_at":    true,
+	"verified_by":    true,
+	"rejection_note": true,
+	"deleted_at":     true,
+
+	"resubmission_count": true,
+	"possible_duplicate": true,
+	"sla_reminded_at":    true,
+	"escalated_at":       true,
+	"points":             true,
+}
+
+// referenceColumns adalah kolom achievement_references yang dibaca oleh scanReference
+const referenceColumns = `id, student_id, mongo_achievement_id, achievement_title, status,
+		       submitted_at, verified_at, verified_by, rejection_note, resubmission_count, version, possible_duplicate, team_id, team_role, sla_reminded_at, escalated_at, valid_until, expiry_reminded_at, deleted_at, created_at, updated_at, points`
+
+// rowScanner mewakili *sql.Row maupun *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// sqlExecutor mewakili *sql.DB maupun *sql.Tx, sehingga penulisan yang sama bisa ikut transaksi pemanggil
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// scanReference membaca satu baris achievement_references sesuai urutan referenceColumns
+func scanReference(row rowScanner) (*model.AchievementReference, error) {
+	var ref model.AchievementReference
+	err := row.Scan(
+		&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.AchievementTitle,
+		&ref.Status, &ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy,
+		&ref.RejectionNote, &ref.ResubmissionCount, &ref.Version, &ref.PossibleDuplicate, &ref.TeamID, &ref.TeamRole, &ref.SLARemindedAt, &ref.EscalatedAt, &ref.ValidUntil, &ref.ExpiryRemindedAt, &ref.DeletedAt, &ref.CreatedAt, &ref.UpdatedAt, &ref.Points,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
+
+// newAchievementWithReference menggabungkan data MongoDB dengan reference PostgreSQL. Pemilik dan poin diambil
+// dari reference karena dokumen prestasi tim dipakai bersama oleh semua anggota.
+func newAchievementWithReference(ref *model.AchievementReference, achievement model.Achievement) *model.AchievementWithReference {
+	achievement.StudentID = ref.StudentID
+	if ref.Points != nil {
+		achievement.Points = *ref.Points
+	}
+	return &model.AchievementWithReference{
+		Achievement:       achievement,
+		ReferenceID:       ref.ID,
+		Status:            ref.Status,
+		SubmittedAt:       ref.SubmittedAt,
+		VerifiedAt:        ref.VerifiedAt,
+		VerifiedBy:        ref.VerifiedBy,
+		RejectionNote:     ref.RejectionNote,
+		ResubmissionCount: ref.ResubmissionCount,
+		Version:           ref.Version,
+		PossibleDuplicate: ref.PossibleDuplicate,
+		TeamID:            ref.TeamID,
+		TeamRole:          ref.TeamRole,
+		SLARemindedAt:     ref.SLARemindedAt,
+		EscalatedAt:       ref.EscalatedAt,
+		ValidUntil:        ref.ValidUntil,
+		Expired:           model.IsValidityExpired(ref.ValidUntil, time.Now()),
+		DeletedAt:         ref.DeletedAt,
+	}
+}
+
+// referencePoints mengembalikan poin yang tersimpan di reference, atau poin dokumen untuk reference lama
+func referencePoints(points sql.NullInt64, achievement model.Achievement) int {
+	if points.Valid {
+		return int(points.Int64)
+	}
+	return achievement.Points
 }
 
 // achievementRepositoryImpl adalah implementasi dari AchievementRepository
 type achievementRepositoryImpl struct {
 	db              *sql.DB
 	mongoCollection *mongo.Collection // Add MongoDB collection
+	documents       AchievementDocumentFinder
+	searcher        AchievementDocumentSearcher
+	outbox          *AchievementOutbox
 }
 
 // NewAchievementRepository membuat instance repository achievement baru
@@ -54,68 +259,188 @@ func NewAchievementRepository(db *sql.DB) AchievementRepository {
 		collection = mongoDB.Collection("achievements")
 	}
 
+	documents := &mongoDocumentStore{collection: collection}
 	return &achievementRepositoryImpl{
 		db:              db,
 		mongoCollection: collection,
+		documents:       documents,
+		searcher:        documents,
+		outbox:          NewAchievementOutbox(&postgresOutboxStore{db: db}, documents),
 	}
 }
 
-// CreateAchievement menyimpan achievement ke MongoDB dan reference ke PostgreSQL
-func (r *achievementRepositoryImpl) CreateAchievement(achievement *model.Achievement, studentID string) (*model.AchievementWithReference, error) {
-	ctx := context.Background()
+// syncAchievementDocument menerapkan outbox dokumen segera setelah transaksi PostgreSQL di-commit. Kegagalan
+// tidak membatalkan perubahan: entri tetap tersimpan dan dicoba ulang oleh ProcessAchievementOutbox.
+func (r *achievementRepositoryImpl) syncAchievementDocument(mongoID string) {
+	if err := r.outbox.Flush(mongoID); err != nil {
+		log.Printf("warning: achievement document %s not yet synced to MongoDB, will retry: %v", mongoID, err)
+	}
+}
+
+// ProcessAchievementOutbox menerapkan ulang perubahan MongoDB yang belum berhasil untuk maksimal limit dokumen
+func (r *achievementRepositoryImpl) ProcessAchievementOutbox(limit int) (int, error) {
+	return r.outbox.Process(limit)
+}
 
-	// 1. Insert achievement data to MongoDB
+// CreateAchievement menyimpan reference ke PostgreSQL dan achievement ke MongoDB. Reference dan entri
+// outbox disimpan dalam satu transaksi, lalu dokumen MongoDB ditulis lewat outbox sehingga keduanya
+// tetap sama meskipun proses berhenti di antara kedua penulisan.
+func (r *achievementRepositoryImpl) CreateAchievement(achievement *model.Achievement, studentID string) (*model.AchievementWithReference, error) {
+	// 1. Siapkan dokumen MongoDB dengan ObjectID yang dibuat aplikasi
 	achievement.StudentID = studentID
 	achievement.CreatedAt = time.Now()
 	achievement.UpdatedAt = time.Now()
+	achievement.SearchTerms = model.AchievementSearchTerms(achievement)
 
-	result, err := r.mongoCollection.InsertOne(ctx, achievement)
+	mongoID := primitive.NewObjectID().Hex()
+	document, err := bson.Marshal(achievement)
 	if err != nil {
-		return nil, fmt.Errorf("failed to insert achievement to MongoDB: %w", err)
+		return nil, fmt.Errorf("failed to encode achievement document: %w", err)
 	}
 
-	mongoID := result.InsertedID.(primitive.ObjectID).Hex()
+	// 2. Create reference and outbox entry in PostgreSQL
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	// 2. Create reference in PostgreSQL
 	referenceID := uuid.New().String()
 	query := `
 		INSERT INTO achievement_references (id, student_id, mongo_achievement_id, achievement_title, status, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 	`
 
-	_, err = r.db.Exec(query, referenceID, studentID, mongoID, achievement.Title, model.AchievementStatusDraft)
+	_, err = tx.Exec(query, referenceID, studentID, mongoID, achievement.Title, model.AchievementStatusDraft)
 	if err != nil {
-		// Rollback: delete from MongoDB if PostgreSQL insert fails
-		r.mongoCollection.DeleteOne(ctx, bson.M{"_id": result.InsertedID})
 		return nil, fmt.Errorf("failed to create achievement reference in PostgreSQL: %w", err)
 	}
+	if err := enqueueAchievementOutbox(tx, mongoID, model.OutboxOperationUpsert, document); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
-	// 3. Return combined result
+	// 3. Write the document to MongoDB; failures are retried by the outbox job
+	r.syncAchievementDocument(mongoID)
+
+	// 4. Return combined result
 	return &model.AchievementWithReference{
 		Achievement: *achievement,
+		ReferenceID: referenceID,
 		Status:      model.AchievementStatusDraft,
+		Version:     1,
 	}, nil
 }
 
-// GetAchievementByID mengambil achievement dari MongoDB dan reference dari PostgreSQL
-func (r *achievementRepositoryImpl) GetAchievementByID(referenceID string) (*model.AchievementWithReference, error) {
-	ctx := context.Background()
+// CreateTeamAchievement menyimpan prestasi tim: deskripsi dan bukti disimpan sekali di MongoDB, sedangkan
+// tiap anggota mendapat reference sendiri agar diverifikasi oleh dosen walinya masing-masing.
+// Seperti CreateAchievement, dokumen MongoDB ditulis lewat outbox setelah semua reference di-commit.
+func (r *achievementRepositoryImpl) CreateTeamAchievement(achievement *model.Achievement, members []*model.TeamMember) (*model.AchievementWithReference, error) {
+	var leader *model.TeamMember
+	for _, member := range members {
+		if member.Role == model.TeamRoleLeader {
+			leader = member
+		}
+	}
+	if leader == nil {
+		return nil, errors.New("prestasi tim harus memiliki satu leader")
+	}
+
+	achievement.StudentID = leader.StudentID
+	achievement.CreatedAt = time.Now()
+	achievement.UpdatedAt = time.Now()
+	achievement.SearchTerms = model.AchievementSearchTerms(achievement)
+
+	mongoID := primitive.NewObjectID().Hex()
+	document, err := bson.Marshal(achievement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode achievement document: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	teamID := uuid.New().String()
+	referenceIDs := make([]string, len(members))
+	for i, member := range members {
+		referenceIDs[i] = uuid.New().String()
+		_, err := tx.Exec(`
+			INSERT INTO achievement_references (id, student_id, mongo_achievement_id, achievement_title, status, team_id, team_role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		`, referenceIDs[i], member.StudentID, mongoID, achievement.Title, model.AchievementStatusDraft, teamID, member.Role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create team achievement reference in PostgreSQL: %w", err)
+		}
+	}
+	if err := enqueueAchievementOutbox(tx, mongoID, model.OutboxOperationUpsert, document); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.syncAchievementDocument(mongoID)
+
+	for i, member := range members {
+		member.ReferenceID = referenceIDs[i]
+		member.Status = model.AchievementStatusDraft
+	}
+
+	leaderRole := model.TeamRoleLeader
+	return &model.AchievementWithReference{
+		Achievement: *achievement,
+		ReferenceID: leader.ReferenceID,
+		Status:      model.AchievementStatusDraft,
+		Version:     1,
+		TeamID:      &teamID,
+		TeamRole:    &leaderRole,
+		TeamMembers: members,
+	}, nil
+}
+
+// GetTeamMembers mengambil anggota prestasi tim beserta status reference masing-masing, ketua lebih dulu
+func (r *achievementRepositoryImpl) GetTeamMembers(teamID string) ([]*model.TeamMember, error) {
+	query := `
+		SELECT ar.id, ar.student_id, s.student_id, u.full_name, ar.team_role, ar.status
+		FROM achievement_references ar
+		JOIN students s ON ar.student_id = s.id
+		JOIN users u ON s.user_id = u.id
+		WHERE ar.team_id = $1
+		ORDER BY ar.team_role = 'leader' DESC, u.full_name
+	`
+
+	rows, err := r.db.Query(query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*model.TeamMember
+	for rows.Next() {
+		member := &model.TeamMember{}
+		if err := rows.Scan(&member.ReferenceID, &member.StudentID, &member.StudentNumber, &member.FullName, &member.Role, &member.Status); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
 
+// GetAchievementByID mengambil achievement dari MongoDB dan reference dari PostgreSQL. Entri outbox yang
+// tertinggal diterapkan dulu; jika MongoDB belum bisa ditulis, ErrAchievementDocumentPending dikembalikan.
+func (r *achievementRepositoryImpl) GetAchievementByID(referenceID string) (*model.AchievementWithReference, error) {
 	// 1. Get reference from PostgreSQL
 	query := `
-		SELECT id, student_id, mongo_achievement_id, achievement_title, status, 
-		       submitted_at, verified_at, verified_by, rejection_note, deleted_at, created_at, updated_at
-		FROM achievement_references 
+		SELECT ` + referenceColumns + `
+		FROM achievement_references
 		WHERE id = $1
 	`
 
-	var ref model.AchievementReference
-	err := r.db.QueryRow(query, referenceID).Scan(
-		&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.AchievementTitle,
-		&ref.Status, &ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy,
-		&ref.RejectionNote, &ref.DeletedAt, &ref.CreatedAt, &ref.UpdatedAt,
-	)
-
+	ref, err := scanReference(r.db.QueryRow(query, referenceID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -123,36 +448,29 @@ func (r *achievementRepositoryImpl) GetAchievementByID(referenceID string) (*mod
 		return nil, err
 	}
 
-	// 2. Get achievement data from MongoDB
-	mongoObjID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	if err != nil {
-		return nil, err
+	// 2. Apply outbox entries left behind by a failed post-commit flush before reading
+	if err := r.outbox.FlushPending(ref.MongoAchievementID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAchievementDocumentPending, err)
 	}
 
-	var achievement model.Achievement
-	err = r.mongoCollection.FindOne(ctx, bson.M{"_id": mongoObjID}).Decode(&achievement)
+	// 3. Get achievement data from MongoDB
+	documents, err := r.documents.FindAchievementDocuments([]string{ref.MongoAchievementID}, nil)
 	if err != nil {
 		return nil, err
 	}
+	achievement, ok := documents[ref.MongoAchievementID]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
 
-	// 3. Combine both
-	return &model.AchievementWithReference{
-		Achievement:   achievement,
-		Status:        ref.Status,
-		SubmittedAt:   ref.SubmittedAt,
-		VerifiedAt:    ref.VerifiedAt,
-		VerifiedBy:    ref.VerifiedBy,
-		RejectionNote: ref.RejectionNote,
-	}, nil
+	// 4. Combine both
+	return newAchievementWithReference(ref, achievement), nil
 }
 
 func (r *achievementRepositoryImpl) GetAchievementsByStudentID(studentID string) ([]*model.AchievementWithReference, error) {
-	ctx := context.Background()
-
 	// 1. Get all references from PostgreSQL
 	query := `
-		SELECT id, student_id, mongo_achievement_id, achievement_title, status,
-		       submitted_at, verified_at, verified_by, rejection_note, deleted_at, created_at, updated_at
+		SELECT ` + referenceColumns + `
 		FROM achievement_references
 		WHERE student_id = $1 AND status != $2
 		ORDER BY created_at DESC
@@ -164,51 +482,132 @@ func (r *achievementRepositoryImpl) GetAchievementsByStudentID(studentID string)
 	}
 	defer rows.Close()
 
-	var results []*model.AchievementWithReference
+	refs, err := scanReferences(rows)
+	if err != nil {
+		return nil, err
+	}
 
-	for rows.Next() {
-		var ref model.AchievementReference
-		err := rows.Scan(
-			&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.AchievementTitle,
-			&ref.Status, &ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy,
-			&ref.RejectionNote, &ref.DeletedAt, &ref.CreatedAt, &ref.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
+	// 2. Get achievement data from MongoDB in one batch and combine in reference order
+	return JoinAchievementDocuments(r.documents, refs, nil)
+}
 
-		// 2. Get achievement data from MongoDB
-		mongoObjID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
-			continue
+// GetDeletedAchievements mengambil prestasi di trash, diurutkan dari yang paling baru dihapus
+func (r *achievementRepositoryImpl) GetDeletedAchievements(studentID string) ([]*model.AchievementWithReference, error) {
+	query := `
+		SELECT ` + referenceColumns + `
+		FROM achievement_references
+		WHERE status = $1 AND ($2 = '' OR student_id::text = $2)
+		ORDER BY deleted_at DESC NULLS LAST
+	`
+
+	rows, err := r.db.Query(query, model.AchievementStatusDeleted, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs, err := scanReferences(rows)
+	if err != nil {
+		return nil, err
+	}
+	return JoinAchievementDocuments(r.documents, refs, nil)
+}
+
+// GetPurgeCandidates mengambil reference di trash yang dihapus sebelum deletedBefore, dari yang terlama
+func (r *achievementRepositoryImpl) GetPurgeCandidates(deletedBefore time.Time) ([]*model.PurgeCandidate, error) {
+	rows, err := r.db.Query(`
+		SELECT id, deleted_at, team_id
+		FROM achievement_references
+		WHERE status = $1 AND deleted_at < $2
+		ORDER BY deleted_at
+	`, model.AchievementStatusDeleted, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.PurgeCandidate
+	for rows.Next() {
+		item := &model.PurgeCandidate{}
+		if err := rows.Scan(&item.ReferenceID, &item.DeletedAt, &item.TeamID); err != nil {
+			return nil, err
 		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
 
-		var achievement model.Achievement
-		err = r.mongoCollection.FindOne(ctx, bson.M{"_id": mongoObjID}).Decode(&achievement)
-		if err != nil {
-			continue
+// PurgeAchievement menghapus permanen prestasi yang sudah di-soft delete. Data PostgreSQL dihapus dalam
+// satu transaksi bersama entri outbox penghapusan; dokumen MongoDB dihapus setelahnya lewat outbox agar
+// reference tidak menunjuk dokumen yang hilang dan dokumen tidak tertinggal jika penghapusan MongoDB gagal.
+// Untuk prestasi tim, dokumen MongoDB dan lampiran baru dihapus saat reference anggota terakhir dihapus.
+func (r *achievementRepositoryImpl) PurgeAchievement(referenceID string) error {
+	var mongoID string
+	err := r.db.QueryRow(`
+		SELECT mongo_achievement_id FROM achievement_references WHERE id = $1 AND status = $2
+	`, referenceID, model.AchievementStatusDeleted).Scan(&mongoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrAchievementStatusConflict
 		}
+		return err
+	}
 
-		// 3. Combine
-		results = append(results, &model.AchievementWithReference{
-			Achievement:   achievement,
-			Status:        ref.Status,
-			SubmittedAt:   ref.SubmittedAt,
-			VerifiedAt:    ref.VerifiedAt,
-			VerifiedBy:    ref.VerifiedBy,
-			RejectionNote: ref.RejectionNote,
-		})
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Dokumen prestasi tim masih dipakai anggota lain, sehingga lampirannya dipindahkan ke reference anggota tersebut
+	var otherReferenceID string
+	err = tx.QueryRow(`
+		SELECT id FROM achievement_references
+		WHERE mongo_achievement_id = $1 AND id != $2
+		ORDER BY team_role = 'leader' DESC
+		LIMIT 1
+	`, mongoID, referenceID).Scan(&otherReferenceID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
 	}
+	shared := otherReferenceID != ""
 
-	return results, nil
+	// Revisi, approval, dan komentar ikut terhapus lewat ON DELETE CASCADE
+	if _, err := tx.Exec(`DELETE FROM achievement_history WHERE achievement_id = $1`, referenceID); err != nil {
+		return err
+	}
+	if shared {
+		_, err = tx.Exec(`UPDATE achievement_attachments SET achievement_id = $1 WHERE achievement_id = $2`, otherReferenceID, referenceID)
+	} else {
+		_, err = tx.Exec(`DELETE FROM achievement_attachments WHERE achievement_id = $1`, referenceID)
+	}
+	if err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM achievement_references WHERE id = $1 AND status = $2`, referenceID, model.AchievementStatusDeleted)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrAchievementStatusConflict
+	}
+	if !shared {
+		if err := enqueueAchievementOutbox(tx, mongoID, model.OutboxOperationDelete, nil); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if !shared {
+		r.syncAchievementDocument(mongoID)
+	}
+	return nil
 }
 
 func (r *achievementRepositoryImpl) GetAchievementsByStatus(status string) ([]*model.AchievementWithReference, error) {
-	ctx := context.Background()
-
 	query := `
-		SELECT id, student_id, mongo_achievement_id, achievement_title, status,
-		       submitted_at, verified_at, verified_by, rejection_note, deleted_at, created_at, updated_at
+		SELECT ` + referenceColumns + `
 		FROM achievement_references
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -220,45 +619,14 @@ func (r *achievementRepositoryImpl) GetAchievementsByStatus(status string) ([]*m
 	}
 	defer rows.Close()
 
-	var results []*model.AchievementWithReference
-
-	for rows.Next() {
-		var ref model.AchievementReference
-		err := rows.Scan(
-			&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.AchievementTitle,
-			&ref.Status, &ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy,
-			&ref.RejectionNote, &ref.DeletedAt, &ref.CreatedAt, &ref.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-
-		mongoObjID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
-			continue
-		}
-
-		var achievement model.Achievement
-		err = r.mongoCollection.FindOne(ctx, bson.M{"_id": mongoObjID}).Decode(&achievement)
-		if err != nil {
-			continue
-		}
-
-		results = append(results, &model.AchievementWithReference{
-			Achievement:   achievement,
-			Status:        ref.Status,
-			SubmittedAt:   ref.SubmittedAt,
-			VerifiedAt:    ref.VerifiedAt,
-			VerifiedBy:    ref.VerifiedBy,
-			RejectionNote: ref.RejectionNote,
-		})
+	refs, err := scanReferences(rows)
+	if err != nil {
+		return nil, err
 	}
-
-	return results, nil
+	return JoinAchievementDocuments(r.documents, refs, nil)
 }
 
 func (r *achievementRepositoryImpl) GetAllAchievements(page, pageSize int) ([]*model.AchievementWithReference, int, error) {
-	ctx := context.Background()
 	offset := (page - 1) * pageSize
 
 	// Count total
@@ -271,8 +639,7 @@ func (r *achievementRepositoryImpl) GetAllAchievements(page, pageSize int) ([]*m
 
 	// Get paginated references
 	query := `
-		SELECT id, student_id, mongo_achievement_id, achievement_title, status,
-		       submitted_at, verified_at, verified_by, rejection_note, deleted_at, created_at, updated_at
+		SELECT ` + referenceColumns + `
 		FROM achievement_references
 		WHERE status != $1
 		ORDER BY created_at DESC
@@ -285,47 +652,20 @@ func (r *achievementRepositoryImpl) GetAllAchievements(page, pageSize int) ([]*m
 	}
 	defer rows.Close()
 
-	var results []*model.AchievementWithReference
-
-	for rows.Next() {
-		var ref model.AchievementReference
-		err := rows.Scan(
-			&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.AchievementTitle,
-			&ref.Status, &ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy,
-			&ref.RejectionNote, &ref.DeletedAt, &ref.CreatedAt, &ref.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-
-		mongoObjID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
-			continue
-		}
-
-		var achievement model.Achievement
-		err = r.mongoCollection.FindOne(ctx, bson.M{"_id": mongoObjID}).Decode(&achievement)
-		if err != nil {
-			continue
-		}
-
-		results = append(results, &model.AchievementWithReference{
-			Achievement:   achievement,
-			Status:        ref.Status,
-			SubmittedAt:   ref.SubmittedAt,
-			VerifiedAt:    ref.VerifiedAt,
-			VerifiedBy:    ref.VerifiedBy,
-			RejectionNote: ref.RejectionNote,
-		})
+	refs, err := scanReferences(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	results, err := JoinAchievementDocuments(r.documents, refs, nil)
+	if err != nil {
+		return nil, 0, err
 	}
-
 	return results, totalItems, nil
 }
 
-func (r *achievementRepositoryImpl) GetAchievementsWithFilters(page, pageSize int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.AchievementWithReference, int, error) {
-	ctx := context.Background()
-	offset := (page - 1) * pageSize
-
+// referenceFilterClauses membuat kondisi WHERE achievement_references dari filter daftar prestasi. Placeholder
+// dinomori mulai $1 sesuai urutan args. Filter achievement_type ada di MongoDB sehingga tidak ikut di sini.
+func referenceFilterClauses(filters map[string]interface{}) ([]string, []interface{}) {
 	var whereClauses []string
 	var args []interface{}
 	argCounter := 1
@@ -368,6 +708,29 @@ func (r *achievementRepositoryImpl) GetAchievementsWithFilters(page, pageSize in
 		argCounter++
 	}
 
+	if expired, ok := filters["expired"].(bool); ok {
+		if expired {
+			whereClauses = append(whereClauses, "valid_until < CURRENT_DATE")
+		} else {
+			whereClauses = append(whereClauses, "(valid_until IS NULL OR valid_until >= CURRENT_DATE)")
+		}
+	}
+
+	if expiresBefore, ok := filters["expires_before"].(time.Time); ok {
+		whereClauses = append(whereClauses, fmt.Sprintf("valid_until >= CURRENT_DATE AND valid_until <= $%d", argCounter))
+		args = append(args, expiresBefore.Format(model.ValidUntilLayout))
+		argCounter++
+	}
+
+	return whereClauses, args
+}
+
+func (r *achievementRepositoryImpl) GetAchievementsWithFilters(page, pageSize int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.AchievementWithReference, int, error) {
+	offset := (page - 1) * pageSize
+
+	whereClauses, args := referenceFilterClauses(filters)
+	argCounter := len(args) + 1
+
 	whereClause := strings.Join(whereClauses, " AND ")
 
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM achievement_references WHERE %s", whereClause)
@@ -385,8 +748,7 @@ func (r *achievementRepositoryImpl) GetAchievementsWithFilters(page, pageSize in
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, student_id, mongo_achievement_id, achievement_title, status,
-		       submitted_at, verified_at, verified_by, rejection_note, deleted_at, created_at, updated_at
+		SELECT `+referenceColumns+`
 		FROM achievement_references
 		WHERE %s
 		ORDER BY %s %s
@@ -401,215 +763,1347 @@ func (r *achievementRepositoryImpl) GetAchievementsWithFilters(page, pageSize in
 	}
 	defer rows.Close()
 
-	var results []*model.AchievementWithReference
-
-	for rows.Next() {
-		var ref model.AchievementReference
-		err := rows.Scan(
-			&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.AchievementTitle,
-			&ref.Status, &ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy,
-			&ref.RejectionNote, &ref.DeletedAt, &ref.CreatedAt, &ref.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-
-		mongoObjID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
-			continue
-		}
-
-		var achievement model.Achievement
-		mongoFilter := bson.M{"_id": mongoObjID}
-
-		if achievementType, ok := filters["achievement_type"].(string); ok && achievementType != "" {
-			mongoFilter["achievement_type"] = achievementType
-		}
-
-		err = r.mongoCollection.FindOne(ctx, mongoFilter).Decode(&achievement)
-		if err != nil {
-			continue
-		}
+	refs, err := scanReferences(rows)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		results = append(results, &model.AchievementWithReference{
-			Achievement:   achievement,
-			Status:        ref.Status,
-			SubmittedAt:   ref.SubmittedAt,
-			VerifiedAt:    ref.VerifiedAt,
-			VerifiedBy:    ref.VerifiedBy,
-			RejectionNote: ref.RejectionNote,
-		})
+	mongoFilter := bson.M{}
+	if achievementType, ok := filters["achievement_type"].(string); ok && achievementType != "" {
+		mongoFilter["achievement_type"] = achievementType
 	}
 
+	results, err := JoinAchievementDocuments(r.documents, refs, mongoFilter)
+	if err != nil {
+		return nil, 0, err
+	}
 	return results, totalItems, nil
 }
 
-func (r *achievementRepositoryImpl) UpdateAchievement(referenceID string, achievement *model.Achievement) error {
-	ctx := context.Background()
+func (r *achievementRepositoryImpl) UpdateAchievement(referenceID string, achievement *model.Achievement, expectedVersion int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Versi dinaikkan lebih dulu secara kondisional sehingga hanya satu penulis yang lolos
+	var mongoID string
+	err = tx.QueryRow(`
+		UPDATE achievement_references
+		SET achievement_title = $1, version = version + 1, updated_at = NOW()
+		WHERE id = $2 AND ($3 = 0 OR version = $3)
+		RETURNING mongo_achievement_id
+	`, achievement.Title, referenceID, expectedVersion).Scan(&mongoID)
+	if err != nil {
+		if err == sql.ErrNoRows && expectedVersion != 0 {
+			return ErrAchievementVersionConflict
+		}
+		return err
+	}
+
+	// Anggota prestasi tim berbagi dokumen yang sama, sehingga judul dan versi reference mereka ikut berubah
+	if _, err := tx.Exec(`
+		UPDATE achievement_references
+		SET achievement_title = $1, version = version + 1, updated_at = NOW()
+		WHERE mongo_achievement_id = $2 AND id != $3 AND team_id IS NOT NULL
+	`, achievement.Title, mongoID, referenceID); err != nil {
+		return err
+	}
+
+	// Isi dokumen ditulis ke MongoDB lewat outbox yang di-commit bersama kenaikan versi
+	achievement.UpdatedAt = time.Now()
+	achievement.SearchTerms = model.AchievementSearchTerms(achievement)
+	fields, err := bson.Marshal(bson.M{
+		"achievement_type": achievement.AchievementType,
+		"title":            achievement.Title,
+		"description":      achievement.Description,
+		"details":          achievement.Details,
+		"tags":             achievement.Tags,
+		"schema_version":   achievement.SchemaVersion,
+		"search_terms":     achievement.SearchTerms,
+		"updated_at":       achievement.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode achievement document: %w", err)
+	}
+	if err := enqueueAchievementOutbox(tx, mongoID, model.OutboxOperationUpdate, fields); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.syncAchievementDocument(mongoID)
+	return nil
+}
+
+// TransitionAchievementStatus mengubah status reference secara kondisional (WHERE status = fromStatus)
+// sehingga dua transisi yang berjalan bersamaan tidak saling menimpa. Efek transisi baru ditulis setelah
+// perubahan status berhasil, di dalam transaksi yang sama.
+func (r *achievementRepositoryImpl) TransitionAchievementStatus(id, fromStatus, toStatus string, expectedVersion int, fields map[string]interface{}, effects *model.AchievementTransitionEffects) error {
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		if !transitionColumns[column] {
+			return fmt.Errorf("kolom %s tidak boleh diubah lewat transisi status", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	setClauses := []string{"status = $1", "version = version + 1", "updated_at = NOW()"}
+	args := []interface{}{toStatus}
+	for _, column := range columns {
+		args = append(args, fields[column])
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	args = append(args, id, fromStatus, expectedVersion)
+
+	query := fmt.Sprintf(`
+		UPDATE achievement_references
+		SET %s
+		WHERE id = $%d AND status = $%d AND ($%d = 0 OR version = $%d)
+		RETURNING mongo_achievement_id
+	`, strings.Join(setClauses, ", "), len(args)-2, len(args)-1, len(args), len(args))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var mongoID string
+	if err := tx.QueryRow(query, args...).Scan(&mongoID); err != nil {
+		if err != sql.ErrNoRows {
+			return err
+		}
+		// Bedakan status yang sudah berpindah dengan isi yang diubah tanpa perubahan status
+		var currentStatus string
+		if err := tx.QueryRow(`SELECT status FROM achievement_references WHERE id = $1`, id).Scan(&currentStatus); err == nil && currentStatus == fromStatus {
+			return ErrAchievementVersionConflict
+		}
+		return ErrAchievementStatusConflict
+	}
+
+	if effects != nil {
+		if err := r.writeTransitionEffects(tx, id, mongoID, effects); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if effects != nil && len(effects.DocumentFields) > 0 {
+		r.syncAchievementDocument(mongoID)
+	}
+	return nil
+}
+
+// writeTransitionEffects menulis efek transisi di dalam transaksi yang sudah memegang lock baris reference
+func (r *achievementRepositoryImpl) writeTransitionEffects(tx *sql.Tx, id, mongoID string, effects *model.AchievementTransitionEffects) error {
+	if effects.ResetApprovals {
+		if err := resetAchievementApprovals(tx, id); err != nil {
+			return err
+		}
+	}
+	if effects.ResolveRevisionRequests {
+		if err := resolveRevisionRequests(tx, id); err != nil {
+			return err
+		}
+	}
+	if effects.Snapshot != nil {
+		if err := createAchievementSnapshot(tx, effects.Snapshot); err != nil {
+			return err
+		}
+	}
+	if effects.RevisionRequest != nil {
+		if err := createRevisionRequest(tx, effects.RevisionRequest); err != nil {
+			return err
+		}
+	}
+	if effects.Approval != nil {
+		if err := createAchievementApproval(tx, effects.Approval); err != nil {
+			return err
+		}
+	}
+	if len(effects.DocumentFields) > 0 {
+		set := bson.M{"updated_at": time.Now()}
+		for field, value := range effects.DocumentFields {
+			set[field] = value
+		}
+		payload, err := bson.Marshal(set)
+		if err != nil {
+			return fmt.Errorf("failed to encode achievement document: %w", err)
+		}
+		if err := enqueueAchievementOutbox(tx, mongoID, model.OutboxOperationUpdate, payload); err != nil {
+			return err
+		}
+	}
+	if effects.History != nil {
+		return createAchievementHistory(tx, effects.History)
+	}
+	return nil
+}
+
+// CreateAchievementHistory menyimpan history perubahan status achievement
+func (r *achievementRepositoryImpl) CreateAchievementHistory(history *model.AchievementHistory) error {
+	return createAchievementHistory(r.db, history)
+}
+
+func createAchievementHistory(exec sqlExecutor, history *model.AchievementHistory) error {
+	query := `
+		INSERT INTO achievement_history (id, achievement_id, action, previous_status, new_status, changed_by, on_behalf_of, notes, old_points, new_points, changed_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7, $8, $9, $10, NOW())
+	`
+	if history.ID == "" {
+		history.ID = uuid.New().String()
+	}
+	result, err := exec.Exec(query, history.ID, history.AchievementID, history.Action, history.OldStatus, history.NewStatus, history.ChangedBy, history.OnBehalfOf, history.Note, history.OldPoints, history.NewPoints)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("no rows inserted into achievement_history")
+	}
+
+	return nil
+}
+
+// GetAchievementHistory mengambil riwayat perubahan achievement
+func (r *achievementRepositoryImpl) GetAchievementHistory(achievementID string) ([]*model.AchievementHistory, error) {
+	query := `
+		SELECT ah.id, ah.achievement_id, ah.action, ah.previous_status, ah.new_status,
+		       COALESCE(ah.changed_by::text, ''), COALESCE(u.full_name, 'System'),
+		       ah.on_behalf_of, ob.full_name, ah.notes, ah.old_points, ah.new_points, ah.changed_at
+		FROM achievement_history ah
+		LEFT JOIN users u ON ah.changed_by = u.id
+		LEFT JOIN users ob ON ah.on_behalf_of = ob.id
+		WHERE ah.achievement_id = $1
+		ORDER BY ah.changed_at DESC
+	`
+
+	rows, err := r.db.Query(query, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var histories []*model.AchievementHistory
+	for rows.Next() {
+		history := &model.AchievementHistory{}
+		err := rows.Scan(&history.ID, &history.AchievementID, &history.Action, &history.OldStatus, &history.NewStatus, &history.ChangedBy, &history.ChangedByName, &history.OnBehalfOf, &history.OnBehalfOfName, &history.Note, &history.OldPoints, &history.NewPoints, &history.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+
+	return histories, nil
+}
+
+// CreateAttachment menyimpan attachment file untuk achievement
+func (r *achievementRepositoryImpl) CreateAttachment(attachment *model.AchievementAttachment) error {
+	query := `
+		INSERT INTO achievement_attachments (id, achievement_id, file_name, file_path, file_size, file_type, content_hash, uploaded_by, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NOW())
+	`
+	_, err := r.db.Exec(query, attachment.ID, attachment.AchievementID, attachment.FileName, attachment.FilePath, attachment.FileSize, attachment.FileType, attachment.ContentHash, attachment.UploadedBy)
+	return err
+}
+
+// GetAttachmentsByAchievementID mengambil semua attachment dari achievement. Reference anggota tim memakai
+// dokumen MongoDB yang sama, sehingga bukti yang diunggah salah satu anggota terlihat oleh semua anggota.
+func (r *achievementRepositoryImpl) GetAttachmentsByAchievementID(achievementID string) ([]*model.AchievementAttachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM achievement_attachments
+		WHERE achievement_id IN (
+			SELECT id FROM achievement_references
+			WHERE mongo_achievement_id = (SELECT mongo_achievement_id FROM achievement_references WHERE id = $1)
+		)
+		ORDER BY uploaded_at DESC
+	`
+
+	rows, err := r.db.Query(query, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAttachments(rows)
+}
+
+// attachmentColumns adalah kolom achievement_attachments yang dibaca oleh scanAttachments
+const attachmentColumns = `id, achievement_id, file_name, file_path, file_size, file_type, COALESCE(content_hash, ''), uploaded_by, uploaded_at`
+
+func scanAttachments(rows *sql.Rows) ([]*model.AchievementAttachment, error) {
+	var attachments []*model.AchievementAttachment
+	for rows.Next() {
+		attachment := &model.AchievementAttachment{}
+		err := rows.Scan(&attachment.ID, &attachment.AchievementID, &attachment.FileName, &attachment.FilePath, &attachment.FileSize, &attachment.FileType, &attachment.ContentHash, &attachment.UploadedBy, &attachment.UploadedAt)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// FindAttachmentsByHash mengambil attachment dengan isi file yang sama dari semua prestasi
+func (r *achievementRepositoryImpl) FindAttachmentsByHash(hashes []string) ([]*model.AchievementAttachment, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(hashes))
+	args := make([]interface{}, len(hashes))
+	for i, hash := range hashes {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = hash
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+attachmentColumns+`
+		FROM achievement_attachments
+		WHERE content_hash IN (%s)
+	`, strings.Join(placeholders, ","))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAttachments(rows)
+}
+
+// GetOverdueSubmissions mengambil prestasi yang menunggu verifikasi sejak sebelum submittedBefore beserta data
+// mahasiswa dan dosen walinya. Hanya membaca PostgreSQL karena judul sudah tersimpan di reference.
+func (r *achievementRepositoryImpl) GetOverdueSubmissions(submittedBefore time.Time) ([]*model.OverdueSubmission, error) {
+	query := `
+		SELECT ar.id, ar.achievement_title, ar.student_id, s.student_id, su.full_name,
+		       COALESCE(l.id::text, ''), COALESCE(l.user_id::text, ''), COALESCE(lu.full_name, ''), COALESCE(l.department, ''),
+		       ar.submitted_at, ar.sla_reminded_at, ar.escalated_at
+		FROM achievement_references ar
+		JOIN students s ON ar.student_id = s.id
+		JOIN users su ON s.user_id = su.id
+		LEFT JOIN lecturers l ON s.advisor_id = l.id
+		LEFT JOIN users lu ON l.user_id = lu.id
+		WHERE ar.status = $1 AND ar.submitted_at < $2
+		ORDER BY ar.submitted_at ASC
+	`
+
+	rows, err := r.db.Query(query, model.AchievementStatusSubmitted, submittedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.OverdueSubmission
+	for rows.Next() {
+		item := &model.OverdueSubmission{}
+		err := rows.Scan(&item.AchievementID, &item.Title, &item.StudentID, &item.StudentNumber, &item.StudentName,
+			&item.AdvisorID, &item.AdvisorUserID, &item.AdvisorName, &item.AdvisorDepartment,
+			&item.SubmittedAt, &item.SLARemindedAt, &item.EscalatedAt)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// SetValidUntil mengatur masa berlaku untuk semua reference yang berbagi dokumen MongoDB yang sama
+func (r *achievementRepositoryImpl) SetValidUntil(referenceID string, validUntil *time.Time) error {
+	var value interface{}
+	if validUntil != nil {
+		value = validUntil.Format(model.ValidUntilLayout)
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE achievement_references
+		SET valid_until = $1, expiry_reminded_at = NULL
+		WHERE mongo_achievement_id = (SELECT mongo_achievement_id FROM achievement_references WHERE id = $2)
+	`, value, referenceID)
+	return err
+}
+
+// GetExpiringAchievements mengambil prestasi terverifikasi yang masih berlaku dan habis paling lambat
+// validUntilBefore, dari yang paling cepat habis
+func (r *achievementRepositoryImpl) GetExpiringAchievements(validUntilBefore time.Time) ([]*model.ExpiringAchievement, error) {
+	rows, err := r.db.Query(`
+		SELECT id, achievement_title, student_id, valid_until
+		FROM achievement_references
+		WHERE status = $1 AND valid_until IS NOT NULL
+		  AND valid_until >= CURRENT_DATE AND valid_until <= $2
+		  AND expiry_reminded_at IS NULL
+		ORDER BY valid_until
+	`, model.AchievementStatusVerified, validUntilBefore.Format(model.ValidUntilLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*model.ExpiringAchievement
+	for rows.Next() {
+		item := &model.ExpiringAchievement{}
+		if err := rows.Scan(&item.AchievementID, &item.Title, &item.StudentID, &item.ValidUntil); err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// MarkExpiryReminded mencatat waktu pengingat kedaluwarsa hanya jika belum tercatat
+func (r *achievementRepositoryImpl) MarkExpiryReminded(referenceID string, at time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE achievement_references
+		SET expiry_reminded_at = $1
+		WHERE id = $2 AND expiry_reminded_at IS NULL
+	`, at, referenceID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// GetDuplicateCandidates mengambil prestasi bertipe sama sebagai kandidat pembanding deteksi duplikat.
+// Tipe disimpan di MongoDB sehingga filter tipe dilakukan setelah dokumen dibaca.
+func (r *achievementRepositoryImpl) GetDuplicateCandidates(studentID, achievementType string) ([]*model.AchievementWithReference, error) {
+	query := `
+		SELECT ` + referenceColumns + `
+		FROM achievement_references
+		WHERE status != $1 AND ($2 = '' OR student_id::text = $2)
+	`
+
+	rows, err := r.db.Query(query, model.AchievementStatusDeleted, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs, err := scanReferences(rows)
+	if err != nil {
+		return nil, err
+	}
+	return JoinAchievementDocuments(r.documents, refs, bson.M{"achievement_type": achievementType})
+}
+
+// CreateAchievementSnapshot menyimpan salinan isi prestasi dengan nomor versi berikutnya. Nomor versi
+// dihitung di query yang sama; submit bersamaan ditolak oleh UNIQUE (achievement_id, version).
+func (r *achievementRepositoryImpl) CreateAchievementSnapshot(snapshot *model.AchievementSnapshot) error {
+	return createAchievementSnapshot(r.db, snapshot)
+}
+
+func createAchievementSnapshot(exec sqlExecutor, snapshot *model.AchievementSnapshot) error {
+	if snapshot.ID == "" {
+		snapshot.ID = uuid.New().String()
+	}
+
+	content, err := json.Marshal(snapshot.Content)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO achievement_snapshots (id, achievement_id, version, content, created_by, created_at)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, NOW()
+		FROM achievement_snapshots
+		WHERE achievement_id = $2
+		RETURNING version, created_at
+	`
+	return exec.QueryRow(query, snapshot.ID, snapshot.AchievementID, content, snapshot.CreatedBy).Scan(&snapshot.Version, &snapshot.CreatedAt)
+}
+
+// GetAchievementSnapshots mengambil semua versi isi prestasi, dari versi 1
+func (r *achievementRepositoryImpl) GetAchievementSnapshots(achievementID string) ([]*model.AchievementSnapshot, error) {
+	query := `
+		SELECT id, achievement_id, version, content, created_by, created_at
+		FROM achievement_snapshots
+		WHERE achievement_id = $1
+		ORDER BY version ASC
+	`
+
+	rows, err := r.db.Query(query, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []*model.AchievementSnapshot
+	for rows.Next() {
+		snapshot := &model.AchievementSnapshot{}
+		var raw []byte
+		if err := rows.Scan(&snapshot.ID, &snapshot.AchievementID, &snapshot.Version, &raw, &snapshot.CreatedBy, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &snapshot.Content); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// CreateRevisionRequest menyimpan satu putaran permintaan revisi beserta komentar per field
+func (r *achievementRepositoryImpl) CreateRevisionRequest(request *model.AchievementRevisionRequest) error {
+	return createRevisionRequest(r.db, request)
+}
+
+func createRevisionRequest(exec sqlExecutor, request *model.AchievementRevisionRequest) error {
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+
+	comments, err := json.Marshal(request.Comments)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO achievement_revision_requests (id, achievement_id, round, comments, requested_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at
+	`
+	return exec.QueryRow(query, request.ID, request.AchievementID, request.Round, comments, request.RequestedBy).Scan(&request.CreatedAt)
+}
+
+// GetRevisionRequests mengambil semua putaran permintaan revisi, dari yang terlama
+func (r *achievementRepositoryImpl) GetRevisionRequests(achievementID string) ([]*model.AchievementRevisionRequest, error) {
+	query := `
+		SELECT rr.id, rr.achievement_id, rr.round, rr.comments, rr.requested_by, u.full_name, rr.created_at, rr.resolved_at
+		FROM achievement_revision_requests rr
+		JOIN users u ON rr.requested_by = u.id
+		WHERE rr.achievement_id = $1
+		ORDER BY rr.round ASC
+	`
+
+	rows, err := r.db.Query(query, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*model.AchievementRevisionRequest
+	for rows.Next() {
+		request := &model.AchievementRevisionRequest{}
+		var comments []byte
+		err := rows.Scan(&request.ID, &request.AchievementID, &request.Round, &comments, &request.RequestedBy, &request.RequestedByName, &request.CreatedAt, &request.ResolvedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(comments, &request.Comments); err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+// ResolveRevisionRequests menutup permintaan revisi yang masih terbuka saat mahasiswa submit ulang
+func (r *achievementRepositoryImpl) ResolveRevisionRequests(achievementID string) error {
+	return resolveRevisionRequests(r.db, achievementID)
+}
+
+func resolveRevisionRequests(exec sqlExecutor, achievementID string) error {
+	_, err := exec.Exec(`
+		UPDATE achievement_revision_requests
+		SET resolved_at = NOW()
+		WHERE achievement_id = $1 AND resolved_at IS NULL
+	`, achievementID)
+	return err
+}
+
+// GetApprovalChains mengambil approval chain beserta tahapannya
+func (r *achievementRepositoryImpl) GetApprovalChains(activeOnly bool) ([]*model.ApprovalChain, error) {
+	query := `
+		SELECT id, name, achievement_type, min_points, is_active, created_at
+		FROM approval_chains
+	`
+	if activeOnly {
+		query += " WHERE is_active = TRUE"
+	}
+	query += " ORDER BY created_at ASC"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chains []*model.ApprovalChain
+	chainByID := make(map[string]*model.ApprovalChain)
+	for rows.Next() {
+		chain := &model.ApprovalChain{}
+		if err := rows.Scan(&chain.ID, &chain.Name, &chain.AchievementType, &chain.MinPoints, &chain.IsActive, &chain.CreatedAt); err != nil {
+			return nil, err
+		}
+		chains = append(chains, chain)
+		chainByID[chain.ID] = chain
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadApprovalStages(chainByID, ""); err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// GetApprovalChainByID mengambil satu approval chain (termasuk yang sudah tidak aktif)
+func (r *achievementRepositoryImpl) GetApprovalChainByID(id string) (*model.ApprovalChain, error) {
+	chain := &model.ApprovalChain{}
+	err := r.db.QueryRow(`
+		SELECT id, name, achievement_type, min_points, is_active, created_at
+		FROM approval_chains
+		WHERE id = $1
+	`, id).Scan(&chain.ID, &chain.Name, &chain.AchievementType, &chain.MinPoints, &chain.IsActive, &chain.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadApprovalStages(map[string]*model.ApprovalChain{chain.ID: chain}, chain.ID); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// loadApprovalStages mengisi tahapan chain di chainByID; chainID kosong berarti tahapan semua chain dibaca
+func (r *achievementRepositoryImpl) loadApprovalStages(chainByID map[string]*model.ApprovalChain, chainID string) error {
+	rows, err := r.db.Query(`
+		SELECT id, chain_id, stage_order, name, approver_role, approver_user_ids
+		FROM approval_chain_stages
+		WHERE $1 = '' OR chain_id::text = $1
+		ORDER BY chain_id, stage_order ASC
+	`, chainID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stage model.ApprovalStage
+		// approver_role kosong untuk tahap yang hanya disetujui kelompok user tertentu
+		var approverRole sql.NullString
+		var userIDs []byte
+		if err := rows.Scan(&stage.ID, &stage.ChainID, &stage.StageOrder, &stage.Name, &approverRole, &userIDs); err != nil {
+			return err
+		}
+		stage.ApproverRole = approverRole.String
+		if err := json.Unmarshal(userIDs, &stage.ApproverUserIDs); err != nil {
+			return err
+		}
+		if chain, ok := chainByID[stage.ChainID]; ok {
+			chain.Stages = append(chain.Stages, stage)
+		}
+	}
+
+	return rows.Err()
+}
+
+// CreateApprovalChain menyimpan approval chain dan seluruh tahapannya dalam satu transaksi
+func (r *achievementRepositoryImpl) CreateApprovalChain(chain *model.ApprovalChain) error {
+	if chain.ID == "" {
+		chain.ID = uuid.New().String()
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO approval_chains (id, name, achievement_type, min_points, is_active, created_at)
+		VALUES ($1, $2, $3, $4, TRUE, NOW())
+		RETURNING is_active, created_at
+	`, chain.ID, chain.Name, chain.AchievementType, chain.MinPoints).Scan(&chain.IsActive, &chain.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range chain.Stages {
+		stage := &chain.Stages[i]
+		stage.ID = uuid.New().String()
+		stage.ChainID = chain.ID
+		stage.StageOrder = i + 1
+		if stage.ApproverUserIDs == nil {
+			stage.ApproverUserIDs = []string{}
+		}
+		userIDs, err := json.Marshal(stage.ApproverUserIDs)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO approval_chain_stages (id, chain_id, stage_order, name, approver_role, approver_user_ids)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, stage.ID, stage.ChainID, stage.StageOrder, stage.Name, stage.ApproverRole, userIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeactivateApprovalChain menonaktifkan chain tanpa menghapus riwayat persetujuan yang merujuknya
+func (r *achievementRepositoryImpl) DeactivateApprovalChain(id string) error {
+	result, err := r.db.Exec("UPDATE approval_chains SET is_active = FALSE WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("approval chain tidak ditemukan")
+	}
+
+	return nil
+}
+
+// CreateAchievementApproval mencatat persetujuan satu tahap
+func (r *achievementRepositoryImpl) CreateAchievementApproval(approval *model.AchievementApproval) error {
+	return createAchievementApproval(r.db, approval)
+}
+
+func createAchievementApproval(exec sqlExecutor, approval *model.AchievementApproval) error {
+	if approval.ID == "" {
+		approval.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO achievement_approvals (id, achievement_id, chain_id, stage_order, stage_name, verified_by, on_behalf_of, proposed_points, verified_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING verified_at
+	`
+	return exec.QueryRow(query, approval.ID, approval.AchievementID, approval.ChainID, approval.StageOrder, approval.StageName,
+		approval.VerifiedBy, approval.OnBehalfOf, approval.ProposedPoints).Scan(&approval.VerifiedAt)
+}
+
+// GetAchievementApprovals mengambil persetujuan per tahap untuk satu prestasi, urut sesuai tahap
+func (r *achievementRepositoryImpl) GetAchievementApprovals(achievementID string) ([]*model.AchievementApproval, error) {
+	query := `
+		SELECT aa.id, aa.achievement_id, aa.chain_id, aa.stage_order, aa.stage_name, aa.verified_by, u.full_name,
+		       aa.on_behalf_of, aa.proposed_points, aa.verified_at
+		FROM achievement_approvals aa
+		JOIN users u ON aa.verified_by = u.id
+		WHERE aa.achievement_id = $1
+		ORDER BY aa.stage_order ASC
+	`
+
+	rows, err := r.db.Query(query, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []*model.AchievementApproval
+	for rows.Next() {
+		approval := &model.AchievementApproval{}
+		err := rows.Scan(&approval.ID, &approval.AchievementID, &approval.ChainID, &approval.StageOrder, &approval.StageName,
+			&approval.VerifiedBy, &approval.VerifiedByName, &approval.OnBehalfOf, &approval.ProposedPoints, &approval.VerifiedAt)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+
+	return approvals, nil
+}
+
+// ResetAchievementApprovals menghapus persetujuan tahap yang sudah ada untuk prestasi
+func (r *achievementRepositoryImpl) ResetAchievementApprovals(achievementID string) error {
+	return resetAchievementApprovals(r.db, achievementID)
+}
+
+func resetAchievementApprovals(exec sqlExecutor, achievementID string) error {
+	_, err := exec.Exec("DELETE FROM achievement_approvals WHERE achievement_id = $1", achievementID)
+	return err
+}
+
+// GetPointsRubricRules mengambil aturan rubrik poin, dari yang terlama
+func (r *achievementRepositoryImpl) GetPointsRubricRules(activeOnly bool) ([]*model.PointsRubricRule, error) {
+	query := `
+		SELECT id, achievement_type, conditions, points, COALESCE(description, ''), is_active, created_at
+		FROM points_rubric_rules
+	`
+	if activeOnly {
+		query += " WHERE is_active = TRUE"
+	}
+	query += " ORDER BY created_at ASC"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*model.PointsRubricRule
+	for rows.Next() {
+		rule := &model.PointsRubricRule{}
+		var conditions []byte
+		if err := rows.Scan(&rule.ID, &rule.AchievementType, &conditions, &rule.Points, &rule.Description, &rule.IsActive, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// CreatePointsRubricRule menyimpan aturan rubrik poin baru
+func (r *achievementRepositoryImpl) CreatePointsRubricRule(rule *model.PointsRubricRule) error {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO points_rubric_rules (id, achievement_type, conditions, points, description, is_active, created_at)
+		VALUES ($1, $2, $3, $4, $5, TRUE, NOW())
+		RETURNING is_active, created_at
+	`
+	return r.db.QueryRow(query, rule.ID, rule.AchievementType, conditions, rule.Points, rule.Description).Scan(&rule.IsActive, &rule.CreatedAt)
+}
+
+// DeactivatePointsRubricRule menonaktifkan aturan rubrik poin
+func (r *achievementRepositoryImpl) DeactivatePointsRubricRule(id string) error {
+	result, err := r.db.Exec(`UPDATE points_rubric_rules SET is_active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("aturan rubrik tidak ditemukan")
+	}
+	return nil
+}
+
+const achievementTypeColumns = `code, name_id, name_en, COALESCE(description, ''), is_active, requires_attachment, evidence_rules, created_at, updated_at`
+
+func scanAchievementType(row rowScanner) (*model.AchievementTypeDefinition, error) {
+	t := &model.AchievementTypeDefinition{}
+	var evidenceRules []byte
+	err := row.Scan(&t.Code, &t.NameID, &t.NameEN, &t.Description, &t.IsActive, &t.RequiresAttachment, &evidenceRules, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(evidenceRules, &t.EvidenceRules); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// GetAchievementTypes mengambil katalog tipe prestasi
+func (r *achievementRepositoryImpl) GetAchievementTypes(activeOnly bool) ([]*model.AchievementTypeDefinition, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types`
+	if activeOnly {
+		query += " WHERE is_active = TRUE"
+	}
+	query += " ORDER BY created_at ASC, code ASC"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []*model.AchievementTypeDefinition
+	for rows.Next() {
+		t, err := scanAchievementType(rows)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+
+	return types, nil
+}
+
+// GetAchievementType mengambil satu tipe prestasi berdasarkan kode
+func (r *achievementRepositoryImpl) GetAchievementType(code string) (*model.AchievementTypeDefinition, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types WHERE code = $1`
+
+	t, err := scanAchievementType(r.db.QueryRow(query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// CreateAchievementType menambah tipe prestasi ke katalog
+func (r *achievementRepositoryImpl) CreateAchievementType(t *model.AchievementTypeDefinition) error {
+	query := `
+		INSERT INTO achievement_types (code, name_id, name_en, description, is_active, requires_attachment, evidence_rules, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, TRUE, $5, $6, NOW(), NOW())
+		RETURNING is_active, created_at, updated_at
+	`
+	evidenceRules, err := json.Marshal(t.EvidenceRules)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRow(query, t.Code, t.NameID, t.NameEN, t.Description, t.RequiresAttachment, evidenceRules).
+		Scan(&t.IsActive, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// UpdateAchievementType mengubah nama, pengaturan, atau status aktif tipe prestasi
+func (r *achievementRepositoryImpl) UpdateAchievementType(t *model.AchievementTypeDefinition) error {
+	query := `
+		UPDATE achievement_types
+		SET name_id = $1, name_en = $2, description = $3, is_active = $4, requires_attachment = $5, evidence_rules = $6, updated_at = NOW()
+		WHERE code = $7
+		RETURNING updated_at
+	`
+	evidenceRules, err := json.Marshal(t.EvidenceRules)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRow(query, t.NameID, t.NameEN, t.Description, t.IsActive, t.RequiresAttachment, evidenceRules, t.Code).Scan(&t.UpdatedAt)
+}
+
+// GetTags mengambil katalog tag beserta aliasnya, urut nama
+func (r *achievementRepositoryImpl) GetTags() ([]*model.Tag, error) {
+	rows, err := r.db.Query(`SELECT slug, name, created_at, updated_at FROM tags ORDER BY name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*model.Tag
+	bySlug := make(map[string]*model.Tag)
+	for rows.Next() {
+		tag := &model.Tag{Aliases: []string{}}
+		if err := rows.Scan(&tag.Slug, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+		bySlug[tag.Slug] = tag
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	aliasRows, err := r.db.Query(`SELECT tag_slug, alias FROM tag_aliases ORDER BY alias ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer aliasRows.Close()
 
-	var mongoID string
-	err := r.db.QueryRow("SELECT mongo_achievement_id FROM achievement_references WHERE id = $1", referenceID).Scan(&mongoID)
+	for aliasRows.Next() {
+		var slug, alias string
+		if err := aliasRows.Scan(&slug, &alias); err != nil {
+			return nil, err
+		}
+		if tag, ok := bySlug[slug]; ok {
+			tag.Aliases = append(tag.Aliases, alias)
+		}
+	}
+
+	return tags, aliasRows.Err()
+}
+
+// GetTag mengambil satu tag berdasarkan slug
+func (r *achievementRepositoryImpl) GetTag(slug string) (*model.Tag, error) {
+	tag := &model.Tag{Aliases: []string{}}
+	err := r.db.QueryRow(`SELECT slug, name, created_at, updated_at FROM tags WHERE slug = $1`, slug).
+		Scan(&tag.Slug, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
 	if err != nil {
-		return err
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	mongoObjID, err := primitive.ObjectIDFromHex(mongoID)
+	rows, err := r.db.Query(`SELECT alias FROM tag_aliases WHERE tag_slug = $1 ORDER BY alias ASC`, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		tag.Aliases = append(tag.Aliases, alias)
+	}
+
+	return tag, rows.Err()
+}
+
+// CreateTag menambah tag beserta aliasnya ke katalog
+func (r *achievementRepositoryImpl) CreateTag(tag *model.Tag) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	achievement.UpdatedAt = time.Now()
-	update := bson.M{
-		"$set": bson.M{
-			"achievement_type": achievement.AchievementType,
-			"title":            achievement.Title,
-			"description":      achievement.Description,
-			"details":          achievement.Details,
-			"tags":             achievement.Tags,
-			"points":           achievement.Points,
-			"updated_at":       achievement.UpdatedAt,
-		},
+	err = tx.QueryRow(`
+		INSERT INTO tags (slug, name, name_key, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`, tag.Slug, tag.Name, model.TagKey(tag.Name)).Scan(&tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return err
 	}
+	if err := insertTagAliases(tx, tag); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
-	_, err = r.mongoCollection.UpdateOne(ctx, bson.M{"_id": mongoObjID}, update)
+// UpdateTag mengganti nama dan seluruh alias tag
+func (r *achievementRepositoryImpl) UpdateTag(tag *model.Tag) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	_, err = r.db.Exec("UPDATE achievement_references SET achievement_title = $1, updated_at = NOW() WHERE id = $2", achievement.Title, referenceID)
-	return err
+	if err := updateTag(tx, tag); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *achievementRepositoryImpl) SubmitAchievementForVerification(id string) error {
-	query := `
-		UPDATE achievement_references
-		SET status = $1, submitted_at = NOW(), updated_at = NOW()
-		WHERE id = $2 AND status = $3
-	`
-	_, err := r.db.Exec(query, "submitted", id, "draft")
-	return err
+// DeleteTag menghapus tag dari katalog; prestasi tetap menyimpan nama tag sebagai tag bebas
+func (r *achievementRepositoryImpl) DeleteTag(slug string) error {
+	result, err := r.db.Exec(`DELETE FROM tags WHERE slug = $1`, slug)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("tag tidak ditemukan")
+	}
+	return nil
 }
 
-func (r *achievementRepositoryImpl) VerifyAchievement(id string, verifiedBy string) error {
-	query := `
-		UPDATE achievement_references
-		SET status = $1, verified_at = NOW(), verified_by = $2, updated_at = NOW()
-		WHERE id = $3 AND status = $4
-	`
-	_, err := r.db.Exec(query, "verified", verifiedBy, id, "submitted")
-	return err
+// MergeTags menghapus tag sourceSlugs lalu menyimpan target dalam satu transaksi. Tag sumber dihapus
+// lebih dulu agar alias yang dipindahkan ke target tidak bentrok dengan unique key.
+func (r *achievementRepositoryImpl) MergeTags(target *model.Tag, sourceSlugs []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, slug := range sourceSlugs {
+		if _, err := tx.Exec(`DELETE FROM tags WHERE slug = $1`, slug); err != nil {
+			return err
+		}
+	}
+	if err := updateTag(tx, target); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *achievementRepositoryImpl) RejectAchievement(id string, verifiedBy string, rejectionNote string) error {
-	query := `
-		UPDATE achievement_references
-		SET status = $1, verified_at = NOW(), verified_by = $2, rejection_note = $3, updated_at = NOW()
-		WHERE id = $4 AND status = $5
-	`
-	_, err := r.db.Exec(query, "rejected", verifiedBy, rejectionNote, id, "submitted")
-	return err
+func updateTag(tx *sql.Tx, tag *model.Tag) error {
+	err := tx.QueryRow(`
+		UPDATE tags SET name = $1, name_key = $2, updated_at = NOW()
+		WHERE slug = $3
+		RETURNING updated_at
+	`, tag.Name, model.TagKey(tag.Name), tag.Slug).Scan(&tag.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("tag tidak ditemukan")
+		}
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM tag_aliases WHERE tag_slug = $1`, tag.Slug); err != nil {
+		return err
+	}
+	return insertTagAliases(tx, tag)
 }
 
-func (r *achievementRepositoryImpl) DeleteAchievement(id string) error {
-	query := `
-		UPDATE achievement_references
-		SET status = $1, deleted_at = NOW(), updated_at = NOW()
-		WHERE id = $2 AND status = $3
-	`
-	_, err := r.db.Exec(query, model.AchievementStatusDeleted, id, model.AchievementStatusDraft)
-	return err
+func insertTagAliases(tx *sql.Tx, tag *model.Tag) error {
+	for _, alias := range tag.Aliases {
+		_, err := tx.Exec(`
+			INSERT INTO tag_aliases (alias_key, alias, tag_slug) VALUES ($1, $2, $3)
+		`, model.TagKey(alias), alias, tag.Slug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// CreateAchievementHistory menyimpan history perubahan status achievement
-func (r *achievementRepositoryImpl) CreateAchievementHistory(history *model.AchievementHistory) error {
-	query := `
-		INSERT INTO achievement_history (id, achievement_id, previous_status, new_status, changed_by, notes, changed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())
-	`
-	result, err := r.db.Exec(query, history.ID, history.AchievementID, history.OldStatus, history.NewStatus, history.ChangedBy, history.Note)
+// RenameAchievementTags mengganti tag prestasi yang cocok dengan variants menjadi name dan memperbarui
+// search_terms lewat outbox. Dokumen MongoDB dipakai bersama anggota tim, sehingga versi semua reference ikut dinaikkan.
+func (r *achievementRepositoryImpl) RenameAchievementTags(variants []string, name string) (int, error) {
+	ctx := context.Background()
+
+	variantKeys := make(map[string]bool)
+	patterns := bson.A{}
+	for _, variant := range variants {
+		if model.TagKey(variant) == "" || variantKeys[model.TagKey(variant)] {
+			continue
+		}
+		variantKeys[model.TagKey(variant)] = true
+		patterns = append(patterns, primitive.Regex{
+			Pattern: `^[\s#]*` + regexp.QuoteMeta(model.CleanTag(variant)) + `[\s#]*$`,
+			Options: "i",
+		})
+	}
+	if len(patterns) == 0 {
+		return 0, nil
+	}
+
+	cursor, err := r.mongoCollection.Find(ctx, bson.M{"tags": bson.M{"$in": patterns}})
 	if err != nil {
-		return err
+		return 0, err
+	}
+	var achievements []model.Achievement
+	if err := cursor.All(ctx, &achievements); err != nil {
+		return 0, err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows inserted into achievement_history")
+	// Semua dokumen diubah lewat outbox dalam satu transaksi, dan versi reference dinaikkan agar edit
+	// yang masih memegang tag lama ditolak alih-alih mengembalikannya
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	return nil
+	var mongoIDs []string
+	for i := range achievements {
+		achievement := &achievements[i]
+		if _, err := primitive.ObjectIDFromHex(achievement.ID); err != nil {
+			continue
+		}
+		achievement.Tags = renameTags(achievement.Tags, variantKeys, name)
+		payload, err := bson.Marshal(bson.M{
+			"tags":         achievement.Tags,
+			"search_terms": model.AchievementSearchTerms(achievement),
+			"updated_at":   time.Now(),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode achievement document: %w", err)
+		}
+		if err := enqueueAchievementOutbox(tx, achievement.ID, model.OutboxOperationUpdate, payload); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(`
+			UPDATE achievement_references SET version = version + 1, updated_at = NOW()
+			WHERE mongo_achievement_id = $1
+		`, achievement.ID); err != nil {
+			return 0, err
+		}
+		mongoIDs = append(mongoIDs, achievement.ID)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for _, mongoID := range mongoIDs {
+		r.syncAchievementDocument(mongoID)
+	}
+	return len(mongoIDs), nil
 }
 
-// GetAchievementHistory mengambil riwayat perubahan achievement
-func (r *achievementRepositoryImpl) GetAchievementHistory(achievementID string) ([]*model.AchievementHistory, error) {
-	query := `
-		SELECT ah.id, ah.achievement_id, ah.previous_status, ah.new_status, ah.changed_by, u.full_name, ah.notes, ah.changed_at
-		FROM achievement_history ah
-		JOIN users u ON ah.changed_by = u.id
-		WHERE ah.achievement_id = $1
-		ORDER BY ah.changed_at DESC
-	`
+// renameTags mengganti tag yang key-nya ada di variantKeys menjadi name tanpa menyisakan duplikat
+func renameTags(tags []string, variantKeys map[string]bool, name string) []string {
+	renamed := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if variantKeys[model.TagKey(tag)] {
+			tag = name
+		}
+		if seen[model.TagKey(tag)] {
+			continue
+		}
+		seen[model.TagKey(tag)] = true
+		renamed = append(renamed, tag)
+	}
+	return renamed
+}
 
-	rows, err := r.db.Query(query, achievementID)
+// GetTagUsage menghitung jumlah dokumen prestasi per tag persis seperti yang tersimpan
+func (r *achievementRepositoryImpl) GetTagUsage() (map[string]int, error) {
+	ctx := context.Background()
+
+	cursor, err := r.mongoCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Tag   string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]int, len(results))
+	for _, result := range results {
+		usage[result.Tag] = result.Count
+	}
+	return usage, nil
+}
+
+// GetAchievementStatsByTag menghitung prestasi per tag (persis seperti yang tersimpan) dengan filter
+// akses yang sama dengan GetAchievementStatsByType. Prestasi tim dihitung per anggota.
+func (r *achievementRepositoryImpl) GetAchievementStatsByTag(role, userID string, includeExpired bool) (map[string]*model.TagStat, error) {
+	whereClause := "status != $1"
+	args := []interface{}{model.AchievementStatusDeleted}
+
+	if role == "Mahasiswa" {
+		whereClause += " AND student_id = $2"
+		args = append(args, userID)
+	} else if role == "Dosen Wali" {
+		whereClause += " AND student_id IN (SELECT id FROM students WHERE advisor_id = $2)"
+		args = append(args, userID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT mongo_achievement_id, status, (valid_until IS NOT NULL AND valid_until < CURRENT_DATE), points
+		FROM achievement_references
+		WHERE %s
+	`, whereClause)
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var histories []*model.AchievementHistory
+	type tagRow struct {
+		mongoID, status string
+		expired         bool
+		points          sql.NullInt64
+	}
+	var tagRows []tagRow
+	var mongoIDs []string
 	for rows.Next() {
-		history := &model.AchievementHistory{}
-		err := rows.Scan(&history.ID, &history.AchievementID, &history.OldStatus, &history.NewStatus, &history.ChangedBy, &history.ChangedByName, &history.Note, &history.CreatedAt)
-		if err != nil {
-			return nil, err
+		var row tagRow
+		if err := rows.Scan(&row.mongoID, &row.status, &row.expired, &row.points); err != nil {
+			continue
 		}
-		histories = append(histories, history)
+		tagRows = append(tagRows, row)
+		mongoIDs = append(mongoIDs, row.mongoID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return histories, nil
-}
+	documents, err := r.documents.FindAchievementDocuments(mongoIDs, nil)
+	if err != nil {
+		return nil, err
+	}
 
-// CreateAttachment menyimpan attachment file untuk achievement
-func (r *achievementRepositoryImpl) CreateAttachment(attachment *model.AchievementAttachment) error {
-	query := `
-		INSERT INTO achievement_attachments (id, achievement_id, file_name, file_path, file_size, file_type, uploaded_by, uploaded_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
-	`
-	_, err := r.db.Exec(query, attachment.ID, attachment.AchievementID, attachment.FileName, attachment.FilePath, attachment.FileSize, attachment.FileType, attachment.UploadedBy)
-	return err
+	tagStats := make(map[string]*model.TagStat)
+	for _, row := range tagRows {
+		achievement, ok := documents[row.mongoID]
+		if !ok {
+			continue
+		}
+		status, expired := row.status, row.expired
+
+		for _, tag := range achievement.Tags {
+			stat, ok := tagStats[tag]
+			if !ok {
+				stat = &model.TagStat{Tag: tag, ByStatus: map[string]int{}}
+				tagStats[tag] = stat
+			}
+			stat.Total++
+			stat.ByStatus[status]++
+			if status == model.AchievementStatusVerified && (includeExpired || !expired) {
+				stat.VerifiedPoints += referencePoints(row.points, achievement)
+			}
+		}
+	}
+
+	return tagStats, nil
 }
 
-// GetAttachmentsByAchievementID mengambil semua attachment dari achievement
-func (r *achievementRepositoryImpl) GetAttachmentsByAchievementID(achievementID string) ([]*model.AchievementAttachment, error) {
+// GetAchievementTypeSchema mengambil schema Details versi tertentu; version 0 berarti versi terbaru
+func (r *achievementRepositoryImpl) GetAchievementTypeSchema(achievementType string, version int) (*model.AchievementTypeSchema, error) {
 	query := `
-		SELECT id, achievement_id, file_name, file_path, file_size, file_type, uploaded_by, uploaded_at
-		FROM achievement_attachments
-		WHERE achievement_id = $1
-		ORDER BY uploaded_at DESC
+		SELECT achievement_type, version, schema, created_at
+		FROM achievement_type_schemas
+		WHERE achievement_type = $1 AND ($2 = 0 OR version = $2)
+		ORDER BY version DESC
+		LIMIT 1
 	`
 
-	rows, err := r.db.Query(query, achievementID)
+	schema := &model.AchievementTypeSchema{}
+	var raw []byte
+	err := r.db.QueryRow(query, achievementType, version).Scan(&schema.AchievementType, &schema.Version, &raw, &schema.CreatedAt)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &schema.Schema); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var attachments []*model.AchievementAttachment
-	for rows.Next() {
-		attachment := &model.AchievementAttachment{}
-		err := rows.Scan(&attachment.ID, &attachment.AchievementID, &attachment.FileName, &attachment.FilePath, &attachment.FileSize, &attachment.FileType, &attachment.UploadedBy, &attachment.UploadedAt)
-		if err != nil {
-			return nil, err
-		}
-		attachments = append(attachments, attachment)
+	return schema, nil
+}
+
+// CreateAchievementTypeSchema menyimpan versi baru schema Details; versi lama tetap tersimpan. Nomor versi
+// dihitung di query yang sama agar tidak memakai versi terbaru yang sudah basi.
+func (r *achievementRepositoryImpl) CreateAchievementTypeSchema(schema *model.AchievementTypeSchema) error {
+	raw, err := json.Marshal(schema.Schema)
+	if err != nil {
+		return err
 	}
 
-	return attachments, nil
+	query := `
+		INSERT INTO achievement_type_schemas (achievement_type, version, schema, created_at)
+		SELECT $1, GREATEST(COALESCE(MAX(version), 0) + 1, $2), $3, NOW()
+		FROM achievement_type_schemas
+		WHERE achievement_type = $1
+		RETURNING version, created_at
+	`
+	err = r.db.QueryRow(query, schema.AchievementType, schema.Version, raw).Scan(&schema.Version, &schema.CreatedAt)
+	// Penerbitan bersamaan bisa menghitung versi yang sama dan ditolak oleh primary key
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrSchemaVersionConflict
+	}
+	return err
 }
 
 // GetAchievementStatsByPeriod mengambil statistik achievement berdasarkan periode waktu
-func (r *achievementRepositoryImpl) GetAchievementStatsByPeriod(startDate, endDate time.Time, role, userID string) (map[string]interface{}, error) {
-	ctx := context.Background()
-
+func (r *achievementRepositoryImpl) GetAchievementStatsByPeriod(startDate, endDate time.Time, role, userID string, includeExpired bool) (map[string]interface{}, error) {
 	whereClause := "status != $1 AND created_at >= $2 AND created_at <= $3"
 	args := []interface{}{model.AchievementStatusDeleted, startDate, endDate}
 
@@ -622,7 +2116,7 @@ func (r *achievementRepositoryImpl) GetAchievementStatsByPeriod(startDate, endDa
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, student_id, mongo_achievement_id, status
+		SELECT id, student_id, mongo_achievement_id, status, COALESCE(valid_until < CURRENT_DATE, FALSE), points
 		FROM achievement_references
 		WHERE %s
 	`, whereClause)
@@ -636,33 +2130,48 @@ func (r *achievementRepositoryImpl) GetAchievementStatsByPeriod(startDate, endDa
 	statusCount := make(map[string]int)
 	typeCount := make(map[string]int)
 	totalPoints := 0
-	var mongoIDs []primitive.ObjectID
+	var mongoIDs []string
+	countsPoints := make(map[int]bool)
+	var rowPoints []sql.NullInt64
 
 	for rows.Next() {
 		var id, studentID, mongoID, status string
-		if err := rows.Scan(&id, &studentID, &mongoID, &status); err != nil {
+		var expired bool
+		var points sql.NullInt64
+		if err := rows.Scan(&id, &studentID, &mongoID, &status, &expired, &points); err != nil {
 			continue
 		}
 
 		statusCount[status]++
 
-		objID, err := primitive.ObjectIDFromHex(mongoID)
-		if err != nil {
+		if _, err := primitive.ObjectIDFromHex(mongoID); err != nil {
 			continue
 		}
-		mongoIDs = append(mongoIDs, objID)
+		if status == model.AchievementStatusVerified && (includeExpired || !expired) {
+			countsPoints[len(mongoIDs)] = true
+		}
+		mongoIDs = append(mongoIDs, mongoID)
+		rowPoints = append(rowPoints, points)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	for _, objID := range mongoIDs {
-		var achievement model.Achievement
-		err := r.mongoCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&achievement)
-		if err != nil {
+	documents, err := r.documents.FindAchievementDocuments(mongoIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prestasi tim dihitung per reference, sehingga dokumen yang sama bisa muncul lebih dari sekali dengan poin berbeda
+	for i, mongoID := range mongoIDs {
+		achievement, ok := documents[mongoID]
+		if !ok {
 			continue
 		}
 
 		typeCount[achievement.AchievementType]++
-		if statusCount["verified"] > 0 {
-			totalPoints += achievement.Points
+		if countsPoints[i] {
+			totalPoints += referencePoints(rowPoints[i], achievement)
 		}
 	}
 
@@ -682,8 +2191,6 @@ func (r *achievementRepositoryImpl) GetAchievementStatsByPeriod(startDate, endDa
 
 // GetAchievementStatsByType mengambil statistik achievement berdasarkan jenis achievement
 func (r *achievementRepositoryImpl) GetAchievementStatsByType(role, userID string) (map[string]interface{}, error) {
-	ctx := context.Background()
-
 	whereClause := "status != $1"
 	args := []interface{}{model.AchievementStatusDeleted}
 
@@ -707,24 +2214,31 @@ func (r *achievementRepositoryImpl) GetAchievementStatsByType(role, userID strin
 	}
 	defer rows.Close()
 
-	typeStats := make(map[string]map[string]int)
-
+	var mongoIDs, statuses []string
 	for rows.Next() {
 		var mongoID, status string
 		if err := rows.Scan(&mongoID, &status); err != nil {
 			continue
 		}
+		mongoIDs = append(mongoIDs, mongoID)
+		statuses = append(statuses, status)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		objID, err := primitive.ObjectIDFromHex(mongoID)
-		if err != nil {
-			continue
-		}
+	documents, err := r.documents.FindAchievementDocuments(mongoIDs, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		var achievement model.Achievement
-		err = r.mongoCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&achievement)
-		if err != nil {
+	typeStats := make(map[string]map[string]int)
+	for i, mongoID := range mongoIDs {
+		achievement, ok := documents[mongoID]
+		if !ok {
 			continue
 		}
+		status := statuses[i]
 
 		if _, ok := typeStats[achievement.AchievementType]; !ok {
 			typeStats[achievement.AchievementType] = make(map[string]int)
@@ -738,44 +2252,54 @@ func (r *achievementRepositoryImpl) GetAchievementStatsByType(role, userID strin
 }
 
 // GetTopStudents mengambil top students berdasarkan total poin achievement yang diverifikasi
-func (r *achievementRepositoryImpl) GetTopStudents(limit int) ([]*model.StudentStats, error) {
-	ctx := context.Background()
-
+func (r *achievementRepositoryImpl) GetTopStudents(limit int, includeExpired bool) ([]*model.StudentStats, error) {
 	// Get all verified achievement references
 	query := `
-		SELECT ar.student_id, s.student_id, u.full_name, ar.mongo_achievement_id
+		SELECT ar.student_id, s.student_id, u.full_name, ar.mongo_achievement_id, ar.team_id IS NOT NULL, ar.points
 		FROM achievement_references ar
 		JOIN students s ON ar.student_id = s.id
 		JOIN users u ON s.user_id = u.id
-		WHERE ar.status = $1
+		WHERE ar.status = $1 AND ($2 OR ar.valid_until IS NULL OR ar.valid_until >= CURRENT_DATE)
 		ORDER BY ar.student_id
 	`
 
-	rows, err := r.db.Query(query, "verified")
+	rows, err := r.db.Query(query, "verified", includeExpired)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	// Group by student and calculate total points
-	studentMap := make(map[string]*model.StudentStats)
-
+	type verifiedRow struct {
+		studentID, nim, name, mongoID string
+		isTeam                        bool
+		points                        sql.NullInt64
+	}
+	var verifiedRows []verifiedRow
+	var mongoIDs []string
 	for rows.Next() {
-		var studentID, nim, name, mongoID string
-
-		if err := rows.Scan(&studentID, &nim, &name, &mongoID); err != nil {
+		var row verifiedRow
+		if err := rows.Scan(&row.studentID, &row.nim, &row.name, &row.mongoID, &row.isTeam, &row.points); err != nil {
 			continue
 		}
+		verifiedRows = append(verifiedRows, row)
+		mongoIDs = append(mongoIDs, row.mongoID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Reference lama belum menyimpan poin, ambil dari MongoDB dalam satu batch
+	documents, err := r.documents.FindAchievementDocuments(mongoIDs, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		points := 0 // Default points if MongoDB query fails
+	// Group by student and calculate total points
+	studentMap := make(map[string]*model.StudentStats)
 
-		// Try to get points from MongoDB
-		if objID, err := primitive.ObjectIDFromHex(mongoID); err == nil {
-			var achievement model.Achievement
-			if err := r.mongoCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&achievement); err == nil {
-				points = achievement.Points
-			}
-		}
+	for _, row := range verifiedRows {
+		studentID, nim, name, isTeam := row.studentID, row.nim, row.name, row.isTeam
+		points := referencePoints(row.points, documents[row.mongoID]) // Default 0 points if the document is missing
 
 		if studentMap[studentID] == nil {
 			studentMap[studentID] = &model.StudentStats{
@@ -789,6 +2313,9 @@ func (r *achievementRepositoryImpl) GetTopStudents(limit int) ([]*model.StudentS
 
 		studentMap[studentID].TotalAchievements++
 		studentMap[studentID].TotalPoints += points
+		if isTeam {
+			studentMap[studentID].TeamAchievements++
+		}
 	}
 
 	// Convert map to slice and sort by total points
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+	"uas_be/app/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestGetPurgeCandidates_MissingDocument tests a trashed reference is still a purge candidate
+// when its MongoDB document no longer exists
+func TestGetPurgeCandidates_MissingDocument(t *testing.T) {
+	// Arrange
+	referenceID := uuid.New().String()
+	teamID := uuid.New().String()
+	deletedAt := time.Now().Add(-60 * 24 * time.Hour)
+	repo := fixedRowsRepository(NewMockAchievementDocumentStore(),
+		fixedResult{[]string{"id", "deleted_at", "team_id"}, [][]driver.Value{{referenceID, deletedAt, teamID}}},
+	)
+
+	// Act
+	candidates, err := repo.GetPurgeCandidates(time.Now().Add(-30 * 24 * time.Hour))
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, candidates, 1) {
+		assert.Equal(t, referenceID, candidates[0].ReferenceID)
+		assert.True(t, deletedAt.Equal(candidates[0].DeletedAt))
+		if assert.NotNil(t, candidates[0].TeamID) {
+			assert.Equal(t, teamID, *candidates[0].TeamID)
+		}
+	}
+}
+
+// pendingDocumentFixture membuat reference yang dokumen MongoDB-nya baru tercatat di outbox, seperti setelah
+// flush pasca-commit gagal
+func pendingDocumentFixture(t *testing.T) (*achievementRepositoryImpl, *MockAchievementOutboxStore, *MockAchievementDocumentStore, *model.AchievementReference) {
+	documents := NewMockAchievementDocumentStore()
+	store := NewMockAchievementOutboxStore()
+	ref := &model.AchievementReference{ID: uuid.New().String(), StudentID: uuid.New().String(), MongoAchievementID: primitive.NewObjectID().Hex()}
+	payload, _ := bson.Marshal(&model.Achievement{Title: "Juara 1 Gemastik"})
+	store.Enqueue(ref.MongoAchievementID, model.OutboxOperationUpsert, payload, time.Now())
+
+	repo := fixedRowsRepository(documents, referenceResult(t, []*model.AchievementReference{ref}))
+	repo.outbox = NewAchievementOutbox(store, documents)
+	return repo, store, documents, ref
+}
+
+// TestGetAchievementByID_FlushesPendingDocument tests an achievement whose post-commit flush failed is
+// readable right away because pending outbox entries are applied before the document is read
+func TestGetAchievementByID_FlushesPendingDocument(t *testing.T) {
+	// Arrange
+	repo, store, _, ref := pendingDocumentFixture(t)
+
+	// Act
+	achievement, err := repo.GetAchievementByID(ref.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, achievement) {
+		assert.Equal(t, ref.ID, achievement.ReferenceID)
+		assert.Equal(t, "Juara 1 Gemastik", achievement.Title)
+	}
+	assert.Empty(t, store.Entries())
+}
+
+// TestGetAchievementByID_DocumentPending tests ErrAchievementDocumentPending is returned while MongoDB
+// still cannot be written, and the entry stays queued for the retry job
+func TestGetAchievementByID_DocumentPending(t *testing.T) {
+	// Arrange
+	repo, store, documents, ref := pendingDocumentFixture(t)
+	documents.FailWrites = 1
+
+	// Act
+	achievement, err := repo.GetAchievementByID(ref.ID)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAchievementDocumentPending)
+	assert.Nil(t, achievement)
+	if assert.Len(t, store.Entries(), 1) {
+		assert.Equal(t, 1, store.Entries()[0].Attempts)
+	}
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"uas_be/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchCandidateLimit adalah jumlah dokumen dengan skor tertinggi yang diambil dari MongoDB sebelum
+// difilter di PostgreSQL, agar kata yang sangat umum tidak memindai seluruh collection
+const searchCandidateLimit = 1000
+
+// AchievementDocumentSearcher mencari dokumen prestasi dengan text index MongoDB
+type AchievementDocumentSearcher interface {
+	// SearchAchievementDocuments mengembalikan skor relevansi (textScore) per ID dokumen (hex) untuk maksimal
+	// limit dokumen dengan skor tertinggi yang cocok dengan search dan filter tambahan
+	SearchAchievementDocuments(search string, filter bson.M, limit int) (map[string]float64, error)
+}
+
+func (s *mongoDocumentStore) SearchAchievementDocuments(search string, filter bson.M, limit int) (map[string]float64, error) {
+	if s.collection == nil {
+		return nil, errors.New("MongoDB belum terhubung")
+	}
+	ctx := context.Background()
+
+	query := bson.M{"$text": bson.M{"$search": search}}
+	for key, value := range filter {
+		query[key] = value
+	}
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1, "score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(int64(limit))
+
+	cursor, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	scores := make(map[string]float64)
+	for cursor.Next(ctx) {
+		objID, ok := cursor.Current.Lookup("_id").ObjectIDOK()
+		if !ok {
+			continue
+		}
+		scores[objID.Hex()] = cursor.Current.Lookup("score").Double()
+	}
+	return scores, cursor.Err()
+}
+
+// SearchAchievements mengambil kandidat dari MongoDB, lalu reference yang lolos filter dari PostgreSQL.
+// Anggota prestasi tim mendapat skor dokumen yang sama; skor sama diurutkan dari yang terbaru.
+func (r *achievementRepositoryImpl) SearchAchievements(search string, filters map[string]interface{}, page, pageSize int) ([]*model.AchievementSearchResult, int, error) {
+	mongoFilter := bson.M{}
+	if achievementType, ok := filters["achievement_type"].(string); ok && achievementType != "" {
+		mongoFilter["achievement_type"] = achievementType
+	}
+
+	// Filter PostgreSQL (cakupan peran, status, tanggal) dijalankan lebih dulu lalu dibawa ke MongoDB sebagai
+	// daftar _id, agar kandidat dengan skor tertinggi tidak habis oleh prestasi di luar cakupan. Tanpa filter,
+	// hanya kandidat teratas yang diambil agar kata yang sangat umum tidak memindai seluruh collection.
+	whereClauses, args := referenceFilterClauses(filters)
+	limit := max(searchCandidateLimit, page*pageSize)
+	if len(whereClauses) > 1 {
+		scopedIDs, err := r.scopedDocumentIDs(whereClauses, args)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(scopedIDs) == 0 {
+			return []*model.AchievementSearchResult{}, 0, nil
+		}
+		mongoFilter["_id"] = bson.M{"$in": scopedIDs}
+		limit = len(scopedIDs)
+	}
+
+	scores, err := r.searcher.SearchAchievementDocuments(search, mongoFilter, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(scores) == 0 {
+		return []*model.AchievementSearchResult{}, 0, nil
+	}
+
+	placeholders := make([]string, 0, len(scores))
+	for mongoID := range scores {
+		args = append(args, mongoID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	whereClauses = append(whereClauses, fmt.Sprintf("mongo_achievement_id IN (%s)", strings.Join(placeholders, ",")))
+
+	query := `
+		SELECT ` + referenceColumns + `
+		FROM achievement_references
+		WHERE ` + strings.Join(whereClauses, " AND ") + `
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	refs, err := scanReferences(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		return scores[refs[i].MongoAchievementID] > scores[refs[j].MongoAchievementID]
+	})
+
+	total := len(refs)
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	achievements, err := JoinAchievementDocuments(r.documents, refs[start:end], nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	refScores := make(map[string]float64, end-start)
+	for _, ref := range refs[start:end] {
+		refScores[ref.ID] = scores[ref.MongoAchievementID]
+	}
+	results := make([]*model.AchievementSearchResult, len(achievements))
+	for i, achievement := range achievements {
+		results[i] = &model.AchievementSearchResult{
+			AchievementWithReference: *achievement,
+			Score:                    refScores[achievement.ReferenceID],
+		}
+	}
+	return results, total, nil
+}
+
+// scopedDocumentIDs mengambil ID dokumen MongoDB dari reference yang lolos whereClauses
+func (r *achievementRepositoryImpl) scopedDocumentIDs(whereClauses []string, args []interface{}) ([]primitive.ObjectID, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT mongo_achievement_id
+		FROM achievement_references
+		WHERE `+strings.Join(whereClauses, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objIDs []primitive.ObjectID
+	for rows.Next() {
+		var mongoID string
+		if err := rows.Scan(&mongoID); err != nil {
+			continue
+		}
+		if objID, err := primitive.ObjectIDFromHex(mongoID); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	return objIDs, rows.Err()
+}
+
+// BackfillSearchTerms mengisi search_terms dokumen lama yang belum memilikinya lewat outbox, per batch
+// documentBatchSize. Dengan dryRun hanya jumlah dokumen yang perlu diisi yang dihitung.
+func (r *achievementRepositoryImpl) BackfillSearchTerms(dryRun bool) (int, error) {
+	if r.mongoCollection == nil {
+		return 0, errors.New("MongoDB belum terhubung")
+	}
+	ctx := context.Background()
+
+	cursor, err := r.mongoCollection.Find(ctx, bson.M{"search_terms": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, err
+	}
+	var achievements []model.Achievement
+	if err := cursor.All(ctx, &achievements); err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return len(achievements), nil
+	}
+
+	filled := 0
+	for start := 0; start < len(achievements); start += documentBatchSize {
+		mongoIDs, err := r.enqueueSearchTerms(achievements[start:min(start+documentBatchSize, len(achievements))])
+		if err != nil {
+			return filled, err
+		}
+		for _, mongoID := range mongoIDs {
+			r.syncAchievementDocument(mongoID)
+		}
+		filled += len(mongoIDs)
+	}
+	return filled, nil
+}
+
+// enqueueSearchTerms menyimpan entri outbox search_terms untuk achievements dalam satu transaksi
+func (r *achievementRepositoryImpl) enqueueSearchTerms(achievements []model.Achievement) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var mongoIDs []string
+	for i := range achievements {
+		achievement := &achievements[i]
+		if _, err := primitive.ObjectIDFromHex(achievement.ID); err != nil {
+			continue
+		}
+		// Array kosong tetap disimpan agar dokumen tidak dipilih lagi pada backfill berikutnya
+		terms := model.AchievementSearchTerms(achievement)
+		if terms == nil {
+			terms = []string{}
+		}
+		payload, err := bson.Marshal(bson.M{"search_terms": terms})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode achievement document: %w", err)
+		}
+		if err := enqueueAchievementOutbox(tx, achievement.ID, model.OutboxOperationUpdate, payload); err != nil {
+			return nil, err
+		}
+		mongoIDs = append(mongoIDs, achievement.ID)
+	}
+	return mongoIDs, tx.Commit()
+}
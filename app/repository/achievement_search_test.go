@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"uas_be/app/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestSearchAchievements_ScopeBeyondCandidateLimit tests the student scope is applied in MongoDB, so an advisee's
+// low-scoring achievement is still found when more than 1000 higher-scoring achievements are outside the scope
+func TestSearchAchievements_ScopeBeyondCandidateLimit(t *testing.T) {
+	// Arrange
+	documents := NewMockAchievementDocumentStore()
+	for i := 0; i < 1001; i++ {
+		payload, _ := bson.Marshal(&model.Achievement{Title: fmt.Sprintf("Juara %d Gemastik", i)})
+		documents.ReplaceAchievementDocument(primitive.NewObjectID().Hex(), payload)
+	}
+	advisee := &model.AchievementReference{ID: uuid.New().String(), StudentID: uuid.New().String(), MongoAchievementID: primitive.NewObjectID().Hex()}
+	payload, _ := bson.Marshal(&model.Achievement{Title: "Finalis nasional", Description: "Kategori Gemastik"})
+	documents.ReplaceAchievementDocument(advisee.MongoAchievementID, payload)
+	repo := fixedRowsRepository(documents,
+		fixedResult{[]string{"mongo_achievement_id"}, [][]driver.Value{{advisee.MongoAchievementID}}},
+		referenceResult(t, []*model.AchievementReference{advisee}),
+	)
+
+	// Act
+	results, total, err := repo.SearchAchievements("gemastik", map[string]interface{}{"student_ids": []string{advisee.StudentID}}, 1, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, advisee.ID, results[0].ReferenceID)
+		assert.Equal(t, 5.0, results[0].Score)
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"uas_be/app/model"
+
+	"github.com/google/uuid"
+)
+
+// CommentRepository adalah interface untuk akses data komentar prestasi
+type CommentRepository interface {
+	// CreateComment menyimpan komentar atau balasan baru
+	CreateComment(comment *model.AchievementComment) error
+
+	// GetCommentByID mengambil komentar berdasarkan ID
+	GetCommentByID(id string) (*model.AchievementComment, error)
+
+	// GetCommentsByAchievementID mengambil semua komentar prestasi, dari yang terlama
+	GetCommentsByAchievementID(achievementID string) ([]*model.AchievementComment, error)
+
+	// UpdateComment mengubah isi dan mention komentar
+	UpdateComment(comment *model.AchievementComment) error
+
+	// DeleteComment menghapus komentar secara soft delete
+	DeleteComment(id string) error
+}
+
+// commentRepositoryImpl adalah implementasi dari CommentRepository
+type commentRepositoryImpl struct {
+	db *sql.DB
+}
+
+// NewCommentRepository membuat instance repository komentar baru
+func NewCommentRepository(db *sql.DB) CommentRepository {
+	return &commentRepositoryImpl{db: db}
+}
+
+const commentColumns = `c.id, c.achievement_id, c.parent_id, c.author_id, u.full_name, c.body, c.mentions,
+		       c.attachment_id, c.created_at, c.edited_at, c.deleted_at`
+
+func scanComment(row rowScanner) (*model.AchievementComment, error) {
+	comment := &model.AchievementComment{}
+	var mentions []byte
+	err := row.Scan(
+		&comment.ID, &comment.AchievementID, &comment.ParentID, &comment.AuthorID, &comment.AuthorName,
+		&comment.Body, &mentions, &comment.AttachmentID, &comment.CreatedAt, &comment.EditedAt, &comment.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mentions, &comment.Mentions); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// CreateComment menyimpan komentar atau balasan baru
+func (r *commentRepositoryImpl) CreateComment(comment *model.AchievementComment) error {
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+
+	mentions, err := json.Marshal(comment.Mentions)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO achievement_comments (id, achievement_id, parent_id, author_id, body, mentions, attachment_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRow(query, comment.ID, comment.AchievementID, comment.ParentID, comment.AuthorID,
+		comment.Body, mentions, comment.AttachmentID).Scan(&comment.CreatedAt)
+}
+
+// GetCommentByID mengambil komentar berdasarkan ID
+func (r *commentRepositoryImpl) GetCommentByID(id string) (*model.AchievementComment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM achievement_comments c
+		JOIN users u ON c.author_id = u.id
+		WHERE c.id = $1
+	`
+
+	comment, err := scanComment(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// GetCommentsByAchievementID mengambil semua komentar prestasi, dari yang terlama
+func (r *commentRepositoryImpl) GetCommentsByAchievementID(achievementID string) ([]*model.AchievementComment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM achievement_comments c
+		JOIN users u ON c.author_id = u.id
+		WHERE c.achievement_id = $1
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*model.AchievementComment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// UpdateComment mengubah isi dan mention komentar
+func (r *commentRepositoryImpl) UpdateComment(comment *model.AchievementComment) error {
+	mentions, err := json.Marshal(comment.Mentions)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE achievement_comments
+		SET body = $1, mentions = $2, edited_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING edited_at
+	`
+	return r.db.QueryRow(query, comment.Body, mentions, comment.ID).Scan(&comment.EditedAt)
+}
+
+// DeleteComment menghapus komentar secara soft delete; isi dikosongkan, balasan tetap ditampilkan
+func (r *commentRepositoryImpl) DeleteComment(id string) error {
+	query := `UPDATE achievement_comments SET body = '', mentions = '[]', deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.db.Exec(query, id)
+	return err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 	"uas_be/app/model"
 )
 
@@ -27,6 +28,27 @@ type LecturerRepository interface {
 	
 	// DeleteLecturer menghapus lecturer
 	DeleteLecturer(id string) error
+
+	// CreateDelegation menyimpan pelimpahan wewenang verifikasi
+	CreateDelegation(delegation *model.VerificationDelegation) error
+
+	// GetDelegationByID mengambil pelimpahan berdasarkan ID
+	GetDelegationByID(id string) (*model.VerificationDelegation, error)
+
+	// GetDelegationsByLecturerID mengambil pelimpahan yang diberikan maupun diterima dosen
+	GetDelegationsByLecturerID(lecturerID string) ([]*model.VerificationDelegation, error)
+
+	// GetActiveDelegatorIDs mengambil ID dosen wali yang melimpahkan wewenang ke dosen pada waktu tertentu
+	GetActiveDelegatorIDs(delegateID string, at time.Time) ([]string, error)
+
+	// RevokeDelegation membatalkan pelimpahan
+	RevokeDelegation(id string) error
+
+	// GetDepartmentReviewers mengambil dosen yang menjadi reviewer eskalasi untuk departemen
+	GetDepartmentReviewers(department string) ([]*model.Lecturer, error)
+
+	// SetDepartmentReviewer menandai atau mencabut dosen sebagai reviewer departemen
+	SetDepartmentReviewer(id string, enabled bool) error
 }
 
 // lecturerRepositoryImpl adalah implementasi dari LecturerRepository
@@ -176,3 +198,140 @@ func (r *lecturerRepositoryImpl) DeleteLecturer(id string) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// CreateDelegation menyimpan pelimpahan wewenang verifikasi
+func (r *lecturerRepositoryImpl) CreateDelegation(delegation *model.VerificationDelegation) error {
+	query := `
+		INSERT INTO verification_delegations (id, delegator_id, delegate_id, start_date, end_date, reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+	`
+	_, err := r.db.Exec(query, delegation.ID, delegation.DelegatorID, delegation.DelegateID,
+		delegation.StartDate, delegation.EndDate, delegation.Reason, delegation.CreatedBy)
+	return err
+}
+
+// GetDelegationByID mengambil pelimpahan berdasarkan ID
+func (r *lecturerRepositoryImpl) GetDelegationByID(id string) (*model.VerificationDelegation, error) {
+	query := `
+		SELECT id, delegator_id, delegate_id, start_date, end_date, COALESCE(reason, ''), created_by, revoked_at, created_at
+		FROM verification_delegations WHERE id = $1
+	`
+
+	delegation := &model.VerificationDelegation{}
+	err := r.db.QueryRow(query, id).Scan(
+		&delegation.ID, &delegation.DelegatorID, &delegation.DelegateID, &delegation.StartDate,
+		&delegation.EndDate, &delegation.Reason, &delegation.CreatedBy, &delegation.RevokedAt, &delegation.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// GetDelegationsByLecturerID mengambil pelimpahan yang diberikan maupun diterima dosen
+func (r *lecturerRepositoryImpl) GetDelegationsByLecturerID(lecturerID string) ([]*model.VerificationDelegation, error) {
+	query := `
+		SELECT id, delegator_id, delegate_id, start_date, end_date, COALESCE(reason, ''), created_by, revoked_at, created_at
+		FROM verification_delegations
+		WHERE delegator_id = $1 OR delegate_id = $1
+		ORDER BY start_date DESC
+	`
+
+	rows, err := r.db.Query(query, lecturerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var delegations []*model.VerificationDelegation
+	for rows.Next() {
+		delegation := &model.VerificationDelegation{}
+		err := rows.Scan(
+			&delegation.ID, &delegation.DelegatorID, &delegation.DelegateID, &delegation.StartDate,
+			&delegation.EndDate, &delegation.Reason, &delegation.CreatedBy, &delegation.RevokedAt, &delegation.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, delegation)
+	}
+
+	return delegations, nil
+}
+
+// GetActiveDelegatorIDs mengambil ID dosen wali yang melimpahkan wewenang ke dosen pada waktu tertentu
+func (r *lecturerRepositoryImpl) GetActiveDelegatorIDs(delegateID string, at time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT delegator_id
+		FROM verification_delegations
+		WHERE delegate_id = $1 AND revoked_at IS NULL
+		  AND start_date <= $2::date AND end_date >= $2::date
+	`
+
+	rows, err := r.db.Query(query, delegateID, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var delegatorIDs []string
+	for rows.Next() {
+		var delegatorID string
+		if err := rows.Scan(&delegatorID); err != nil {
+			return nil, err
+		}
+		delegatorIDs = append(delegatorIDs, delegatorID)
+	}
+
+	return delegatorIDs, nil
+}
+
+// RevokeDelegation membatalkan pelimpahan
+func (r *lecturerRepositoryImpl) RevokeDelegation(id string) error {
+	query := `UPDATE verification_delegations SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// GetDepartmentReviewers mengambil dosen yang menjadi reviewer eskalasi untuk departemen
+func (r *lecturerRepositoryImpl) GetDepartmentReviewers(department string) ([]*model.Lecturer, error) {
+	query := `
+		SELECT id, user_id, lecturer_id, department, created_at
+		FROM lecturers
+		WHERE department = $1 AND department_reviewer = TRUE
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, department)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lecturers []*model.Lecturer
+	for rows.Next() {
+		lecturer := &model.Lecturer{}
+		if err := rows.Scan(&lecturer.ID, &lecturer.UserID, &lecturer.LecturerID, &lecturer.Department, &lecturer.CreatedAt); err != nil {
+			return nil, err
+		}
+		lecturers = append(lecturers, lecturer)
+	}
+	return lecturers, nil
+}
+
+// SetDepartmentReviewer menandai atau mencabut dosen sebagai reviewer departemen
+func (r *lecturerRepositoryImpl) SetDepartmentReviewer(id string, enabled bool) error {
+	result, err := r.db.Exec(`UPDATE lecturers SET department_reviewer = $1 WHERE id = $2`, enabled, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
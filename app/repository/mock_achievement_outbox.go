@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"uas_be/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInjectedFault adalah error yang dikembalikan mock saat kegagalan sengaja disuntikkan
+var ErrInjectedFault = errors.New("injected fault")
+
+// MockAchievementOutboxStore adalah mock AchievementOutboxStore. Perubahan di dalam WithOutboxLock baru
+// disimpan jika fn berhasil, sama seperti transaksi yang di-rollback.
+type MockAchievementOutboxStore struct {
+	mu      sync.Mutex
+	entries []*model.AchievementOutboxEntry
+	nextID  int64
+
+	// FailComplete membuat CompleteOutboxEntry gagal sebanyak n kali, mensimulasikan proses yang berhenti
+	// setelah MongoDB ditulis tetapi sebelum entri outbox di-commit
+	FailComplete int
+}
+
+// NewMockAchievementOutboxStore membuat instance mock outbox store
+func NewMockAchievementOutboxStore() *MockAchievementOutboxStore {
+	return &MockAchievementOutboxStore{}
+}
+
+// Enqueue mencatat entri seperti enqueueAchievementOutbox di transaksi yang sudah di-commit
+func (m *MockAchievementOutboxStore) Enqueue(mongoID, operation string, payload []byte, now time.Time) *model.AchievementOutboxEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	entry := &model.AchievementOutboxEntry{
+		ID:                 m.nextID,
+		MongoAchievementID: mongoID,
+		Operation:          operation,
+		Payload:            payload,
+		NextAttemptAt:      now,
+		CreatedAt:          now,
+	}
+	m.entries = append(m.entries, entry)
+	return entry
+}
+
+// Entries mengambil salinan semua entri pending
+func (m *MockAchievementOutboxStore) Entries() []model.AchievementOutboxEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := make([]model.AchievementOutboxEntry, len(m.entries))
+	for i, entry := range m.entries {
+		entries[i] = *entry
+	}
+	return entries
+}
+
+func (m *MockAchievementOutboxStore) DueOutboxDocuments(now time.Time, limit int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := make(map[string]bool)
+	var mongoIDs []string
+	for _, entry := range m.entries {
+		if seen[entry.MongoAchievementID] {
+			continue
+		}
+		seen[entry.MongoAchievementID] = true
+		if !entry.NextAttemptAt.After(now) && (limit <= 0 || len(mongoIDs) < limit) {
+			mongoIDs = append(mongoIDs, entry.MongoAchievementID)
+		}
+	}
+	return mongoIDs, nil
+}
+
+func (m *MockAchievementOutboxStore) HasPendingOutbox(mongoID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, entry := range m.entries {
+		if entry.MongoAchievementID == mongoID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockAchievementOutboxStore) WithOutboxLock(mongoID string, fn func(tx AchievementOutboxTx) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tx := &mockOutboxTx{store: m}
+	for _, entry := range m.entries {
+		copied := *entry
+		tx.entries = append(tx.entries, &copied)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	m.entries = tx.entries
+	return nil
+}
+
+type mockOutboxTx struct {
+	store   *MockAchievementOutboxStore
+	entries []*model.AchievementOutboxEntry
+}
+
+func (t *mockOutboxTx) PendingOutboxEntries(mongoID string) ([]*model.AchievementOutboxEntry, error) {
+	var entries []*model.AchievementOutboxEntry
+	for _, entry := range t.entries {
+		if entry.MongoAchievementID == mongoID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (t *mockOutboxTx) CompleteOutboxEntry(id int64) error {
+	if t.store.FailComplete > 0 {
+		t.store.FailComplete--
+		return ErrInjectedFault
+	}
+	for i, entry := range t.entries {
+		if entry.ID == id {
+			t.entries = append(t.entries[:i], t.entries[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (t *mockOutboxTx) FailOutboxEntry(id int64, lastError string, nextAttemptAt time.Time) error {
+	for _, entry := range t.entries {
+		if entry.ID == id {
+			entry.Attempts++
+			entry.LastError = &lastError
+			entry.NextAttemptAt = nextAttemptAt
+		}
+	}
+	return nil
+}
+
+// MockAchievementDocumentStore adalah mock AchievementDocumentStore yang menyimpan dokumen di memori
+type MockAchievementDocumentStore struct {
+	documents map[string]bson.M
+
+	// FailWrites membuat penulisan berikutnya gagal sebanyak n kali, mensimulasikan MongoDB yang tidak tersedia
+	FailWrites int
+	// Writes menghitung penulisan yang berhasil
+	Writes int
+	// RoundTrips menghitung query baca, RoundTripLatency mensimulasikan jeda jaringan tiap query
+	RoundTrips       int
+	RoundTripLatency time.Duration
+}
+
+// NewMockAchievementDocumentStore membuat instance mock document store
+func NewMockAchievementDocumentStore() *MockAchievementDocumentStore {
+	return &MockAchievementDocumentStore{documents: make(map[string]bson.M)}
+}
+
+// Document mengambil dokumen yang tersimpan, nil jika tidak ada
+func (m *MockAchievementDocumentStore) Document(mongoID string) bson.M {
+	return m.documents[mongoID]
+}
+
+func (m *MockAchievementDocumentStore) fault() error {
+	if m.FailWrites > 0 {
+		m.FailWrites--
+		return ErrInjectedFault
+	}
+	return nil
+}
+
+func (m *MockAchievementDocumentStore) ReplaceAchievementDocument(mongoID string, document []byte) error {
+	if err := m.fault(); err != nil {
+		return err
+	}
+	decoded := bson.M{}
+	if err := bson.Unmarshal(document, &decoded); err != nil {
+		return err
+	}
+	m.documents[mongoID] = decoded
+	m.Writes++
+	return nil
+}
+
+func (m *MockAchievementDocumentStore) UpdateAchievementDocument(mongoID string, fields []byte) error {
+	if err := m.fault(); err != nil {
+		return err
+	}
+	decoded := bson.M{}
+	if err := bson.Unmarshal(fields, &decoded); err != nil {
+		return err
+	}
+	if document, ok := m.documents[mongoID]; ok {
+		for key, value := range decoded {
+			document[key] = value
+		}
+	}
+	m.Writes++
+	return nil
+}
+
+func (m *MockAchievementDocumentStore) DeleteAchievementDocument(mongoID string) error {
+	if err := m.fault(); err != nil {
+		return err
+	}
+	delete(m.documents, mongoID)
+	m.Writes++
+	return nil
+}
+
+func (m *MockAchievementDocumentStore) FindAchievementDocuments(mongoIDs []string, filter bson.M) (map[string]model.Achievement, error) {
+	// Sama seperti mongoDocumentStore: ID dibuat unik lalu dibaca per batch documentBatchSize
+	seen := make(map[string]bool, len(mongoIDs))
+	unique := make([]string, 0, len(mongoIDs))
+	for _, mongoID := range mongoIDs {
+		if !seen[mongoID] {
+			seen[mongoID] = true
+			unique = append(unique, mongoID)
+		}
+	}
+
+	documents := make(map[string]model.Achievement, len(unique))
+	for start := 0; start < len(unique); start += documentBatchSize {
+		m.RoundTrips++
+		if m.RoundTripLatency > 0 {
+			time.Sleep(m.RoundTripLatency)
+		}
+		for _, mongoID := range unique[start:min(start+documentBatchSize, len(unique))] {
+			achievement, ok, err := m.matchDocument(mongoID, filter)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				documents[mongoID] = achievement
+			}
+		}
+	}
+	return documents, nil
+}
+
+// matchDocument mengambil dokumen mongoID jika ada dan cocok dengan filter
+func (m *MockAchievementDocumentStore) matchDocument(mongoID string, filter bson.M) (model.Achievement, bool, error) {
+	var achievement model.Achievement
+	document, ok := m.documents[mongoID]
+	if !ok {
+		return achievement, false, nil
+	}
+	for key, value := range filter {
+		if key == "_id" {
+			if !mockIDIn(mongoID, value) {
+				return achievement, false, nil
+			}
+			continue
+		}
+		if document[key] != value {
+			return achievement, false, nil
+		}
+	}
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return achievement, false, err
+	}
+	if err := bson.Unmarshal(raw, &achievement); err != nil {
+		return achievement, false, err
+	}
+	return achievement, true, nil
+}
+
+// mockIDIn mengecek filter _id berbentuk {"$in": []primitive.ObjectID}
+func mockIDIn(mongoID string, value interface{}) bool {
+	in, _ := value.(bson.M)["$in"].([]primitive.ObjectID)
+	for _, objID := range in {
+		if objID.Hex() == mongoID {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchAchievementDocuments menilai dokumen seperti text index MongoDB: kata di title berbobot 10, description
+// dan tags 5, search_terms 1. Kata berawalan "-" dan frasa dalam tanda kutip diabaikan.
+func (m *MockAchievementDocumentStore) SearchAchievementDocuments(search string, filter bson.M, limit int) (map[string]float64, error) {
+	m.RoundTrips++
+	var words []string
+	for _, word := range strings.Fields(search) {
+		if !strings.HasPrefix(word, "-") && !strings.Contains(word, "\"") {
+			words = append(words, word)
+		}
+	}
+
+	type hit struct {
+		mongoID string
+		score   float64
+	}
+	var hits []hit
+	for mongoID := range m.documents {
+		achievement, ok, err := m.matchDocument(mongoID, filter)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		weighted := map[float64][]string{
+			10: model.SearchTokens(achievement.Title),
+			5:  append(model.SearchTokens(achievement.Description), model.SearchTokens(strings.Join(achievement.Tags, " "))...),
+			1:  achievement.SearchTerms,
+		}
+		var score float64
+		for weight, tokens := range weighted {
+			for _, token := range tokens {
+				for _, word := range words {
+					if token == word {
+						score += weight
+					}
+				}
+			}
+		}
+		if score > 0 {
+			hits = append(hits, hit{mongoID, score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].mongoID < hits[j].mongoID
+	})
+
+	scores := make(map[string]float64)
+	for _, h := range hits[:min(limit, len(hits))] {
+		scores[h.mongoID] = h.score
+	}
+	return scores, nil
+}
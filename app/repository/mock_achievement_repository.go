@@ -2,6 +2,8 @@ package repository
 
 import (
 	"errors"
+	"sort"
+	"strings"
 	"time"
 	"uas_be/app/model"
 
@@ -12,6 +14,22 @@ type MockAchievementRepository struct {
 	achievements map[string]*model.AchievementWithReference
 	histories    map[string][]*model.AchievementHistory
 	attachments  map[string][]*model.AchievementAttachment
+	revisions    map[string][]*model.AchievementRevisionRequest
+	snapshots    map[string][]*model.AchievementSnapshot
+	chains       []*model.ApprovalChain
+	approvals    map[string][]*model.AchievementApproval
+	rubricRules  []*model.PointsRubricRule
+	typeSchemas  []*model.AchievementTypeSchema
+	types        []*model.AchievementTypeDefinition
+	tags         []*model.Tag
+	expiryNotice map[string]bool
+
+	// Advisors memetakan student ID ke dosen wali untuk GetOverdueSubmissions
+	Advisors map[string]*model.Lecturer
+	// Documents menggantikan dokumen MongoDB untuk rekonsiliasi; nil berarti dokumen sama dengan achievements
+	Documents map[string]*model.ReconcileDocument
+	// PendingOutbox berisi ID dokumen yang masih punya entri outbox
+	PendingOutbox []string
 }
 
 func NewMockAchievementRepository() *MockAchievementRepository {
@@ -19,6 +37,12 @@ func NewMockAchievementRepository() *MockAchievementRepository {
 		achievements: make(map[string]*model.AchievementWithReference),
 		histories:    make(map[string][]*model.AchievementHistory),
 		attachments:  make(map[string][]*model.AchievementAttachment),
+		revisions:    make(map[string][]*model.AchievementRevisionRequest),
+		snapshots:    make(map[string][]*model.AchievementSnapshot),
+		approvals:    make(map[string][]*model.AchievementApproval),
+		types:        model.DefaultAchievementTypes(),
+		expiryNotice: make(map[string]bool),
+		Advisors:     make(map[string]*model.Lecturer),
 	}
 }
 
@@ -26,10 +50,13 @@ func (m *MockAchievementRepository) Create(achievement *model.Achievement, stude
 	achievement.StudentID = studentID
 	achievement.CreatedAt = time.Now()
 	achievement.UpdatedAt = time.Now()
+	achievement.SearchTerms = model.AchievementSearchTerms(achievement)
 
 	achWithRef := &model.AchievementWithReference{
 		Achievement: *achievement,
+		ReferenceID: studentID,
 		Status:      model.AchievementStatusDraft,
+		Version:     1,
 	}
 	// Use studentID as key for simplicity in tests
 	m.achievements[studentID] = achWithRef
@@ -40,6 +67,68 @@ func (m *MockAchievementRepository) CreateAchievement(achievement *model.Achieve
 	return m.Create(achievement, studentID)
 }
 
+func (m *MockAchievementRepository) CreateTeamAchievement(achievement *model.Achievement, members []*model.TeamMember) (*model.AchievementWithReference, error) {
+	teamID := uuid.New().String()
+	achievement.SearchTerms = model.AchievementSearchTerms(achievement)
+	var leader *model.AchievementWithReference
+	for _, member := range members {
+		role := member.Role
+		content := *achievement
+		content.StudentID = member.StudentID
+		content.CreatedAt = time.Now()
+		content.UpdatedAt = time.Now()
+		member.ReferenceID = uuid.New().String()
+		member.Status = model.AchievementStatusDraft
+		achWithRef := &model.AchievementWithReference{
+			Achievement: content,
+			ReferenceID: member.ReferenceID,
+			Status:      model.AchievementStatusDraft,
+			Version:     1,
+			TeamID:      &teamID,
+			TeamRole:    &role,
+		}
+		m.achievements[member.ReferenceID] = achWithRef
+		if role == model.TeamRoleLeader {
+			leader = achWithRef
+		}
+	}
+	if leader == nil {
+		return nil, errors.New("prestasi tim harus memiliki satu leader")
+	}
+	result := *leader
+	result.TeamMembers = members
+	return &result, nil
+}
+
+func (m *MockAchievementRepository) GetTeamMembers(teamID string) ([]*model.TeamMember, error) {
+	var members []*model.TeamMember
+	for _, ach := range m.teamReferences(teamID, "") {
+		members = append(members, &model.TeamMember{
+			ReferenceID: ach.ReferenceID,
+			StudentID:   ach.StudentID,
+			Role:        *ach.TeamRole,
+			Status:      ach.Status,
+		})
+	}
+	return members, nil
+}
+
+// teamReferences mengambil reference anggota tim, kecuali excludeID; ketua lebih dulu
+func (m *MockAchievementRepository) teamReferences(teamID, excludeID string) []*model.AchievementWithReference {
+	var results []*model.AchievementWithReference
+	for id, ach := range m.achievements {
+		if ach.TeamID == nil || *ach.TeamID != teamID || id == excludeID {
+			continue
+		}
+		if *ach.TeamRole == model.TeamRoleLeader {
+			results = append([]*model.AchievementWithReference{ach}, results...)
+		} else {
+			results = append(results, ach)
+		}
+	}
+	return results
+}
+
 func (m *MockAchievementRepository) GetAchievementByID(id string) (*model.AchievementWithReference, error) {
 	if achievement, exists := m.achievements[id]; exists {
 		return achievement, nil
@@ -80,40 +169,73 @@ func (m *MockAchievementRepository) GetAllAchievements(page, pageSize int) ([]*m
 func (m *MockAchievementRepository) GetAchievementsWithFilters(page, pageSize int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.AchievementWithReference, int, error) {
 	var achievements []*model.AchievementWithReference
 	for _, achievement := range m.achievements {
-		if achievement.Status != model.AchievementStatusDeleted {
-			// Apply filters
-			if status, ok := filters["status"].(string); ok && status != "" {
-				if achievement.Status != status {
-					continue
-				}
-			}
-			if studentID, ok := filters["student_id"].(string); ok && studentID != "" {
-				if achievement.StudentID != studentID {
-					continue
-				}
-			}
-			if studentIDs, ok := filters["student_ids"].([]string); ok {
-				found := false
-				for _, sid := range studentIDs {
-					if achievement.StudentID == sid {
-						found = true
-						break
-					}
-				}
-				if !found {
-					continue
-				}
-			}
+		if matchesListFilters(achievement, filters) {
 			achievements = append(achievements, achievement)
 		}
 	}
 	return achievements, len(achievements), nil
 }
 
-func (m *MockAchievementRepository) UpdateAchievement(id string, achievement *model.Achievement) error {
+// matchesListFilters menerapkan filter daftar prestasi seperti referenceFilterClauses
+func matchesListFilters(achievement *model.AchievementWithReference, filters map[string]interface{}) bool {
+	if achievement.Status == model.AchievementStatusDeleted {
+		return false
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		if achievement.Status != status {
+			return false
+		}
+	}
+	if studentID, ok := filters["student_id"].(string); ok && studentID != "" {
+		if achievement.StudentID != studentID {
+			return false
+		}
+	}
+	if studentIDs, ok := filters["student_ids"].([]string); ok {
+		found := false
+		for _, sid := range studentIDs {
+			if achievement.StudentID == sid {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if expired, ok := filters["expired"].(bool); ok && achievement.IsExpired(time.Now()) != expired {
+		return false
+	}
+	if expiresBefore, ok := filters["expires_before"].(time.Time); ok {
+		if achievement.ValidUntil == nil || achievement.IsExpired(time.Now()) || achievement.ValidUntil.After(expiresBefore) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *MockAchievementRepository) UpdateAchievement(id string, achievement *model.Achievement, expectedVersion int) error {
 	if ach, exists := m.achievements[id]; exists {
+		if expectedVersion != 0 && ach.Version != expectedVersion {
+			return ErrAchievementVersionConflict
+		}
+		achievement.SearchTerms = model.AchievementSearchTerms(achievement)
+		// Poin tidak ikut diubah karena disimpan per reference
+		points := ach.Points
 		ach.Achievement = *achievement
+		ach.Points = points
 		ach.UpdatedAt = time.Now()
+		ach.Version++
+		// Anggota tim memakai isi prestasi yang sama
+		if ach.TeamID != nil {
+			for _, member := range m.teamReferences(*ach.TeamID, id) {
+				studentID, points := member.StudentID, member.Points
+				member.Achievement = *achievement
+				member.StudentID = studentID
+				member.Points = points
+				member.Version++
+			}
+		}
 		return nil
 	}
 	return errors.New("achievement tidak ditemukan")
@@ -124,55 +246,247 @@ func (m *MockAchievementRepository) Submit(id string) error {
 		ach.Status = model.AchievementStatusSubmitted
 		now := time.Now()
 		ach.SubmittedAt = &now
+		ach.Version++
 		return nil
 	}
 	return errors.New("achievement tidak ditemukan")
 }
 
-func (m *MockAchievementRepository) SubmitAchievementForVerification(id string) error {
-	return m.Submit(id)
+func (m *MockAchievementRepository) TransitionAchievementStatus(id, fromStatus, toStatus string, expectedVersion int, fields map[string]interface{}, effects *model.AchievementTransitionEffects) error {
+	ach, exists := m.achievements[id]
+	if !exists {
+		return errors.New("achievement tidak ditemukan")
+	}
+	if ach.Status != fromStatus {
+		return ErrAchievementStatusConflict
+	}
+	if expectedVersion != 0 && ach.Version != expectedVersion {
+		return ErrAchievementVersionConflict
+	}
+	for column := range fields {
+		if !transitionColumns[column] {
+			return errors.New("kolom " + column + " tidak boleh diubah lewat transisi status")
+		}
+	}
+	// Efek ditulis lebih dulu agar kegagalannya (misal tahap sudah disetujui) tidak mengubah status, seperti rollback
+	if effects != nil {
+		if err := m.writeTransitionEffects(id, effects); err != nil {
+			return err
+		}
+	}
+	for column, value := range fields {
+		switch column {
+		case "submitted_at":
+			ach.SubmittedAt = mockTimeField(value)
+		case "verified_at":
+			ach.VerifiedAt = mockTimeField(value)
+		case "verified_by":
+			ach.VerifiedBy = mockStringField(value)
+		case "rejection_note":
+			ach.RejectionNote = mockStringField(value)
+		case "resubmission_count":
+			ach.ResubmissionCount = value.(int)
+		case "deleted_at":
+			ach.DeletedAt = mockTimeField(value)
+		case "possible_duplicate":
+			ach.PossibleDuplicate = value.(bool)
+		case "sla_reminded_at":
+			ach.SLARemindedAt = mockTimeField(value)
+		case "escalated_at":
+			ach.EscalatedAt = mockTimeField(value)
+		case "points":
+			ach.Points = value.(int)
+		}
+	}
+	ach.Status = toStatus
+	ach.Achievement.UpdatedAt = time.Now()
+	ach.Version++
+	return nil
+}
+
+func (m *MockAchievementRepository) writeTransitionEffects(id string, effects *model.AchievementTransitionEffects) error {
+	if effects.ResetApprovals {
+		m.ResetAchievementApprovals(id)
+	}
+	if effects.Approval != nil {
+		if err := m.CreateAchievementApproval(effects.Approval); err != nil {
+			return err
+		}
+	}
+	if effects.ResolveRevisionRequests {
+		m.ResolveRevisionRequests(id)
+	}
+	if effects.Snapshot != nil {
+		m.CreateAchievementSnapshot(effects.Snapshot)
+	}
+	if effects.RevisionRequest != nil {
+		m.CreateRevisionRequest(effects.RevisionRequest)
+	}
+	if points, ok := effects.DocumentFields["points"].(int); ok {
+		m.achievements[id].Points = points
+	}
+	if effects.History != nil {
+		m.CreateAchievementHistory(effects.History)
+	}
+	return nil
 }
 
-func (m *MockAchievementRepository) VerifyAchievement(id string, verifiedBy string) error {
-	if ach, exists := m.achievements[id]; exists {
-		if ach.Status != model.AchievementStatusSubmitted {
-			return errors.New("hanya prestasi submitted yang bisa diverify")
+func (m *MockAchievementRepository) FindAttachmentsByHash(hashes []string) ([]*model.AchievementAttachment, error) {
+	wanted := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		wanted[hash] = true
+	}
+	var results []*model.AchievementAttachment
+	for _, attachments := range m.attachments {
+		for _, attachment := range attachments {
+			if attachment.ContentHash != "" && wanted[attachment.ContentHash] {
+				results = append(results, attachment)
+			}
 		}
-		ach.Status = model.AchievementStatusVerified
-		now := time.Now()
-		ach.VerifiedAt = &now
-		ach.VerifiedBy = &verifiedBy
-		return nil
 	}
-	return errors.New("achievement tidak ditemukan")
+	return results, nil
 }
 
-func (m *MockAchievementRepository) RejectAchievement(id string, verifiedBy string, rejectionNote string) error {
-	if ach, exists := m.achievements[id]; exists {
-		if ach.Status != model.AchievementStatusSubmitted {
-			return errors.New("hanya prestasi submitted yang bisa direject")
+func (m *MockAchievementRepository) GetDuplicateCandidates(studentID, achievementType string) ([]*model.AchievementWithReference, error) {
+	var results []*model.AchievementWithReference
+	for _, ach := range m.achievements {
+		if ach.Status == model.AchievementStatusDeleted || ach.AchievementType != achievementType {
+			continue
+		}
+		if studentID == "" || ach.StudentID == studentID {
+			results = append(results, ach)
 		}
-		ach.Status = model.AchievementStatusRejected
-		now := time.Now()
-		ach.VerifiedAt = &now
-		ach.VerifiedBy = &verifiedBy
-		ach.RejectionNote = &rejectionNote
-		return nil
 	}
-	return errors.New("achievement tidak ditemukan")
+	return results, nil
 }
 
-func (m *MockAchievementRepository) DeleteAchievement(id string) error {
-	if ach, exists := m.achievements[id]; exists {
-		if ach.Status != model.AchievementStatusDraft {
-			return errors.New("hanya prestasi draft yang bisa dihapus")
+// GetOverdueSubmissions pada mock tidak mengisi data mahasiswa dan dosen; dosen wali diambil dari AdvisorIDs jika diisi test
+func (m *MockAchievementRepository) GetOverdueSubmissions(submittedBefore time.Time) ([]*model.OverdueSubmission, error) {
+	var results []*model.OverdueSubmission
+	for _, ach := range m.achievements {
+		if ach.Status != model.AchievementStatusSubmitted || ach.SubmittedAt == nil || !ach.SubmittedAt.Before(submittedBefore) {
+			continue
 		}
-		ach.Status = model.AchievementStatusDeleted
-		now := time.Now()
-		ach.Achievement.UpdatedAt = now
-		return nil
+		item := &model.OverdueSubmission{
+			AchievementID: ach.ReferenceID,
+			Title:         ach.Title,
+			StudentID:     ach.StudentID,
+			SubmittedAt:   *ach.SubmittedAt,
+			SLARemindedAt: ach.SLARemindedAt,
+			EscalatedAt:   ach.EscalatedAt,
+		}
+		if advisor, ok := m.Advisors[ach.StudentID]; ok {
+			item.AdvisorID = advisor.ID
+			item.AdvisorUserID = advisor.UserID
+			item.AdvisorDepartment = advisor.Department
+		}
+		results = append(results, item)
 	}
-	return errors.New("achievement tidak ditemukan")
+	sort.Slice(results, func(i, j int) bool { return results[i].SubmittedAt.Before(results[j].SubmittedAt) })
+	return results, nil
+}
+
+func (m *MockAchievementRepository) SetValidUntil(referenceID string, validUntil *time.Time) error {
+	ach, exists := m.achievements[referenceID]
+	if !exists {
+		return errors.New("achievement tidak ditemukan")
+	}
+	refs := []*model.AchievementWithReference{ach}
+	if ach.TeamID != nil {
+		refs = append(refs, m.teamReferences(*ach.TeamID, referenceID)...)
+	}
+	for _, ref := range refs {
+		ref.ValidUntil = validUntil
+		ref.Expired = model.IsValidityExpired(validUntil, time.Now())
+		delete(m.expiryNotice, ref.ReferenceID)
+	}
+	return nil
+}
+
+func (m *MockAchievementRepository) GetExpiringAchievements(validUntilBefore time.Time) ([]*model.ExpiringAchievement, error) {
+	var results []*model.ExpiringAchievement
+	now := time.Now()
+	for _, ach := range m.achievements {
+		if ach.Status != model.AchievementStatusVerified || ach.ValidUntil == nil || m.expiryNotice[ach.ReferenceID] {
+			continue
+		}
+		if ach.IsExpired(now) || ach.ValidUntil.After(validUntilBefore) {
+			continue
+		}
+		results = append(results, &model.ExpiringAchievement{
+			AchievementID: ach.ReferenceID,
+			Title:         ach.Title,
+			StudentID:     ach.StudentID,
+			ValidUntil:    *ach.ValidUntil,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ValidUntil.Before(results[j].ValidUntil) })
+	return results, nil
+}
+
+func (m *MockAchievementRepository) MarkExpiryReminded(referenceID string, at time.Time) (bool, error) {
+	if _, exists := m.achievements[referenceID]; !exists || m.expiryNotice[referenceID] {
+		return false, nil
+	}
+	m.expiryNotice[referenceID] = true
+	return true, nil
+}
+
+func (m *MockAchievementRepository) GetDeletedAchievements(studentID string) ([]*model.AchievementWithReference, error) {
+	var results []*model.AchievementWithReference
+	for _, ach := range m.achievements {
+		if ach.Status == model.AchievementStatusDeleted && (studentID == "" || ach.StudentID == studentID) {
+			results = append(results, ach)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockAchievementRepository) GetPurgeCandidates(deletedBefore time.Time) ([]*model.PurgeCandidate, error) {
+	var results []*model.PurgeCandidate
+	for _, ach := range m.achievements {
+		if ach.Status == model.AchievementStatusDeleted && ach.DeletedAt != nil && ach.DeletedAt.Before(deletedBefore) {
+			results = append(results, &model.PurgeCandidate{ReferenceID: ach.ReferenceID, DeletedAt: *ach.DeletedAt, TeamID: ach.TeamID})
+		}
+	}
+	return results, nil
+}
+
+func (m *MockAchievementRepository) PurgeAchievement(referenceID string) error {
+	ach, exists := m.achievements[referenceID]
+	if !exists || ach.Status != model.AchievementStatusDeleted {
+		return ErrAchievementStatusConflict
+	}
+	delete(m.achievements, referenceID)
+	delete(m.histories, referenceID)
+	if ach.TeamID != nil {
+		if others := m.teamReferences(*ach.TeamID, referenceID); len(others) > 0 {
+			for _, attachment := range m.attachments[referenceID] {
+				attachment.AchievementID = others[0].ReferenceID
+				m.attachments[others[0].ReferenceID] = append(m.attachments[others[0].ReferenceID], attachment)
+			}
+		}
+	}
+	delete(m.attachments, referenceID)
+	delete(m.revisions, referenceID)
+	delete(m.snapshots, referenceID)
+	delete(m.approvals, referenceID)
+	return nil
+}
+
+// mockTimeField dan mockStringField meniru kolom nullable: nil berarti kolom dikosongkan
+func mockTimeField(value interface{}) *time.Time {
+	if t, ok := value.(time.Time); ok {
+		return &t
+	}
+	return nil
+}
+
+func mockStringField(value interface{}) *string {
+	if v, ok := value.(string); ok {
+		return &v
+	}
+	return nil
 }
 
 func (m *MockAchievementRepository) CreateAchievementHistory(history *model.AchievementHistory) error {
@@ -180,6 +494,8 @@ func (m *MockAchievementRepository) CreateAchievementHistory(history *model.Achi
 		history.ID = uuid.New().String()
 	}
 	history.CreatedAt = time.Now()
+	// ID dari c.Params memakai buffer request Fiber yang dipakai ulang, salin sebelum dijadikan key
+	history.AchievementID = strings.Clone(history.AchievementID)
 	m.histories[history.AchievementID] = append(m.histories[history.AchievementID], history)
 	return nil
 }
@@ -201,29 +517,555 @@ func (m *MockAchievementRepository) CreateAttachment(attachment *model.Achieveme
 }
 
 func (m *MockAchievementRepository) GetAttachmentsByAchievementID(achievementID string) ([]*model.AchievementAttachment, error) {
+	if ach, exists := m.achievements[achievementID]; exists && ach.TeamID != nil {
+		attachments := []*model.AchievementAttachment{}
+		for _, member := range m.teamReferences(*ach.TeamID, "") {
+			attachments = append(attachments, m.attachments[member.ReferenceID]...)
+		}
+		return attachments, nil
+	}
 	if attachments, exists := m.attachments[achievementID]; exists {
 		return attachments, nil
 	}
 	return []*model.AchievementAttachment{}, nil
 }
 
-func (m *MockAchievementRepository) GetAchievementStatsByPeriod(startDate, endDate time.Time, role, userID string) (map[string]interface{}, error) {
+func (m *MockAchievementRepository) CreateAchievementSnapshot(snapshot *model.AchievementSnapshot) error {
+	if snapshot.ID == "" {
+		snapshot.ID = uuid.New().String()
+	}
+	snapshot.Version = len(m.snapshots[snapshot.AchievementID]) + 1
+	snapshot.CreatedAt = time.Now()
+	m.snapshots[snapshot.AchievementID] = append(m.snapshots[snapshot.AchievementID], snapshot)
+	return nil
+}
+
+func (m *MockAchievementRepository) GetAchievementSnapshots(achievementID string) ([]*model.AchievementSnapshot, error) {
+	return m.snapshots[achievementID], nil
+}
+
+func (m *MockAchievementRepository) CreateRevisionRequest(request *model.AchievementRevisionRequest) error {
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+	request.CreatedAt = time.Now()
+	m.revisions[request.AchievementID] = append(m.revisions[request.AchievementID], request)
+	return nil
+}
+
+func (m *MockAchievementRepository) GetRevisionRequests(achievementID string) ([]*model.AchievementRevisionRequest, error) {
+	return m.revisions[achievementID], nil
+}
+
+func (m *MockAchievementRepository) ResolveRevisionRequests(achievementID string) error {
+	now := time.Now()
+	for _, request := range m.revisions[achievementID] {
+		if request.ResolvedAt == nil {
+			request.ResolvedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockAchievementRepository) GetApprovalChains(activeOnly bool) ([]*model.ApprovalChain, error) {
+	var chains []*model.ApprovalChain
+	for _, chain := range m.chains {
+		if !activeOnly || chain.IsActive {
+			chains = append(chains, chain)
+		}
+	}
+	return chains, nil
+}
+
+func (m *MockAchievementRepository) GetApprovalChainByID(id string) (*model.ApprovalChain, error) {
+	for _, chain := range m.chains {
+		if chain.ID == id {
+			return chain, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockAchievementRepository) CreateApprovalChain(chain *model.ApprovalChain) error {
+	if chain.ID == "" {
+		chain.ID = uuid.New().String()
+	}
+	for i := range chain.Stages {
+		chain.Stages[i].ChainID = chain.ID
+		chain.Stages[i].StageOrder = i + 1
+	}
+	chain.IsActive = true
+	chain.CreatedAt = time.Now()
+	m.chains = append(m.chains, chain)
+	return nil
+}
+
+func (m *MockAchievementRepository) DeactivateApprovalChain(id string) error {
+	for _, chain := range m.chains {
+		if chain.ID == id {
+			chain.IsActive = false
+			return nil
+		}
+	}
+	return errors.New("approval chain tidak ditemukan")
+}
+
+func (m *MockAchievementRepository) CreateAchievementApproval(approval *model.AchievementApproval) error {
+	for _, existing := range m.approvals[approval.AchievementID] {
+		if existing.StageOrder == approval.StageOrder {
+			return errors.New("tahap sudah disetujui")
+		}
+	}
+	if approval.ID == "" {
+		approval.ID = uuid.New().String()
+	}
+	approval.VerifiedAt = time.Now()
+	m.approvals[approval.AchievementID] = append(m.approvals[approval.AchievementID], approval)
+	return nil
+}
+
+func (m *MockAchievementRepository) GetAchievementApprovals(achievementID string) ([]*model.AchievementApproval, error) {
+	return m.approvals[achievementID], nil
+}
+
+func (m *MockAchievementRepository) ResetAchievementApprovals(achievementID string) error {
+	delete(m.approvals, achievementID)
+	return nil
+}
+
+func (m *MockAchievementRepository) GetPointsRubricRules(activeOnly bool) ([]*model.PointsRubricRule, error) {
+	var rules []*model.PointsRubricRule
+	for _, rule := range m.rubricRules {
+		if !activeOnly || rule.IsActive {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *MockAchievementRepository) CreatePointsRubricRule(rule *model.PointsRubricRule) error {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	rule.IsActive = true
+	rule.CreatedAt = time.Now()
+	m.rubricRules = append(m.rubricRules, rule)
+	return nil
+}
+
+func (m *MockAchievementRepository) DeactivatePointsRubricRule(id string) error {
+	for _, rule := range m.rubricRules {
+		if rule.ID == id {
+			rule.IsActive = false
+			return nil
+		}
+	}
+	return errors.New("aturan rubrik tidak ditemukan")
+}
+
+func (m *MockAchievementRepository) GetAchievementTypes(activeOnly bool) ([]*model.AchievementTypeDefinition, error) {
+	var types []*model.AchievementTypeDefinition
+	for _, t := range m.types {
+		if !activeOnly || t.IsActive {
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+func (m *MockAchievementRepository) GetAchievementType(code string) (*model.AchievementTypeDefinition, error) {
+	for _, t := range m.types {
+		if t.Code == code {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockAchievementRepository) CreateAchievementType(achievementType *model.AchievementTypeDefinition) error {
+	if existing, _ := m.GetAchievementType(achievementType.Code); existing != nil {
+		return errors.New("kode tipe sudah dipakai")
+	}
+	achievementType.IsActive = true
+	achievementType.CreatedAt = time.Now()
+	achievementType.UpdatedAt = achievementType.CreatedAt
+	m.types = append(m.types, achievementType)
+	return nil
+}
+
+func (m *MockAchievementRepository) UpdateAchievementType(achievementType *model.AchievementTypeDefinition) error {
+	for i, t := range m.types {
+		if t.Code == achievementType.Code {
+			achievementType.UpdatedAt = time.Now()
+			m.types[i] = achievementType
+			return nil
+		}
+	}
+	return errors.New("tipe prestasi tidak ditemukan")
+}
+
+func (m *MockAchievementRepository) GetAchievementTypeSchema(achievementType string, version int) (*model.AchievementTypeSchema, error) {
+	var latest *model.AchievementTypeSchema
+	for _, schema := range m.typeSchemas {
+		if schema.AchievementType != achievementType {
+			continue
+		}
+		if version != 0 && schema.Version == version {
+			return schema, nil
+		}
+		if version == 0 && (latest == nil || schema.Version > latest.Version) {
+			latest = schema
+		}
+	}
+	return latest, nil
+}
+
+func (m *MockAchievementRepository) CreateAchievementTypeSchema(schema *model.AchievementTypeSchema) error {
+	for _, existing := range m.typeSchemas {
+		if existing.AchievementType == schema.AchievementType && existing.Version >= schema.Version {
+			schema.Version = existing.Version + 1
+		}
+	}
+	schema.CreatedAt = time.Now()
+	m.typeSchemas = append(m.typeSchemas, schema)
+	return nil
+}
+
+func (m *MockAchievementRepository) GetAchievementStatsByPeriod(startDate, endDate time.Time, role, userID string, includeExpired bool) (map[string]interface{}, error) {
 	return map[string]interface{}{
 		"total": len(m.achievements),
 	}, nil
 }
 
 func (m *MockAchievementRepository) GetAchievementStatsByType(role, userID string) (map[string]interface{}, error) {
+	typeStats := make(map[string]map[string]int)
+	for _, achievement := range m.achievements {
+		if achievement.Status == model.AchievementStatusDeleted {
+			continue
+		}
+		if _, ok := typeStats[achievement.AchievementType]; !ok {
+			typeStats[achievement.AchievementType] = make(map[string]int)
+		}
+		typeStats[achievement.AchievementType][achievement.Status]++
+	}
 	return map[string]interface{}{
-		"academic":      0,
-		"competition":   0,
-		"organization":  0,
-		"publication":   0,
-		"certification": 0,
-		"other":         0,
+		"stats_by_type": typeStats,
 	}, nil
 }
 
-func (m *MockAchievementRepository) GetTopStudents(limit int) ([]*model.StudentStats, error) {
+func (m *MockAchievementRepository) GetTags() ([]*model.Tag, error) {
+	// Salinan dikembalikan agar pengisian Usage oleh service tidak menumpuk di katalog mock
+	tags := []*model.Tag{}
+	for _, tag := range m.tags {
+		copied := *tag
+		tags = append(tags, &copied)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (m *MockAchievementRepository) GetTag(slug string) (*model.Tag, error) {
+	for _, tag := range m.tags {
+		if tag.Slug == slug {
+			return tag, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockAchievementRepository) CreateTag(tag *model.Tag) error {
+	if existing, _ := m.GetTag(tag.Slug); existing != nil {
+		return errors.New("slug tag sudah dipakai")
+	}
+	tag.CreatedAt = time.Now()
+	tag.UpdatedAt = tag.CreatedAt
+	m.tags = append(m.tags, tag)
+	return nil
+}
+
+func (m *MockAchievementRepository) UpdateTag(tag *model.Tag) error {
+	for i, existing := range m.tags {
+		if existing.Slug == tag.Slug {
+			tag.UpdatedAt = time.Now()
+			m.tags[i] = tag
+			return nil
+		}
+	}
+	return errors.New("tag tidak ditemukan")
+}
+
+func (m *MockAchievementRepository) DeleteTag(slug string) error {
+	for i, tag := range m.tags {
+		if tag.Slug == slug {
+			m.tags = append(m.tags[:i], m.tags[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("tag tidak ditemukan")
+}
+
+func (m *MockAchievementRepository) MergeTags(target *model.Tag, sourceSlugs []string) error {
+	for _, slug := range sourceSlugs {
+		if err := m.DeleteTag(slug); err != nil {
+			return err
+		}
+	}
+	return m.UpdateTag(target)
+}
+
+func (m *MockAchievementRepository) RenameAchievementTags(variants []string, name string) (int, error) {
+	variantKeys := make(map[string]bool)
+	for _, variant := range variants {
+		variantKeys[model.TagKey(variant)] = true
+	}
+	updated := 0
+	for _, achievement := range m.achievements {
+		matched := false
+		for _, tag := range achievement.Tags {
+			matched = matched || variantKeys[model.TagKey(tag)]
+		}
+		if matched {
+			achievement.Tags = renameTags(achievement.Tags, variantKeys, name)
+			achievement.SearchTerms = model.AchievementSearchTerms(&achievement.Achievement)
+			achievement.Version++
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *MockAchievementRepository) GetTagUsage() (map[string]int, error) {
+	usage := make(map[string]int)
+	for _, achievement := range m.achievements {
+		for _, tag := range achievement.Tags {
+			usage[tag]++
+		}
+	}
+	return usage, nil
+}
+
+func (m *MockAchievementRepository) GetAchievementStatsByTag(role, userID string, includeExpired bool) (map[string]*model.TagStat, error) {
+	tagStats := make(map[string]*model.TagStat)
+	for _, achievement := range m.achievements {
+		if achievement.Status == model.AchievementStatusDeleted {
+			continue
+		}
+		for _, tag := range achievement.Tags {
+			stat, ok := tagStats[tag]
+			if !ok {
+				stat = &model.TagStat{Tag: tag, ByStatus: map[string]int{}}
+				tagStats[tag] = stat
+			}
+			stat.Total++
+			stat.ByStatus[achievement.Status]++
+			if achievement.Status == model.AchievementStatusVerified && (includeExpired || !achievement.IsExpired(time.Now())) {
+				stat.VerifiedPoints += achievement.Points
+			}
+		}
+	}
+	return tagStats, nil
+}
+
+func (m *MockAchievementRepository) GetTopStudents(limit int, includeExpired bool) ([]*model.StudentStats, error) {
 	return []*model.StudentStats{}, nil
 }
+
+func (m *MockAchievementRepository) ProcessAchievementOutbox(limit int) (int, error) {
+	return 0, nil
+}
+
+// mockMongoID adalah ID dokumen MongoDB milik prestasi di mock; anggota tim berbagi satu dokumen dan
+// prestasi tanpa ID memakai reference ID
+func mockMongoID(achievement *model.AchievementWithReference) string {
+	if achievement.ID != "" {
+		return achievement.ID
+	}
+	if achievement.TeamID != nil {
+		return *achievement.TeamID
+	}
+	return achievement.ReferenceID
+}
+
+func (m *MockAchievementRepository) GetReconcileReferences() ([]*model.ReconcileReference, error) {
+	var references []*model.ReconcileReference
+	for _, achievement := range m.achievements {
+		references = append(references, &model.ReconcileReference{
+			ReferenceID:        achievement.ReferenceID,
+			StudentID:          achievement.StudentID,
+			MongoAchievementID: mockMongoID(achievement),
+			Title:              achievement.Title,
+			Status:             achievement.Status,
+			TeamRole:           achievement.TeamRole,
+		})
+	}
+	sort.Slice(references, func(i, j int) bool { return references[i].ReferenceID < references[j].ReferenceID })
+	return references, nil
+}
+
+func (m *MockAchievementRepository) GetReconcileDocuments() ([]*model.ReconcileDocument, error) {
+	var documents []*model.ReconcileDocument
+	if m.Documents == nil {
+		for _, achievement := range m.achievements {
+			documents = append(documents, &model.ReconcileDocument{
+				MongoAchievementID: mockMongoID(achievement),
+				StudentID:          achievement.StudentID,
+				Title:              achievement.Title,
+			})
+		}
+	} else {
+		for _, document := range m.Documents {
+			copied := *document
+			documents = append(documents, &copied)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool { return documents[i].MongoAchievementID < documents[j].MongoAchievementID })
+	return documents, nil
+}
+
+func (m *MockAchievementRepository) GetPendingOutboxDocuments() ([]string, error) {
+	return m.PendingOutbox, nil
+}
+
+func (m *MockAchievementRepository) UpdateReferenceTitle(referenceID, title string) error {
+	for _, achievement := range m.achievements {
+		if achievement.ReferenceID == referenceID {
+			achievement.Title = title
+			return nil
+		}
+	}
+	return ErrReconcileStale
+}
+
+func (m *MockAchievementRepository) SetAchievementDocumentStudent(mongoID, studentID string) error {
+	if document, ok := m.Documents[mongoID]; ok {
+		document.StudentID = studentID
+	}
+	return nil
+}
+
+func (m *MockAchievementRepository) DeleteOrphanDocument(mongoID string) error {
+	for _, achievement := range m.achievements {
+		if mockMongoID(achievement) == mongoID {
+			return ErrReconcileStale
+		}
+	}
+	delete(m.Documents, mongoID)
+	return nil
+}
+
+func (m *MockAchievementRepository) DeleteDanglingReference(referenceID string) error {
+	for key, achievement := range m.achievements {
+		if achievement.ReferenceID != referenceID {
+			continue
+		}
+		if _, ok := m.Documents[mockMongoID(achievement)]; ok || m.Documents == nil {
+			return ErrReconcileStale
+		}
+		delete(m.achievements, key)
+		delete(m.histories, referenceID)
+		delete(m.attachments, referenceID)
+		return nil
+	}
+	return ErrReconcileStale
+}
+
+// SearchAchievements menilai dokumen seperti text index MongoDB: kata di title berbobot 10, description dan
+// tags 5, search_terms (kata Details dan kata dasar) 1. Frasa dalam tanda kutip wajib ada dan kata berawalan "-" mengecualikan dokumen.
+func (m *MockAchievementRepository) SearchAchievements(search string, filters map[string]interface{}, page, pageSize int) ([]*model.AchievementSearchResult, int, error) {
+	var words, phrases, excluded []string
+	for i, part := range strings.Split(search, "\"") {
+		// Bagian bernomor ganjil berada di dalam tanda kutip
+		if i%2 == 1 {
+			phrases = append(phrases, part)
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			if strings.HasPrefix(field, "-") {
+				excluded = append(excluded, strings.TrimPrefix(field, "-"))
+			} else {
+				words = append(words, field)
+			}
+		}
+	}
+
+	var results []*model.AchievementSearchResult
+	for _, achievement := range m.achievements {
+		if !matchesListFilters(achievement, filters) {
+			continue
+		}
+		if achievementType, ok := filters["achievement_type"].(string); ok && achievementType != "" && achievement.AchievementType != achievementType {
+			continue
+		}
+		if score := mockSearchScore(&achievement.Achievement, words, phrases, excluded); score > 0 {
+			results = append(results, &model.AchievementSearchResult{AchievementWithReference: *achievement, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ReferenceID < results[j].ReferenceID
+	})
+
+	total := len(results)
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	return results[start:end], total, nil
+}
+
+func mockSearchScore(achievement *model.Achievement, words, phrases, excluded []string) float64 {
+	weighted := []struct {
+		weight float64
+		tokens []string
+	}{
+		{10, model.SearchTokens(achievement.Title)},
+		{5, model.SearchTokens(achievement.Description)},
+		{5, model.SearchTokens(strings.Join(achievement.Tags, " "))},
+		{1, achievement.SearchTerms},
+	}
+
+	var all []string
+	for _, field := range weighted {
+		all = append(all, field.tokens...)
+	}
+	text := " " + strings.Join(all, " ") + " "
+	for _, word := range excluded {
+		if strings.Contains(text, " "+word+" ") {
+			return 0
+		}
+	}
+	for _, phrase := range phrases {
+		if !strings.Contains(text, " "+phrase+" ") {
+			return 0
+		}
+	}
+
+	var score float64
+	for _, word := range words {
+		for _, field := range weighted {
+			for _, token := range field.tokens {
+				if token == word {
+					score += field.weight
+				}
+			}
+		}
+	}
+	return score
+}
+
+func (m *MockAchievementRepository) BackfillSearchTerms(dryRun bool) (int, error) {
+	filled := 0
+	for _, achievement := range m.achievements {
+		if achievement.SearchTerms != nil {
+			continue
+		}
+		if !dryRun {
+			achievement.SearchTerms = model.AchievementSearchTerms(&achievement.Achievement)
+			if achievement.SearchTerms == nil {
+				achievement.SearchTerms = []string{}
+			}
+		}
+		filled++
+	}
+	return filled, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"uas_be/app/model"
+
+	"github.com/google/uuid"
+)
+
+// MockCommentRepository adalah mock untuk CommentRepository
+type MockCommentRepository struct {
+	comments []*model.AchievementComment
+}
+
+// NewMockCommentRepository membuat instance mock repository
+func NewMockCommentRepository() *MockCommentRepository {
+	return &MockCommentRepository{}
+}
+
+func (m *MockCommentRepository) CreateComment(comment *model.AchievementComment) error {
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+	comment.CreatedAt = time.Now()
+	m.comments = append(m.comments, comment)
+	return nil
+}
+
+func (m *MockCommentRepository) GetCommentByID(id string) (*model.AchievementComment, error) {
+	for _, comment := range m.comments {
+		if comment.ID == id {
+			return comment, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockCommentRepository) GetCommentsByAchievementID(achievementID string) ([]*model.AchievementComment, error) {
+	var comments []*model.AchievementComment
+	for _, comment := range m.comments {
+		if comment.AchievementID == achievementID {
+			// Salinan agar Replies yang disusun service tidak menumpuk antar panggilan
+			copied := *comment
+			comments = append(comments, &copied)
+		}
+	}
+	return comments, nil
+}
+
+func (m *MockCommentRepository) UpdateComment(comment *model.AchievementComment) error {
+	existing, _ := m.GetCommentByID(comment.ID)
+	if existing == nil || existing.DeletedAt != nil {
+		return errors.New("komentar tidak ditemukan")
+	}
+	now := time.Now()
+	existing.Body = comment.Body
+	existing.Mentions = comment.Mentions
+	existing.EditedAt = &now
+	comment.EditedAt = &now
+	return nil
+}
+
+func (m *MockCommentRepository) DeleteComment(id string) error {
+	existing, _ := m.GetCommentByID(id)
+	if existing == nil {
+		return errors.New("komentar tidak ditemukan")
+	}
+	now := time.Now()
+	existing.Body = ""
+	existing.Mentions = []string{}
+	existing.DeletedAt = &now
+	return nil
+}
@@ -2,16 +2,21 @@ package repository
 
 import (
 	"errors"
+	"time"
 	"uas_be/app/model"
 )
 
 type MockLecturerRepository struct {
-	lecturers map[string]*model.Lecturer
+	lecturers   map[string]*model.Lecturer
+	delegations map[string]*model.VerificationDelegation
+	reviewers   map[string]bool
 }
 
 func NewMockLecturerRepository() *MockLecturerRepository {
 	return &MockLecturerRepository{
-		lecturers: make(map[string]*model.Lecturer),
+		lecturers:   make(map[string]*model.Lecturer),
+		delegations: make(map[string]*model.VerificationDelegation),
+		reviewers:   make(map[string]bool),
 	}
 }
 
@@ -78,3 +83,71 @@ func (m *MockLecturerRepository) DeleteLecturer(id string) error {
 	delete(m.lecturers, id)
 	return nil
 }
+
+func (m *MockLecturerRepository) CreateDelegation(delegation *model.VerificationDelegation) error {
+	if delegation.DelegatorID == "" || delegation.DelegateID == "" {
+		return errors.New("delegator dan delegate tidak boleh kosong")
+	}
+	delegation.CreatedAt = time.Now()
+	m.delegations[delegation.ID] = delegation
+	return nil
+}
+
+func (m *MockLecturerRepository) GetDelegationByID(id string) (*model.VerificationDelegation, error) {
+	if delegation, exists := m.delegations[id]; exists {
+		return delegation, nil
+	}
+	return nil, nil
+}
+
+func (m *MockLecturerRepository) GetDelegationsByLecturerID(lecturerID string) ([]*model.VerificationDelegation, error) {
+	var delegations []*model.VerificationDelegation
+	for _, delegation := range m.delegations {
+		if delegation.DelegatorID == lecturerID || delegation.DelegateID == lecturerID {
+			delegations = append(delegations, delegation)
+		}
+	}
+	return delegations, nil
+}
+
+func (m *MockLecturerRepository) GetActiveDelegatorIDs(delegateID string, at time.Time) ([]string, error) {
+	var delegatorIDs []string
+	for _, delegation := range m.delegations {
+		if delegation.DelegateID == delegateID && delegation.IsActiveAt(at) {
+			delegatorIDs = append(delegatorIDs, delegation.DelegatorID)
+		}
+	}
+	return delegatorIDs, nil
+}
+
+func (m *MockLecturerRepository) RevokeDelegation(id string) error {
+	delegation, exists := m.delegations[id]
+	if !exists {
+		return errors.New("delegation tidak ditemukan")
+	}
+	now := time.Now()
+	delegation.RevokedAt = &now
+	return nil
+}
+
+func (m *MockLecturerRepository) GetDepartmentReviewers(department string) ([]*model.Lecturer, error) {
+	var reviewers []*model.Lecturer
+	for id := range m.reviewers {
+		if lecturer, exists := m.lecturers[id]; exists && lecturer.Department == department {
+			reviewers = append(reviewers, lecturer)
+		}
+	}
+	return reviewers, nil
+}
+
+func (m *MockLecturerRepository) SetDepartmentReviewer(id string, enabled bool) error {
+	if _, exists := m.lecturers[id]; !exists {
+		return errors.New("lecturer tidak ditemukan")
+	}
+	if enabled {
+		m.reviewers[id] = true
+	} else {
+		delete(m.reviewers, id)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"uas_be/app/model"
+
+	"github.com/google/uuid"
+)
+
+// MockNotificationRepository adalah mock untuk NotificationRepository
+type MockNotificationRepository struct {
+	notifications []*model.Notification
+}
+
+// NewMockNotificationRepository membuat instance mock repository
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return &MockNotificationRepository{}
+}
+
+func (m *MockNotificationRepository) CreateNotification(notification *model.Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	notification.CreatedAt = time.Now()
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+func (m *MockNotificationRepository) GetNotificationsByUserID(userID string, unreadOnly bool) ([]*model.Notification, error) {
+	var notifications []*model.Notification
+	for _, notification := range m.notifications {
+		if notification.UserID == userID && (!unreadOnly || !notification.IsRead) {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications, nil
+}
+
+func (m *MockNotificationRepository) MarkNotificationRead(id, userID string) error {
+	for _, notification := range m.notifications {
+		if notification.ID == id && notification.UserID == userID {
+			notification.IsRead = true
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"database/sql"
+	"uas_be/app/model"
+
+	"github.com/google/uuid"
+)
+
+// NotificationRepository adalah interface untuk akses data notifikasi user
+type NotificationRepository interface {
+	// CreateNotification menyimpan notifikasi baru
+	CreateNotification(notification *model.Notification) error
+
+	// GetNotificationsByUserID mengambil notifikasi user, dari yang terbaru
+	GetNotificationsByUserID(userID string, unreadOnly bool) ([]*model.Notification, error)
+
+	// MarkNotificationRead menandai notifikasi milik user sebagai sudah dibaca
+	MarkNotificationRead(id, userID string) error
+}
+
+// notificationRepositoryImpl adalah implementasi dari NotificationRepository
+type notificationRepositoryImpl struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository membuat instance repository notifikasi baru
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepositoryImpl{db: db}
+}
+
+// CreateNotification menyimpan notifikasi baru
+func (r *notificationRepositoryImpl) CreateNotification(notification *model.Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO notifications (id, user_id, type, message, achievement_id, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, FALSE, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRow(query, notification.ID, notification.UserID, notification.Type,
+		notification.Message, notification.AchievementID).Scan(&notification.CreatedAt)
+}
+
+// GetNotificationsByUserID mengambil notifikasi user, dari yang terbaru
+func (r *notificationRepositoryImpl) GetNotificationsByUserID(userID string, unreadOnly bool) ([]*model.Notification, error) {
+	query := `
+		SELECT id, user_id, type, message, achievement_id, is_read, created_at
+		FROM notifications
+		WHERE user_id = $1 AND ($2 = FALSE OR is_read = FALSE)
+		ORDER BY created_at DESC
+		LIMIT 100
+	`
+
+	rows, err := r.db.Query(query, userID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		notification := &model.Notification{}
+		err := rows.Scan(&notification.ID, &notification.UserID, &notification.Type, &notification.Message,
+			&notification.AchievementID, &notification.IsRead, &notification.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+// MarkNotificationRead menandai notifikasi milik user sebagai sudah dibaca
+func (r *notificationRepositoryImpl) MarkNotificationRead(id, userID string) error {
+	result, err := r.db.Exec(`UPDATE notifications SET is_read = TRUE WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"strconv"
+	"uas_be/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxBulkReviewItems membatasi jumlah prestasi dalam satu batch verifikasi/penolakan
+const maxBulkReviewItems = 100
+
+// BulkReviewAchievements godoc
+// @Summary Verifikasi/tolak prestasi secara batch
+// @Description Memverifikasi atau menolak banyak prestasi sekaligus (dosen wali/admin). Setiap item diperiksa sama seperti endpoint verify/reject satuan; item yang gagal tidak membatalkan item lain.
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body model.BulkReviewRequest true "Daftar prestasi beserta poin atau catatan penolakan"
+// @Success 200 {object} model.APIResponse{data=object{results=[]model.BulkReviewResult,succeeded=int,failed=int}} "Batch selesai diproses"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid atau jumlah item melebihi batas"
+// @Failure 403 {object} model.APIResponse "Mahasiswa tidak dapat memverifikasi prestasi"
+// @Router /achievements/bulk-review [post]
+func (s *achievementServiceImpl) BulkReviewAchievements(c *fiber.Ctx) error {
+	role := c.Locals("role").(string)
+
+	var req model.BulkReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format request tidak valid: " + err.Error(),
+		})
+	}
+
+	if len(req.Items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "items tidak boleh kosong",
+		})
+	}
+	if len(req.Items) > maxBulkReviewItems {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "maksimal " + strconv.Itoa(maxBulkReviewItems) + " prestasi per batch",
+		})
+	}
+
+	if role == "Mahasiswa" {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "mahasiswa tidak dapat memverifikasi prestasi",
+		})
+	}
+
+	results := make([]model.BulkReviewResult, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	succeeded := 0
+	for _, item := range req.Items {
+		result := model.BulkReviewResult{AchievementID: item.AchievementID, Action: item.Action}
+
+		var achievement *model.AchievementWithReference
+		var message string
+		var actionErr *actionError
+		switch {
+		case item.AchievementID == "":
+			actionErr = newActionError(fiber.StatusBadRequest, "achievement_id tidak boleh kosong")
+		case seen[item.AchievementID]:
+			actionErr = newActionError(fiber.StatusBadRequest, "prestasi muncul lebih dari sekali dalam batch")
+		case item.Action == model.AchievementActionVerify:
+			if item.Points == nil {
+				actionErr = newActionError(fiber.StatusBadRequest, "points wajib diisi untuk verify")
+				break
+			}
+			achievement, message, actionErr = s.verifyOne(c, item.AchievementID, bulkItemIfMatch(item), model.VerifyAchievementRequest{
+				Points:        *item.Points,
+				Justification: item.Justification,
+			})
+		case item.Action == model.AchievementActionReject:
+			achievement, actionErr = s.rejectOne(c, item.AchievementID, bulkItemIfMatch(item), item.RejectionNote)
+			message = "achievement berhasil ditolak"
+		default:
+			actionErr = newActionError(fiber.StatusBadRequest, "action harus verify atau reject")
+		}
+		seen[item.AchievementID] = true
+
+		if actionErr != nil {
+			result.StatusCode = actionErr.status
+			result.Message = actionErr.message
+			result.Data = actionErr.data
+		} else {
+			result.Success = true
+			result.StatusCode = fiber.StatusOK
+			result.Message = message
+			result.Data = achievement
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: strconv.Itoa(succeeded) + " dari " + strconv.Itoa(len(req.Items)) + " prestasi berhasil diproses",
+		Data: fiber.Map{
+			"results":   results,
+			"succeeded": succeeded,
+			"failed":    len(req.Items) - succeeded,
+		},
+	})
+}
+
+// bulkItemIfMatch mengubah versi opsional pada item batch menjadi nilai If-Match
+func bulkItemIfMatch(item model.BulkReviewItem) string {
+	if item.Version == nil {
+		return ""
+	}
+	return achievementETag(*item.Version)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBulkReviewAchievements_PartialFailure tests each item is checked on its own and failures do not stop the batch
+func TestBulkReviewAchievements_PartialFailure(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	lecturerID := uuid.New().String()
+	lecturerUserID := uuid.New().String()
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: lecturerID, UserID: lecturerUserID, LecturerID: "789012"})
+	newAchievement := func(advisorID string, submit bool) string {
+		studentID := uuid.New().String()
+		mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: uuid.New().String(), StudentID: studentID[:8], AdvisorID: advisorID})
+		achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+		if submit {
+			mockAchRepo.Submit(achWithRef.StudentID)
+		}
+		return achWithRef.StudentID
+	}
+	toVerify := newAchievement(lecturerID, true)
+	toReject := newAchievement(lecturerID, true)
+	draft := newAchievement(lecturerID, false)
+	otherAdvisee := newAchievement(uuid.New().String(), true)
+	app.Post("/achievements/bulk-review", func(c *fiber.Ctx) error {
+		c.Locals("userID", lecturerUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.BulkReviewAchievements(c)
+	})
+	points := 50
+	bodyBytes, _ := json.Marshal(model.BulkReviewRequest{Items: []model.BulkReviewItem{
+		{AchievementID: toVerify, Action: "verify", Points: &points},
+		{AchievementID: toReject, Action: "reject", RejectionNote: "Sertifikat tidak terbaca"},
+		{AchievementID: draft, Action: "verify", Points: &points},
+		{AchievementID: otherAdvisee, Action: "verify", Points: &points},
+		{AchievementID: toVerify, Action: "reject", RejectionNote: "Duplikat"},
+	}})
+
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/bulk-review", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	var result struct {
+		Data struct {
+			Results   []model.BulkReviewResult `json:"results"`
+			Succeeded int                      `json:"succeeded"`
+			Failed    int                      `json:"failed"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, result.Data.Succeeded)
+	assert.Equal(t, 3, result.Data.Failed)
+	statusCodes := make([]int, 0, len(result.Data.Results))
+	for _, item := range result.Data.Results {
+		statusCodes = append(statusCodes, item.StatusCode)
+	}
+	assert.Equal(t, []int{200, 200, 400, 401, 400}, statusCodes)
+
+	verified, _ := mockAchRepo.GetAchievementByID(toVerify)
+	rejected, _ := mockAchRepo.GetAchievementByID(toReject)
+	assert.Equal(t, model.AchievementStatusVerified, verified.Status)
+	assert.Equal(t, 50, verified.Points)
+	assert.Equal(t, model.AchievementStatusRejected, rejected.Status)
+	verifiedHistory, _ := mockAchRepo.GetAchievementHistory(toVerify)
+	rejectedHistory, _ := mockAchRepo.GetAchievementHistory(toReject)
+	assert.Len(t, verifiedHistory, 1)
+	assert.Len(t, rejectedHistory, 1)
+}
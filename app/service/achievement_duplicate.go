@@ -0,0 +1,120 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"uas_be/app/model"
+)
+
+// duplicateAcrossStudents menentukan apakah deteksi duplikat juga membandingkan prestasi mahasiswa lain
+var duplicateAcrossStudents = false
+
+// SetDuplicateCheckAcrossStudents mengatur cakupan deteksi duplikat prestasi
+func SetDuplicateCheckAcrossStudents(enabled bool) {
+	duplicateAcrossStudents = enabled
+}
+
+// findDuplicates mencari prestasi lain yang kemungkinan sama berdasarkan judul, tipe, tanggal kegiatan,
+// dan hash isi lampiran
+func (s *achievementServiceImpl) findDuplicates(achievement *model.AchievementWithReference) ([]*model.DuplicateMatch, error) {
+	scope := achievement.StudentID
+	if duplicateAcrossStudents {
+		scope = ""
+	}
+
+	var matches []*model.DuplicateMatch
+	byID := make(map[string]*model.DuplicateMatch)
+	add := func(candidate *model.AchievementWithReference, reasons []string) {
+		if match, ok := byID[candidate.ReferenceID]; ok {
+			match.Reasons = append(match.Reasons, reasons...)
+			return
+		}
+		match := model.NewDuplicateMatch(candidate, reasons)
+		byID[candidate.ReferenceID] = match
+		matches = append(matches, match)
+	}
+
+	candidates, err := s.achievementRepo.GetDuplicateCandidates(scope, achievement.AchievementType)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if candidate.ReferenceID == achievement.ReferenceID || sameTeam(achievement, candidate) {
+			continue
+		}
+		if reasons := model.DuplicateReasons(&achievement.Achievement, &candidate.Achievement); len(reasons) > 0 {
+			add(candidate, reasons)
+		}
+	}
+
+	attachments, err := s.achievementRepo.GetAttachmentsByAchievementID(achievement.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		if attachment.ContentHash != "" {
+			hashes = append(hashes, attachment.ContentHash)
+		}
+	}
+	if len(hashes) == 0 {
+		return matches, nil
+	}
+
+	sameFiles, err := s.achievementRepo.FindAttachmentsByHash(hashes)
+	if err != nil {
+		return nil, err
+	}
+	checked := map[string]bool{achievement.ReferenceID: true}
+	for _, file := range sameFiles {
+		if checked[file.AchievementID] {
+			continue
+		}
+		checked[file.AchievementID] = true
+
+		other, err := s.achievementRepo.GetAchievementByID(file.AchievementID)
+		if err != nil {
+			return nil, err
+		}
+		if other == nil || other.Status == model.AchievementStatusDeleted || sameTeam(achievement, other) || (scope != "" && other.StudentID != scope) {
+			continue
+		}
+		add(other, []string{model.DuplicateReasonAttachment})
+	}
+
+	return matches, nil
+}
+
+// sameTeam mengecek apakah dua reference adalah anggota prestasi tim yang sama (bukan duplikat)
+func sameTeam(a, b *model.AchievementWithReference) bool {
+	return a.TeamID != nil && b.TeamID != nil && *a.TeamID == *b.TeamID
+}
+
+// ownDuplicates menyaring hasil deteksi agar mahasiswa hanya melihat prestasi miliknya sendiri;
+// kecocokan dengan mahasiswa lain tetap terlihat oleh verifikator lewat detail prestasi
+func ownDuplicates(matches []*model.DuplicateMatch, studentID string) []*model.DuplicateMatch {
+	var own []*model.DuplicateMatch
+	for _, match := range matches {
+		if match.StudentID == studentID {
+			own = append(own, match)
+		}
+	}
+	return own
+}
+
+// fileContentHash menghitung SHA-256 isi file lampiran
+func fileContentHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateAchievement_DuplicateWarning tests a near-identical title with the same event date is reported as a warning
+func TestCreateAchievement_DuplicateWarning(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	details := map[string]interface{}{"certification_name": "TOEFL ITP", "issuer": "ETS", "issued_date": "2024-05-10"}
+	// Mock menyimpan satu prestasi per kunci, jadi prestasi lama dibuat dengan kunci lain lalu diberikan ke mahasiswa yang sama
+	existing, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "certification", Title: "Sertifikat TOEFL ITP 2024", Details: details}, uuid.New().String())
+	existing.StudentID = studentID
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		return service.CreateAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(model.CreateAchievementRequest{
+		AchievementType: "certification",
+		Title:           "sertifikat TOEFL-ITP (2024)",
+		Details:         details,
+	})
+
+	// Act
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	var result struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	// Assert
+	assert.Equal(t, 201, resp.StatusCode)
+	if assert.Len(t, result.Data.Duplicates, 1) {
+		assert.Equal(t, existing.ReferenceID, result.Data.Duplicates[0].AchievementID)
+		assert.Equal(t, []string{model.DuplicateReasonTitle, model.DuplicateReasonEventDate}, result.Data.Duplicates[0].Reasons)
+		assert.Equal(t, "/api/v1/achievements/"+existing.ReferenceID, result.Data.Duplicates[0].Link)
+	}
+}
+
+// TestSubmitAchievement_FlagsSharedAttachmentAcrossStudents tests an identical file from another student flags the submission for verifiers only
+func TestSubmitAchievement_FlagsSharedAttachmentAcrossStudents(t *testing.T) {
+	// Arrange
+	SetDuplicateCheckAcrossStudents(true)
+	defer SetDuplicateCheckAcrossStudents(false)
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	otherID := uuid.New().String()
+	mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Juara Kelas"}, otherID)
+	mockAchRepo.CreateAttachment(&model.AchievementAttachment{AchievementID: otherID, FileName: "sertifikat.pdf", ContentHash: "abc123"})
+	mine, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Beasiswa Unggulan"}, studentID)
+	mockAchRepo.CreateAttachment(&model.AchievementAttachment{AchievementID: mine.ReferenceID, FileName: "scan.pdf", ContentHash: "abc123"})
+	app.Post("/achievements/:id/submit", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return service.SubmitAchievement(c)
+	})
+	app.Get("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New().String())
+		c.Locals("role", "Admin")
+		return service.GetAchievementDetail(c)
+	})
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/"+mine.ReferenceID+"/submit", nil))
+	var submitted struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&submitted)
+	resp, _ = app.Test(httptest.NewRequest("GET", "/achievements/"+mine.ReferenceID, nil))
+	var detail struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&detail)
+
+	// Assert
+	assert.True(t, submitted.Data.PossibleDuplicate)
+	assert.Empty(t, submitted.Data.Duplicates)
+	assert.True(t, detail.Data.PossibleDuplicate)
+	if assert.Len(t, detail.Data.Duplicates, 1) {
+		assert.Equal(t, otherID, detail.Data.Duplicates[0].AchievementID)
+		assert.Equal(t, []string{model.DuplicateReasonAttachment}, detail.Data.Duplicates[0].Reasons)
+	}
+}
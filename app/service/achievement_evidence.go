@@ -0,0 +1,53 @@
+package service
+
+import (
+	"strings"
+	"uas_be/app/model"
+)
+
+// evidenceIncompleteError membawa checklist bukti yang belum terpenuhi saat submit
+type evidenceIncompleteError struct {
+	achievementType string
+	checklist       *model.EvidenceChecklist
+}
+
+func (e *evidenceIncompleteError) Error() string {
+	var labels []string
+	for _, item := range e.checklist.Missing() {
+		labels = append(labels, item.Label)
+	}
+	return "bukti prestasi tipe " + e.achievementType + " belum lengkap: " + strings.Join(labels, "; ")
+}
+
+// Unwrap membuat error ini dipetakan ke 400 oleh transitionErrorResponse
+func (e *evidenceIncompleteError) Unwrap() error {
+	return errInvalidTransitionInput
+}
+
+// evidenceChecklist menilai prestasi terhadap aturan bukti tipenya. Tipe yang tidak ada di katalog
+// (data lama) atau tanpa aturan bukti menghasilkan nil.
+func (s *achievementServiceImpl) evidenceChecklist(achievementID string, achievement *model.Achievement) (*model.EvidenceChecklist, error) {
+	definition, err := s.achievementRepo.GetAchievementType(achievement.AchievementType)
+	if err != nil || definition == nil {
+		return nil, err
+	}
+	rules := definition.EffectiveEvidenceRules()
+	if rules.MinAttachments == 0 && len(rules.AllowedFileTypes) == 0 && len(rules.RequiredDetails) == 0 {
+		return nil, nil
+	}
+
+	attachments, err := s.achievementRepo.GetAttachmentsByAchievementID(achievementID)
+	if err != nil {
+		return nil, err
+	}
+	return model.BuildEvidenceChecklist(rules, achievement.Details, attachments), nil
+}
+
+// checkEvidence adalah hook submit yang menolak prestasi jika syarat bukti tipenya belum terpenuhi
+func checkEvidence(s *achievementServiceImpl, tc *transitionContext) error {
+	checklist, err := s.evidenceChecklist(tc.achievementID, &tc.achievement.Achievement)
+	if err != nil || checklist == nil || checklist.Complete {
+		return err
+	}
+	return &evidenceIncompleteError{achievementType: tc.achievement.AchievementType, checklist: checklist}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// evidenceFixture membuat draft prestasi yang baru punya satu lampiran dari dua yang diwajibkan dan belum mengisi
+// details organizer, lalu mengembalikan app dengan route detail dan submit milik mahasiswa tersebut
+func evidenceFixture() (*fiber.App, *repository.MockAchievementRepository, string) {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID, userID := uuid.New().String(), uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	definition, _ := mockAchRepo.GetAchievementType(model.AchievementTypeOther)
+	definition.EvidenceRules = model.EvidenceRules{MinAttachments: 2, AllowedFileTypes: []string{".PDF", "jpg"}, RequiredDetails: []string{"organizer"}}.Normalize()
+	mockAchRepo.UpdateAchievementType(definition)
+	mockAchRepo.Create(&model.Achievement{AchievementType: model.AchievementTypeOther, Title: "Relawan Bencana", Details: map[string]interface{}{}}, studentID)
+	mockAchRepo.CreateAttachment(&model.AchievementAttachment{AchievementID: studentID, FileName: "sertifikat.pdf"})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return c.Next()
+	})
+	app.Get("/achievements/:id", service.GetAchievementDetail)
+	app.Post("/achievements/:id/submit", service.SubmitAchievement)
+	return app, mockAchRepo, studentID
+}
+
+func missingEvidenceCodes(checklist *model.EvidenceChecklist) []string {
+	var codes []string
+	for _, item := range checklist.Missing() {
+		codes = append(codes, item.Code)
+	}
+	return codes
+}
+
+// TestSubmitAchievement_EvidenceIncomplete tests submission is blocked with the missing evidence checklist and the achievement stays a draft
+func TestSubmitAchievement_EvidenceIncomplete(t *testing.T) {
+	// Arrange
+	app, mockAchRepo, achievementID := evidenceFixture()
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/submit", nil))
+	var blocked struct {
+		Data model.EvidenceChecklist `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&blocked)
+
+	// Assert
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.False(t, blocked.Data.Complete)
+	assert.Equal(t, []string{model.EvidenceItemAttachments, model.EvidenceItemDetails}, missingEvidenceCodes(&blocked.Data))
+	achievement, _ := mockAchRepo.GetAchievementByID(achievementID)
+	assert.Equal(t, model.AchievementStatusDraft, achievement.Status)
+}
+
+// TestGetAchievementDetail_EvidenceChecklist tests the detail shows the same missing evidence as a blocked submission
+func TestGetAchievementDetail_EvidenceChecklist(t *testing.T) {
+	// Arrange
+	app, _, achievementID := evidenceFixture()
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+achievementID, nil))
+	var detail struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&detail)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.NotNil(t, detail.Data.EvidenceChecklist) {
+		assert.Equal(t, []string{model.EvidenceItemAttachments, model.EvidenceItemDetails}, missingEvidenceCodes(detail.Data.EvidenceChecklist))
+	}
+}
+
+// TestSubmitAchievement_EvidenceComplete tests submit succeeds once every checklist item is fulfilled
+func TestSubmitAchievement_EvidenceComplete(t *testing.T) {
+	// Arrange
+	app, mockAchRepo, achievementID := evidenceFixture()
+	achievement, _ := mockAchRepo.GetAchievementByID(achievementID)
+	achievement.Details["organizer"] = "BPBD Jawa Timur"
+	mockAchRepo.CreateAttachment(&model.AchievementAttachment{AchievementID: achievementID, FileName: "surat_tugas.jpg"})
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/submit", nil))
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	achievement, _ = mockAchRepo.GetAchievementByID(achievementID)
+	assert.Equal(t, model.AchievementStatusSubmitted, achievement.Status)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// expiryWarning adalah berapa lama sebelum masa berlaku habis mahasiswa diingatkan (0 = tanpa pengingat)
+var expiryWarning = 30 * 24 * time.Hour
+
+// countExpiredPoints menentukan apakah poin prestasi yang masa berlakunya sudah lewat tetap dihitung di laporan
+var countExpiredPoints = true
+
+// SetAchievementExpiry mengatur pengingat kedaluwarsa dan aturan poin prestasi yang sudah kedaluwarsa
+func SetAchievementExpiry(warnBefore time.Duration, countExpired bool) {
+	expiryWarning = warnBefore
+	countExpiredPoints = countExpired
+}
+
+// resolveValidUntil menentukan masa berlaku prestasi. valid_until dari request dipakai jika ada (string kosong
+// berarti tanpa masa berlaku); jika tidak, sertifikasi memakai details.expiry_date. changed bernilai false jika
+// tidak ada sumber masa berlaku sehingga nilai lama dipertahankan.
+func resolveValidUntil(achievementType string, details map[string]interface{}, requested *string) (validUntil *time.Time, changed bool, actionErr *actionError) {
+	value := ""
+	switch {
+	case requested != nil:
+		value = *requested
+	case achievementType == model.AchievementTypeCertification:
+		expiryDate, ok := details["expiry_date"].(string)
+		if !ok {
+			return nil, false, nil
+		}
+		value = expiryDate
+	default:
+		return nil, false, nil
+	}
+
+	if value == "" {
+		return nil, true, nil
+	}
+	parsed, err := model.ParseValidUntil(value)
+	if err != nil {
+		return nil, false, newActionError(fiber.StatusBadRequest, "masa berlaku harus berformat YYYY-MM-DD")
+	}
+	return &parsed, true, nil
+}
+
+// countsTowardPoints mengecek apakah poin prestasi dihitung di laporan: hanya prestasi terverifikasi, dan
+// prestasi kedaluwarsa hanya jika countExpiredPoints aktif
+func countsTowardPoints(achievement *model.AchievementWithReference, now time.Time) bool {
+	if achievement.Status != model.AchievementStatusVerified {
+		return false
+	}
+	return countExpiredPoints || !achievement.IsExpired(now)
+}
+
+// SendExpiryWarnings mengingatkan mahasiswa yang prestasi terverifikasinya akan habis masa berlakunya dalam
+// expiryWarning. Setiap prestasi hanya diingatkan sekali per masa berlaku. Mengembalikan jumlah pengingat.
+func SendExpiryWarnings(
+	achievementRepo repository.AchievementRepository,
+	studentRepo repository.StudentRepository,
+	notificationRepo repository.NotificationRepository,
+	now time.Time,
+) (int, error) {
+	if expiryWarning <= 0 {
+		return 0, nil
+	}
+
+	expiring, err := achievementRepo.GetExpiringAchievements(now.Add(expiryWarning))
+	if err != nil {
+		return 0, err
+	}
+
+	warned := 0
+	for _, item := range expiring {
+		student, err := studentRepo.GetStudentByID(item.StudentID)
+		if err != nil {
+			return warned, err
+		}
+		if student == nil {
+			continue
+		}
+
+		marked, err := achievementRepo.MarkExpiryReminded(item.AchievementID, now)
+		if err != nil {
+			return warned, err
+		}
+		if !marked {
+			continue
+		}
+
+		achievementID := item.AchievementID
+		err = notificationRepo.CreateNotification(&model.Notification{
+			UserID: student.UserID,
+			Type:   model.NotificationTypeExpiryWarning,
+			Message: fmt.Sprintf("Masa berlaku prestasi \"%s\" habis pada %s (%d hari lagi)",
+				item.Title, item.ValidUntil.Format(model.ValidUntilLayout), item.DaysLeft(now)),
+			AchievementID: &achievementID,
+		})
+		if err != nil {
+			log.Println("warning: failed to create expiry notification:", err)
+			continue
+		}
+		warned++
+	}
+
+	return warned, nil
+}
+
+// StartExpiryWarningJob menjalankan SendExpiryWarnings secara berkala di background
+func StartExpiryWarningJob(
+	achievementRepo repository.AchievementRepository,
+	studentRepo repository.StudentRepository,
+	notificationRepo repository.NotificationRepository,
+	interval time.Duration,
+) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			warned, err := SendExpiryWarnings(achievementRepo, studentRepo, notificationRepo, time.Now())
+			if err != nil {
+				log.Println("warning: failed to send expiry warnings:", err)
+			} else if warned > 0 {
+				log.Printf("📅 Sent %d achievement expiry warnings", warned)
+			}
+			<-ticker.C
+		}
+	}()
+}
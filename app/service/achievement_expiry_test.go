@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// expiryFixture menyiapkan route prestasi dan laporan untuk seorang mahasiswa, beserta sertifikasi TOEFL
+// terverifikasi bernilai 40 poin yang masa berlakunya diambil dari details dan habis 10 hari lagi
+type expiryFixture struct {
+	app                  *fiber.App
+	mockAchRepo          *repository.MockAchievementRepository
+	mockStudentRepo      *repository.MockStudentRepository
+	mockNotificationRepo *repository.MockNotificationRepository
+	studentID            string
+	userID               string
+	toefl                *model.AchievementWithReference
+	createStatus         int
+}
+
+func newExpiryFixture(now time.Time) *expiryFixture {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	achievementService := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	reportService := NewReportService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID, userID := uuid.New().String(), uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return c.Next()
+	})
+	app.Post("/achievements", achievementService.CreateAchievement)
+	app.Get("/achievements", achievementService.GetAllAchievements)
+	app.Get("/reports/student/:id", reportService.GetStudentReport)
+
+	bodyBytes, _ := json.Marshal(model.CreateAchievementRequest{
+		AchievementType: model.AchievementTypeCertification,
+		Title:           "TOEFL ITP",
+		Details: map[string]interface{}{
+			"certification_name": "TOEFL ITP",
+			"issuer":             "ETS",
+			"expiry_date":        now.AddDate(0, 0, 10).Format(model.ValidUntilLayout),
+		},
+	})
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	toefl, _ := mockAchRepo.GetAchievementByID(studentID)
+	toefl.Status = model.AchievementStatusVerified
+	toefl.Points = 40
+
+	return &expiryFixture{
+		app:                  app,
+		mockAchRepo:          mockAchRepo,
+		mockStudentRepo:      mockStudentRepo,
+		mockNotificationRepo: repository.NewMockNotificationRepository(),
+		studentID:            studentID,
+		userID:               userID,
+		toefl:                toefl,
+		createStatus:         resp.StatusCode,
+	}
+}
+
+// expire memindahkan masa berlaku TOEFL ke tanggal yang sudah lewat
+func (f *expiryFixture) expire() {
+	expiredAt, _ := model.ParseValidUntil("2020-01-31")
+	f.mockAchRepo.SetValidUntil(f.toefl.ReferenceID, &expiredAt)
+}
+
+// studentReport mengambil laporan mahasiswa dengan aturan poin kedaluwarsa countExpired; aturan global
+// dikembalikan setelah test selesai
+func (f *expiryFixture) studentReport(t *testing.T, countExpired bool) (int, map[string]interface{}) {
+	warnBefore, previous := expiryWarning, countExpiredPoints
+	t.Cleanup(func() { SetAchievementExpiry(warnBefore, previous) })
+	SetAchievementExpiry(30*24*time.Hour, countExpired)
+
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/reports/student/"+f.studentID, nil))
+	var report struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&report)
+	return resp.StatusCode, report.Data
+}
+
+// TestCreateAchievement_ValidityFromDetails tests a certification takes its validity from the expiry date in details
+func TestCreateAchievement_ValidityFromDetails(t *testing.T) {
+	// Act
+	f := newExpiryFixture(time.Now())
+
+	// Assert
+	assert.Equal(t, 201, f.createStatus)
+	if assert.NotNil(t, f.toefl.ValidUntil) {
+		assert.False(t, f.toefl.Expired)
+	}
+}
+
+// TestSendExpiryWarnings_WarnsOnce tests the student is warned once before a verified achievement expires
+func TestSendExpiryWarnings_WarnsOnce(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	f := newExpiryFixture(now)
+	SendExpiryWarnings(f.mockAchRepo, f.mockStudentRepo, f.mockNotificationRepo, now)
+
+	// Act
+	second, err := SendExpiryWarnings(f.mockAchRepo, f.mockStudentRepo, f.mockNotificationRepo, now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, second)
+	notifications, _ := f.mockNotificationRepo.GetNotificationsByUserID(f.userID, false)
+	if assert.Len(t, notifications, 1) {
+		assert.Equal(t, model.NotificationTypeExpiryWarning, notifications[0].Type)
+		assert.Equal(t, f.toefl.ReferenceID, *notifications[0].AchievementID)
+	}
+}
+
+// TestGetAllAchievements_ExpiredFilter tests the expired filter returns achievements whose validity has passed
+func TestGetAllAchievements_ExpiredFilter(t *testing.T) {
+	// Arrange
+	f := newExpiryFixture(time.Now())
+	f.expire()
+
+	// Act
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/achievements?expired=true", nil))
+	var list struct {
+		Data struct {
+			Achievements []*model.AchievementWithReference `json:"achievements"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.Len(t, list.Data.Achievements, 1) {
+		assert.True(t, list.Data.Achievements[0].Expired)
+	}
+}
+
+// TestGetStudentReport_CountsExpiredPoints tests expired points still count when the rule keeps them
+func TestGetStudentReport_CountsExpiredPoints(t *testing.T) {
+	// Arrange
+	f := newExpiryFixture(time.Now())
+	f.expire()
+
+	// Act
+	status, report := f.studentReport(t, true)
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.EqualValues(t, 40, report["total_points"])
+	assert.EqualValues(t, 1, report["expired_achievements"])
+}
+
+// TestGetStudentReport_ExcludesExpiredPoints tests expired points are left out when the rule excludes them
+func TestGetStudentReport_ExcludesExpiredPoints(t *testing.T) {
+	// Arrange
+	f := newExpiryFixture(time.Now())
+	f.expire()
+
+	// Act
+	status, report := f.studentReport(t, false)
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.EqualValues(t, 0, report["total_points"])
+	assert.EqualValues(t, 1, report["expired_achievements"])
+}
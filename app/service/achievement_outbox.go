@@ -0,0 +1,30 @@
+package service
+
+import (
+	"log"
+	"time"
+	"uas_be/app/repository"
+)
+
+// outboxBatchSize adalah jumlah dokumen maksimal yang disamakan dalam satu putaran job outbox
+const outboxBatchSize = 100
+
+// StartAchievementOutboxJob menerapkan ulang perubahan dokumen prestasi di MongoDB yang tertunda secara
+// berkala di background, sampai PostgreSQL dan MongoDB kembali sama. Entri yang masih gagal dicoba lagi
+// pada putaran berikutnya setelah jeda percobaan ulangnya lewat.
+func StartAchievementOutboxJob(achievementRepo repository.AchievementRepository, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			synced, err := achievementRepo.ProcessAchievementOutbox(outboxBatchSize)
+			if err != nil {
+				log.Println("warning: failed to sync achievement documents to MongoDB:", err)
+			}
+			if synced > 0 {
+				log.Printf("🔄 Synced %d achievement documents to MongoDB", synced)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func outboxDocument(t *testing.T, value interface{}) []byte {
+	payload, err := bson.Marshal(value)
+	assert.NoError(t, err)
+	return payload
+}
+
+// TestAchievementOutbox_CrashBeforeMongoWrite tests an entry committed to PostgreSQL but never applied (process stopped) is applied by the job
+func TestAchievementOutbox_CrashBeforeMongoWrite(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	store := repository.NewMockAchievementOutboxStore()
+	documents := repository.NewMockAchievementDocumentStore()
+	outbox := repository.NewAchievementOutbox(store, documents)
+	outbox.SetClock(func() time.Time { return now })
+	mongoID := primitive.NewObjectID().Hex()
+	store.Enqueue(mongoID, model.OutboxOperationUpsert, outboxDocument(t, &model.Achievement{Title: "Juara 1", Points: 0}), now)
+
+	// Act
+	synced, err := outbox.Process(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, synced)
+	assert.Equal(t, "Juara 1", documents.Document(mongoID)["title"])
+	assert.Empty(t, store.Entries())
+}
+
+// TestAchievementOutbox_RetryWithBackoff tests a failed MongoDB write stays pending, is scheduled with backoff and converges on a later run
+func TestAchievementOutbox_RetryWithBackoff(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	store := repository.NewMockAchievementOutboxStore()
+	documents := repository.NewMockAchievementDocumentStore()
+	documents.FailWrites = 2
+	outbox := repository.NewAchievementOutbox(store, documents)
+	outbox.SetClock(func() time.Time { return now })
+	mongoID := primitive.NewObjectID().Hex()
+	store.Enqueue(mongoID, model.OutboxOperationUpsert, outboxDocument(t, &model.Achievement{Title: "Juara 1"}), now)
+
+	// Act
+	err := outbox.Flush(mongoID)
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrInjectedFault)
+	entries := store.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].Attempts)
+	assert.NotNil(t, entries[0].LastError)
+	assert.True(t, entries[0].NextAttemptAt.After(now))
+	assert.Nil(t, documents.Document(mongoID))
+
+	// Act: entri belum jatuh tempo sehingga tidak dicoba
+	synced, err := outbox.Process(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, synced)
+
+	// Act: percobaan kedua gagal lagi dan jedanya bertambah
+	now = entries[0].NextAttemptAt
+	_, err = outbox.Process(10)
+
+	// Assert
+	assert.Error(t, err)
+	entries = store.Entries()
+	assert.Equal(t, 2, entries[0].Attempts)
+	assert.True(t, entries[0].NextAttemptAt.Sub(now) > 30*time.Second)
+
+	// Act: MongoDB kembali tersedia
+	now = entries[0].NextAttemptAt
+	synced, err = outbox.Process(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, synced)
+	assert.Equal(t, "Juara 1", documents.Document(mongoID)["title"])
+	assert.Empty(t, store.Entries())
+}
+
+// TestAchievementOutbox_PreservesOrder tests later changes are not applied before an earlier failed change, so stale data never overwrites newer data
+func TestAchievementOutbox_PreservesOrder(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	store := repository.NewMockAchievementOutboxStore()
+	documents := repository.NewMockAchievementDocumentStore()
+	outbox := repository.NewAchievementOutbox(store, documents)
+	outbox.SetClock(func() time.Time { return now })
+	mongoID := primitive.NewObjectID().Hex()
+	store.Enqueue(mongoID, model.OutboxOperationUpsert, outboxDocument(t, &model.Achievement{Title: "Draft", Points: 0}), now)
+	assert.NoError(t, outbox.Flush(mongoID))
+
+	// Act: perubahan pertama gagal, perubahan kedua (poin dari verifikasi) dicatat setelahnya
+	documents.FailWrites = 1
+	store.Enqueue(mongoID, model.OutboxOperationUpdate, outboxDocument(t, bson.M{"title": "Revisi", "points": 0}), now)
+	firstErr := outbox.Flush(mongoID)
+	store.Enqueue(mongoID, model.OutboxOperationUpdate, outboxDocument(t, bson.M{"title": "Revisi", "points": 50}), now)
+	secondErr := outbox.Flush(mongoID)
+
+	// Assert
+	assert.Error(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, "Revisi", documents.Document(mongoID)["title"])
+	assert.EqualValues(t, 50, documents.Document(mongoID)["points"])
+	assert.Empty(t, store.Entries())
+}
+
+// TestAchievementOutbox_CrashAfterMongoWrite tests an entry applied to MongoDB but not marked done is reapplied without changing the result
+func TestAchievementOutbox_CrashAfterMongoWrite(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	store := repository.NewMockAchievementOutboxStore()
+	documents := repository.NewMockAchievementDocumentStore()
+	outbox := repository.NewAchievementOutbox(store, documents)
+	outbox.SetClock(func() time.Time { return now })
+	mongoID := primitive.NewObjectID().Hex()
+	store.Enqueue(mongoID, model.OutboxOperationUpsert, outboxDocument(t, &model.Achievement{Title: "Juara 1", Tags: []string{"AI"}}), now)
+	store.Enqueue(mongoID, model.OutboxOperationUpdate, outboxDocument(t, bson.M{"points": 30}), now)
+	store.FailComplete = 1
+
+	// Act
+	err := outbox.Flush(mongoID)
+
+	// Assert: MongoDB sudah ditulis tetapi entri outbox tetap ada karena transaksi di-rollback
+	assert.ErrorIs(t, err, repository.ErrInjectedFault)
+	assert.Len(t, store.Entries(), 2)
+	assert.Equal(t, "Juara 1", documents.Document(mongoID)["title"])
+
+	// Act
+	synced, err := outbox.Process(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, synced)
+	assert.Empty(t, store.Entries())
+	document := documents.Document(mongoID)
+	assert.Equal(t, "Juara 1", document["title"])
+	assert.EqualValues(t, 30, document["points"])
+	assert.Equal(t, bson.A{"AI"}, document["tags"])
+}
+
+// TestAchievementOutbox_DeleteIsIdempotent tests deleting a document that is already gone still completes the entry
+func TestAchievementOutbox_DeleteIsIdempotent(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	store := repository.NewMockAchievementOutboxStore()
+	documents := repository.NewMockAchievementDocumentStore()
+	outbox := repository.NewAchievementOutbox(store, documents)
+	outbox.SetClock(func() time.Time { return now })
+	mongoID := primitive.NewObjectID().Hex()
+	store.Enqueue(mongoID, model.OutboxOperationUpsert, outboxDocument(t, &model.Achievement{Title: "Juara 1"}), now)
+	store.Enqueue(mongoID, model.OutboxOperationDelete, nil, now)
+	store.Enqueue(mongoID, model.OutboxOperationDelete, nil, now)
+
+	// Act
+	err := outbox.Flush(mongoID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, documents.Document(mongoID))
+	assert.Empty(t, store.Entries())
+	assert.Equal(t, 3, documents.Writes)
+}
+
+// TestAchievementOutbox_FailureIsolatedPerDocument tests a document that keeps failing does not block other documents
+func TestAchievementOutbox_FailureIsolatedPerDocument(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	store := repository.NewMockAchievementOutboxStore()
+	documents := repository.NewMockAchievementDocumentStore()
+	outbox := repository.NewAchievementOutbox(store, documents)
+	outbox.SetClock(func() time.Time { return now })
+	brokenID := primitive.NewObjectID().Hex()
+	healthyID := primitive.NewObjectID().Hex()
+	store.Enqueue(brokenID, "rename", nil, now)
+	store.Enqueue(healthyID, model.OutboxOperationUpsert, outboxDocument(t, &model.Achievement{Title: "Juara 2"}), now)
+
+	// Act
+	synced, err := outbox.Process(10)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 1, synced)
+	assert.Equal(t, "Juara 2", documents.Document(healthyID)["title"])
+	entries := store.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, brokenID, entries[0].MongoAchievementID)
+}
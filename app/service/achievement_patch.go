@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"uas_be/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PatchAchievement godoc
+// @Summary Ubah sebagian field prestasi
+// @Description Menerapkan JSON Merge Patch (RFC 7396, Content-Type application/merge-patch+json atau application/json) atau JSON Patch (RFC 6902, Content-Type application/json-patch+json) ke prestasi, sehingga satu field Details atau satu tag bisa diubah tanpa mengirim ulang semuanya. Field yang bisa diubah sama dengan PUT: achievement_type, title, description, details, tags, valid_until. Aturan PUT tetap berlaku (hanya draft atau revision_requested, points tidak bisa diubah).
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param body body object true "Merge patch (objek) atau JSON Patch (array model.JSONPatchOperation)"
+// @Param If-Match header string false "ETag prestasi yang terakhir dibaca"
+// @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Prestasi berhasil diupdate"
+// @Header 200 {string} ETag "Versi prestasi"
+// @Failure 400 {object} model.APIResponse "Patch tidak valid, menyentuh field read-only, atau status bukan draft"
+// @Failure 401 {object} model.APIResponse "Prestasi bukan milik anda"
+// @Failure 403 {object} model.APIResponse "Dosen wali atau anggota tim selain ketua tidak dapat mengedit prestasi"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Operasi test JSON Patch gagal"
+// @Failure 412 {object} model.APIResponse "Prestasi sudah diubah sejak terakhir dibaca (If-Match tidak cocok)"
+// @Failure 415 {object} model.APIResponse "Content-Type patch tidak didukung"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievements/{id} [patch]
+func (s *achievementServiceImpl) PatchAchievement(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]))
+	if contentType != model.MergePatchContentType && contentType != model.JSONPatchContentType && contentType != fiber.MIMEApplicationJSON {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "content-type harus " + model.MergePatchContentType + " atau " + model.JSONPatchContentType,
+		})
+	}
+
+	// Body diurai sebelum prestasi diambil, sama seperti PUT
+	var mergePatch map[string]interface{}
+	var operations []model.JSONPatchOperation
+	var err error
+	if contentType == model.JSONPatchContentType {
+		err = json.Unmarshal(c.Body(), &operations)
+	} else {
+		err = json.Unmarshal(c.Body(), &mergePatch)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format patch tidak valid: " + err.Error(),
+		})
+	}
+
+	achievement, actionErr := s.loadEditableAchievement(c, achievementID)
+	if actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	// Patch diterapkan ke salinan dokumen; dokumen asal dipakai untuk menentukan field yang berubah
+	original, err := model.PatchableAchievement(achievement)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal membaca prestasi",
+		})
+	}
+	document, _ := model.PatchableAchievement(achievement)
+
+	var patched map[string]interface{}
+	if contentType == model.JSONPatchContentType {
+		patched, err = model.ApplyJSONPatch(document, operations)
+	} else {
+		patched, err = model.ApplyMergePatch(document, mergePatch)
+	}
+	if err != nil {
+		return patchErrorResponse(c, err)
+	}
+
+	req, err := model.PatchedAchievementRequest(original, patched)
+	if err != nil {
+		return patchErrorResponse(c, err)
+	}
+
+	return s.saveAchievementUpdate(c, achievementID, achievement, req)
+}
+
+// patchErrorResponse memetakan error patch ke response; operasi test yang gagal dikembalikan sebagai 409
+func patchErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	if errors.Is(err, model.ErrPatchTestFailed) {
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(model.APIResponse{
+		Status:  "error",
+		Message: "patch tidak bisa diterapkan: " + err.Error(),
+	})
+}
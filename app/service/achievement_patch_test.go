@@ -0,0 +1,142 @@
+package service
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// patchFixture membuat draft prestasi milik seorang mahasiswa dan mengembalikan fungsi untuk mengirim PATCH ke prestasi tersebut
+func patchFixture() (func(contentType, body string) int, *model.AchievementWithReference) {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID, userID := uuid.New().String(), uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	achievement, _ := mockAchRepo.Create(&model.Achievement{
+		AchievementType: model.AchievementTypeOther,
+		Title:           "Relawan Bencana",
+		Details:         map[string]interface{}{"organizer": "BPBD", "location": "Malang", "role": "Koordinator"},
+		Tags:            []string{"sosial"},
+	}, studentID)
+	app.Patch("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return service.PatchAchievement(c)
+	})
+	patch := func(contentType, body string) int {
+		req := httptest.NewRequest("PATCH", "/achievements/"+studentID, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	return patch, achievement
+}
+
+// TestPatchAchievement_MergePatch tests a merge patch changes and removes single Details keys while other fields stay the same
+func TestPatchAchievement_MergePatch(t *testing.T) {
+	// Arrange
+	patch, achievement := patchFixture()
+
+	// Act
+	status := patch(model.MergePatchContentType, `{"details": {"location": "Batu", "role": null}}`)
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, map[string]interface{}{"organizer": "BPBD", "location": "Batu"}, achievement.Details)
+	assert.Equal(t, "Relawan Bencana", achievement.Title)
+	assert.Equal(t, []string{"sosial"}, achievement.Tags)
+}
+
+// TestPatchAchievement_JSONPatch tests JSON patch operations are applied in order after a passing test operation
+func TestPatchAchievement_JSONPatch(t *testing.T) {
+	// Arrange
+	patch, achievement := patchFixture()
+
+	// Act
+	status := patch(model.JSONPatchContentType, `[
+		{"op": "test", "path": "/details/location", "value": "Malang"},
+		{"op": "add", "path": "/tags/-", "value": "kebencanaan"},
+		{"op": "replace", "path": "/title", "value": "Relawan Banjir Batu"}
+	]`)
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, []string{"sosial", "kebencanaan"}, achievement.Tags)
+	assert.Equal(t, "Relawan Banjir Batu", achievement.Title)
+	assert.Equal(t, "Malang", achievement.Details["location"])
+}
+
+// TestPatchAchievement_JSONPatchTestFailed tests a failing test operation rejects the whole patch
+func TestPatchAchievement_JSONPatchTestFailed(t *testing.T) {
+	// Arrange
+	patch, achievement := patchFixture()
+
+	// Act
+	status := patch(model.JSONPatchContentType, `[{"op": "test", "path": "/title", "value": "Lain"}, {"op": "remove", "path": "/tags"}]`)
+
+	// Assert
+	assert.Equal(t, 409, status)
+	assert.Equal(t, []string{"sosial"}, achievement.Tags)
+}
+
+// TestPatchAchievement_MergePatchPoints tests points cannot be changed through a merge patch
+func TestPatchAchievement_MergePatchPoints(t *testing.T) {
+	// Arrange
+	patch, achievement := patchFixture()
+
+	// Act
+	status := patch(model.MergePatchContentType, `{"points": 100}`)
+
+	// Assert
+	assert.Equal(t, 400, status)
+	assert.Equal(t, 0, achievement.Points)
+}
+
+// TestPatchAchievement_JSONPatchPoints tests points cannot be changed through a JSON patch
+func TestPatchAchievement_JSONPatchPoints(t *testing.T) {
+	// Arrange
+	patch, achievement := patchFixture()
+
+	// Act
+	status := patch(model.JSONPatchContentType, `[{"op": "replace", "path": "/points", "value": 100}]`)
+
+	// Assert
+	assert.Equal(t, 400, status)
+	assert.Equal(t, 0, achievement.Points)
+}
+
+// TestPatchAchievement_UnsupportedContentType tests a body that is neither merge patch nor JSON patch is rejected
+func TestPatchAchievement_UnsupportedContentType(t *testing.T) {
+	// Arrange
+	patch, achievement := patchFixture()
+
+	// Act
+	status := patch("text/plain", `{"title": "x"}`)
+
+	// Assert
+	assert.Equal(t, 415, status)
+	assert.Equal(t, "Relawan Bencana", achievement.Title)
+}
+
+// TestPatchAchievement_NotDraft tests a submitted achievement cannot be patched
+func TestPatchAchievement_NotDraft(t *testing.T) {
+	// Arrange
+	patch, achievement := patchFixture()
+	achievement.Status = model.AchievementStatusSubmitted
+
+	// Act
+	status := patch(model.MergePatchContentType, `{"title": "Sesudah submit"}`)
+
+	// Assert
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "Relawan Bencana", achievement.Title)
+}
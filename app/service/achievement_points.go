@@ -0,0 +1,171 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"uas_be/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdjustAchievementPoints godoc
+// @Summary Koreksi poin prestasi terverifikasi
+// @Description Mengubah atau mencabut (revoke) poin prestasi yang sudah diverifikasi dengan alasan wajib. Poin lama dan baru dicatat di history; total poin di laporan langsung mengikuti. Poin prestasi tim dikoreksi per anggota. Kenaikan poin yang melewati ambang approval chain ditolak karena harus disetujui lewat chain tersebut.
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param body body model.AdjustPointsRequest true "Poin baru atau revoke, beserta alasan"
+// @Param If-Match header string false "ETag prestasi yang terakhir dibaca"
+// @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Poin berhasil dikoreksi"
+// @Header 200 {string} ETag "Versi prestasi"
+// @Failure 400 {object} model.APIResponse "Alasan kosong, poin tidak valid, prestasi belum diverifikasi, atau poin baru memerlukan approval chain"
+// @Failure 401 {object} model.APIResponse "Bukan dosen wali mahasiswa ini"
+// @Failure 403 {object} model.APIResponse "Mahasiswa tidak dapat mengoreksi poin"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 412 {object} model.APIResponse "Prestasi sudah diubah sejak terakhir dibaca (If-Match tidak cocok)"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/points [put]
+func (s *achievementServiceImpl) AdjustAchievementPoints(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	var req model.AdjustPointsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format request tidak valid: " + err.Error(),
+		})
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "alasan koreksi poin wajib diisi",
+		})
+	}
+
+	newPoints := 0
+	action := model.AchievementActionRevokePoints
+	if !req.Revoke {
+		if req.Points == nil || *req.Points < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "points wajib diisi dan tidak boleh negatif, atau gunakan revoke",
+			})
+		}
+		newPoints = *req.Points
+		action = model.AchievementActionAdjustPoints
+	}
+
+	if role == "Mahasiswa" {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "mahasiswa tidak dapat mengoreksi poin prestasi",
+		})
+	}
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil || achievement == nil {
+		return loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan").respond(c)
+	}
+
+	var onBehalfOf *model.Lecturer
+	if role == "Dosen Wali" {
+		advisor, status, message := s.authorizeAdvisor(userID, achievement)
+		if status != 0 {
+			return c.Status(status).JSON(model.APIResponse{
+				Status:  "error",
+				Message: message,
+			})
+		}
+		onBehalfOf = advisor
+	}
+
+	if achievement.Status != model.AchievementStatusVerified {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "hanya poin prestasi terverifikasi yang bisa dikoreksi, status saat ini " + achievement.Status,
+		})
+	}
+
+	if actionErr := checkIfMatch(c.Get(fiber.HeaderIfMatch), achievement); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	oldPoints := achievement.Points
+	if oldPoints == newPoints {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "poin tidak berubah (" + strconv.Itoa(oldPoints) + ")",
+		})
+	}
+
+	// Menaikkan poin melewati ambang approval chain harus lewat persetujuan chain tersebut, bukan koreksi langsung
+	if newPoints > oldPoints {
+		approvals, err := s.achievementRepo.GetAchievementApprovals(achievementID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil approval prestasi",
+			})
+		}
+		var chain *model.ApprovalChain
+		if len(approvals) > 0 {
+			if chain, err = s.achievementRepo.GetApprovalChainByID(approvals[0].ChainID); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+					Status:  "error",
+					Message: "gagal mengambil approval chain",
+				})
+			}
+		}
+		if actionErr := s.checkChainThreshold(achievement.AchievementType, chain, newPoints); actionErr != nil {
+			return actionErr.respond(c)
+		}
+	}
+
+	note := "Points adjusted from " + strconv.Itoa(oldPoints) + " to " + strconv.Itoa(newPoints) + ": " + reason
+	if req.Revoke {
+		note = "Points revoked (was " + strconv.Itoa(oldPoints) + "): " + reason
+	}
+	history := &model.AchievementHistory{
+		AchievementID: achievementID,
+		Action:        action,
+		OldStatus:     model.AchievementStatusVerified,
+		NewStatus:     model.AchievementStatusVerified,
+		ChangedBy:     userID,
+		Note:          &note,
+		OldPoints:     &oldPoints,
+		NewPoints:     &newPoints,
+	}
+	if onBehalfOf != nil {
+		history.OnBehalfOf = &onBehalfOf.UserID
+	}
+
+	// Poin disimpan per reference, sehingga koreksi pada prestasi tim hanya berlaku untuk anggota ini.
+	// History ditulis dalam transaksi yang sama dengan perubahan poin.
+	fields := map[string]interface{}{"points": newPoints}
+	effects := &model.AchievementTransitionEffects{History: history}
+	if achievement.TeamID == nil {
+		effects.DocumentFields = map[string]interface{}{"points": newPoints}
+	}
+	if err := s.achievementRepo.TransitionAchievementStatus(achievementID, model.AchievementStatusVerified, model.AchievementStatusVerified, achievement.Version, fields, effects); err != nil {
+		return transitionErrorResponse(c, err, "gagal mengoreksi poin prestasi")
+	}
+
+	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
+	setAchievementETag(c, achievement)
+	message := "poin prestasi berhasil dikoreksi"
+	if req.Revoke {
+		message = "poin prestasi berhasil dicabut"
+	}
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: message,
+		Data:    achievement,
+	})
+}
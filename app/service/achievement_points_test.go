@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// pointsFixture menyiapkan prestasi terverifikasi bernilai 50 poin milik mahasiswa bimbingan seorang dosen wali,
+// beserta route koreksi poin untuk mahasiswa dan dosen wali serta laporan mahasiswa
+type pointsFixture struct {
+	app           *fiber.App
+	mockAchRepo   *repository.MockAchievementRepository
+	studentID     string
+	advisorUserID string
+}
+
+func newPointsFixture() *pointsFixture {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	achievementService := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	reportService := NewReportService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID, studentUserID := uuid.New().String(), uuid.New().String()
+	advisor := &model.Lecturer{ID: uuid.New().String(), UserID: uuid.New().String(), LecturerID: "789012"}
+	mockLecturerRepo.CreateLecturer(advisor)
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: studentUserID, StudentID: "123456", AdvisorID: advisor.ID})
+	mockAchRepo.Create(&model.Achievement{AchievementType: "other", Title: "Relawan Bencana"}, studentID)
+	achievement, _ := mockAchRepo.GetAchievementByID(studentID)
+	achievement.Status = model.AchievementStatusVerified
+	achievement.Points = 50
+	app.Put("/student/achievements/:id/points", func(c *fiber.Ctx) error {
+		c.Locals("userID", studentUserID)
+		c.Locals("role", "Mahasiswa")
+		return achievementService.AdjustAchievementPoints(c)
+	})
+	app.Put("/advisor/achievements/:id/points", func(c *fiber.Ctx) error {
+		c.Locals("userID", advisor.UserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return achievementService.AdjustAchievementPoints(c)
+	})
+	app.Get("/reports/student/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", studentUserID)
+		c.Locals("role", "Mahasiswa")
+		return reportService.GetStudentReport(c)
+	})
+	return &pointsFixture{app: app, mockAchRepo: mockAchRepo, studentID: studentID, advisorUserID: advisor.UserID}
+}
+
+func (f *pointsFixture) adjust(path, achievementID string, body map[string]interface{}) int {
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("PUT", path+"/achievements/"+achievementID+"/points", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := f.app.Test(req)
+	return resp.StatusCode
+}
+
+func (f *pointsFixture) totalPoints() interface{} {
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/reports/student/"+f.studentID, nil))
+	var report struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&report)
+	return report.Data["total_points"]
+}
+
+// TestAdjustAchievementPoints_Success tests an advisor corrects points with a reason, history keeps old and new values, and the report total follows
+func TestAdjustAchievementPoints_Success(t *testing.T) {
+	// Arrange
+	f := newPointsFixture()
+
+	// Act
+	status := f.adjust("/advisor", f.studentID, map[string]interface{}{"points": 30, "reason": "tingkat lomba ternyata regional"})
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.EqualValues(t, 30, f.totalPoints())
+	history, _ := f.mockAchRepo.GetAchievementHistory(f.studentID)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, model.AchievementActionAdjustPoints, history[0].Action)
+		assert.Equal(t, 50, *history[0].OldPoints)
+		assert.Equal(t, 30, *history[0].NewPoints)
+		assert.Equal(t, f.advisorUserID, history[0].ChangedBy)
+	}
+}
+
+// TestAdjustAchievementPoints_Revoke tests revoking sets the points to 0 and keeps the achievement verified
+func TestAdjustAchievementPoints_Revoke(t *testing.T) {
+	// Arrange
+	f := newPointsFixture()
+
+	// Act
+	status := f.adjust("/advisor", f.studentID, map[string]interface{}{"revoke": true, "reason": "sertifikat tidak valid"})
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.EqualValues(t, 0, f.totalPoints())
+	achievement, _ := f.mockAchRepo.GetAchievementByID(f.studentID)
+	assert.Equal(t, model.AchievementStatusVerified, achievement.Status)
+	history, _ := f.mockAchRepo.GetAchievementHistory(f.studentID)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, model.AchievementActionRevokePoints, history[0].Action)
+		assert.Equal(t, 50, *history[0].OldPoints)
+		assert.Equal(t, 0, *history[0].NewPoints)
+	}
+}
+
+// TestAdjustAchievementPoints_StudentForbidden tests a student cannot adjust their own points
+func TestAdjustAchievementPoints_StudentForbidden(t *testing.T) {
+	// Arrange
+	f := newPointsFixture()
+
+	// Act
+	status := f.adjust("/student", f.studentID, map[string]interface{}{"points": 100, "reason": "salah input"})
+
+	// Assert
+	assert.Equal(t, 403, status)
+	assert.EqualValues(t, 50, f.totalPoints())
+}
+
+// TestAdjustAchievementPoints_MissingReason tests an adjustment without a reason is rejected
+func TestAdjustAchievementPoints_MissingReason(t *testing.T) {
+	// Arrange
+	f := newPointsFixture()
+
+	// Act
+	status := f.adjust("/advisor", f.studentID, map[string]interface{}{"points": 30})
+
+	// Assert
+	assert.Equal(t, 400, status)
+	history, _ := f.mockAchRepo.GetAchievementHistory(f.studentID)
+	assert.Empty(t, history)
+}
+
+// TestAdjustAchievementPoints_Unchanged tests an adjustment to the current value is rejected
+func TestAdjustAchievementPoints_Unchanged(t *testing.T) {
+	// Arrange
+	f := newPointsFixture()
+
+	// Act
+	status := f.adjust("/advisor", f.studentID, map[string]interface{}{"points": 50, "reason": "tidak berubah"})
+
+	// Assert
+	assert.Equal(t, 400, status)
+}
+
+// TestAdjustAchievementPoints_TeamMemberOnly tests adjusting one team member's points leaves the other members' points unchanged
+func TestAdjustAchievementPoints_TeamMemberOnly(t *testing.T) {
+	// Arrange
+	f := newPointsFixture()
+	leader, member := teamFixture(f.mockAchRepo)
+	member.StudentID = f.studentID
+	for _, ref := range []*model.AchievementWithReference{leader, member} {
+		ref.Status = model.AchievementStatusVerified
+		ref.Points = 50
+	}
+
+	// Act
+	status := f.adjust("/advisor", member.ReferenceID, map[string]interface{}{"points": 20, "reason": "peran anggota lebih kecil"})
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 20, member.Points)
+	assert.Equal(t, 50, leader.Points)
+	history, _ := f.mockAchRepo.GetAchievementHistory(member.ReferenceID)
+	assert.Len(t, history, 1)
+}
+
+// TestAdjustAchievementPoints_AboveChainThreshold tests points cannot be raised past an approval chain threshold without going through the chain
+func TestAdjustAchievementPoints_AboveChainThreshold(t *testing.T) {
+	// Arrange
+	f := newPointsFixture()
+	f.mockAchRepo.CreateApprovalChain(&model.ApprovalChain{
+		Name:      "Komite Fakultas",
+		MinPoints: 100,
+		Stages: []model.ApprovalStage{
+			{Name: "Dosen Wali", ApproverRole: "Dosen Wali"},
+			{Name: "Komite Fakultas", ApproverUserIDs: []string{uuid.New().String()}},
+		},
+	})
+
+	// Act
+	status := f.adjust("/advisor", f.studentID, map[string]interface{}{"points": 150, "reason": "tingkat internasional"})
+
+	// Assert
+	assert.Equal(t, 400, status)
+	achievement, _ := f.mockAchRepo.GetAchievementByID(f.studentID)
+	assert.Equal(t, 50, achievement.Points)
+	history, _ := f.mockAchRepo.GetAchievementHistory(f.studentID)
+	assert.Empty(t, history)
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIndonesianStems tests affixed words produce their root word as one of the candidates
+func TestIndonesianStems(t *testing.T) {
+	cases := map[string]string{
+		"kejuaraan":      "juara",
+		"perlombaan":     "lomba",
+		"menulis":        "tulis",
+		"mendapatkan":    "dapat",
+		"menyusun":       "susun",
+		"membaca":        "baca",
+		"pemenang":       "menang",
+		"pengalaman":     "alam",
+		"berprestasi":    "prestasi",
+		"pelatihan":      "latih",
+		"memperjuangkan": "juang",
+		"diikutinya":     "ikut",
+	}
+	for word, root := range cases {
+		// Act
+		stems := model.IndonesianStems(word)
+
+		// Assert
+		assert.Equal(t, word, stems[0])
+		assert.Contains(t, stems, root, word)
+	}
+}
+
+// TestIndonesianStems_RootWord tests root words, including those ending in -si, are returned unchanged and lowercased
+func TestIndonesianStems_RootWord(t *testing.T) {
+	// Act
+	prestasi := model.IndonesianStems("Prestasi")
+	gemastik := model.IndonesianStems("GEMASTIK")
+
+	// Assert
+	assert.Equal(t, []string{"prestasi"}, prestasi)
+	assert.Equal(t, []string{"gemastik"}, gemastik)
+}
+
+// TestTextSearchQuery tests words are expanded with their stems while phrases and exclusions pass through
+func TestTextSearchQuery(t *testing.T) {
+	// Act
+	search, terms := model.TextSearchQuery(`Kejuaraan "Juara 1" -internal di Gemastik`)
+
+	// Assert
+	assert.Equal(t, `"juara 1" kejuaraan juaraan kejuara juara -internal gemastik`, search)
+	assert.Contains(t, terms, "juara")
+	assert.Contains(t, terms, "gemastik")
+	assert.NotContains(t, terms, "internal")
+	assert.NotContains(t, terms, "di")
+}
+
+// TestTextSearchQuery_OnlyExclusions tests a query with only exclusions and stopwords has no terms to search
+func TestTextSearchQuery_OnlyExclusions(t *testing.T) {
+	// Act
+	_, terms := model.TextSearchQuery("-internal di")
+
+	// Assert
+	assert.Empty(t, terms)
+}
+
+// TestHighlightAchievement tests matches are wrapped in <mark>, text is HTML-escaped and long fields are cut around the match
+func TestHighlightAchievement(t *testing.T) {
+	// Arrange
+	long := ""
+	for i := 0; i < 30; i++ {
+		long += "kegiatan rutin "
+	}
+	achievement := &model.Achievement{
+		Title:       "Juara 1 <GEMASTIK> & Hackathon",
+		Description: long + "di ajang perlombaan nasional " + long,
+		Tags:        []string{"lomba"},
+		Details: map[string]interface{}{
+			"organizer": "Puspresnas",
+			"event":     map[string]interface{}{"name": "Gemastik XVI"},
+		},
+	}
+	_, terms := model.TextSearchQuery("gemastik lomba")
+
+	// Act
+	highlights := model.HighlightAchievement(achievement, terms)
+
+	// Assert
+	byField := make(map[string]string)
+	for _, highlight := range highlights {
+		byField[highlight.Field] = highlight.Snippet
+	}
+	assert.Equal(t, "Juara 1 &lt;<mark>GEMASTIK</mark>&gt; &amp; Hackathon", byField["title"])
+	assert.Equal(t, "<mark>lomba</mark>", byField["tags"])
+	assert.Equal(t, "<mark>Gemastik</mark> XVI", byField["details.event.name"])
+	assert.NotContains(t, byField, "details.organizer")
+	assert.Contains(t, byField["description"], "<mark>perlombaan</mark>")
+	assert.Less(t, len([]rune(byField["description"])), 200)
+	assert.True(t, []rune(byField["description"])[0] == '…')
+}
+
+// searchFixture membuat prestasi dua mahasiswa bimbingan seorang dosen wali dan satu mahasiswa lain. Dosen wali
+// melimpahkan wewenangnya kepada dosen lain; ids["advisor_user"] dan ids["delegate_user"] adalah user ID keduanya.
+func searchFixture() (*fiber.App, *repository.MockAchievementRepository, *repository.MockStudentRepository, map[string]string) {
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	achievementService := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	ids := map[string]string{
+		"advisor":       uuid.New().String(),
+		"advisor_user":  uuid.New().String(),
+		"delegate":      uuid.New().String(),
+		"delegate_user": uuid.New().String(),
+		"title":         uuid.New().String(),
+		"details":       uuid.New().String(),
+		"other":         uuid.New().String(),
+	}
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: ids["advisor"], UserID: ids["advisor_user"], LecturerID: "198001"})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: ids["delegate"], UserID: ids["delegate_user"], LecturerID: "198002"})
+	mockLecturerRepo.CreateDelegation(&model.VerificationDelegation{
+		ID:          uuid.New().String(),
+		DelegatorID: ids["advisor"],
+		DelegateID:  ids["delegate"],
+		StartDate:   time.Now().AddDate(0, 0, -1),
+		EndDate:     time.Now().AddDate(0, 0, 1),
+	})
+	mockStudentRepo.CreateStudent(&model.Student{ID: ids["title"], UserID: uuid.New().String(), StudentID: "1", AdvisorID: ids["advisor"]})
+	mockStudentRepo.CreateStudent(&model.Student{ID: ids["details"], UserID: uuid.New().String(), StudentID: "2", AdvisorID: ids["advisor"]})
+	mockStudentRepo.CreateStudent(&model.Student{ID: ids["other"], UserID: uuid.New().String(), StudentID: "3", AdvisorID: uuid.New().String()})
+
+	mockAchRepo.Create(&model.Achievement{
+		AchievementType: model.AchievementTypeCompetition,
+		Title:           "Juara 1 Gemastik 2024",
+		Description:     "Kategori pengembangan perangkat lunak",
+	}, ids["title"])
+	mockAchRepo.Create(&model.Achievement{
+		AchievementType: model.AchievementTypeCompetition,
+		Title:           "Finalis lomba nasional",
+		Details:         map[string]interface{}{"competition_name": "Gemastik XVI"},
+	}, ids["details"])
+	mockAchRepo.Create(&model.Achievement{
+		AchievementType: model.AchievementTypeCompetition,
+		Title:           "Juara 2 Gemastik",
+	}, ids["other"])
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", c.Get("X-User-ID"))
+		c.Locals("role", c.Get("X-Role"))
+		return c.Next()
+	})
+	app.Get("/achievements/search", achievementService.SearchAchievements)
+	return app, mockAchRepo, mockStudentRepo, ids
+}
+
+type searchResponse struct {
+	Data struct {
+		Achievements []*model.AchievementSearchResult `json:"achievements"`
+		Pagination   struct {
+			Total int `json:"total"`
+		} `json:"pagination"`
+	} `json:"data"`
+}
+
+func searchAchievements(app *fiber.App, query url.Values, userID, role string) (int, searchResponse) {
+	req := httptest.NewRequest("GET", "/achievements/search?"+query.Encode(), nil)
+	req.Header.Set("X-User-ID", userID)
+	req.Header.Set("X-Role", role)
+	resp, _ := app.Test(req)
+	var result searchResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+// TestSearchAchievements_AdvisorScopeAndRanking tests an advisor only finds advisees' achievements, title matches rank above Details matches, and highlights are returned
+func TestSearchAchievements_AdvisorScopeAndRanking(t *testing.T) {
+	// Arrange
+	app, _, _, ids := searchFixture()
+
+	// Act
+	status, result := searchAchievements(app, url.Values{"q": {"gemastik"}}, ids["advisor_user"], "Dosen Wali")
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 2, result.Data.Pagination.Total)
+	if assert.Len(t, result.Data.Achievements, 2) {
+		first, second := result.Data.Achievements[0], result.Data.Achievements[1]
+		assert.Equal(t, ids["title"], first.ReferenceID)
+		assert.Equal(t, ids["details"], second.ReferenceID)
+		assert.Greater(t, first.Score, second.Score)
+		assert.Equal(t, "title", first.Highlights[0].Field)
+		assert.Equal(t, "Juara 1 <mark>Gemastik</mark> 2024", first.Highlights[0].Snippet)
+		assert.Equal(t, "details.competition_name", second.Highlights[0].Field)
+	}
+}
+
+// TestSearchAchievements_DelegateScope tests a lecturer acting under an active delegation finds the delegator's advisees
+func TestSearchAchievements_DelegateScope(t *testing.T) {
+	// Arrange
+	app, _, _, ids := searchFixture()
+
+	// Act
+	status, result := searchAchievements(app, url.Values{"q": {"gemastik"}}, ids["delegate_user"], "Dosen Wali")
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 2, result.Data.Pagination.Total)
+	for _, achievement := range result.Data.Achievements {
+		assert.NotEqual(t, ids["other"], achievement.ReferenceID)
+	}
+}
+
+// TestSearchAchievements_Stemming tests an affixed query word matches the root word
+func TestSearchAchievements_Stemming(t *testing.T) {
+	// Arrange
+	app, _, _, ids := searchFixture()
+
+	// Act
+	status, result := searchAchievements(app, url.Values{"q": {"perlombaan"}}, uuid.New().String(), "Admin")
+
+	// Assert
+	assert.Equal(t, 200, status)
+	if assert.Len(t, result.Data.Achievements, 1) {
+		assert.Equal(t, ids["details"], result.Data.Achievements[0].ReferenceID)
+		assert.Equal(t, "Finalis <mark>lomba</mark> nasional", result.Data.Achievements[0].Highlights[0].Snippet)
+	}
+}
+
+// TestSearchAchievements_StatusFilter tests the query combines with the list status filter
+func TestSearchAchievements_StatusFilter(t *testing.T) {
+	// Arrange
+	app, mockAchRepo, _, ids := searchFixture()
+	verified, _ := mockAchRepo.GetAchievementByID(ids["details"])
+	verified.Status = model.AchievementStatusVerified
+
+	// Act
+	_, result := searchAchievements(app, url.Values{"q": {"gemastik"}, "status": {model.AchievementStatusVerified}}, uuid.New().String(), "Admin")
+
+	// Assert
+	if assert.Len(t, result.Data.Achievements, 1) {
+		assert.Equal(t, ids["details"], result.Data.Achievements[0].ReferenceID)
+	}
+}
+
+// TestSearchAchievements_Exclusion tests a word prefixed with "-" excludes achievements containing it
+func TestSearchAchievements_Exclusion(t *testing.T) {
+	// Arrange
+	app, _, _, ids := searchFixture()
+
+	// Act
+	_, result := searchAchievements(app, url.Values{"q": {"gemastik -finalis"}}, uuid.New().String(), "Admin")
+
+	// Assert
+	assert.Len(t, result.Data.Achievements, 2)
+	for _, achievement := range result.Data.Achievements {
+		assert.NotEqual(t, ids["details"], achievement.ReferenceID)
+	}
+}
+
+// TestSearchAchievements_AdvisorWithoutAdvisees tests an advisor without advisees gets no results
+func TestSearchAchievements_AdvisorWithoutAdvisees(t *testing.T) {
+	// Arrange
+	app, _, _, _ := searchFixture()
+
+	// Act
+	status, result := searchAchievements(app, url.Values{"q": {"gemastik"}}, uuid.New().String(), "Dosen Wali")
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Empty(t, result.Data.Achievements)
+	assert.Equal(t, 0, result.Data.Pagination.Total)
+}
+
+// TestSearchAchievements_EmptyQuery tests a blank query is rejected
+func TestSearchAchievements_EmptyQuery(t *testing.T) {
+	// Arrange
+	app, _, _, _ := searchFixture()
+
+	// Act
+	status, _ := searchAchievements(app, url.Values{"q": {"  "}}, uuid.New().String(), "Admin")
+
+	// Assert
+	assert.Equal(t, 400, status)
+}
+
+// TestSearchAchievements_OnlyExclusions tests a query with nothing to match besides exclusions is rejected
+func TestSearchAchievements_OnlyExclusions(t *testing.T) {
+	// Arrange
+	app, _, _, _ := searchFixture()
+
+	// Act
+	status, _ := searchAchievements(app, url.Values{"q": {"-gemastik"}}, uuid.New().String(), "Admin")
+
+	// Assert
+	assert.Equal(t, 400, status)
+}
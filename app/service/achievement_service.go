@@ -1,8 +1,11 @@
 package service
 
 import (
+	"errors"
+	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 	"uas_be/app/model"
 	"uas_be/app/repository"
@@ -13,16 +16,27 @@ import (
 
 type AchievementService interface {
 	GetAllAchievements(c *fiber.Ctx) error
+	SearchAchievements(c *fiber.Ctx) error
 	GetAchievementDetail(c *fiber.Ctx) error
 	CreateAchievement(c *fiber.Ctx) error
 	UpdateAchievement(c *fiber.Ctx) error
+	PatchAchievement(c *fiber.Ctx) error
 	SubmitAchievement(c *fiber.Ctx) error
 	VerifyAchievement(c *fiber.Ctx) error
 	RejectAchievement(c *fiber.Ctx) error
+	RequestRevision(c *fiber.Ctx) error
+	ReopenAchievement(c *fiber.Ctx) error
 	DeleteAchievement(c *fiber.Ctx) error
+	TransitionAchievement(c *fiber.Ctx) error
 	GetAdviseeAchievements(c *fiber.Ctx) error
 	GetAchievementHistory(c *fiber.Ctx) error
 	UploadAttachment(c *fiber.Ctx) error
+	GetTrash(c *fiber.Ctx) error
+	RestoreAchievement(c *fiber.Ctx) error
+	BulkReviewAchievements(c *fiber.Ctx) error
+	GetAchievementVersions(c *fiber.Ctx) error
+	GetAchievementVersionDiff(c *fiber.Ctx) error
+	AdjustAchievementPoints(c *fiber.Ctx) error
 }
 
 type achievementServiceImpl struct {
@@ -52,14 +66,17 @@ func NewAchievementService(
 // @Security BearerAuth
 // @Param page query int false "Nomor halaman" default(1)
 // @Param page_size query int false "Jumlah data per halaman" default(10)
-// @Param status query string false "Filter berdasarkan status" Enums(draft, submitted, verified, rejected)
-// @Param achievement_type query string false "Filter berdasarkan tipe" Enums(academic, competition, organization, publication, certification, other)
+// @Param status query string false "Filter berdasarkan status" Enums(draft, submitted, revision_requested, verified, rejected)
+// @Param achievement_type query string false "Filter berdasarkan kode tipe dari katalog /achievement-types"
 // @Param student_id query string false "Filter berdasarkan student ID"
 // @Param start_date query string false "Filter tanggal mulai (YYYY-MM-DD)"
 // @Param end_date query string false "Filter tanggal akhir (YYYY-MM-DD)"
+// @Param expired query bool false "Filter prestasi yang masa berlakunya sudah lewat (true) atau masih berlaku/tanpa masa berlaku (false)"
+// @Param expiring_within_days query int false "Filter prestasi yang masih berlaku dan habis dalam N hari"
 // @Param sort_by query string false "Field untuk sorting" default(created_at)
 // @Param sort_order query string false "Urutan sorting" Enums(ASC, DESC) default(DESC)
 // @Success 200 {object} model.APIResponse{data=object{achievements=[]model.AchievementWithReference,filters=object,pagination=object}} "Prestasi berhasil diambil"
+// @Failure 400 {object} model.APIResponse "Tipe prestasi tidak dikenal atau filter masa berlaku tidak valid"
 // @Failure 500 {object} model.APIResponse "Internal server error"
 // @Router /achievements [get]
 func (s *achievementServiceImpl) GetAllAchievements(c *fiber.Ctx) error {
@@ -73,9 +90,6 @@ func (s *achievementServiceImpl) GetAllAchievements(c *fiber.Ctx) error {
 	sortBy := c.Query("sort_by", "created_at")
 	sortOrder := c.Query("sort_order", "DESC")
 
-	userID := c.Locals("userID").(string)
-	role := c.Locals("role").(string)
-
 	if page < 1 {
 		page = 1
 	}
@@ -83,12 +97,85 @@ func (s *achievementServiceImpl) GetAllAchievements(c *fiber.Ctx) error {
 		pageSize = 10
 	}
 
+	filters, noAdvisees, actionErr := s.achievementListFilters(c)
+	if actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	var achievements []*model.AchievementWithReference
+	var total int
+	var err error
+
+	if noAdvisees {
+		// No advisees, return empty result
+		achievements = []*model.AchievementWithReference{}
+	} else if len(filters) > 0 || sortBy != "created_at" || sortOrder != "DESC" {
+		// Use filtered query if any filters are provided
+		achievements, total, err = s.achievementRepo.GetAchievementsWithFilters(page, pageSize, filters, sortBy, sortOrder)
+	} else {
+		achievements, total, err = s.achievementRepo.GetAllAchievements(page, pageSize)
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil achievements: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "achievements berhasil diambil",
+		Data: map[string]interface{}{
+			"achievements": achievements,
+			"filters": map[string]interface{}{
+				"status":           status,
+				"achievement_type": achievementType,
+				"student_id":       studentID,
+				"start_date":       startDate,
+				"end_date":         endDate,
+				"sort_by":          sortBy,
+				"sort_order":       sortOrder,
+			},
+			"pagination": map[string]interface{}{
+				"page":       page,
+				"page_size":  pageSize,
+				"total":      total,
+				"total_page": (total + pageSize - 1) / pageSize,
+			},
+		},
+	})
+}
+
+// achievementListFilters membaca filter daftar prestasi dari query (status, achievement_type, student_id,
+// start_date, end_date, expired, expiring_within_days) lalu menambahkan cakupan sesuai role: mahasiswa hanya
+// prestasinya sendiri dan dosen wali hanya mahasiswa bimbingannya beserta bimbingan dosen yang sedang melimpahkan
+// wewenang kepadanya. noAdvisees true jika dosen wali belum punya mahasiswa bimbingan sehingga hasilnya harus
+// kosong, bukan tanpa filter.
+func (s *achievementServiceImpl) achievementListFilters(c *fiber.Ctx) (filters map[string]interface{}, noAdvisees bool, actionErr *actionError) {
+	status := c.Query("status")
+	achievementType := c.Query("achievement_type")
+	studentID := c.Query("student_id")
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
 	// Build filters map
-	filters := make(map[string]interface{})
+	filters = make(map[string]interface{})
 	if status != "" {
 		filters["status"] = status
 	}
 	if achievementType != "" {
+		// Tipe yang sudah dipensiunkan tetap boleh dipakai untuk memfilter prestasi lama
+		definition, err := s.achievementRepo.GetAchievementType(achievementType)
+		if err != nil {
+			return nil, false, newActionError(fiber.StatusInternalServerError, "gagal mengambil tipe prestasi: "+err.Error())
+		}
+		if definition == nil {
+			return nil, false, newActionError(fiber.StatusBadRequest, "achievement_type "+achievementType+" tidak dikenal")
+		}
 		filters["achievement_type"] = achievementType
 	}
 	if studentID != "" {
@@ -100,6 +187,20 @@ func (s *achievementServiceImpl) GetAllAchievements(c *fiber.Ctx) error {
 	if endDate != "" {
 		filters["end_date"] = endDate
 	}
+	if expired := c.Query("expired"); expired != "" {
+		value, err := strconv.ParseBool(expired)
+		if err != nil {
+			return nil, false, newActionError(fiber.StatusBadRequest, "expired harus bernilai true atau false")
+		}
+		filters["expired"] = value
+	}
+	if within := c.Query("expiring_within_days"); within != "" {
+		days, err := strconv.Atoi(within)
+		if err != nil || days < 0 {
+			return nil, false, newActionError(fiber.StatusBadRequest, "expiring_within_days harus berupa angka >= 0")
+		}
+		filters["expires_before"] = time.Now().AddDate(0, 0, days)
+	}
 
 	// Add role-based access control
 	switch role {
@@ -107,51 +208,37 @@ func (s *achievementServiceImpl) GetAllAchievements(c *fiber.Ctx) error {
 		// Students can only see their own achievements
 		student, err := s.studentRepo.GetStudentByUserID(userID)
 		if err != nil || student == nil {
-			return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "student data tidak ditemukan",
-			})
+			return nil, false, newActionError(fiber.StatusNotFound, "student data tidak ditemukan")
 		}
 		filters["student_id"] = student.ID
 
 	case "Dosen Wali":
-		// Advisors can only see achievements of their advisees
-		students, err := s.studentRepo.GetStudentsByAdvisorID(userID)
+		// Advisors can only see achievements of their advisees, plus the advisees of lecturers
+		// who currently delegate their verification authority to them
+		lecturer, err := s.lecturerRepo.GetLecturerByUserID(userID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "gagal mengambil data mahasiswa bimbingan",
-			})
+			return nil, false, newActionError(fiber.StatusInternalServerError, "gagal mengambil data lecturer")
 		}
-		if len(students) == 0 {
-			// No advisees, return empty result
-			return c.Status(fiber.StatusOK).JSON(model.APIResponse{
-				Status:  "success",
-				Message: "achievements berhasil diambil",
-				Data: map[string]interface{}{
-					"achievements": []*model.AchievementWithReference{},
-					"filters": map[string]interface{}{
-						"status":           status,
-						"achievement_type": achievementType,
-						"student_id":       studentID,
-						"start_date":       startDate,
-						"end_date":         endDate,
-						"sort_by":          sortBy,
-						"sort_order":       sortOrder,
-					},
-					"pagination": map[string]interface{}{
-						"page":       page,
-						"page_size":  pageSize,
-						"total":      0,
-						"total_page": 0,
-					},
-				},
-			})
+		if lecturer == nil {
+			return filters, true, nil
 		}
-		// Add student IDs to filter
-		studentIDs := make([]string, len(students))
-		for i, student := range students {
-			studentIDs[i] = student.ID
+		delegatorIDs, err := s.lecturerRepo.GetActiveDelegatorIDs(lecturer.ID, time.Now())
+		if err != nil {
+			return nil, false, newActionError(fiber.StatusInternalServerError, "gagal mengambil pelimpahan wewenang")
+		}
+
+		var studentIDs []string
+		for _, advisorID := range append([]string{lecturer.ID}, delegatorIDs...) {
+			students, err := s.studentRepo.GetStudentsByAdvisorID(advisorID)
+			if err != nil {
+				return nil, false, newActionError(fiber.StatusInternalServerError, "gagal mengambil data mahasiswa bimbingan")
+			}
+			for _, student := range students {
+				studentIDs = append(studentIDs, student.ID)
+			}
+		}
+		if len(studentIDs) == 0 {
+			return filters, true, nil
 		}
 		filters["student_ids"] = studentIDs
 
@@ -161,38 +248,82 @@ func (s *achievementServiceImpl) GetAllAchievements(c *fiber.Ctx) error {
 		break
 	}
 
-	var achievements []*model.AchievementWithReference
-	var total int
-	var err error
+	return filters, false, nil
+}
 
-	// Use filtered query if any filters are provided
-	if len(filters) > 0 || sortBy != "created_at" || sortOrder != "DESC" {
-		achievements, total, err = s.achievementRepo.GetAchievementsWithFilters(page, pageSize, filters, sortBy, sortOrder)
-	} else {
-		achievements, total, err = s.achievementRepo.GetAllAchievements(page, pageSize)
+// SearchAchievements godoc
+// @Summary Cari prestasi (full-text)
+// @Description Mencari prestasi berdasarkan kata di title, description, tags, dan Details memakai text index MongoDB. Kata dicocokkan beserta kata dasarnya dalam bahasa Indonesia (misalnya "perlombaan" menemukan "lomba"), frasa dalam tanda kutip harus muncul utuh, dan kata berawalan "-" mengecualikan prestasi. Hasil diurutkan berdasarkan relevansi (title paling berbobot) dan setiap hasil berisi highlight HTML dengan kata yang cocok dibungkus <mark>. Filter dan cakupan role sama dengan GET /achievements.
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Kata pencarian, contoh: gemastik -internal"
+// @Param page query int false "Nomor halaman" default(1)
+// @Param page_size query int false "Jumlah data per halaman" default(10)
+// @Param status query string false "Filter berdasarkan status" Enums(draft, submitted, revision_requested, verified, rejected)
+// @Param achievement_type query string false "Filter berdasarkan kode tipe dari katalog /achievement-types"
+// @Param student_id query string false "Filter berdasarkan student ID"
+// @Param start_date query string false "Filter tanggal mulai (YYYY-MM-DD)"
+// @Param end_date query string false "Filter tanggal akhir (YYYY-MM-DD)"
+// @Param expired query bool false "Filter prestasi yang masa berlakunya sudah lewat (true) atau masih berlaku/tanpa masa berlaku (false)"
+// @Param expiring_within_days query int false "Filter prestasi yang masih berlaku dan habis dalam N hari"
+// @Success 200 {object} model.APIResponse{data=object{achievements=[]model.AchievementSearchResult,query=string,pagination=object}} "Hasil pencarian"
+// @Failure 400 {object} model.APIResponse "q kosong, tipe prestasi tidak dikenal, atau filter tidak valid"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievements/search [get]
+func (s *achievementServiceImpl) SearchAchievements(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
 	}
 
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "gagal mengambil achievements: " + err.Error(),
+			Message: "parameter q wajib diisi",
+		})
+	}
+	search, terms := model.TextSearchQuery(query)
+	if len(terms) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "q harus berisi minimal satu kata yang dicari",
 		})
 	}
 
+	filters, noAdvisees, actionErr := s.achievementListFilters(c)
+	if actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	results := []*model.AchievementSearchResult{}
+	total := 0
+	if !noAdvisees {
+		var err error
+		results, total, err = s.achievementRepo.SearchAchievements(search, filters, page, pageSize)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mencari achievements: " + err.Error(),
+			})
+		}
+	}
+	for _, result := range results {
+		result.Highlights = model.HighlightAchievement(&result.Achievement, terms)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
 		Status:  "success",
-		Message: "achievements berhasil diambil",
+		Message: "pencarian achievements berhasil",
 		Data: map[string]interface{}{
-			"achievements": achievements,
-			"filters": map[string]interface{}{
-				"status":           status,
-				"achievement_type": achievementType,
-				"student_id":       studentID,
-				"start_date":       startDate,
-				"end_date":         endDate,
-				"sort_by":          sortBy,
-				"sort_order":       sortOrder,
-			},
+			"achievements": results,
+			"query":        query,
 			"pagination": map[string]interface{}{
 				"page":       page,
 				"page_size":  pageSize,
@@ -212,9 +343,11 @@ func (s *achievementServiceImpl) GetAllAchievements(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Achievement ID"
 // @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Detail prestasi berhasil diambil"
+// @Header 200 {string} ETag "Versi prestasi, kirim lewat If-Match saat mengubah prestasi"
 // @Failure 401 {object} model.APIResponse "Unauthorized"
 // @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id} [get]
 func (s *achievementServiceImpl) GetAchievementDetail(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
@@ -222,10 +355,7 @@ func (s *achievementServiceImpl) GetAchievementDetail(c *fiber.Ctx) error {
 
 	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "gagal mengambil achievement",
-		})
+		return loadActionError(err, fiber.StatusInternalServerError, "gagal mengambil achievement").respond(c)
 	}
 	if achievement == nil {
 		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
@@ -254,14 +384,92 @@ func (s *achievementServiceImpl) GetAchievementDetail(c *fiber.Ctx) error {
 
 	if role == "Dosen Wali" {
 		student, err := s.studentRepo.GetStudentByID(achievement.StudentID)
-		if err != nil || student == nil || student.AdvisorID != studentID {
+		if err != nil || student == nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
 				Status:  "error",
 				Message: "anda tidak memiliki akses ke prestasi ini",
 			})
 		}
+		// Penerima pelimpahan (dan reviewer departemen untuk prestasi yang dieskalasi) perlu melihat
+		// prestasi yang boleh mereka verifikasi
+		if student.AdvisorID != studentID {
+			if _, status, message := s.authorizeAdvisor(studentID, achievement); status != 0 {
+				return c.Status(status).JSON(model.APIResponse{
+					Status:  "error",
+					Message: message,
+				})
+			}
+		}
+	}
+
+	// Komentar revisi per field ditampilkan agar mahasiswa tahu apa yang harus diperbaiki
+	revisions, err := s.achievementRepo.GetRevisionRequests(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil permintaan revisi",
+		})
+	}
+	achievement.RevisionRequests = revisions
+
+	progress, err := s.getApprovalProgress(achievementID, achievement)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil progres persetujuan",
+		})
+	}
+	achievement.ApprovalProgress = progress
+
+	// Rekomendasi poin membantu dosen memberi poin yang konsisten saat verifikasi; mahasiswa tidak melihatnya
+	// agar tidak mengarahkan isi prestasi ke rubrik
+	achievement.PointsSuggestion = nil
+	if role != "Mahasiswa" && hasPermission(c, "achievement:verify") {
+		suggestion, err := s.suggestPoints(&achievement.Achievement)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil rubrik poin",
+			})
+		}
+		achievement.PointsSuggestion = suggestion
+	}
+
+	// Checklist bukti sama dengan yang dipakai saat submit, agar mahasiswa tahu apa yang masih kurang
+	checklist, err := s.evidenceChecklist(achievementID, &achievement.Achievement)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal memeriksa syarat bukti prestasi",
+		})
+	}
+	achievement.EvidenceChecklist = checklist
+
+	// Verifikator melihat semua kecocokan, mahasiswa hanya kecocokan dengan prestasinya sendiri
+	duplicates, err := s.findDuplicates(achievement)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal memeriksa duplikat prestasi",
+		})
+	}
+	if role == "Mahasiswa" {
+		duplicates = ownDuplicates(duplicates, achievement.StudentID)
+	}
+	achievement.Duplicates = duplicates
+
+	if achievement.TeamID != nil {
+		members, err := s.achievementRepo.GetTeamMembers(*achievement.TeamID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil anggota tim",
+			})
+		}
+		achievement.TeamMembers = members
 	}
 
+	setAchievementETag(c, achievement)
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
 		Status:  "success",
 		Message: "achievement berhasil diambil",
@@ -271,14 +479,16 @@ func (s *achievementServiceImpl) GetAchievementDetail(c *fiber.Ctx) error {
 
 // CreateAchievement godoc
 // @Summary Buat prestasi baru
-// @Description Membuat prestasi baru (hanya mahasiswa)
+// @Description Membuat prestasi baru (hanya mahasiswa). Jika mirip prestasi lain, data.duplicates berisi link ke prestasi tersebut sebagai peringatan.
+// @Description Isi team_members untuk prestasi tim: isi dan bukti disimpan sekali, tiap anggota mendapat reference sendiri yang diverifikasi dosen walinya.
+// @Description Tag dinormalisasi ke nama kanonik di katalog tag (alias dan variasi huruf besar/kecil digabung).
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param body body model.CreateAchievementRequest true "Data prestasi"
 // @Success 201 {object} model.APIResponse{data=model.Achievement} "Prestasi berhasil dibuat"
-// @Failure 400 {object} model.APIResponse "Format request tidak valid"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid atau anggota tim tidak valid"
 // @Failure 404 {object} model.APIResponse "Student tidak ditemukan"
 // @Failure 500 {object} model.APIResponse "Internal server error"
 // @Router /achievements [post]
@@ -308,17 +518,58 @@ func (s *achievementServiceImpl) CreateAchievement(c *fiber.Ctx) error {
 		})
 	}
 
+	if _, status, message := checkAchievementType(s.achievementRepo, req.AchievementType); status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	var teamMembers []*model.TeamMember
+	if len(req.TeamMembers) > 0 {
+		var actionErr *actionError
+		if teamMembers, actionErr = s.buildTeamMembers(student, req.TeamMembers); actionErr != nil {
+			return actionErr.respond(c)
+		}
+	}
+
+	// Tag disimpan dengan nama kanonik dari katalog agar "AI" dan "ai" tidak menjadi tag berbeda
+	tags, err := normalizeAchievementTags(s.achievementRepo, req.Tags)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil katalog tag",
+		})
+	}
+
 	// Create achievement (will be saved to MongoDB + PostgreSQL reference)
 	achievement := &model.Achievement{
 		AchievementType: req.AchievementType,
 		Title:           req.Title,
 		Description:     req.Description,
 		Details:         req.Details,
-		Tags:            req.Tags,
+		Tags:            tags,
 		Points:          0, // Points will be assigned by lecturer during verification
 	}
 
-	result, err := s.achievementRepo.CreateAchievement(achievement, student.ID)
+	if err := validateAchievementDetails(s.achievementRepo, achievement); err != nil {
+		return transitionErrorResponse(c, err, "gagal mengambil schema details")
+	}
+
+	validUntil, _, actionErr := resolveValidUntil(req.AchievementType, req.Details, req.ValidUntil)
+	if actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	var result *model.AchievementWithReference
+	if teamMembers != nil {
+		result, err = s.achievementRepo.CreateTeamAchievement(achievement, teamMembers)
+		if err == nil {
+			result.TeamMembers, err = s.achievementRepo.GetTeamMembers(*result.TeamID)
+		}
+	} else {
+		result, err = s.achievementRepo.CreateAchievement(achievement, student.ID)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
 			Status:  "error",
@@ -326,33 +577,56 @@ func (s *achievementServiceImpl) CreateAchievement(c *fiber.Ctx) error {
 		})
 	}
 
+	if validUntil != nil {
+		if err := s.achievementRepo.SetValidUntil(result.ReferenceID, validUntil); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal menyimpan masa berlaku prestasi",
+			})
+		}
+		result.ValidUntil = validUntil
+		result.Expired = result.IsExpired(time.Now())
+	}
+
+	// Duplikat hanya berupa peringatan; prestasi tetap dibuat
+	message := "achievement berhasil dibuat"
+	duplicates, err := s.findDuplicates(result)
+	if err != nil {
+		log.Println("warning: failed to check duplicate achievements:", err)
+	}
+	if result.Duplicates = ownDuplicates(duplicates, student.ID); len(result.Duplicates) > 0 {
+		message += ", tetapi kemungkinan duplikat dengan " + strconv.Itoa(len(result.Duplicates)) + " prestasi lain"
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
 		Status:  "success",
-		Message: "achievement berhasil dibuat",
+		Message: message,
 		Data:    result,
 	})
 }
 
 // UpdateAchievement godoc
 // @Summary Update prestasi
-// @Description Memperbarui data prestasi (hanya yang berstatus draft)
+// @Description Memperbarui data prestasi (hanya yang berstatus draft atau revision_requested). Prestasi tim hanya bisa diubah ketua selama belum ada anggota yang mensubmit. Tag dinormalisasi ke katalog tag.
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Achievement ID"
 // @Param body body model.UpdateAchievementRequest true "Data prestasi yang diupdate"
+// @Param If-Match header string false "ETag prestasi yang terakhir dibaca"
 // @Success 200 {object} model.APIResponse{data=model.Achievement} "Prestasi berhasil diupdate"
+// @Header 200 {string} ETag "Versi prestasi"
 // @Failure 400 {object} model.APIResponse "Format request tidak valid atau status bukan draft"
 // @Failure 401 {object} model.APIResponse "Prestasi bukan milik anda"
-// @Failure 403 {object} model.APIResponse "Dosen wali tidak dapat mengedit prestasi"
+// @Failure 403 {object} model.APIResponse "Dosen wali atau anggota tim selain ketua tidak dapat mengedit prestasi"
 // @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 412 {object} model.APIResponse "Prestasi sudah diubah sejak terakhir dibaca (If-Match tidak cocok)"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id} [put]
 func (s *achievementServiceImpl) UpdateAchievement(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
-	studentID := c.Locals("userID").(string)
-	role := c.Locals("role").(string)
 
 	var req model.UpdateAchievementRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -362,40 +636,62 @@ func (s *achievementServiceImpl) UpdateAchievement(c *fiber.Ctx) error {
 		})
 	}
 
+	achievement, actionErr := s.loadEditableAchievement(c, achievementID)
+	if actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	return s.saveAchievementUpdate(c, achievementID, achievement, &req)
+}
+
+// loadEditableAchievement mengambil prestasi dan memastikan pengguna boleh mengubahnya: pemilik,
+// status bisa diedit, aturan prestasi tim, dan If-Match. Dipakai oleh PUT dan PATCH.
+func (s *achievementServiceImpl) loadEditableAchievement(c *fiber.Ctx, achievementID string) (*model.AchievementWithReference, *actionError) {
+	studentID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
 	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
 	if err != nil || achievement == nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "prestasi tidak ditemukan",
-		})
+		return nil, loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan")
 	}
 
 	if role == "Mahasiswa" {
 		student, err := s.studentRepo.GetStudentByUserID(studentID)
 		if err != nil || student == nil || achievement.StudentID != student.ID {
-			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "prestasi bukan milik anda",
-			})
+			return nil, newActionError(fiber.StatusUnauthorized, "prestasi bukan milik anda")
 		}
 	}
 
 	if role == "Dosen Wali" {
-		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "dosen wali tidak dapat mengedit prestasi",
-		})
+		return nil, newActionError(fiber.StatusForbidden, "dosen wali tidak dapat mengedit prestasi")
 	}
 
-	if achievement.Status != model.AchievementStatusDraft {
-		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "hanya prestasi draft yang bisa diupdate",
-		})
+	if !achievementWorkflow.IsEditable(achievement.Status) {
+		return nil, newActionError(fiber.StatusBadRequest, "prestasi berstatus "+achievement.Status+" tidak bisa diupdate")
 	}
 
+	if actionErr := s.checkTeamEditable(achievement, role); actionErr != nil {
+		return nil, actionErr
+	}
+
+	if actionErr := checkIfMatch(c.Get(fiber.HeaderIfMatch), achievement); actionErr != nil {
+		return nil, actionErr
+	}
+
+	return achievement, nil
+}
+
+// saveAchievementUpdate menerapkan field request yang tidak nil ke prestasi, memvalidasi Details,
+// lalu menyimpannya dengan cek versi dan mengirim prestasi terbaru sebagai response
+func (s *achievementServiceImpl) saveAchievementUpdate(c *fiber.Ctx, achievementID string, achievement *model.AchievementWithReference, req *model.UpdateAchievementRequest) error {
 	// Update fields if provided
-	if req.AchievementType != nil {
+	if req.AchievementType != nil && *req.AchievementType != achievement.AchievementType {
+		if _, status, message := checkAchievementType(s.achievementRepo, *req.AchievementType); status != 0 {
+			return c.Status(status).JSON(model.APIResponse{
+				Status:  "error",
+				Message: message,
+			})
+		}
 		achievement.AchievementType = *req.AchievementType
 	}
 	if req.Title != nil {
@@ -408,19 +704,58 @@ func (s *achievementServiceImpl) UpdateAchievement(c *fiber.Ctx) error {
 		achievement.Details = *req.Details
 	}
 	if req.Tags != nil {
-		achievement.Tags = *req.Tags
+		tags, err := normalizeAchievementTags(s.achievementRepo, *req.Tags)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil katalog tag",
+			})
+		}
+		achievement.Tags = tags
 	}
 	// Points cannot be updated by students - only assigned during verification
 
-	if err := s.achievementRepo.UpdateAchievement(achievementID, &achievement.Achievement); err != nil {
+	if err := validateAchievementDetails(s.achievementRepo, &achievement.Achievement); err != nil {
+		return transitionErrorResponse(c, err, "gagal mengambil schema details")
+	}
+
+	// Masa berlaku hanya dihitung ulang jika dikirim atau details sertifikasi berubah
+	var validUntil *time.Time
+	validUntilChanged := false
+	if req.ValidUntil != nil || req.Details != nil || req.AchievementType != nil {
+		var actionErr *actionError
+		validUntil, validUntilChanged, actionErr = resolveValidUntil(achievement.AchievementType, achievement.Details, req.ValidUntil)
+		if actionErr != nil {
+			return actionErr.respond(c)
+		}
+	}
+
+	// Versi yang dibaca di awal request ikut dicek agar edit yang berjalan bersamaan tidak saling menimpa
+	if err := s.achievementRepo.UpdateAchievement(achievementID, &achievement.Achievement, achievement.Version); err != nil {
+		if errors.Is(err, repository.ErrAchievementVersionConflict) {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(model.APIResponse{
+				Status:  "error",
+				Message: err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
 			Status:  "error",
 			Message: "gagal update achievement",
 		})
 	}
 
+	if validUntilChanged {
+		if err := s.achievementRepo.SetValidUntil(achievementID, validUntil); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal menyimpan masa berlaku prestasi",
+			})
+		}
+	}
+
 	// Refresh data
 	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
+	setAchievementETag(c, achievement)
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
 		Status:  "success",
 		Message: "achievement berhasil diupdate",
@@ -430,17 +765,23 @@ func (s *achievementServiceImpl) UpdateAchievement(c *fiber.Ctx) error {
 
 // SubmitAchievement godoc
 // @Summary Submit prestasi untuk verifikasi
-// @Description Mengubah status prestasi dari draft menjadi submitted
+// @Description Mengubah status prestasi dari draft atau revision_requested menjadi submitted. Prestasi yang mirip prestasi lain ditandai possible_duplicate untuk verifikator.
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Achievement ID"
+// @Param If-Match header string false "ETag prestasi yang terakhir dibaca"
 // @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Prestasi berhasil disubmit"
-// @Failure 400 {object} model.APIResponse "Hanya prestasi draft yang bisa disubmit"
+// @Header 200 {string} ETag "Versi prestasi"
+// @Failure 400 {object} model.APIResponse "Status prestasi tidak bisa disubmit"
 // @Failure 401 {object} model.APIResponse "Prestasi bukan milik anda"
+// @Failure 403 {object} model.APIResponse "Dosen wali tidak dapat mensubmit prestasi"
 // @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
+// @Failure 412 {object} model.APIResponse "Prestasi sudah diubah sejak terakhir dibaca (If-Match tidak cocok)"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id}/submit [post]
 func (s *achievementServiceImpl) SubmitAchievement(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
@@ -449,10 +790,7 @@ func (s *achievementServiceImpl) SubmitAchievement(c *fiber.Ctx) error {
 
 	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
 	if err != nil || achievement == nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "prestasi tidak ditemukan",
-		})
+		return loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan").respond(c)
 	}
 
 	// Check ownership for students
@@ -474,64 +812,81 @@ func (s *achievementServiceImpl) SubmitAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	if achievement.Status != model.AchievementStatusDraft {
+	transition, ok := achievementWorkflow.FindTransition(model.AchievementActionSubmit, achievement.Status)
+	if !ok {
 		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "hanya prestasi draft yang bisa disubmit",
+			Message: "prestasi berstatus " + achievement.Status + " tidak bisa disubmit",
 		})
 	}
 
-	if err := s.achievementRepo.SubmitAchievementForVerification(achievementID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+	if !hasPermission(c, transition.Permission) {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "gagal submit achievement",
+			Message: "anda tidak memiliki permission: " + transition.Permission,
 		})
 	}
 
+	if actionErr := checkIfMatch(c.Get(fiber.HeaderIfMatch), achievement); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
 	note := "Achievement submitted for verification"
-	history := &model.AchievementHistory{
-		ID:            uuid.New().String(),
-		AchievementID: achievementID,
-		OldStatus:     model.AchievementStatusDraft,
-		NewStatus:     model.AchievementStatusSubmitted,
-		ChangedBy:     userID,
-		Note:          &note,
+	if achievement.Status == model.AchievementStatusRevisionRequested {
+		note = "Achievement resubmitted after revision"
+	} else if achievement.ResubmissionCount > 0 {
+		note = "Achievement resubmitted after rejection (attempt " + strconv.Itoa(achievement.ResubmissionCount) + ")"
+	}
+
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        userID,
+		note:          note,
 	}
-	if err := s.achievementRepo.CreateAchievementHistory(history); err != nil {
-		// Log the actual error for debugging
-		println("[v0] Error creating history:", err.Error())
+	if err := s.applyTransition(transition, tc); err != nil {
+		return transitionErrorResponse(c, err, "gagal submit achievement")
 	}
 
 	// Refresh data
 	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
+	setAchievementETag(c, achievement)
+	message := "achievement berhasil disubmit"
+	if achievement != nil {
+		if achievement.Duplicates = ownDuplicates(tc.duplicates, achievement.StudentID); len(achievement.Duplicates) > 0 {
+			message += ", tetapi kemungkinan duplikat dengan " + strconv.Itoa(len(achievement.Duplicates)) + " prestasi lain"
+		}
+	}
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
 		Status:  "success",
-		Message: "achievement berhasil disubmit",
+		Message: message,
 		Data:    achievement,
 	})
 }
 
 // VerifyAchievement godoc
 // @Summary Verifikasi prestasi
-// @Description Memverifikasi prestasi yang telah disubmit dan memberikan poin (dosen wali/admin)
+// @Description Memverifikasi prestasi yang telah disubmit dan memberikan poin (dosen wali/admin).
+// @Description Jika prestasi masuk approval chain, setiap panggilan menyetujui satu tahap dan poin baru diberikan di tahap terakhir.
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Achievement ID"
 // @Param body body model.VerifyAchievementRequest true "Data verifikasi dengan poin"
+// @Param If-Match header string false "ETag prestasi yang terakhir dibaca"
 // @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Prestasi berhasil diverifikasi"
-// @Failure 400 {object} model.APIResponse "Format request tidak valid atau hanya prestasi submitted yang bisa diverify"
+// @Header 200 {string} ETag "Versi prestasi"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid, prestasi belum submitted, poin menyimpang dari rubrik tanpa justification, atau poin tahap terakhir memerlukan approval chain yang lebih ketat"
 // @Failure 401 {object} model.APIResponse "Anda bukan advisor dari student ini"
-// @Failure 403 {object} model.APIResponse "Mahasiswa tidak dapat memverifikasi prestasi"
+// @Failure 403 {object} model.APIResponse "Mahasiswa tidak dapat memverifikasi prestasi, bukan approver tahap ini, atau sudah menyetujui tahap sebelumnya"
 // @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
+// @Failure 412 {object} model.APIResponse "Prestasi sudah diubah sejak terakhir dibaca (If-Match tidak cocok)"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id}/verify [post]
 func (s *achievementServiceImpl) VerifyAchievement(c *fiber.Ctx) error {
-	achievementID := c.Params("id")
-	verifiedBy := c.Locals("userID").(string)
-	role := c.Locals("role").(string)
-
 	var req model.VerifyAchievementRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
@@ -540,98 +895,165 @@ func (s *achievementServiceImpl) VerifyAchievement(c *fiber.Ctx) error {
 		})
 	}
 
+	achievement, message, actionErr := s.verifyOne(c, c.Params("id"), c.Get(fiber.HeaderIfMatch), req)
+	if actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	setAchievementETag(c, achievement)
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: message,
+		Data:    achievement,
+	})
+}
+
+// verifyOne menjalankan pemeriksaan dan transisi verifikasi untuk satu prestasi.
+// Dipakai oleh endpoint verifikasi satuan maupun batch.
+func (s *achievementServiceImpl) verifyOne(c *fiber.Ctx, achievementID, ifMatch string, req model.VerifyAchievementRequest) (*model.AchievementWithReference, string, *actionError) {
+	verifiedBy := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
 	if req.Points < 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "poin tidak boleh negatif",
-		})
+		return nil, "", newActionError(fiber.StatusBadRequest, "poin tidak boleh negatif")
 	}
 
 	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
 	if err != nil || achievement == nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "prestasi tidak ditemukan",
-		})
+		return nil, "", loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan")
 	}
 
-	if achievement.Status != model.AchievementStatusSubmitted {
-		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "hanya prestasi submitted yang bisa diverify",
-		})
+	transition, ok := achievementWorkflow.FindTransition(model.AchievementActionVerify, achievement.Status)
+	if !ok {
+		return nil, "", newActionError(fiber.StatusBadRequest, "prestasi berstatus "+achievement.Status+" tidak bisa diverify")
 	}
 
 	if role == "Mahasiswa" {
-		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "mahasiswa tidak dapat memverifikasi prestasi",
-		})
+		return nil, "", newActionError(fiber.StatusForbidden, "mahasiswa tidak dapat memverifikasi prestasi")
 	}
 
-	if role == "Dosen Wali" {
+	if !hasPermission(c, transition.Permission) {
+		return nil, "", newActionError(fiber.StatusForbidden, "anda tidak memiliki permission: "+transition.Permission)
+	}
+
+	if actionErr := checkIfMatch(ifMatch, achievement); actionErr != nil {
+		return nil, "", actionErr
+	}
+
+	// Prestasi bernilai tinggi bisa membutuhkan beberapa tahap persetujuan (approval chain)
+	plan, err := s.resolveApprovalPlan(achievementID, achievement, req.Points)
+	if err != nil {
+		return nil, "", newActionError(fiber.StatusInternalServerError, "gagal mengambil approval chain")
+	}
+
+	// Poin yang diberikan tahap terakhir diperiksa lagi terhadap ambang chain, karena bisa lebih tinggi dari
+	// poin yang menentukan chain di tahap pertama
+	if plan == nil || plan.isFinal() {
+		var current *model.ApprovalChain
+		if plan != nil {
+			current = plan.chain
+		}
+		if actionErr := s.checkChainThreshold(achievement.AchievementType, current, req.Points); actionErr != nil {
+			return nil, "", actionErr
+		}
+	}
+
+	// onBehalfOf terisi jika dosen memverifikasi lewat pelimpahan wewenang dari dosen wali asli.
+	// Anggota kelompok approver suatu tahap tidak harus dosen wali mahasiswa tersebut.
+	var onBehalfOf *model.Lecturer
+	if role == "Dosen Wali" && (plan == nil || !plan.stage.HasApprover(verifiedBy)) {
 		student, _ := s.studentRepo.GetStudentByID(achievement.StudentID)
 		if student == nil {
-			return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "student tidak ditemukan",
-			})
+			return nil, "", newActionError(fiber.StatusNotFound, "student tidak ditemukan")
 		}
 
 		// Get lecturer data for the logged-in user
 		lecturer, err := s.lecturerRepo.GetLecturerByUserID(verifiedBy)
 		if err != nil || lecturer == nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "gagal mengambil data lecturer",
-			})
+			return nil, "", newActionError(fiber.StatusInternalServerError, "gagal mengambil data lecturer")
 		}
 
-		if student.AdvisorID != lecturer.ID {
-			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "anda bukan advisor dari student ini (student advisor: " + student.AdvisorID + ", your lecturer ID: " + lecturer.ID + ")",
-			})
+		allowed, advisor, err := s.checkAdvisorAccess(lecturer, student)
+		if err != nil {
+			return nil, "", newActionError(fiber.StatusInternalServerError, "gagal memeriksa pelimpahan wewenang")
+		}
+		if !allowed && achievement.EscalatedAt != nil {
+			allowed, advisor, err = s.checkEscalationAccess(lecturer, student)
+			if err != nil {
+				return nil, "", newActionError(fiber.StatusInternalServerError, "gagal memeriksa reviewer departemen")
+			}
+		}
+		if !allowed {
+			return nil, "", newActionError(fiber.StatusUnauthorized, "anda bukan advisor dari student ini (student advisor: "+student.AdvisorID+", your lecturer ID: "+lecturer.ID+")")
 		}
+		onBehalfOf = advisor
 	}
 
-	// Update points in MongoDB before verifying
-	achievement.Points = req.Points
-	if err := s.achievementRepo.UpdateAchievement(achievementID, &achievement.Achievement); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "gagal update poin achievement",
-		})
-	}
+	note := "Achievement verified with " + strconv.Itoa(req.Points) + " points"
+	message := "achievement berhasil diverifikasi"
+	var approval *model.AchievementApproval
+	if plan != nil {
+		if role != "Admin" && !plan.stage.CanApprove(verifiedBy, role) {
+			return nil, "", newActionError(fiber.StatusForbidden, "tahap "+plan.stage.Name+" harus disetujui oleh "+plan.stage.ApproverRole)
+		}
+		approvers := []string{verifiedBy}
+		if onBehalfOf != nil {
+			approvers = append(approvers, onBehalfOf.UserID)
+		}
+		if plan.approvedEarlierStage(approvers...) {
+			return nil, "", newActionError(fiber.StatusForbidden, "anda sudah menyetujui tahap sebelumnya, tahap "+plan.stage.Name+" harus disetujui oleh approver lain")
+		}
 
-	if err := s.achievementRepo.VerifyAchievement(achievementID, verifiedBy); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "gagal verify achievement",
-		})
+		stageLabel := "stage " + strconv.Itoa(plan.stage.StageOrder) + "/" + strconv.Itoa(len(plan.chain.Stages)) + " (" + plan.stage.Name + ")"
+		if plan.isFinal() {
+			note += ", " + stageLabel
+		} else {
+			// Tahap perantara: poin hanya diusulkan, baru diberikan saat tahap terakhir disetujui
+			transition, ok = achievementWorkflow.FindTransition(model.AchievementActionApproveStage, achievement.Status)
+			if !ok {
+				return nil, "", newActionError(fiber.StatusBadRequest, "workflow tidak mendukung persetujuan bertahap")
+			}
+			if !hasPermission(c, transition.Permission) {
+				return nil, "", newActionError(fiber.StatusForbidden, "anda tidak memiliki permission: "+transition.Permission)
+			}
+			note = "Achievement approved at " + stageLabel + " with proposed " + strconv.Itoa(req.Points) + " points"
+			message = "tahap " + plan.stage.Name + " berhasil disetujui, menunggu tahap berikutnya"
+		}
+
+		approval = &model.AchievementApproval{
+			AchievementID:  achievementID,
+			ChainID:        plan.chain.ID,
+			StageOrder:     plan.stage.StageOrder,
+			StageName:      plan.stage.Name,
+			VerifiedBy:     verifiedBy,
+			ProposedPoints: req.Points,
+		}
+		if onBehalfOf != nil {
+			approval.OnBehalfOf = &onBehalfOf.UserID
+		}
+	}
+	if onBehalfOf != nil {
+		note += " on behalf of advisor " + onBehalfOf.LecturerID
 	}
 
-	note := "Achievement verified with " + strconv.Itoa(req.Points) + " points"
-	history := &model.AchievementHistory{
-		ID:            uuid.New().String(),
-		AchievementID: achievementID,
-		OldStatus:     model.AchievementStatusSubmitted,
-		NewStatus:     model.AchievementStatusVerified,
-		ChangedBy:     verifiedBy,
-		Note:          &note,
+	// Poin disimpan ke MongoDB oleh hook assign_points bersama perubahan status
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        verifiedBy,
+		note:          note,
+		points:        &req.Points,
+		justification: req.Justification,
+		onBehalfOf:    onBehalfOf,
+		approval:      approval,
 	}
-	if err := s.achievementRepo.CreateAchievementHistory(history); err != nil {
-		// Log the actual error for debugging
-		println("[v0] Error creating history:", err.Error())
+	if err := s.applyTransition(transition, tc); err != nil {
+		return nil, "", transitionActionError(err, "gagal verify achievement")
 	}
 
 	// Refresh data
 	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
-	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
-		Status:  "success",
-		Message: "achievement berhasil diverifikasi",
-		Data:    achievement,
-	})
+	return achievement, message, nil
 }
 
 // RejectAchievement godoc
@@ -643,18 +1065,19 @@ func (s *achievementServiceImpl) VerifyAchievement(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Achievement ID"
 // @Param body body object{rejection_note=string} true "Catatan penolakan"
+// @Param If-Match header string false "ETag prestasi yang terakhir dibaca"
 // @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Prestasi berhasil ditolak"
+// @Header 200 {string} ETag "Versi prestasi"
 // @Failure 400 {object} model.APIResponse "Format request tidak valid atau hanya prestasi submitted yang bisa direject"
 // @Failure 401 {object} model.APIResponse "Anda bukan advisor dari student ini"
 // @Failure 403 {object} model.APIResponse "Mahasiswa tidak dapat menolak prestasi"
 // @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
+// @Failure 412 {object} model.APIResponse "Prestasi sudah diubah sejak terakhir dibaca (If-Match tidak cocok)"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id}/reject [post]
 func (s *achievementServiceImpl) RejectAchievement(c *fiber.Ctx) error {
-	achievementID := c.Params("id")
-	rejectedBy := c.Locals("userID").(string)
-	role := c.Locals("role").(string)
-
 	type RejectRequest struct {
 		RejectionNote string `json:"rejection_note"`
 	}
@@ -667,88 +1090,292 @@ func (s *achievementServiceImpl) RejectAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate rejection note
-	if req.RejectionNote == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "rejection_note tidak boleh kosong",
-		})
+	achievement, actionErr := s.rejectOne(c, c.Params("id"), c.Get(fiber.HeaderIfMatch), req.RejectionNote)
+	if actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	setAchievementETag(c, achievement)
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "achievement berhasil ditolak",
+		Data:    achievement,
+	})
+}
+
+// rejectOne menjalankan pemeriksaan dan transisi penolakan untuk satu prestasi.
+// Dipakai oleh endpoint penolakan satuan maupun batch.
+func (s *achievementServiceImpl) rejectOne(c *fiber.Ctx, achievementID, ifMatch, rejectionNote string) (*model.AchievementWithReference, *actionError) {
+	rejectedBy := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	// Validate rejection note
+	if rejectionNote == "" {
+		return nil, newActionError(fiber.StatusBadRequest, "rejection_note tidak boleh kosong")
+	}
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil || achievement == nil {
+		return nil, loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan")
+	}
+
+	transition, ok := achievementWorkflow.FindTransition(model.AchievementActionReject, achievement.Status)
+	if !ok {
+		return nil, newActionError(fiber.StatusBadRequest, "prestasi berstatus "+achievement.Status+" tidak bisa direject")
+	}
+
+	if role == "Mahasiswa" {
+		return nil, newActionError(fiber.StatusForbidden, "mahasiswa tidak dapat menolak prestasi")
+	}
+
+	if !hasPermission(c, transition.Permission) {
+		return nil, newActionError(fiber.StatusForbidden, "anda tidak memiliki permission: "+transition.Permission)
+	}
+
+	if actionErr := checkIfMatch(ifMatch, achievement); actionErr != nil {
+		return nil, actionErr
+	}
+
+	var onBehalfOf *model.Lecturer
+	if role == "Dosen Wali" {
+		student, _ := s.studentRepo.GetStudentByID(achievement.StudentID)
+		if student == nil {
+			return nil, newActionError(fiber.StatusNotFound, "student tidak ditemukan")
+		}
+
+		// Get lecturer data for the logged-in user
+		lecturer, err := s.lecturerRepo.GetLecturerByUserID(rejectedBy)
+		if err != nil || lecturer == nil {
+			return nil, newActionError(fiber.StatusInternalServerError, "gagal mengambil data lecturer")
+		}
+
+		allowed, advisor, err := s.checkAdvisorAccess(lecturer, student)
+		if err != nil {
+			return nil, newActionError(fiber.StatusInternalServerError, "gagal memeriksa pelimpahan wewenang")
+		}
+		if !allowed && achievement.EscalatedAt != nil {
+			allowed, advisor, err = s.checkEscalationAccess(lecturer, student)
+			if err != nil {
+				return nil, newActionError(fiber.StatusInternalServerError, "gagal memeriksa reviewer departemen")
+			}
+		}
+		if !allowed {
+			return nil, newActionError(fiber.StatusUnauthorized, "anda tidak memiliki akses ke prestasi ini")
+		}
+		onBehalfOf = advisor
+	}
+
+	note := "Achievement rejected: " + rejectionNote
+	if onBehalfOf != nil {
+		note += " (on behalf of advisor " + onBehalfOf.LecturerID + ")"
+	}
+
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        rejectedBy,
+		note:          note,
+		rejectionNote: rejectionNote,
+		onBehalfOf:    onBehalfOf,
+	}
+	if err := s.applyTransition(transition, tc); err != nil {
+		return nil, transitionActionError(err, "gagal reject achievement")
+	}
+
+	// Refresh data
+	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
+	return achievement, nil
+}
+
+// RequestRevision godoc
+// @Summary Minta revisi prestasi
+// @Description Mengembalikan prestasi yang telah disubmit ke mahasiswa dengan komentar per field (dosen wali/admin)
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param body body model.RequestRevisionRequest true "Komentar revisi per field"
+// @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Permintaan revisi berhasil dikirim"
+// @Failure 400 {object} model.APIResponse "Komentar tidak valid atau hanya prestasi submitted yang bisa direvisi"
+// @Failure 401 {object} model.APIResponse "Anda bukan advisor dari student ini"
+// @Failure 403 {object} model.APIResponse "Mahasiswa tidak dapat meminta revisi"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/request-revision [post]
+func (s *achievementServiceImpl) RequestRevision(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	var req model.RequestRevisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format request tidak valid. Pastikan mengirim JSON dengan field 'comments'",
+		})
+	}
+
+	if err := validateRevisionComments(req.Comments); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil || achievement == nil {
+		return loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan").respond(c)
+	}
+
+	transition, ok := achievementWorkflow.FindTransition(model.AchievementActionRequestRevision, achievement.Status)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "prestasi berstatus " + achievement.Status + " tidak bisa dimintakan revisi",
+		})
+	}
+
+	if role == "Mahasiswa" {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "mahasiswa tidak dapat meminta revisi prestasi",
+		})
+	}
+
+	if !hasPermission(c, transition.Permission) {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "anda tidak memiliki permission: " + transition.Permission,
+		})
+	}
+
+	var onBehalfOf *model.Lecturer
+	if role == "Dosen Wali" {
+		advisor, status, message := s.authorizeAdvisor(userID, achievement)
+		if status != 0 {
+			return c.Status(status).JSON(model.APIResponse{
+				Status:  "error",
+				Message: message,
+			})
+		}
+		onBehalfOf = advisor
+	}
+
+	fields := make([]string, len(req.Comments))
+	for i, comment := range req.Comments {
+		fields[i] = comment.Field
+	}
+	note := "Revision requested for: " + strings.Join(fields, ", ")
+	if onBehalfOf != nil {
+		note += " (on behalf of advisor " + onBehalfOf.LecturerID + ")"
+	}
+
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        userID,
+		note:          note,
+		comments:      req.Comments,
+		onBehalfOf:    onBehalfOf,
+	}
+	if err := s.applyTransition(transition, tc); err != nil {
+		return transitionErrorResponse(c, err, "gagal meminta revisi achievement")
+	}
+
+	// Refresh data
+	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
+	if achievement != nil {
+		achievement.RevisionRequests, _ = s.achievementRepo.GetRevisionRequests(achievementID)
+	}
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "permintaan revisi berhasil dikirim",
+		Data:    achievement,
+	})
+}
+
+// ReopenAchievement godoc
+// @Summary Buka kembali prestasi yang ditolak
+// @Description Mengembalikan prestasi berstatus rejected menjadi draft agar bisa diperbaiki dan disubmit ulang. Jumlah pembukaan ulang dibatasi konfigurasi.
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Prestasi berhasil dibuka kembali"
+// @Failure 400 {object} model.APIResponse "Hanya prestasi rejected yang bisa dibuka kembali atau batas pengajuan ulang tercapai"
+// @Failure 401 {object} model.APIResponse "Prestasi bukan milik anda"
+// @Failure 403 {object} model.APIResponse "Dosen wali tidak dapat membuka kembali prestasi"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/reopen [post]
+func (s *achievementServiceImpl) ReopenAchievement(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil || achievement == nil {
+		return loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan").respond(c)
+	}
+
+	if role == "Mahasiswa" {
+		student, err := s.studentRepo.GetStudentByUserID(userID)
+		if err != nil || student == nil || achievement.StudentID != student.ID {
+			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "prestasi bukan milik anda",
+			})
+		}
 	}
 
-	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
-	if err != nil || achievement == nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+	if role == "Dosen Wali" {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "prestasi tidak ditemukan",
+			Message: "dosen wali tidak dapat membuka kembali prestasi",
 		})
 	}
 
-	if achievement.Status != model.AchievementStatusSubmitted {
+	transition, ok := achievementWorkflow.FindTransition(model.AchievementActionReopen, achievement.Status)
+	if !ok {
 		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "hanya prestasi submitted yang bisa direject",
+			Message: "prestasi berstatus " + achievement.Status + " tidak bisa dibuka kembali",
 		})
 	}
 
-	if role == "Mahasiswa" {
+	if !hasPermission(c, transition.Permission) {
 		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "mahasiswa tidak dapat menolak prestasi",
+			Message: "anda tidak memiliki permission: " + transition.Permission,
 		})
 	}
 
-	if role == "Dosen Wali" {
-		student, _ := s.studentRepo.GetStudentByID(achievement.StudentID)
-		if student == nil {
-			return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "student tidak ditemukan",
-			})
-		}
-
-		// Get lecturer data for the logged-in user
-		lecturer, err := s.lecturerRepo.GetLecturerByUserID(rejectedBy)
-		if err != nil || lecturer == nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "gagal mengambil data lecturer",
-			})
-		}
-
-		if student.AdvisorID != lecturer.ID {
-			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "anda tidak memiliki akses ke prestasi ini",
-			})
-		}
-	}
-
-	if err := s.achievementRepo.RejectAchievement(achievementID, rejectedBy, req.RejectionNote); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "gagal reject achievement",
-		})
+	note := "Achievement reopened after rejection"
+	if achievement.RejectionNote != nil {
+		note += ". Previous rejection note: " + *achievement.RejectionNote
 	}
 
-	note := "Achievement rejected: " + req.RejectionNote
-	history := &model.AchievementHistory{
-		ID:            uuid.New().String(),
-		AchievementID: achievementID,
-		OldStatus:     model.AchievementStatusSubmitted,
-		NewStatus:     model.AchievementStatusRejected,
-		ChangedBy:     rejectedBy,
-		Note:          &note,
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        userID,
+		note:          note,
 	}
-	if err := s.achievementRepo.CreateAchievementHistory(history); err != nil {
-		// Log the actual error for debugging
-		println("[v0] Error creating history:", err.Error())
+	if err := s.applyTransition(transition, tc); err != nil {
+		return transitionErrorResponse(c, err, "gagal membuka kembali achievement")
 	}
 
 	// Refresh data
 	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
 		Status:  "success",
-		Message: "achievement berhasil ditolak",
+		Message: "achievement berhasil dibuka kembali",
 		Data:    achievement,
 	})
 }
@@ -766,7 +1393,9 @@ func (s *achievementServiceImpl) RejectAchievement(c *fiber.Ctx) error {
 // @Failure 401 {object} model.APIResponse "Prestasi bukan milik anda"
 // @Failure 403 {object} model.APIResponse "Dosen wali tidak dapat menghapus prestasi"
 // @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id} [delete]
 func (s *achievementServiceImpl) DeleteAchievement(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
@@ -775,10 +1404,7 @@ func (s *achievementServiceImpl) DeleteAchievement(c *fiber.Ctx) error {
 
 	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "gagal mengambil achievement",
-		})
+		return loadActionError(err, fiber.StatusInternalServerError, "gagal mengambil achievement").respond(c)
 	}
 	if achievement == nil {
 		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
@@ -806,30 +1432,30 @@ func (s *achievementServiceImpl) DeleteAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	if achievement.Status != model.AchievementStatusDraft {
+	transition, ok := achievementWorkflow.FindTransition(model.AchievementActionDelete, achievement.Status)
+	if !ok {
 		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "hanya prestasi draft yang bisa dihapus",
+			Message: "prestasi berstatus " + achievement.Status + " tidak bisa dihapus",
 		})
 	}
 
-	if err := s.achievementRepo.DeleteAchievement(achievementID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+	if !hasPermission(c, transition.Permission) {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "gagal menghapus achievement",
+			Message: "anda tidak memiliki permission: " + transition.Permission,
 		})
 	}
 
-	// Create history
-	note := "Achievement soft deleted"
-	history := &model.AchievementHistory{
-		AchievementID: achievementID,
-		OldStatus:     model.AchievementStatusDraft,
-		NewStatus:     model.AchievementStatusDeleted,
-		ChangedBy:     userID,
-		Note:          &note,
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        userID,
+		note:          "Achievement soft deleted",
+	}
+	if err := s.applyTransition(transition, tc); err != nil {
+		return transitionErrorResponse(c, err, "gagal menghapus achievement")
 	}
-	s.achievementRepo.CreateAchievementHistory(history)
 
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
 		Status:  "success",
@@ -837,6 +1463,127 @@ func (s *achievementServiceImpl) DeleteAchievement(c *fiber.Ctx) error {
 	})
 }
 
+// TransitionAchievement godoc
+// @Summary Jalankan aksi workflow prestasi
+// @Description Menjalankan aksi apa pun yang terdaftar di workflow status prestasi (misal aksi tambahan dari file konfigurasi workflow)
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param action path string true "Nama aksi workflow"
+// @Param body body model.TransitionAchievementRequest false "Data tambahan transisi"
+// @Param If-Match header string false "ETag prestasi yang terakhir dibaca"
+// @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Transisi berhasil dijalankan"
+// @Header 200 {string} ETag "Versi prestasi"
+// @Failure 400 {object} model.APIResponse "Aksi tidak bisa dijalankan dari status saat ini"
+// @Failure 401 {object} model.APIResponse "Anda tidak memiliki akses ke prestasi ini"
+// @Failure 403 {object} model.APIResponse "Tidak memiliki permission untuk aksi ini"
+// @Failure 404 {object} model.APIResponse "Prestasi atau aksi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
+// @Failure 412 {object} model.APIResponse "Prestasi sudah diubah sejak terakhir dibaca (If-Match tidak cocok)"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/transitions/{action} [post]
+func (s *achievementServiceImpl) TransitionAchievement(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	action := c.Params("action")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	var req model.TransitionAchievementRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "format request tidak valid: " + err.Error(),
+			})
+		}
+	}
+
+	if !achievementWorkflow.HasAction(action) {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "aksi " + action + " tidak terdaftar di workflow",
+		})
+	}
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil || achievement == nil {
+		return loadActionError(err, fiber.StatusNotFound, "prestasi tidak ditemukan").respond(c)
+	}
+
+	transition, ok := achievementWorkflow.FindTransition(action, achievement.Status)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "aksi " + action + " tidak bisa dijalankan untuk prestasi berstatus " + achievement.Status,
+		})
+	}
+
+	if !hasPermission(c, transition.Permission) {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "anda tidak memiliki permission: " + transition.Permission,
+		})
+	}
+
+	var onBehalfOf *model.Lecturer
+	switch role {
+	case "Mahasiswa":
+		student, err := s.studentRepo.GetStudentByUserID(userID)
+		if err != nil || student == nil || achievement.StudentID != student.ID {
+			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "prestasi bukan milik anda",
+			})
+		}
+
+	case "Dosen Wali":
+		advisor, status, message := s.authorizeAdvisor(userID, achievement)
+		if status != 0 {
+			return c.Status(status).JSON(model.APIResponse{
+				Status:  "error",
+				Message: message,
+			})
+		}
+		onBehalfOf = advisor
+	}
+
+	if actionErr := checkIfMatch(c.Get(fiber.HeaderIfMatch), achievement); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	note := "Achievement " + action + ": " + achievement.Status + " -> " + transition.To
+	if req.Note != "" {
+		note += " (" + req.Note + ")"
+	}
+
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        userID,
+		note:          note,
+		points:        req.Points,
+		justification: req.Justification,
+		rejectionNote: req.RejectionNote,
+		comments:      req.Comments,
+		onBehalfOf:    onBehalfOf,
+	}
+	if err := s.applyTransition(transition, tc); err != nil {
+		return transitionErrorResponse(c, err, "gagal menjalankan aksi "+action)
+	}
+
+	// Refresh data
+	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
+	setAchievementETag(c, achievement)
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "aksi " + action + " berhasil dijalankan",
+		Data:    achievement,
+	})
+}
+
 // GetAdviseeAchievements godoc
 // @Summary Dapatkan prestasi anak bimbingan
 // @Description Mengambil daftar prestasi mahasiswa yang dibimbing (khusus dosen wali)
@@ -872,13 +1619,38 @@ func (s *achievementServiceImpl) GetAdviseeAchievements(c *fiber.Ctx) error {
 		})
 	}
 
-	students, err := s.studentRepo.GetStudentsByAdvisorID(advisorID)
+	// Dosen wali melihat anak bimbingannya sendiri ditambah anak bimbingan
+	// dosen lain yang sedang melimpahkan wewenang verifikasi kepadanya
+	advisorIDs := []string{advisorID}
+	lecturer, err := s.lecturerRepo.GetLecturerByUserID(advisorID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
 			Status:  "error",
-			Message: "gagal mengambil students",
+			Message: "gagal mengambil data lecturer",
 		})
 	}
+	if lecturer != nil {
+		delegatorIDs, err := s.lecturerRepo.GetActiveDelegatorIDs(lecturer.ID, time.Now())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil pelimpahan wewenang",
+			})
+		}
+		advisorIDs = append([]string{lecturer.ID}, delegatorIDs...)
+	}
+
+	var students []*model.StudentWithUser
+	for _, id := range advisorIDs {
+		advisees, err := s.studentRepo.GetStudentsByAdvisorID(id)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil students",
+			})
+		}
+		students = append(students, advisees...)
+	}
 
 	if len(students) == 0 {
 		return c.Status(fiber.StatusOK).JSON(model.APIResponse{
@@ -962,6 +1734,7 @@ func (s *achievementServiceImpl) GetAdviseeAchievements(c *fiber.Ctx) error {
 // @Failure 401 {object} model.APIResponse "Unauthorized"
 // @Failure 404 {object} model.APIResponse "Achievement tidak ditemukan"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id}/history [get]
 func (s *achievementServiceImpl) GetAchievementHistory(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
@@ -976,10 +1749,7 @@ func (s *achievementServiceImpl) GetAchievementHistory(c *fiber.Ctx) error {
 
 	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "gagal mengambil achievement",
-		})
+		return loadActionError(err, fiber.StatusInternalServerError, "gagal mengambil achievement").respond(c)
 	}
 	if achievement == nil {
 		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
@@ -1000,26 +1770,14 @@ func (s *achievementServiceImpl) GetAchievementHistory(c *fiber.Ctx) error {
 	}
 
 	if role == "Dosen Wali" {
-		student, err := s.studentRepo.GetStudentByID(achievement.StudentID)
-		if err != nil || student == nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "student tidak ditemukan",
-			})
-		}
-
-		lecturer, err := s.lecturerRepo.GetLecturerByUserID(userID)
-		if err != nil || lecturer == nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-				Status:  "error",
-				Message: "gagal mengambil data lecturer",
-			})
-		}
-
-		if student.AdvisorID != lecturer.ID {
-			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
+		// Sama dengan verifikasi: dosen wali langsung, penerima pelimpahan, atau reviewer eskalasi
+		if _, status, message := s.authorizeAdvisor(userID, achievement); status != 0 {
+			if status == fiber.StatusUnauthorized {
+				message = "anda tidak memiliki akses ke history achievement ini"
+			}
+			return c.Status(status).JSON(model.APIResponse{
 				Status:  "error",
-				Message: "anda tidak memiliki akses ke history achievement ini",
+				Message: message,
 			})
 		}
 	}
@@ -1049,10 +1807,11 @@ func (s *achievementServiceImpl) GetAchievementHistory(c *fiber.Ctx) error {
 // @Param id path string true "Achievement ID"
 // @Param file formData file true "File lampiran"
 // @Success 201 {object} model.APIResponse{data=model.AchievementAttachment} "Lampiran berhasil diupload"
-// @Failure 400 {object} model.APIResponse "File harus diupload"
+// @Failure 400 {object} model.APIResponse "File harus diupload atau tipe file tidak diizinkan"
 // @Failure 401 {object} model.APIResponse "Unauthorized"
 // @Failure 404 {object} model.APIResponse "Achievement tidak ditemukan"
 // @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
 // @Router /achievements/{id}/attachments [post]
 func (s *achievementServiceImpl) UploadAttachment(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
@@ -1067,10 +1826,7 @@ func (s *achievementServiceImpl) UploadAttachment(c *fiber.Ctx) error {
 
 	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
-			Status:  "error",
-			Message: "gagal mengambil achievement",
-		})
+		return loadActionError(err, fiber.StatusInternalServerError, "gagal mengambil achievement").respond(c)
 	}
 	if achievement == nil {
 		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
@@ -1105,6 +1861,23 @@ func (s *achievementServiceImpl) UploadAttachment(c *fiber.Ctx) error {
 		})
 	}
 
+	// Tipe prestasi yang tidak ada di katalog (data lama) menerima semua tipe file
+	definition, err := s.achievementRepo.GetAchievementType(achievement.AchievementType)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil tipe prestasi",
+		})
+	}
+	if definition != nil {
+		if rules := definition.EffectiveEvidenceRules(); !rules.IsFileTypeAllowed(file.Filename) {
+			return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "tipe file tidak diizinkan untuk tipe " + definition.Code + ", gunakan: " + strings.Join(rules.AllowedFileTypes, ", "),
+			})
+		}
+	}
+
 	// Save file to storage (simplified - in production use cloud storage)
 	fileName := uuid.New().String() + "_" + file.Filename
 	filePath := "./uploads/" + fileName
@@ -1124,6 +1897,15 @@ func (s *achievementServiceImpl) UploadAttachment(c *fiber.Ctx) error {
 		})
 	}
 
+	// Hash isi file dipakai untuk mendeteksi sertifikat yang sama diupload dua kali
+	contentHash, err := fileContentHash(filePath)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal membaca file",
+		})
+	}
+
 	attachment := &model.AchievementAttachment{
 		ID:            uuid.New().String(),
 		AchievementID: achievementID,
@@ -1131,6 +1913,7 @@ func (s *achievementServiceImpl) UploadAttachment(c *fiber.Ctx) error {
 		FilePath:      "/uploads/" + fileName,
 		FileSize:      file.Size,
 		FileType:      file.Header.Get("Content-Type"),
+		ContentHash:   contentHash,
 		UploadedBy:    userID,
 		UploadedAt:    time.Now(),
 	}
@@ -1148,3 +1931,230 @@ func (s *achievementServiceImpl) UploadAttachment(c *fiber.Ctx) error {
 		Data:    attachment,
 	})
 }
+
+// checkAdvisorAccess mengecek apakah dosen berhak memproses prestasi mahasiswa, baik sebagai
+// dosen wali langsung maupun lewat pelimpahan wewenang yang sedang aktif. Jika akses berasal
+// dari pelimpahan, dosen wali asli dikembalikan sebagai pihak yang diwakili.
+func (s *achievementServiceImpl) checkAdvisorAccess(lecturer *model.Lecturer, student *model.Student) (bool, *model.Lecturer, error) {
+	if student.AdvisorID == lecturer.ID {
+		return true, nil, nil
+	}
+
+	delegatorIDs, err := s.lecturerRepo.GetActiveDelegatorIDs(lecturer.ID, time.Now())
+	if err != nil {
+		return false, nil, err
+	}
+
+	for _, delegatorID := range delegatorIDs {
+		if delegatorID == student.AdvisorID {
+			advisor, err := s.lecturerRepo.GetLecturerByID(delegatorID)
+			if err != nil {
+				return false, nil, err
+			}
+			return advisor != nil, advisor, nil
+		}
+	}
+
+	return false, nil, nil
+}
+
+// checkEscalationAccess mengecek apakah dosen adalah reviewer departemen dosen wali mahasiswa, yang berhak
+// memproses prestasi yang sudah dieskalasi karena melewati SLA. Dosen wali dikembalikan sebagai pihak yang diwakili.
+func (s *achievementServiceImpl) checkEscalationAccess(lecturer *model.Lecturer, student *model.Student) (bool, *model.Lecturer, error) {
+	advisor, err := s.lecturerRepo.GetLecturerByID(student.AdvisorID)
+	if err != nil || advisor == nil {
+		return false, nil, err
+	}
+
+	reviewers, err := s.lecturerRepo.GetDepartmentReviewers(advisor.Department)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, reviewer := range reviewers {
+		if reviewer.ID == lecturer.ID {
+			return true, advisor, nil
+		}
+	}
+	return false, nil, nil
+}
+
+// authorizeAdvisor memastikan dosen yang login berhak memproses prestasi mahasiswa, langsung atau
+// lewat pelimpahan. Jika ditolak, HTTP status dan pesan error dikembalikan (status 0 berarti diizinkan).
+func (s *achievementServiceImpl) authorizeAdvisor(userID string, achievement *model.AchievementWithReference) (*model.Lecturer, int, string) {
+	student, _ := s.studentRepo.GetStudentByID(achievement.StudentID)
+	if student == nil {
+		return nil, fiber.StatusNotFound, "student tidak ditemukan"
+	}
+
+	lecturer, err := s.lecturerRepo.GetLecturerByUserID(userID)
+	if err != nil || lecturer == nil {
+		return nil, fiber.StatusInternalServerError, "gagal mengambil data lecturer"
+	}
+
+	allowed, advisor, err := s.checkAdvisorAccess(lecturer, student)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal memeriksa pelimpahan wewenang"
+	}
+	if !allowed && achievement.EscalatedAt != nil {
+		allowed, advisor, err = s.checkEscalationAccess(lecturer, student)
+		if err != nil {
+			return nil, fiber.StatusInternalServerError, "gagal memeriksa reviewer departemen"
+		}
+	}
+	if !allowed {
+		return nil, fiber.StatusUnauthorized, "anda tidak memiliki akses ke prestasi ini"
+	}
+
+	return advisor, 0, ""
+}
+
+// approvalPlan adalah tahap approval chain yang sedang menunggu persetujuan
+type approvalPlan struct {
+	chain     *model.ApprovalChain
+	stage     *model.ApprovalStage
+	approvals []*model.AchievementApproval // Persetujuan tahap-tahap sebelumnya
+}
+
+func (p *approvalPlan) isFinal() bool {
+	return p.stage.StageOrder == len(p.chain.Stages)
+}
+
+// approvedEarlierStage mengecek apakah salah satu userIDs sudah menyetujui tahap sebelumnya, baik langsung
+// maupun sebagai dosen wali yang dilimpahkan, sehingga setiap tahap disetujui oleh orang yang berbeda
+func (p *approvalPlan) approvedEarlierStage(userIDs ...string) bool {
+	for _, approval := range p.approvals {
+		for _, userID := range userIDs {
+			if approval.VerifiedBy == userID || (approval.OnBehalfOf != nil && *approval.OnBehalfOf == userID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveApprovalPlan menentukan tahap berikutnya untuk prestasi. Chain dipilih saat tahap pertama
+// berdasarkan tipe dan saran poin rubrik, lalu dipakai terus sampai tahap terakhir. Poin yang diusulkan
+// hanya bisa menaikkan chain, sehingga approver tidak bisa menghindari komite dengan mengusulkan poin
+// tepat di bawah ambang chain. Mengembalikan nil jika prestasi cukup diverifikasi satu tahap.
+func (s *achievementServiceImpl) resolveApprovalPlan(achievementID string, achievement *model.AchievementWithReference, points int) (*approvalPlan, error) {
+	approvals, err := s.achievementRepo.GetAchievementApprovals(achievementID)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain *model.ApprovalChain
+	if len(approvals) > 0 {
+		chain, err = s.achievementRepo.GetApprovalChainByID(approvals[0].ChainID)
+	} else {
+		var suggestion *model.PointsSuggestion
+		suggestion, err = s.suggestPoints(&achievement.Achievement)
+		if err != nil {
+			return nil, err
+		}
+		if suggestion != nil && suggestion.Points > points {
+			points = suggestion.Points
+		}
+		chain, err = s.selectApprovalChain(achievement.AchievementType, points)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chain == nil || len(approvals) >= len(chain.Stages) {
+		return nil, nil
+	}
+
+	return &approvalPlan{chain: chain, stage: &chain.Stages[len(approvals)], approvals: approvals}, nil
+}
+
+// selectApprovalChain memilih chain aktif paling spesifik: chain per tipe didahulukan,
+// lalu chain dengan ambang poin tertinggi
+func (s *achievementServiceImpl) selectApprovalChain(achievementType string, points int) (*model.ApprovalChain, error) {
+	chains, err := s.achievementRepo.GetApprovalChains(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected *model.ApprovalChain
+	for _, chain := range chains {
+		if !chain.Matches(achievementType, points) || len(chain.Stages) == 0 {
+			continue
+		}
+		if selected == nil ||
+			(chain.AchievementType != nil && selected.AchievementType == nil) ||
+			((chain.AchievementType != nil) == (selected.AchievementType != nil) && chain.MinPoints > selected.MinPoints) {
+			selected = chain
+		}
+	}
+
+	return selected, nil
+}
+
+// checkChainThreshold menolak pemberian poin yang mewajibkan approval chain lebih ketat dari chain yang sedang
+// dijalani prestasi (current nil jika prestasi tidak melewati chain), agar poin tinggi hanya bisa diberikan
+// setelah semua tahap chain tersebut menyetujuinya
+func (s *achievementServiceImpl) checkChainThreshold(achievementType string, current *model.ApprovalChain, points int) *actionError {
+	required, err := s.selectApprovalChain(achievementType, points)
+	if err != nil {
+		return newActionError(fiber.StatusInternalServerError, "gagal mengambil approval chain")
+	}
+	if required == nil || (current != nil && (required.ID == current.ID || required.MinPoints <= current.MinPoints)) {
+		return nil
+	}
+	return newActionError(fiber.StatusBadRequest, "poin "+strconv.Itoa(points)+" memerlukan persetujuan approval chain "+
+		required.Name+" (minimal "+strconv.Itoa(required.MinPoints)+" poin)")
+}
+
+// getApprovalProgress menyusun progres approval chain untuk ditampilkan di detail prestasi
+func (s *achievementServiceImpl) getApprovalProgress(achievementID string, achievement *model.AchievementWithReference) (*model.ApprovalProgress, error) {
+	approvals, err := s.achievementRepo.GetAchievementApprovals(achievementID)
+	if err != nil {
+		return nil, err
+	}
+	if len(approvals) == 0 {
+		// Chain baru pasti diketahui setelah tahap pertama mengusulkan poin
+		return nil, nil
+	}
+
+	chain, err := s.achievementRepo.GetApprovalChainByID(approvals[0].ChainID)
+	if err != nil || chain == nil {
+		return nil, err
+	}
+
+	currentStage := len(approvals) + 1
+	if currentStage > len(chain.Stages) || achievement.Status != model.AchievementStatusSubmitted {
+		currentStage = 0
+	}
+
+	return &model.ApprovalProgress{
+		Chain:        chain,
+		Approvals:    approvals,
+		CurrentStage: currentStage,
+	}, nil
+}
+
+// suggestPoints mencari aturan rubrik paling spesifik (kondisi terbanyak) yang cocok dengan prestasi.
+// Mengembalikan nil jika tidak ada aturan yang berlaku.
+func (s *achievementServiceImpl) suggestPoints(achievement *model.Achievement) (*model.PointsSuggestion, error) {
+	rules, err := s.achievementRepo.GetPointsRubricRules(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected *model.PointsRubricRule
+	for _, rule := range rules {
+		if rule.Matches(achievement) && (selected == nil || len(rule.Conditions) > len(selected.Conditions)) {
+			selected = rule
+		}
+	}
+	if selected == nil {
+		return nil, nil
+	}
+
+	return &model.PointsSuggestion{
+		RuleID:      selected.ID,
+		Points:      selected.Points,
+		Tolerance:   selected.Points * pointsTolerancePercent / 100,
+		Description: selected.Description,
+		Matched:     selected.Conditions,
+	}, nil
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 	"uas_be/app/model"
 	"uas_be/app/repository"
 
@@ -391,6 +395,7 @@ func TestSubmitAchievement_Success(t *testing.T) {
 	app.Post("/achievements/:id/submit", func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
 		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
 		return service.SubmitAchievement(c)
 	})
 	// Act
@@ -426,6 +431,7 @@ func TestSubmitAchievement_NotDraft(t *testing.T) {
 	app.Post("/achievements/:id/submit", func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
 		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
 		return service.SubmitAchievement(c)
 	})
 	// Act
@@ -470,6 +476,7 @@ func TestVerifyAchievement_Success(t *testing.T) {
 	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
 		c.Locals("userID", lecturerUserID)
 		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
 		return service.VerifyAchievement(c)
 	})
 	reqBody := model.VerifyAchievementRequest{
@@ -510,6 +517,7 @@ func TestVerifyAchievement_StudentCannotVerify(t *testing.T) {
 	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
 		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
 		return service.VerifyAchievement(c)
 	})
 	reqBody := model.VerifyAchievementRequest{
@@ -536,6 +544,7 @@ func TestVerifyAchievement_NegativePoints(t *testing.T) {
 	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
 		c.Locals("role", "Admin")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "achievement:delete", "report:read"})
 		return service.VerifyAchievement(c)
 	})
 	reqBody := model.VerifyAchievementRequest{
@@ -585,6 +594,7 @@ func TestRejectAchievement_Success(t *testing.T) {
 	app.Post("/achievements/:id/reject", func(c *fiber.Ctx) error {
 		c.Locals("userID", lecturerUserID)
 		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
 		return service.RejectAchievement(c)
 	})
 	reqBody := map[string]string{
@@ -625,6 +635,7 @@ func TestRejectAchievement_EmptyNote(t *testing.T) {
 	app.Post("/achievements/:id/reject", func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
 		c.Locals("role", "Admin")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "achievement:delete", "report:read"})
 		return service.RejectAchievement(c)
 	})
 	reqBody := map[string]string{
@@ -664,6 +675,7 @@ func TestDeleteAchievement_Success(t *testing.T) {
 	app.Delete("/achievements/:id", func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
 		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
 		return service.DeleteAchievement(c)
 	})
 	// Act
@@ -699,6 +711,7 @@ func TestDeleteAchievement_NotDraft(t *testing.T) {
 	app.Delete("/achievements/:id", func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
 		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
 		return service.DeleteAchievement(c)
 	})
 	// Act
@@ -866,3 +879,976 @@ func TestGetAdviseeAchievements_StudentForbidden(t *testing.T) {
 	// Assert
 	assert.Equal(t, 403, resp.StatusCode)
 }
+
+// TestVerifyAchievement_DelegatedLecturer tests a delegate can verify on behalf of the advisor
+func TestVerifyAchievement_DelegatedLecturer(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisorID := uuid.New().String()
+	advisorUserID := uuid.New().String()
+	delegateID := uuid.New().String()
+	delegateUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: advisorID,
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: advisorUserID, LecturerID: "111111"})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: delegateID, UserID: delegateUserID, LecturerID: "222222"})
+	today := time.Now().Truncate(24 * time.Hour)
+	mockLecturerRepo.CreateDelegation(&model.VerificationDelegation{
+		ID:          uuid.New().String(),
+		DelegatorID: advisorID,
+		DelegateID:  delegateID,
+		StartDate:   today.AddDate(0, 0, -1),
+		EndDate:     today.AddDate(0, 0, 7),
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", delegateUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.VerifyAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 50})
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	histories, _ := mockAchRepo.GetAchievementHistory(achievementID)
+	if assert.Len(t, histories, 1) {
+		assert.Equal(t, delegateUserID, histories[0].ChangedBy)
+		if assert.NotNil(t, histories[0].OnBehalfOf) {
+			assert.Equal(t, advisorUserID, *histories[0].OnBehalfOf)
+		}
+	}
+}
+
+// TestVerifyAchievement_ExpiredDelegation tests an expired delegation no longer grants access
+func TestVerifyAchievement_ExpiredDelegation(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisorID := uuid.New().String()
+	delegateID := uuid.New().String()
+	delegateUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: advisorID,
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: uuid.New().String(), LecturerID: "111111"})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: delegateID, UserID: delegateUserID, LecturerID: "222222"})
+	today := time.Now().Truncate(24 * time.Hour)
+	mockLecturerRepo.CreateDelegation(&model.VerificationDelegation{
+		ID:          uuid.New().String(),
+		DelegatorID: advisorID,
+		DelegateID:  delegateID,
+		StartDate:   today.AddDate(0, 0, -14),
+		EndDate:     today.AddDate(0, 0, -7),
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", delegateUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.VerifyAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 50})
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+// TestGetAchievementDetail_DelegatedLecturer tests a delegate can open the detail of an achievement they may verify
+func TestGetAchievementDetail_DelegatedLecturer(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisorID := uuid.New().String()
+	delegateID := uuid.New().String()
+	delegateUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: advisorID,
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: uuid.New().String(), LecturerID: "111111"})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: delegateID, UserID: delegateUserID, LecturerID: "222222"})
+	today := time.Now().Truncate(24 * time.Hour)
+	mockLecturerRepo.CreateDelegation(&model.VerificationDelegation{
+		ID:          uuid.New().String(),
+		DelegatorID: advisorID,
+		DelegateID:  delegateID,
+		StartDate:   today.AddDate(0, 0, -1),
+		EndDate:     today.AddDate(0, 0, 7),
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	app.Get("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", delegateUserID)
+		c.Locals("role", "Dosen Wali")
+		return service.GetAchievementDetail(c)
+	})
+	// Act
+	req := httptest.NewRequest("GET", "/achievements/"+achWithRef.StudentID, nil)
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+// TestGetAchievementDetail_OtherLecturer tests a lecturer without advisor access or delegation is refused
+func TestGetAchievementDetail_OtherLecturer(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	otherUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: uuid.New().String(),
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: uuid.New().String(), UserID: otherUserID, LecturerID: "333333"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	app.Get("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", otherUserID)
+		c.Locals("role", "Dosen Wali")
+		return service.GetAchievementDetail(c)
+	})
+	// Act
+	req := httptest.NewRequest("GET", "/achievements/"+achWithRef.StudentID, nil)
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+// TestGetAchievementHistory_DelegatedLecturer tests a delegate can read the history of an achievement they may verify
+func TestGetAchievementHistory_DelegatedLecturer(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisorID := uuid.New().String()
+	delegateID := uuid.New().String()
+	delegateUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: advisorID,
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: uuid.New().String(), LecturerID: "111111"})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: delegateID, UserID: delegateUserID, LecturerID: "222222"})
+	today := time.Now().Truncate(24 * time.Hour)
+	mockLecturerRepo.CreateDelegation(&model.VerificationDelegation{
+		ID:          uuid.New().String(),
+		DelegatorID: advisorID,
+		DelegateID:  delegateID,
+		StartDate:   today.AddDate(0, 0, -1),
+		EndDate:     today.AddDate(0, 0, 7),
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	app.Get("/achievements/:id/history", func(c *fiber.Ctx) error {
+		c.Locals("userID", delegateUserID)
+		c.Locals("role", "Dosen Wali")
+		return service.GetAchievementHistory(c)
+	})
+	// Act
+	req := httptest.NewRequest("GET", "/achievements/"+achWithRef.StudentID+"/history", nil)
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+// TestSubmitAchievement_MissingPermission tests the workflow rejects a transition without its permission
+func TestSubmitAchievement_MissingPermission(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	app.Post("/achievements/:id/submit", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:read"})
+		return service.SubmitAchievement(c)
+	})
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/submit", nil)
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 403, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusDraft, achWithRef.Status)
+}
+
+// TestTransitionAchievement_ConfiguredStatus tests a status added only through workflow configuration
+func TestTransitionAchievement_ConfiguredStatus(t *testing.T) {
+	// Arrange
+	workflowFile := filepath.Join(t.TempDir(), "workflow.json")
+	os.WriteFile(workflowFile, []byte(`{
+		"editable": ["draft"],
+		"transitions": [
+			{"action": "submit", "from": ["draft"], "to": "submitted", "permission": "achievement:submit", "hooks": ["stamp_submitted"]},
+			{"action": "start_review", "from": ["submitted"], "to": "under_review", "permission": "achievement:verify"},
+			{"action": "verify", "from": ["submitted", "under_review"], "to": "verified", "permission": "achievement:verify", "hooks": ["assign_points", "stamp_verifier"]}
+		]
+	}`), 0644)
+	defaultWorkflow := achievementWorkflow
+	defer func() { achievementWorkflow = defaultWorkflow }()
+	assert.NoError(t, InitAchievementWorkflow(workflowFile))
+
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	lecturerID := uuid.New().String()
+	lecturerUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: uuid.New().String(), StudentID: "123456", AdvisorID: lecturerID})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: lecturerID, UserID: lecturerUserID, LecturerID: "789012"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	setLecturer := func(c *fiber.Ctx) error {
+		c.Locals("userID", lecturerUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return c.Next()
+	}
+	app.Post("/achievements/:id/transitions/:action", setLecturer, service.TransitionAchievement)
+	app.Post("/achievements/:id/verify", setLecturer, service.VerifyAchievement)
+
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/transitions/start_review", nil)
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "under_review", achWithRef.Status)
+
+	// Act
+	bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 30})
+	req = httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusVerified, achWithRef.Status)
+	assert.Equal(t, 30, achWithRef.Points)
+	histories, _ := mockAchRepo.GetAchievementHistory(achievementID)
+	if assert.Len(t, histories, 2) {
+		assert.Equal(t, "start_review", histories[0].Action)
+		assert.Equal(t, "under_review", histories[1].OldStatus)
+	}
+
+	// Act
+	req = httptest.NewRequest("POST", "/achievements/"+achievementID+"/transitions/reject", nil)
+	resp, _ = app.Test(req)
+	// Assert
+	assert.Equal(t, 404, resp.StatusCode)
+}
+
+// TestInitAchievementWorkflow_UnknownHook tests workflow files referencing unknown hooks are rejected
+func TestInitAchievementWorkflow_UnknownHook(t *testing.T) {
+	workflowFile := filepath.Join(t.TempDir(), "workflow.json")
+	os.WriteFile(workflowFile, []byte(`{"transitions": [
+		{"action": "submit", "from": ["draft"], "to": "submitted", "permission": "achievement:submit", "hooks": ["send_fax"]}
+	]}`), 0644)
+	defaultWorkflow := achievementWorkflow
+	defer func() { achievementWorkflow = defaultWorkflow }()
+
+	err := InitAchievementWorkflow(workflowFile)
+
+	assert.Error(t, err)
+	assert.Same(t, defaultWorkflow, achievementWorkflow)
+}
+
+// TestRequestRevision_RoundTrip tests a revision request is shown to the student, edited and resubmitted
+func TestRequestRevision_RoundTrip(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	lecturerID := uuid.New().String()
+	lecturerUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456", AdvisorID: lecturerID})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: lecturerID, UserID: lecturerUserID, LecturerID: "789012"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "competition", Title: "Lomba"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	setStudent := func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return c.Next()
+	}
+	app.Post("/achievements/:id/request-revision", func(c *fiber.Ctx) error {
+		c.Locals("userID", lecturerUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.RequestRevision(c)
+	})
+	app.Get("/achievements/:id", setStudent, service.GetAchievementDetail)
+	app.Put("/achievements/:id", setStudent, service.UpdateAchievement)
+	app.Post("/achievements/:id/submit", setStudent, service.SubmitAchievement)
+
+	// Act
+	bodyBytes, _ := json.Marshal(model.RequestRevisionRequest{Comments: []model.AchievementRevisionComment{
+		{Field: "title", Comment: "Sertakan nama lomba lengkap"},
+		{Field: "details.rank", Comment: "Peringkat belum diisi"},
+	}})
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/request-revision", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusRevisionRequested, achWithRef.Status)
+
+	// Act
+	resp, _ = app.Test(httptest.NewRequest("GET", "/achievements/"+achievementID, nil))
+	var detail struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&detail)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.Len(t, detail.Data.RevisionRequests, 1) {
+		assert.Equal(t, 1, detail.Data.RevisionRequests[0].Round)
+		assert.Len(t, detail.Data.RevisionRequests[0].Comments, 2)
+	}
+
+	// Act
+	title := "Lomba Karya Tulis Nasional"
+	details := map[string]interface{}{"competition_name": title, "level": "national", "rank": 2}
+	bodyBytes, _ = json.Marshal(model.UpdateAchievementRequest{Title: &title, Details: &details})
+	req = httptest.NewRequest("PUT", "/achievements/"+achievementID, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Act
+	resp, _ = app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/submit", nil))
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusSubmitted, achWithRef.Status)
+	revisions, _ := mockAchRepo.GetRevisionRequests(achievementID)
+	assert.NotNil(t, revisions[0].ResolvedAt)
+	histories, _ := mockAchRepo.GetAchievementHistory(achievementID)
+	if assert.Len(t, histories, 2) {
+		assert.Equal(t, model.AchievementActionRequestRevision, histories[0].Action)
+		assert.Contains(t, *histories[0].Note, "[round 1]")
+		assert.Equal(t, model.AchievementStatusRevisionRequested, histories[1].OldStatus)
+	}
+}
+
+// TestRequestRevision_UnknownField tests comments must target known achievement fields
+func TestRequestRevision_UnknownField(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	app.Post("/achievements/:id/request-revision", func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New().String())
+		c.Locals("role", "Admin")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify"})
+		return service.RequestRevision(c)
+	})
+	bodyBytes, _ := json.Marshal(model.RequestRevisionRequest{Comments: []model.AchievementRevisionComment{
+		{Field: "password", Comment: "?"},
+	}})
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/request-revision", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusSubmitted, achWithRef.Status)
+}
+
+// TestReopenAchievement_Success tests a rejected achievement can be reopened and resubmitted
+func TestReopenAchievement_Success(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	mockAchRepo.TransitionAchievementStatus(achievementID, model.AchievementStatusSubmitted, model.AchievementStatusRejected, 0, map[string]interface{}{
+		"rejection_note": "Sertifikat tidak terbaca",
+	}, nil)
+	setStudent := func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return c.Next()
+	}
+	app.Post("/achievements/:id/reopen", setStudent, service.ReopenAchievement)
+	app.Post("/achievements/:id/submit", setStudent, service.SubmitAchievement)
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/reopen", nil))
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusDraft, achWithRef.Status)
+	assert.Equal(t, 1, achWithRef.ResubmissionCount)
+	assert.Nil(t, achWithRef.RejectionNote)
+
+	// Act
+	resp, _ = app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/submit", nil))
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	histories, _ := mockAchRepo.GetAchievementHistory(achievementID)
+	if assert.Len(t, histories, 2) {
+		assert.Equal(t, model.AchievementActionReopen, histories[0].Action)
+		assert.Contains(t, *histories[0].Note, "Sertifikat tidak terbaca")
+		assert.Contains(t, *histories[1].Note, "attempt 1")
+	}
+}
+
+// TestReopenAchievement_LimitReached tests the resubmission limit is enforced
+func TestReopenAchievement_LimitReached(t *testing.T) {
+	// Arrange
+	SetAchievementResubmissionLimit(1)
+	defer SetAchievementResubmissionLimit(3)
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	mockAchRepo.TransitionAchievementStatus(achievementID, model.AchievementStatusSubmitted, model.AchievementStatusRejected, 0, map[string]interface{}{
+		"rejection_note":     "Masih kurang bukti",
+		"resubmission_count": 1,
+	}, nil)
+	app.Post("/achievements/:id/reopen", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:update"})
+		return service.ReopenAchievement(c)
+	})
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/reopen", nil))
+	// Assert
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusRejected, achWithRef.Status)
+}
+
+// TestVerifyAchievement_ApprovalChain tests points are only awarded after the last stage approves
+func TestVerifyAchievement_ApprovalChain(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisorID := uuid.New().String()
+	advisorUserID := uuid.New().String()
+	committeeUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: advisorID,
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: advisorUserID, LecturerID: "111111"})
+	achievementType := "competition"
+	mockAchRepo.CreateApprovalChain(&model.ApprovalChain{
+		Name:            "Kompetisi Internasional",
+		AchievementType: &achievementType,
+		MinPoints:       50,
+		Stages: []model.ApprovalStage{
+			{Name: "Dosen Wali", ApproverRole: "Dosen Wali"},
+			{Name: "Komite Fakultas", ApproverUserIDs: []string{committeeUserID}},
+		},
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "competition", Title: "Juara Internasional"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	currentUser := advisorUserID
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", currentUser)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.VerifyAchievement(c)
+	})
+	app.Get("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New().String())
+		c.Locals("role", "Admin")
+		return service.GetAchievementDetail(c)
+	})
+	verify := func(points int) int {
+		bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: points})
+		req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	// Act: tahap pertama oleh dosen wali
+	status := verify(80)
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, model.AchievementStatusSubmitted, achWithRef.Status)
+	assert.Equal(t, 0, achWithRef.Points)
+
+	// Act: progres terlihat di detail
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+achievementID, nil))
+	var detail struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&detail)
+	// Assert
+	if assert.NotNil(t, detail.Data.ApprovalProgress) {
+		assert.Equal(t, 2, detail.Data.ApprovalProgress.CurrentStage)
+		assert.Len(t, detail.Data.ApprovalProgress.Approvals, 1)
+	}
+
+	// Act: tahap terakhir oleh anggota komite
+	currentUser = committeeUserID
+	status = verify(75)
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, model.AchievementStatusVerified, achWithRef.Status)
+	assert.Equal(t, 75, achWithRef.Points)
+	approvals, _ := mockAchRepo.GetAchievementApprovals(achievementID)
+	if assert.Len(t, approvals, 2) {
+		assert.Equal(t, advisorUserID, approvals[0].VerifiedBy)
+		assert.Equal(t, committeeUserID, approvals[1].VerifiedBy)
+	}
+	histories, _ := mockAchRepo.GetAchievementHistory(achievementID)
+	if assert.Len(t, histories, 2) {
+		assert.Equal(t, model.AchievementActionApproveStage, histories[0].Action)
+		assert.Contains(t, *histories[0].Note, "stage 1/2")
+		assert.Equal(t, model.AchievementActionVerify, histories[1].Action)
+	}
+}
+
+// TestVerifyAchievement_ApprovalChainWrongApprover tests a stage rejects users outside its approver group
+func TestVerifyAchievement_ApprovalChainWrongApprover(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisorID := uuid.New().String()
+	advisorUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: advisorID,
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: advisorUserID, LecturerID: "111111"})
+	mockAchRepo.CreateApprovalChain(&model.ApprovalChain{
+		Name:      "Komite",
+		MinPoints: 0,
+		Stages: []model.ApprovalStage{
+			{Name: "Komite Fakultas", ApproverUserIDs: []string{uuid.New().String()}},
+		},
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", advisorUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.VerifyAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 10})
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 403, resp.StatusCode)
+	assert.Equal(t, model.AchievementStatusSubmitted, achWithRef.Status)
+}
+
+// TestVerifyAchievement_ApprovalChainSameApprover tests an approver who signed an earlier stage cannot also sign the next stage
+func TestVerifyAchievement_ApprovalChainSameApprover(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisorID := uuid.New().String()
+	advisorUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{
+		ID:        studentID,
+		UserID:    uuid.New().String(),
+		StudentID: "123456",
+		AdvisorID: advisorID,
+	})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: advisorUserID, LecturerID: "111111"})
+	mockAchRepo.CreateApprovalChain(&model.ApprovalChain{
+		Name:      "Dua Dosen",
+		MinPoints: 0,
+		Stages: []model.ApprovalStage{
+			{Name: "Dosen Pertama", ApproverRole: "Dosen Wali"},
+			{Name: "Dosen Kedua", ApproverRole: "Dosen Wali"},
+		},
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", advisorUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.VerifyAchievement(c)
+	})
+	verify := func() int {
+		bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 10})
+		req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	assert.Equal(t, 200, verify())
+
+	// Act
+	status := verify()
+
+	// Assert
+	assert.Equal(t, 403, status)
+	assert.Equal(t, model.AchievementStatusSubmitted, achWithRef.Status)
+	approvals, _ := mockAchRepo.GetAchievementApprovals(achievementID)
+	assert.Len(t, approvals, 1)
+}
+
+// chainThresholdFixture menyiapkan prestasi kompetisi submitted dengan dua approval chain: "Dosen dan Komite"
+// untuk semua poin dan "Komite Universitas" mulai 100 poin. verify mengirim verifikasi sebagai userID.
+func chainThresholdFixture() (*repository.MockAchievementRepository, *model.AchievementWithReference, map[string]string, func(userID string, points int) int) {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	users := map[string]string{"advisor": uuid.New().String(), "committee": uuid.New().String()}
+	studentID, advisorID := uuid.New().String(), uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: uuid.New().String(), StudentID: "123456", AdvisorID: advisorID})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: users["advisor"], LecturerID: "111111"})
+	achievementType := "competition"
+	mockAchRepo.CreateApprovalChain(&model.ApprovalChain{
+		Name:            "Dosen dan Komite",
+		AchievementType: &achievementType,
+		Stages: []model.ApprovalStage{
+			{Name: "Dosen Wali", ApproverRole: "Dosen Wali"},
+			{Name: "Komite Fakultas", ApproverUserIDs: []string{users["committee"]}},
+		},
+	})
+	mockAchRepo.CreateApprovalChain(&model.ApprovalChain{
+		Name:            "Komite Universitas",
+		AchievementType: &achievementType,
+		MinPoints:       100,
+		Stages: []model.ApprovalStage{
+			{Name: "Dosen Wali", ApproverRole: "Dosen Wali"},
+			{Name: "Komite Fakultas", ApproverUserIDs: []string{users["committee"]}},
+			{Name: "Komite Universitas", ApproverUserIDs: []string{uuid.New().String()}},
+		},
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{
+		AchievementType: "competition",
+		Title:           "Juara 1 Internasional",
+		Details:         map[string]interface{}{"level": "international"},
+	}, studentID)
+	mockAchRepo.Submit(achWithRef.ReferenceID)
+
+	var currentUser string
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", currentUser)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify"})
+		return service.VerifyAchievement(c)
+	})
+	verify := func(userID string, points int) int {
+		currentUser = userID
+		bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: points, Justification: "sesuai bukti"})
+		req := httptest.NewRequest("POST", "/achievements/"+achWithRef.ReferenceID+"/verify", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	return mockAchRepo, achWithRef, users, verify
+}
+
+// TestVerifyAchievement_ApprovalChainFromRubric tests the chain follows the rubric suggestion when the first stage proposes fewer points
+func TestVerifyAchievement_ApprovalChainFromRubric(t *testing.T) {
+	// Arrange
+	mockAchRepo, achWithRef, users, verify := chainThresholdFixture()
+	mockAchRepo.CreatePointsRubricRule(&model.PointsRubricRule{
+		AchievementType: "competition",
+		Conditions:      map[string]string{"level": "international"},
+		Points:          120,
+	})
+
+	// Act
+	status := verify(users["advisor"], 99)
+
+	// Assert
+	assert.Equal(t, 200, status)
+	approvals, _ := mockAchRepo.GetAchievementApprovals(achWithRef.ReferenceID)
+	if assert.Len(t, approvals, 1) {
+		chain, _ := mockAchRepo.GetApprovalChainByID(approvals[0].ChainID)
+		assert.Equal(t, "Komite Universitas", chain.Name)
+	}
+}
+
+// TestVerifyAchievement_FinalStageAboveChainThreshold tests the last stage cannot award points that require a stricter chain
+func TestVerifyAchievement_FinalStageAboveChainThreshold(t *testing.T) {
+	// Arrange
+	mockAchRepo, achWithRef, users, verify := chainThresholdFixture()
+	verify(users["advisor"], 50)
+
+	// Act
+	status := verify(users["committee"], 150)
+
+	// Assert
+	assert.Equal(t, 400, status)
+	assert.Equal(t, model.AchievementStatusSubmitted, achWithRef.Status)
+	assert.Equal(t, 0, achWithRef.Points)
+	approvals, _ := mockAchRepo.GetAchievementApprovals(achWithRef.ReferenceID)
+	assert.Len(t, approvals, 1)
+}
+
+// TestVerifyAchievement_RubricDeviation tests the rubric suggestion and the justification requirement
+func TestVerifyAchievement_RubricDeviation(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: uuid.New().String(), StudentID: "123456"})
+	mockAchRepo.CreatePointsRubricRule(&model.PointsRubricRule{
+		AchievementType: "competition",
+		Conditions:      map[string]string{"level": "national"},
+		Points:          40,
+	})
+	mockAchRepo.CreatePointsRubricRule(&model.PointsRubricRule{
+		AchievementType: "competition",
+		Conditions:      map[string]string{"level": "national", "rank": "1"},
+		Points:          50,
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{
+		AchievementType: "competition",
+		Title:           "Juara 1 Nasional",
+		Details:         map[string]interface{}{"level": "National", "rank": 1},
+	}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	setAdmin := func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New().String())
+		c.Locals("role", "Admin")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify"})
+		return c.Next()
+	}
+	app.Get("/achievements/:id", setAdmin, service.GetAchievementDetail)
+	app.Post("/achievements/:id/verify", setAdmin, service.VerifyAchievement)
+	verify := func(req model.VerifyAchievementRequest) int {
+		bodyBytes, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(httpReq)
+		return resp.StatusCode
+	}
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+achievementID, nil))
+	var detail struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&detail)
+	// Assert: aturan dengan kondisi terbanyak dipilih
+	if assert.NotNil(t, detail.Data.PointsSuggestion) {
+		assert.Equal(t, 50, detail.Data.PointsSuggestion.Points)
+		assert.Equal(t, 10, detail.Data.PointsSuggestion.Tolerance)
+	}
+
+	// Act & Assert: menyimpang tanpa justifikasi ditolak
+	assert.Equal(t, 400, verify(model.VerifyAchievementRequest{Points: 80}))
+	assert.Equal(t, model.AchievementStatusSubmitted, achWithRef.Status)
+
+	// Act & Assert: dengan justifikasi diterima dan dicatat di history
+	assert.Equal(t, 200, verify(model.VerifyAchievementRequest{Points: 80, Justification: "Kompetisi diikuti 300 tim"}))
+	assert.Equal(t, 80, achWithRef.Points)
+	histories, _ := mockAchRepo.GetAchievementHistory(achievementID)
+	if assert.Len(t, histories, 1) {
+		assert.Contains(t, *histories[0].Note, "Kompetisi diikuti 300 tim")
+	}
+}
+
+// TestGetAchievementDetail_StudentNoPointsSuggestion tests the rubric suggestion is hidden from the student owner
+func TestGetAchievementDetail_StudentNoPointsSuggestion(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	mockAchRepo.CreatePointsRubricRule(&model.PointsRubricRule{
+		AchievementType: "competition",
+		Conditions:      map[string]string{"level": "national"},
+		Points:          40,
+	})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{
+		AchievementType: "competition",
+		Title:           "Juara 1 Nasional",
+		Details:         map[string]interface{}{"level": "National"},
+	}, studentID)
+	app.Get("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:read"})
+		return service.GetAchievementDetail(c)
+	})
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+achWithRef.StudentID, nil))
+	var detail struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&detail)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Nil(t, detail.Data.PointsSuggestion)
+}
+
+// TestUpdateAchievement_IfMatch tests a stale If-Match is rejected with 412 and a fresh one returns the new ETag
+func TestUpdateAchievement_IfMatch(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	app.Put("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		return service.UpdateAchievement(c)
+	})
+	update := func(title, ifMatch string) *http.Response {
+		bodyBytes, _ := json.Marshal(model.UpdateAchievementRequest{Title: &title})
+		req := httptest.NewRequest("PUT", "/achievements/"+achievementID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	// Act: tab pertama menyimpan dengan versi yang dibaca
+	first := update("Judul Tab Pertama", `"1"`)
+	// Act: tab kedua masih memegang versi lama
+	second := update("Judul Tab Kedua", `"1"`)
+
+	// Assert
+	assert.Equal(t, 200, first.StatusCode)
+	assert.Equal(t, `"2"`, first.Header.Get("ETag"))
+	assert.Equal(t, 412, second.StatusCode)
+	current, _ := mockAchRepo.GetAchievementByID(achievementID)
+	assert.Equal(t, "Judul Tab Pertama", current.Title)
+}
+
+// TestVerifyAchievement_IfMatchMismatch tests verification is refused when the achievement changed after it was read
+func TestVerifyAchievement_IfMatchMismatch(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	service := NewAchievementService(mockAchRepo, repository.NewMockStudentRepository(), repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	mockAchRepo.Submit(achievementID)
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New().String())
+		c.Locals("role", "Admin")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify"})
+		return service.VerifyAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 10})
+
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achievementID+"/verify", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	resp, _ := app.Test(req)
+
+	// Assert
+	assert.Equal(t, 412, resp.StatusCode)
+	current, _ := mockAchRepo.GetAchievementByID(achievementID)
+	assert.Equal(t, model.AchievementStatusSubmitted, current.Status)
+}
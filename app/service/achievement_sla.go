@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// verificationSLA adalah batas waktu verifikasi sebelum dosen wali diingatkan (0 = SLA tidak dipantau)
+var verificationSLA = 7 * 24 * time.Hour
+
+// escalationAfter adalah lama menunggu sejak submit sebelum prestasi dieskalasi ke reviewer departemen (0 = tanpa eskalasi)
+var escalationAfter = 14 * 24 * time.Hour
+
+// SetVerificationSLA mengatur batas pengingat dan eskalasi verifikasi prestasi
+func SetVerificationSLA(remindAfter, escalateAfter time.Duration) {
+	verificationSLA = remindAfter
+	escalationAfter = escalateAfter
+}
+
+type SLAService interface {
+	GetOverdueSubmissions(c *fiber.Ctx) error
+	// ProcessOverdueSubmissions mengirim pengingat dan eskalasi untuk prestasi yang melewati SLA
+	ProcessOverdueSubmissions(now time.Time) (reminded, escalated int, err error)
+}
+
+type slaServiceImpl struct {
+	achievementRepo  repository.AchievementRepository
+	lecturerRepo     repository.LecturerRepository
+	notificationRepo repository.NotificationRepository
+	workflow         *achievementServiceImpl // Menjalankan transisi tahap SLA
+}
+
+// slaTransitions mencatat tahap SLA tanpa mengubah status. Transisinya dibuat di sini, bukan dari definisi
+// workflow, karena hanya dijalankan oleh scheduler dan tidak boleh dipanggil lewat endpoint transisi generik.
+var slaTransitions = map[string]*model.AchievementTransition{
+	model.AchievementActionSLAReminder: {
+		Action: model.AchievementActionSLAReminder,
+		From:   []string{model.AchievementStatusSubmitted},
+		To:     model.AchievementStatusSubmitted,
+		Hooks:  []string{"stamp_sla_reminded"},
+	},
+	model.AchievementActionEscalate: {
+		Action: model.AchievementActionEscalate,
+		From:   []string{model.AchievementStatusSubmitted},
+		To:     model.AchievementStatusSubmitted,
+		Hooks:  []string{"stamp_escalated"},
+	},
+}
+
+func NewSLAService(
+	achievementRepo repository.AchievementRepository,
+	lecturerRepo repository.LecturerRepository,
+	notificationRepo repository.NotificationRepository,
+) SLAService {
+	return &slaServiceImpl{
+		achievementRepo:  achievementRepo,
+		lecturerRepo:     lecturerRepo,
+		notificationRepo: notificationRepo,
+		workflow: &achievementServiceImpl{
+			achievementRepo: achievementRepo,
+			lecturerRepo:    lecturerRepo,
+		},
+	}
+}
+
+// GetOverdueSubmissions godoc
+// @Summary Dapatkan prestasi yang melewati SLA verifikasi
+// @Description Mengambil prestasi berstatus submitted yang belum diverifikasi lebih lama dari SLA, beserta umur dan tahap pengingat/eskalasi
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param min_days query int false "Minimal umur submit dalam hari, default SLA verifikasi"
+// @Success 200 {object} model.APIResponse{data=object{sla_days=int,escalation_days=int,total=int,reminded=int,escalated=int,submissions=[]model.OverdueSubmission}} "Prestasi yang melewati SLA berhasil diambil"
+// @Failure 400 {object} model.APIResponse "min_days tidak valid"
+// @Failure 403 {object} model.APIResponse "Tidak memiliki permission achievement:monitor"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /reports/overdue-submissions [get]
+func (s *slaServiceImpl) GetOverdueSubmissions(c *fiber.Ctx) error {
+	slaDays := int(verificationSLA / (24 * time.Hour))
+	minDays, err := strconv.Atoi(c.Query("min_days", strconv.Itoa(slaDays)))
+	if err != nil || minDays < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "min_days harus berupa angka >= 0",
+		})
+	}
+
+	now := time.Now()
+	submissions, err := s.achievementRepo.GetOverdueSubmissions(now.Add(-time.Duration(minDays) * 24 * time.Hour))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil prestasi yang melewati SLA: " + err.Error(),
+		})
+	}
+	if submissions == nil {
+		submissions = []*model.OverdueSubmission{}
+	}
+
+	reminded, escalated := 0, 0
+	for _, submission := range submissions {
+		submission.SetAge(now)
+		if submission.SLARemindedAt != nil {
+			reminded++
+		}
+		if submission.EscalatedAt != nil {
+			escalated++
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "prestasi yang melewati SLA berhasil diambil",
+		Data: fiber.Map{
+			"sla_days":        slaDays,
+			"escalation_days": int(escalationAfter / (24 * time.Hour)),
+			"total":           len(submissions),
+			"reminded":        reminded,
+			"escalated":       escalated,
+			"submissions":     submissions,
+		},
+	})
+}
+
+// ProcessOverdueSubmissions mengingatkan dosen wali untuk prestasi yang melewati verificationSLA, lalu
+// mengeskalasi ke reviewer departemen setelah escalationAfter. Setiap tahap hanya dijalankan sekali per submit.
+func (s *slaServiceImpl) ProcessOverdueSubmissions(now time.Time) (int, int, error) {
+	if verificationSLA <= 0 {
+		return 0, 0, nil
+	}
+
+	overdue, err := s.achievementRepo.GetOverdueSubmissions(now.Add(-verificationSLA))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	reminded, escalated := 0, 0
+	for _, submission := range overdue {
+		submission.SetAge(now)
+
+		if escalationAfter > 0 && submission.EscalatedAt == nil && now.Sub(submission.SubmittedAt) >= escalationAfter {
+			done, err := s.escalate(submission, now)
+			if err != nil {
+				return reminded, escalated, err
+			}
+			if done {
+				escalated++
+			}
+			continue
+		}
+
+		if submission.SLARemindedAt == nil {
+			done, err := s.remind(submission, now)
+			if err != nil {
+				return reminded, escalated, err
+			}
+			if done {
+				reminded++
+			}
+		}
+	}
+
+	return reminded, escalated, nil
+}
+
+// remind mencatat pengingat SLA lalu mengirimkannya ke dosen wali
+func (s *slaServiceImpl) remind(submission *model.OverdueSubmission, now time.Time) (bool, error) {
+	note := fmt.Sprintf("Verification SLA exceeded after %d days, advisor reminded", submission.AgeDays)
+	recorded, err := s.recordStage(submission, model.AchievementActionSLAReminder, note, now)
+	if err != nil || !recorded {
+		return false, err
+	}
+
+	if submission.AdvisorUserID != "" {
+		s.notify(submission.AdvisorUserID, model.NotificationTypeSLAReminder, submission,
+			fmt.Sprintf("Prestasi \"%s\" sudah menunggu verifikasi anda selama %d hari", submission.Title, submission.AgeDays))
+	}
+	return true, nil
+}
+
+// escalate mengirim prestasi ke reviewer departemen dosen wali. Reviewer yang menerima eskalasi boleh
+// memverifikasi prestasi atas nama dosen wali (lihat authorizeAdvisor).
+func (s *slaServiceImpl) escalate(submission *model.OverdueSubmission, now time.Time) (bool, error) {
+	var reviewers []*model.Lecturer
+	if submission.AdvisorDepartment != "" {
+		candidates, err := s.lecturerRepo.GetDepartmentReviewers(submission.AdvisorDepartment)
+		if err != nil {
+			return false, err
+		}
+		for _, reviewer := range candidates {
+			if reviewer.ID != submission.AdvisorID {
+				reviewers = append(reviewers, reviewer)
+			}
+		}
+	}
+
+	note := fmt.Sprintf("Escalated after %d days without verification to %d department reviewer(s) of %q",
+		submission.AgeDays, len(reviewers), submission.AdvisorDepartment)
+	if len(reviewers) == 0 {
+		note = fmt.Sprintf("Escalation after %d days without verification: no department reviewer configured for %q",
+			submission.AgeDays, submission.AdvisorDepartment)
+	}
+	recorded, err := s.recordStage(submission, model.AchievementActionEscalate, note, now)
+	if err != nil || !recorded {
+		return false, err
+	}
+
+	for _, reviewer := range reviewers {
+		s.notify(reviewer.UserID, model.NotificationTypeSLAEscalated, submission,
+			fmt.Sprintf("Prestasi \"%s\" dieskalasi ke anda karena belum diverifikasi dosen wali selama %d hari", submission.Title, submission.AgeDays))
+	}
+	return true, nil
+}
+
+// notify mengirim notifikasi; kegagalan hanya dicatat agar tahap SLA tetap tercatat
+func (s *slaServiceImpl) notify(userID, notificationType string, submission *model.OverdueSubmission, message string) {
+	achievementID := submission.AchievementID
+	err := s.notificationRepo.CreateNotification(&model.Notification{
+		UserID:        userID,
+		Type:          notificationType,
+		Message:       message,
+		AchievementID: &achievementID,
+	})
+	if err != nil {
+		log.Println("warning: failed to create SLA notification:", err)
+	}
+}
+
+// recordStage menjalankan transisi tahap SLA, sehingga waktu tahap dan history-nya tersimpan dalam satu
+// transaksi; ChangedBy kosong berarti sistem. Mengembalikan false jika prestasi sudah tidak submitted atau
+// tahap tersebut sudah tercatat, misal oleh instance lain yang berjalan bersamaan.
+func (s *slaServiceImpl) recordStage(submission *model.OverdueSubmission, action, note string, now time.Time) (bool, error) {
+	achievement, err := s.achievementRepo.GetAchievementByID(submission.AchievementID)
+	if err != nil {
+		return false, err
+	}
+	if achievement == nil || achievement.Status != model.AchievementStatusSubmitted {
+		return false, nil
+	}
+	if (action == model.AchievementActionSLAReminder && achievement.SLARemindedAt != nil) ||
+		(action == model.AchievementActionEscalate && achievement.EscalatedAt != nil) {
+		return false, nil
+	}
+
+	tc := &transitionContext{
+		achievementID: submission.AchievementID,
+		achievement:   achievement,
+		note:          note,
+		stampedAt:     now,
+	}
+	err = s.workflow.applyTransition(slaTransitions[action], tc)
+	if errors.Is(err, repository.ErrAchievementStatusConflict) || errors.Is(err, repository.ErrAchievementVersionConflict) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// StartSLAEscalationJob menjalankan ProcessOverdueSubmissions secara berkala di background
+func StartSLAEscalationJob(slaService SLAService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			reminded, escalated, err := slaService.ProcessOverdueSubmissions(time.Now())
+			if err != nil {
+				log.Println("warning: failed to process verification SLA:", err)
+			} else if reminded > 0 || escalated > 0 {
+				log.Printf("⏰ Verification SLA: %d reminded, %d escalated", reminded, escalated)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// slaFixture menyiapkan prestasi submitted milik mahasiswa bimbingan seorang dosen wali, reviewer departemen
+// dosen wali tersebut, dan route verifikasi untuk reviewer
+type slaFixture struct {
+	app                  *fiber.App
+	mockAchRepo          *repository.MockAchievementRepository
+	mockNotificationRepo *repository.MockNotificationRepository
+	slaService           SLAService
+	achievement          *model.AchievementWithReference
+	advisor              *model.Lecturer
+	reviewer             *model.Lecturer
+}
+
+func newSLAFixture(submittedAt time.Time) *slaFixture {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	mockNotificationRepo := repository.NewMockNotificationRepository()
+	achievementService := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	studentID := uuid.New().String()
+	advisor := &model.Lecturer{ID: uuid.New().String(), UserID: uuid.New().String(), LecturerID: "111111", Department: "Informatika"}
+	reviewer := &model.Lecturer{ID: uuid.New().String(), UserID: uuid.New().String(), LecturerID: "222222", Department: "Informatika"}
+	mockLecturerRepo.CreateLecturer(advisor)
+	mockLecturerRepo.CreateLecturer(reviewer)
+	mockLecturerRepo.SetDepartmentReviewer(reviewer.ID, true)
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: uuid.New().String(), StudentID: "123456", AdvisorID: advisor.ID})
+	mockAchRepo.Create(&model.Achievement{AchievementType: "other", Title: "Relawan Bencana"}, studentID)
+	mockAchRepo.Advisors[studentID] = advisor
+	mockAchRepo.Submit(studentID)
+	achievement, _ := mockAchRepo.GetAchievementByID(studentID)
+	achievement.SubmittedAt = &submittedAt
+	app.Post("/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", reviewer.UserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return achievementService.VerifyAchievement(c)
+	})
+	return &slaFixture{
+		app:                  app,
+		mockAchRepo:          mockAchRepo,
+		mockNotificationRepo: mockNotificationRepo,
+		slaService:           NewSLAService(mockAchRepo, mockLecturerRepo, mockNotificationRepo),
+		achievement:          achievement,
+		advisor:              advisor,
+		reviewer:             reviewer,
+	}
+}
+
+// verifyAsReviewer mengirim verifikasi prestasi sebagai reviewer departemen
+func (f *slaFixture) verifyAsReviewer() int {
+	bodyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 10})
+	req := httptest.NewRequest("POST", "/achievements/"+f.achievement.ReferenceID+"/verify", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := f.app.Test(req)
+	return resp.StatusCode
+}
+
+func (f *slaFixture) historyActions() []string {
+	history, _ := f.mockAchRepo.GetAchievementHistory(f.achievement.ReferenceID)
+	var actions []string
+	for _, entry := range history {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+// TestProcessOverdueSubmissions_RemindsAdvisor tests a submission past the SLA reminds the advisor and records it in history
+func TestProcessOverdueSubmissions_RemindsAdvisor(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	f := newSLAFixture(now.Add(-8 * 24 * time.Hour))
+
+	// Act
+	reminded, escalated, err := f.slaService.ProcessOverdueSubmissions(now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reminded)
+	assert.Equal(t, 0, escalated)
+	assert.NotNil(t, f.achievement.SLARemindedAt)
+	advisorNotifications, _ := f.mockNotificationRepo.GetNotificationsByUserID(f.advisor.UserID, false)
+	if assert.Len(t, advisorNotifications, 1) {
+		assert.Equal(t, model.NotificationTypeSLAReminder, advisorNotifications[0].Type)
+	}
+	assert.Equal(t, []string{model.AchievementActionSLAReminder}, f.historyActions())
+}
+
+// TestProcessOverdueSubmissions_RemindsOnce tests a reminded submission is not reminded again before it is due for escalation
+func TestProcessOverdueSubmissions_RemindsOnce(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	f := newSLAFixture(now.Add(-8 * 24 * time.Hour))
+	f.slaService.ProcessOverdueSubmissions(now)
+
+	// Act
+	reminded, escalated, err := f.slaService.ProcessOverdueSubmissions(now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, reminded+escalated)
+	assert.Equal(t, []string{model.AchievementActionSLAReminder}, f.historyActions())
+}
+
+// TestVerifyAchievement_ReviewerBeforeEscalation tests a department reviewer cannot verify a submission that is only reminded
+func TestVerifyAchievement_ReviewerBeforeEscalation(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	f := newSLAFixture(now.Add(-8 * 24 * time.Hour))
+	f.slaService.ProcessOverdueSubmissions(now)
+
+	// Act
+	status := f.verifyAsReviewer()
+
+	// Assert
+	assert.Equal(t, 401, status)
+	assert.Equal(t, model.AchievementStatusSubmitted, f.achievement.Status)
+}
+
+// TestProcessOverdueSubmissions_EscalatesAfterReminder tests a reminded submission is escalated to the department reviewer once the escalation period passes
+func TestProcessOverdueSubmissions_EscalatesAfterReminder(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	f := newSLAFixture(now.Add(-15 * 24 * time.Hour))
+	f.slaService.ProcessOverdueSubmissions(now.Add(-7 * 24 * time.Hour))
+
+	// Act
+	reminded, escalated, err := f.slaService.ProcessOverdueSubmissions(now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, reminded)
+	assert.Equal(t, 1, escalated)
+	assert.NotNil(t, f.achievement.EscalatedAt)
+	reviewerNotifications, _ := f.mockNotificationRepo.GetNotificationsByUserID(f.reviewer.UserID, false)
+	if assert.Len(t, reviewerNotifications, 1) {
+		assert.Equal(t, model.NotificationTypeSLAEscalated, reviewerNotifications[0].Type)
+	}
+	assert.Equal(t, []string{model.AchievementActionSLAReminder, model.AchievementActionEscalate}, f.historyActions())
+}
+
+// TestVerifyAchievement_ReviewerAfterEscalation tests a department reviewer may verify an escalated submission
+func TestVerifyAchievement_ReviewerAfterEscalation(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	f := newSLAFixture(now.Add(-15 * 24 * time.Hour))
+	f.slaService.ProcessOverdueSubmissions(now)
+
+	// Act
+	status := f.verifyAsReviewer()
+
+	// Assert
+	assert.Equal(t, 200, status)
+	assert.Equal(t, model.AchievementStatusVerified, f.achievement.Status)
+}
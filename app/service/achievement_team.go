@@ -0,0 +1,91 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"uas_be/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// buildTeamMembers mengubah daftar anggota dari request menjadi anggota tim lengkap dengan pembuat prestasi.
+// Pembuat menjadi leader kecuali request menunjuk anggota lain sebagai leader.
+func (s *achievementServiceImpl) buildTeamMembers(creator *model.Student, requests []model.TeamMemberRequest) ([]*model.TeamMember, *actionError) {
+	if len(requests)+1 > model.MaxTeamMembers {
+		return nil, newActionError(fiber.StatusBadRequest, "anggota tim maksimal "+strconv.Itoa(model.MaxTeamMembers)+" orang termasuk pembuat")
+	}
+
+	creatorMember := &model.TeamMember{
+		StudentID:     creator.ID,
+		StudentNumber: creator.StudentID,
+		Role:          model.TeamRoleLeader,
+	}
+	members := []*model.TeamMember{creatorMember}
+	seen := map[string]bool{creator.ID: true}
+	leaders := 0
+
+	for _, req := range requests {
+		nim := strings.TrimSpace(req.StudentID)
+		if nim == "" {
+			return nil, newActionError(fiber.StatusBadRequest, "student_id (NIM) anggota tim tidak boleh kosong")
+		}
+		role := req.Role
+		if role == "" {
+			role = model.TeamRoleMember
+		}
+		if !model.IsValidTeamRole(role) {
+			return nil, newActionError(fiber.StatusBadRequest, "role anggota tim harus leader atau member")
+		}
+
+		student, err := s.studentRepo.GetStudentByStudentID(nim)
+		if err != nil {
+			return nil, newActionError(fiber.StatusInternalServerError, "gagal mengambil data anggota tim")
+		}
+		if student == nil {
+			return nil, newActionError(fiber.StatusBadRequest, "mahasiswa dengan NIM "+nim+" tidak ditemukan")
+		}
+		if seen[student.ID] {
+			return nil, newActionError(fiber.StatusBadRequest, "mahasiswa dengan NIM "+nim+" tercantum lebih dari sekali")
+		}
+		seen[student.ID] = true
+
+		if role == model.TeamRoleLeader {
+			leaders++
+		}
+		members = append(members, &model.TeamMember{
+			StudentID:     student.ID,
+			StudentNumber: student.StudentID,
+			Role:          role,
+		})
+	}
+
+	if leaders > 1 {
+		return nil, newActionError(fiber.StatusBadRequest, "prestasi tim hanya boleh memiliki satu leader")
+	}
+	if leaders == 1 {
+		creatorMember.Role = model.TeamRoleMember
+	}
+	return members, nil
+}
+
+// checkTeamEditable memastikan isi prestasi tim hanya diubah oleh ketua dan selama belum ada anggota yang
+// mensubmit, karena isi prestasi dipakai bersama oleh semua anggota
+func (s *achievementServiceImpl) checkTeamEditable(achievement *model.AchievementWithReference, role string) *actionError {
+	if achievement.TeamID == nil {
+		return nil
+	}
+	if role == "Mahasiswa" && (achievement.TeamRole == nil || *achievement.TeamRole != model.TeamRoleLeader) {
+		return newActionError(fiber.StatusForbidden, "hanya ketua tim yang dapat mengubah prestasi tim")
+	}
+
+	members, err := s.achievementRepo.GetTeamMembers(*achievement.TeamID)
+	if err != nil {
+		return newActionError(fiber.StatusInternalServerError, "gagal mengambil anggota tim")
+	}
+	for _, member := range members {
+		if member.Status != model.AchievementStatusDeleted && !achievementWorkflow.IsEditable(member.Status) {
+			return newActionError(fiber.StatusBadRequest, "prestasi tim tidak bisa diupdate karena reference salah satu anggota berstatus "+member.Status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateAchievement_Team tests a team achievement gives each member their own reference, verified by their own advisor
+func TestCreateAchievement_Team(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, mockLecturerRepo)
+	leaderID, leaderUserID := uuid.New().String(), uuid.New().String()
+	memberID, memberUserID := uuid.New().String(), uuid.New().String()
+	leaderAdvisorID, memberAdvisorID := uuid.New().String(), uuid.New().String()
+	memberAdvisorUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: leaderID, UserID: leaderUserID, StudentID: "111111", AdvisorID: leaderAdvisorID})
+	mockStudentRepo.CreateStudent(&model.Student{ID: memberID, UserID: memberUserID, StudentID: "222222", AdvisorID: memberAdvisorID})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: memberAdvisorID, UserID: memberAdvisorUserID, LecturerID: "789012"})
+	asStudent := func(userID string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("userID", userID)
+			c.Locals("role", "Mahasiswa")
+			c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+			return c.Next()
+		}
+	}
+	app.Post("/leader/achievements", asStudent(leaderUserID), service.CreateAchievement)
+	app.Get("/member/achievements/:id", asStudent(memberUserID), service.GetAchievementDetail)
+	app.Put("/member/achievements/:id", asStudent(memberUserID), service.UpdateAchievement)
+	app.Post("/advisor/achievements/:id/verify", func(c *fiber.Ctx) error {
+		c.Locals("userID", memberAdvisorUserID)
+		c.Locals("role", "Dosen Wali")
+		c.Locals("permissions", []string{"achievement:read", "achievement:verify", "report:read"})
+		return service.VerifyAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(model.CreateAchievementRequest{
+		AchievementType: "competition",
+		Title:           "Juara 1 Hackathon Nasional",
+		Details:         map[string]interface{}{"competition_name": "Hackathon Nasional", "level": "national", "rank": 1},
+		TeamMembers:     []model.TeamMemberRequest{{StudentID: "222222"}},
+	})
+
+	// Act
+	req := httptest.NewRequest("POST", "/leader/achievements", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	var created struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&created)
+
+	// Assert
+	assert.Equal(t, 201, resp.StatusCode)
+	if !assert.Len(t, created.Data.TeamMembers, 2) {
+		return
+	}
+	assert.Equal(t, model.TeamRoleLeader, created.Data.TeamMembers[0].Role)
+	assert.Equal(t, leaderID, created.Data.TeamMembers[0].StudentID)
+	assert.Empty(t, created.Data.Duplicates)
+	memberRef := created.Data.TeamMembers[1].ReferenceID
+	assert.NotEqual(t, created.Data.ReferenceID, memberRef)
+
+	// Act
+	resp, _ = app.Test(httptest.NewRequest("GET", "/member/achievements/"+memberRef, nil))
+	var detail struct {
+		Data model.AchievementWithReference `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&detail)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, memberID, detail.Data.StudentID)
+	assert.Equal(t, "Juara 1 Hackathon Nasional", detail.Data.Title)
+	assert.Len(t, detail.Data.TeamMembers, 2)
+
+	// Act
+	updateBytes, _ := json.Marshal(map[string]string{"title": "Juara 2"})
+	req = httptest.NewRequest("PUT", "/member/achievements/"+memberRef, bytes.NewReader(updateBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	// Assert
+	assert.Equal(t, 403, resp.StatusCode)
+
+	// Act
+	mockAchRepo.Submit(memberRef)
+	verifyBytes, _ := json.Marshal(model.VerifyAchievementRequest{Points: 50})
+	req = httptest.NewRequest("POST", "/advisor/achievements/"+memberRef+"/verify", bytes.NewReader(verifyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	member, _ := mockAchRepo.GetAchievementByID(memberRef)
+	leader, _ := mockAchRepo.GetAchievementByID(created.Data.ReferenceID)
+	assert.Equal(t, model.AchievementStatusVerified, member.Status)
+	assert.Equal(t, model.AchievementStatusDraft, leader.Status)
+
+	// Act
+	mockAchRepo.Submit(created.Data.ReferenceID)
+	req = httptest.NewRequest("POST", "/advisor/achievements/"+created.Data.ReferenceID+"/verify", bytes.NewReader(verifyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	// Assert
+	assert.NotEqual(t, 200, resp.StatusCode)
+}
+
+// TestCreateAchievement_TeamInvalidMember tests an unknown NIM or a repeated member is rejected before anything is stored
+func TestCreateAchievement_TeamInvalidMember(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: uuid.New().String(), UserID: userID, StudentID: "111111"})
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		return service.CreateAchievement(c)
+	})
+
+	for _, members := range [][]model.TeamMemberRequest{
+		{{StudentID: "999999"}},
+		{{StudentID: "111111"}},
+	} {
+		bodyBytes, _ := json.Marshal(model.CreateAchievementRequest{AchievementType: "other", Title: "Tim", TeamMembers: members})
+
+		// Act
+		req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		// Assert
+		assert.Equal(t, 400, resp.StatusCode)
+	}
+	all, _ := mockAchRepo.GetAchievementsByStatus(model.AchievementStatusDraft)
+	assert.Empty(t, all)
+}
+
+// teamFixture membuat prestasi tim berisi ketua dan satu anggota, lalu mengembalikan reference keduanya
+func teamFixture(mockAchRepo *repository.MockAchievementRepository) (leader, member *model.AchievementWithReference) {
+	created, _ := mockAchRepo.CreateTeamAchievement(&model.Achievement{
+		AchievementType: model.AchievementTypeOther,
+		Title:           "Juara 1 Hackathon Nasional",
+	}, []*model.TeamMember{
+		{StudentID: uuid.New().String(), Role: model.TeamRoleLeader},
+		{StudentID: uuid.New().String(), Role: model.TeamRoleMember},
+	})
+	leader, _ = mockAchRepo.GetAchievementByID(created.TeamMembers[0].ReferenceID)
+	member, _ = mockAchRepo.GetAchievementByID(created.TeamMembers[1].ReferenceID)
+	return leader, member
+}
+
+// TestVerifyAchievement_TeamPointsPerMember tests each member keeps the points given by their own advisor
+func TestVerifyAchievement_TeamPointsPerMember(t *testing.T) {
+	// Arrange
+	mockAchRepo := repository.NewMockAchievementRepository()
+	service := &achievementServiceImpl{achievementRepo: mockAchRepo}
+	leader, member := teamFixture(mockAchRepo)
+	leader.Status, member.Status = model.AchievementStatusSubmitted, model.AchievementStatusSubmitted
+	transition := &model.AchievementTransition{
+		Action: model.AchievementActionVerify,
+		To:     model.AchievementStatusVerified,
+		Hooks:  []string{"assign_points", "stamp_verifier"},
+	}
+	leaderPoints, memberPoints := 50, 30
+
+	// Act
+	errLeader := service.applyTransition(transition, &transitionContext{achievementID: leader.ReferenceID, achievement: leader, userID: uuid.New().String(), points: &leaderPoints})
+	errMember := service.applyTransition(transition, &transitionContext{achievementID: member.ReferenceID, achievement: member, userID: uuid.New().String(), points: &memberPoints})
+
+	// Assert
+	assert.NoError(t, errLeader)
+	assert.NoError(t, errMember)
+	assert.Equal(t, 50, leader.Points)
+	assert.Equal(t, 30, member.Points)
+}
+
+// TestUpdateAchievement_TeamUpdatesAllReferences tests the leader's edit changes the title and version of every member's reference
+func TestUpdateAchievement_TeamUpdatesAllReferences(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	leader, member := teamFixture(mockAchRepo)
+	member.Points = 30
+	leaderUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: leader.StudentID, UserID: leaderUserID, StudentID: "111111"})
+	app.Put("/achievements/:id", func(c *fiber.Ctx) error {
+		c.Locals("userID", leaderUserID)
+		c.Locals("role", "Mahasiswa")
+		return service.UpdateAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(map[string]string{"title": "Juara 1 Hackathon Nasional 2024"})
+
+	// Act
+	req := httptest.NewRequest("PUT", "/achievements/"+leader.ReferenceID, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, leader.Version)
+	assert.Equal(t, 2, member.Version)
+	assert.Equal(t, "Juara 1 Hackathon Nasional 2024", member.Title)
+	assert.Equal(t, 30, member.Points)
+}
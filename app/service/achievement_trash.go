@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// trashRetention adalah lama prestasi disimpan di trash sebelum dihapus permanen (0 = tidak pernah dihapus)
+var trashRetention = 30 * 24 * time.Hour
+
+// SetTrashRetention mengatur lama penyimpanan prestasi di trash
+func SetTrashRetention(retention time.Duration) {
+	trashRetention = retention
+}
+
+// GetTrash godoc
+// @Summary Dapatkan trash prestasi
+// @Description Mengambil prestasi yang sudah dihapus. Mahasiswa hanya melihat miliknya, admin melihat semua (bisa difilter student_id)
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param student_id query string false "Filter berdasarkan student ID (admin)"
+// @Success 200 {object} model.APIResponse{data=object{achievements=[]model.AchievementWithReference,retention_days=int}} "Trash berhasil diambil"
+// @Failure 403 {object} model.APIResponse "Dosen wali tidak memiliki akses trash"
+// @Failure 404 {object} model.APIResponse "Student tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievements/trash [get]
+func (s *achievementServiceImpl) GetTrash(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	studentID := ""
+	switch role {
+	case "Mahasiswa":
+		student, err := s.studentRepo.GetStudentByUserID(userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil data student",
+			})
+		}
+		if student == nil {
+			return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "student tidak ditemukan",
+			})
+		}
+		studentID = student.ID
+	case "Admin":
+		studentID = c.Query("student_id")
+	default:
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "anda tidak memiliki akses ke trash prestasi",
+		})
+	}
+
+	achievements, err := s.achievementRepo.GetDeletedAchievements(studentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil trash: " + err.Error(),
+		})
+	}
+	if achievements == nil {
+		achievements = []*model.AchievementWithReference{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "trash berhasil diambil",
+		Data: fiber.Map{
+			"achievements":   achievements,
+			"retention_days": int(trashRetention / (24 * time.Hour)),
+		},
+	})
+}
+
+// RestoreAchievement godoc
+// @Summary Pulihkan prestasi dari trash
+// @Description Mengembalikan prestasi yang dihapus ke status sebelum dihapus berdasarkan history
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} model.APIResponse{data=model.AchievementWithReference} "Prestasi berhasil dipulihkan"
+// @Failure 400 {object} model.APIResponse "Prestasi tidak berada di trash"
+// @Failure 401 {object} model.APIResponse "Prestasi bukan milik anda"
+// @Failure 403 {object} model.APIResponse "Dosen wali tidak dapat memulihkan prestasi"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Status prestasi sudah berubah"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/restore [post]
+func (s *achievementServiceImpl) RestoreAchievement(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil {
+		return loadActionError(err, fiber.StatusInternalServerError, "gagal mengambil achievement").respond(c)
+	}
+	if achievement == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "prestasi tidak ditemukan",
+		})
+	}
+
+	if role == "Mahasiswa" {
+		student, err := s.studentRepo.GetStudentByUserID(userID)
+		if err != nil || student == nil || achievement.StudentID != student.ID {
+			return c.Status(fiber.StatusUnauthorized).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "prestasi bukan milik anda",
+			})
+		}
+	}
+
+	if role == "Dosen Wali" {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "dosen wali tidak dapat memulihkan prestasi",
+		})
+	}
+
+	if achievement.Status != model.AchievementStatusDeleted {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "prestasi berstatus " + achievement.Status + " tidak berada di trash",
+		})
+	}
+
+	previousStatus, err := s.statusBeforeDelete(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil history achievement",
+		})
+	}
+
+	// Status tujuan ditentukan dari history, sehingga transisi dibuat di sini, bukan dari definisi workflow
+	transition := &model.AchievementTransition{
+		Action:     model.AchievementActionRestore,
+		From:       []string{model.AchievementStatusDeleted},
+		To:         previousStatus,
+		Permission: "achievement:delete",
+		Hooks:      []string{"clear_deleted"},
+	}
+	if !hasPermission(c, transition.Permission) {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "anda tidak memiliki permission: " + transition.Permission,
+		})
+	}
+
+	tc := &transitionContext{
+		achievementID: achievementID,
+		achievement:   achievement,
+		userID:        userID,
+		note:          "Achievement restored from trash",
+	}
+	if err := s.applyTransition(transition, tc); err != nil {
+		return transitionErrorResponse(c, err, "gagal memulihkan achievement")
+	}
+
+	achievement, _ = s.achievementRepo.GetAchievementByID(achievementID)
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "achievement berhasil dipulihkan",
+		Data:    achievement,
+	})
+}
+
+// statusBeforeDelete mencari status prestasi sebelum penghapusan terakhir; data tanpa history kembali ke draft
+func (s *achievementServiceImpl) statusBeforeDelete(achievementID string) (string, error) {
+	histories, err := s.achievementRepo.GetAchievementHistory(achievementID)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *model.AchievementHistory
+	for _, history := range histories {
+		if history.NewStatus != model.AchievementStatusDeleted || history.OldStatus == model.AchievementStatusDeleted {
+			continue
+		}
+		if latest == nil || history.CreatedAt.After(latest.CreatedAt) {
+			latest = history
+		}
+	}
+	if latest == nil {
+		return model.AchievementStatusDraft, nil
+	}
+	return latest.OldStatus, nil
+}
+
+// PurgeExpiredAchievements menghapus permanen prestasi yang berada di trash lebih lama dari trashRetention,
+// termasuk file attachment di disk. Mengembalikan jumlah prestasi yang terhapus.
+func PurgeExpiredAchievements(achievementRepo repository.AchievementRepository, now time.Time) (int, error) {
+	if trashRetention <= 0 {
+		return 0, nil
+	}
+
+	candidates, err := achievementRepo.GetPurgeCandidates(now.Add(-trashRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, achievement := range candidates {
+		attachments, err := achievementRepo.GetAttachmentsByAchievementID(achievement.ReferenceID)
+		if err != nil {
+			return purged, err
+		}
+		if err := achievementRepo.PurgeAchievement(achievement.ReferenceID); err != nil {
+			// Prestasi yang dipulihkan di tengah proses dilewati
+			if errors.Is(err, repository.ErrAchievementStatusConflict) {
+				continue
+			}
+			return purged, err
+		}
+		// File bukti prestasi tim tetap disimpan selama masih ada anggota lain
+		if achievement.TeamID != nil {
+			remaining, err := achievementRepo.GetTeamMembers(*achievement.TeamID)
+			if err != nil {
+				return purged, err
+			}
+			if len(remaining) > 0 {
+				attachments = nil
+			}
+		}
+		for _, attachment := range attachments {
+			if err := os.Remove("." + attachment.FilePath); err != nil && !os.IsNotExist(err) {
+				log.Println("warning: failed to remove attachment file:", err)
+			}
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// StartTrashPurgeJob menjalankan PurgeExpiredAchievements secara berkala di background
+func StartTrashPurgeJob(achievementRepo repository.AchievementRepository, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purged, err := PurgeExpiredAchievements(achievementRepo, time.Now())
+			if err != nil {
+				log.Println("warning: failed to purge deleted achievements:", err)
+			} else if purged > 0 {
+				log.Printf("🗑️  Purged %d deleted achievements", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
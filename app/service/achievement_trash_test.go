@@ -0,0 +1,87 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRestoreAchievement_FromTrash tests a deleted achievement shows up in the trash and is restored to its previous status
+func TestRestoreAchievement_FromTrash(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	setStudent := func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return c.Next()
+	}
+	app.Delete("/achievements/:id", setStudent, service.DeleteAchievement)
+	app.Get("/achievements/trash", setStudent, service.GetTrash)
+	app.Post("/achievements/:id/restore", setStudent, service.RestoreAchievement)
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/"+achievementID, nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	resp, _ = app.Test(httptest.NewRequest("GET", "/achievements/trash", nil))
+	var trash struct {
+		Data struct {
+			Achievements []*model.AchievementWithReference `json:"achievements"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&trash)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Len(t, trash.Data.Achievements, 1)
+	assert.NotNil(t, trash.Data.Achievements[0].DeletedAt)
+
+	// Act
+	resp, _ = app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/restore", nil))
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	restored, _ := mockAchRepo.GetAchievementByID(achievementID)
+	assert.Equal(t, model.AchievementStatusDraft, restored.Status)
+	assert.Nil(t, restored.DeletedAt)
+
+	resp, _ = app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/restore", nil))
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+// TestPurgeExpiredAchievements tests only achievements past the retention period are removed permanently
+func TestPurgeExpiredAchievements(t *testing.T) {
+	// Arrange
+	mockAchRepo := repository.NewMockAchievementRepository()
+	SetTrashRetention(30 * 24 * time.Hour)
+	expiredID := uuid.New().String()
+	recentID := uuid.New().String()
+	mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Lama"}, expiredID)
+	mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Baru"}, recentID)
+	mockAchRepo.TransitionAchievementStatus(expiredID, model.AchievementStatusDraft, model.AchievementStatusDeleted, 0, map[string]interface{}{"deleted_at": time.Now().Add(-31 * 24 * time.Hour)}, nil)
+	mockAchRepo.TransitionAchievementStatus(recentID, model.AchievementStatusDraft, model.AchievementStatusDeleted, 0, map[string]interface{}{"deleted_at": time.Now().Add(-time.Hour)}, nil)
+
+	// Act
+	purged, err := PurgeExpiredAchievements(mockAchRepo, time.Now())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	expired, _ := mockAchRepo.GetAchievementByID(expiredID)
+	recent, _ := mockAchRepo.GetAchievementByID(recentID)
+	assert.Nil(t, expired)
+	assert.NotNil(t, recent)
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+	"uas_be/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// detailsValidationError membawa error per field dari validasi schema Details
+type detailsValidationError struct {
+	schema *model.AchievementTypeSchema
+	fields []model.FieldError
+}
+
+func (e *detailsValidationError) Error() string {
+	return fmt.Sprintf("details tidak sesuai schema %s versi %d", e.schema.AchievementType, e.schema.Version)
+}
+
+// Unwrap membuat error ini dipetakan ke 400 oleh transitionErrorResponse
+func (e *detailsValidationError) Unwrap() error {
+	return errInvalidTransitionInput
+}
+
+// resolveDetailsSchema mengambil schema Details tipe prestasi; version 0 berarti versi terbaru.
+// Tipe bawaan memiliki versi 1 di aplikasi, versi berikutnya (dan semua versi tipe baru) diterbitkan admin.
+func resolveDetailsSchema(repo repository.AchievementRepository, achievementType string, version int) (*model.AchievementTypeSchema, error) {
+	if version == 1 {
+		if schema := model.DefaultDetailsSchema(achievementType); schema != nil {
+			return schema, nil
+		}
+	}
+
+	schema, err := repo.GetAchievementTypeSchema(achievementType, version)
+	if err != nil || schema != nil {
+		return schema, err
+	}
+	if version == 0 {
+		return model.DefaultDetailsSchema(achievementType), nil
+	}
+	return nil, nil
+}
+
+// validateAchievementDetails memvalidasi Details terhadap schema terbaru tipenya dan mencatat versinya.
+// Tipe tanpa schema tidak divalidasi.
+func validateAchievementDetails(repo repository.AchievementRepository, achievement *model.Achievement) error {
+	schema, err := resolveDetailsSchema(repo, achievement.AchievementType, 0)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	if fields := schema.Schema.ValidateDetails(achievement.Details); len(fields) > 0 {
+		return &detailsValidationError{schema: schema, fields: fields}
+	}
+
+	achievement.SchemaVersion = schema.Version
+	return nil
+}
+
+// checkAchievementType memastikan tipe ada di katalog dan masih aktif. Status 0 berarti valid.
+func checkAchievementType(repo repository.AchievementRepository, code string) (*model.AchievementTypeDefinition, int, string) {
+	achievementType, err := repo.GetAchievementType(code)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal mengambil tipe prestasi"
+	}
+	if achievementType == nil {
+		return nil, fiber.StatusBadRequest, "achievement_type " + code + " tidak dikenal"
+	}
+	if !achievementType.IsActive {
+		return nil, fiber.StatusBadRequest, "achievement_type " + code + " sudah tidak aktif"
+	}
+	return achievementType, 0, ""
+}
+
+type AchievementTypeService interface {
+	GetAchievementTypes(c *fiber.Ctx) error
+	GetAchievementType(c *fiber.Ctx) error
+	CreateAchievementType(c *fiber.Ctx) error
+	UpdateAchievementType(c *fiber.Ctx) error
+	RetireAchievementType(c *fiber.Ctx) error
+	GetTypeSchema(c *fiber.Ctx) error
+	PublishTypeSchema(c *fiber.Ctx) error
+}
+
+type achievementTypeServiceImpl struct {
+	achievementRepo repository.AchievementRepository
+}
+
+func NewAchievementTypeService(achievementRepo repository.AchievementRepository) AchievementTypeService {
+	return &achievementTypeServiceImpl{
+		achievementRepo: achievementRepo,
+	}
+}
+
+// GetAchievementTypes godoc
+// @Summary Dapatkan katalog tipe prestasi
+// @Description Mengambil daftar tipe prestasi beserta nama Indonesia/Inggris dan pengaturannya
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param include_retired query bool false "Sertakan tipe yang sudah dipensiunkan"
+// @Success 200 {object} model.APIResponse{data=[]model.AchievementTypeDefinition} "Tipe prestasi berhasil diambil"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievement-types [get]
+func (s *achievementTypeServiceImpl) GetAchievementTypes(c *fiber.Ctx) error {
+	types, err := s.achievementRepo.GetAchievementTypes(!c.QueryBool("include_retired", false))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tipe prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tipe prestasi berhasil diambil",
+		Data:    types,
+	})
+}
+
+// GetAchievementType godoc
+// @Summary Dapatkan tipe prestasi
+// @Description Mengambil satu tipe prestasi berdasarkan kode
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Kode tipe prestasi"
+// @Success 200 {object} model.APIResponse{data=model.AchievementTypeDefinition} "Tipe prestasi berhasil diambil"
+// @Failure 404 {object} model.APIResponse "Tipe prestasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievement-types/{type} [get]
+func (s *achievementTypeServiceImpl) GetAchievementType(c *fiber.Ctx) error {
+	achievementType, err := s.achievementRepo.GetAchievementType(c.Params("type"))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tipe prestasi: "+err.Error())
+	}
+	if achievementType == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "tipe prestasi tidak ditemukan")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tipe prestasi berhasil diambil",
+		Data:    achievementType,
+	})
+}
+
+// CreateAchievementType godoc
+// @Summary Tambah tipe prestasi
+// @Description Menambah tipe prestasi baru ke katalog tanpa perlu deploy ulang (admin)
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body model.CreateAchievementTypeRequest true "Data tipe prestasi"
+// @Success 201 {object} model.APIResponse{data=model.AchievementTypeDefinition} "Tipe prestasi berhasil dibuat"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid atau field wajib tidak diisi"
+// @Failure 409 {object} model.APIResponse "Kode tipe sudah dipakai"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievement-types [post]
+func (s *achievementTypeServiceImpl) CreateAchievementType(c *fiber.Ctx) error {
+	req := new(model.CreateAchievementTypeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+
+	if !model.IsValidAchievementTypeCode(req.Code) {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "code harus diawali huruf kecil dan hanya berisi huruf kecil, angka, atau underscore")
+	}
+	if strings.TrimSpace(req.NameID) == "" || strings.TrimSpace(req.NameEN) == "" {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "name_id dan name_en harus diisi")
+	}
+
+	if req.EvidenceRules.MinAttachments < 0 {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "evidence_rules.min_attachments tidak boleh negatif")
+	}
+
+	existing, err := s.achievementRepo.GetAchievementType(req.Code)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tipe prestasi: "+err.Error())
+	}
+	if existing != nil {
+		return helper.ErrorResponse(c, fiber.StatusConflict, "kode tipe "+req.Code+" sudah dipakai")
+	}
+
+	achievementType := &model.AchievementTypeDefinition{
+		Code:               req.Code,
+		NameID:             strings.TrimSpace(req.NameID),
+		NameEN:             strings.TrimSpace(req.NameEN),
+		Description:        req.Description,
+		RequiresAttachment: req.RequiresAttachment,
+		EvidenceRules:      req.EvidenceRules.Normalize(),
+	}
+	if err := s.achievementRepo.CreateAchievementType(achievementType); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal membuat tipe prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tipe prestasi berhasil dibuat",
+		Data:    achievementType,
+	})
+}
+
+// UpdateAchievementType godoc
+// @Summary Ubah tipe prestasi
+// @Description Mengubah nama, pengaturan lampiran, atau mengaktifkan kembali tipe prestasi (admin)
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Kode tipe prestasi"
+// @Param body body model.UpdateAchievementTypeRequest true "Data yang diubah"
+// @Success 200 {object} model.APIResponse{data=model.AchievementTypeDefinition} "Tipe prestasi berhasil diubah"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid"
+// @Failure 404 {object} model.APIResponse "Tipe prestasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievement-types/{type} [put]
+func (s *achievementTypeServiceImpl) UpdateAchievementType(c *fiber.Ctx) error {
+	req := new(model.UpdateAchievementTypeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+
+	existing, err := s.achievementRepo.GetAchievementType(c.Params("type"))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tipe prestasi: "+err.Error())
+	}
+	if existing == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "tipe prestasi tidak ditemukan")
+	}
+
+	updated := *existing
+	if req.NameID != nil {
+		updated.NameID = strings.TrimSpace(*req.NameID)
+	}
+	if req.NameEN != nil {
+		updated.NameEN = strings.TrimSpace(*req.NameEN)
+	}
+	if req.Description != nil {
+		updated.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		updated.IsActive = *req.IsActive
+	}
+	if req.RequiresAttachment != nil {
+		updated.RequiresAttachment = *req.RequiresAttachment
+	}
+	if req.EvidenceRules != nil {
+		if req.EvidenceRules.MinAttachments < 0 {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "evidence_rules.min_attachments tidak boleh negatif")
+		}
+		updated.EvidenceRules = req.EvidenceRules.Normalize()
+	}
+	if updated.NameID == "" || updated.NameEN == "" {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "name_id dan name_en tidak boleh kosong")
+	}
+
+	if err := s.achievementRepo.UpdateAchievementType(&updated); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengubah tipe prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tipe prestasi berhasil diubah",
+		Data:    &updated,
+	})
+}
+
+// RetireAchievementType godoc
+// @Summary Pensiunkan tipe prestasi
+// @Description Menonaktifkan tipe prestasi. Prestasi lama tetap tersimpan dan bisa difilter, tetapi tipe ini tidak bisa dipakai untuk prestasi baru (admin)
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Kode tipe prestasi"
+// @Success 200 {object} model.APIResponse{data=model.AchievementTypeDefinition} "Tipe prestasi berhasil dipensiunkan"
+// @Failure 404 {object} model.APIResponse "Tipe prestasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievement-types/{type} [delete]
+func (s *achievementTypeServiceImpl) RetireAchievementType(c *fiber.Ctx) error {
+	existing, err := s.achievementRepo.GetAchievementType(c.Params("type"))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tipe prestasi: "+err.Error())
+	}
+	if existing == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "tipe prestasi tidak ditemukan")
+	}
+
+	retired := *existing
+	retired.IsActive = false
+	if err := s.achievementRepo.UpdateAchievementType(&retired); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mempensiunkan tipe prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tipe prestasi berhasil dipensiunkan",
+		Data:    &retired,
+	})
+}
+
+// GetTypeSchema godoc
+// @Summary Dapatkan schema details tipe prestasi
+// @Description Mengambil JSON Schema untuk field details tipe prestasi, dipakai frontend untuk menampilkan form.
+// @Description Gunakan parameter version untuk membaca prestasi lama sesuai schema_version-nya.
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Tipe prestasi"
+// @Param version query int false "Versi schema (default: terbaru)"
+// @Success 200 {object} model.APIResponse{data=model.AchievementTypeSchema} "Schema berhasil diambil"
+// @Failure 400 {object} model.APIResponse "Versi tidak valid"
+// @Failure 404 {object} model.APIResponse "Schema tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievement-types/{type}/schema [get]
+func (s *achievementTypeServiceImpl) GetTypeSchema(c *fiber.Ctx) error {
+	version := 0
+	if v := c.Query("version"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "version harus berupa angka >= 1")
+		}
+		version = parsed
+	}
+
+	schema, err := resolveDetailsSchema(s.achievementRepo, c.Params("type"), version)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil schema: "+err.Error())
+	}
+	if schema == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "schema tidak ditemukan")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "schema berhasil diambil",
+		Data:    schema,
+	})
+}
+
+// PublishTypeSchema godoc
+// @Summary Terbitkan versi baru schema details
+// @Description Menyimpan versi baru JSON Schema details untuk tipe prestasi (admin). Versi lama tetap bisa dibaca.
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Tipe prestasi"
+// @Param body body model.DetailsSchema true "JSON Schema details"
+// @Success 201 {object} model.APIResponse{data=model.AchievementTypeSchema} "Schema berhasil diterbitkan"
+// @Failure 400 {object} model.APIResponse "Schema tidak valid"
+// @Failure 409 {object} model.APIResponse "Schema tipe yang sama sedang diterbitkan bersamaan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /achievement-types/{type}/schema [post]
+func (s *achievementTypeServiceImpl) PublishTypeSchema(c *fiber.Ctx) error {
+	achievementType := c.Params("type")
+	if _, status, message := checkAchievementType(s.achievementRepo, achievementType); status != 0 {
+		return helper.ErrorResponse(c, status, message)
+	}
+
+	schema := new(model.DetailsSchema)
+	if err := c.BodyParser(schema); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+	if schema.Type != "object" {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "schema details harus bertipe object")
+	}
+	if err := schema.Check(); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "schema tidak valid: "+err.Error())
+	}
+
+	// Versi final dihitung oleh repository; versi dari schema terbaru (termasuk schema bawaan) menjadi batas bawah
+	latest, err := resolveDetailsSchema(s.achievementRepo, achievementType, 0)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil schema: "+err.Error())
+	}
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	published := &model.AchievementTypeSchema{
+		AchievementType: achievementType,
+		Version:         version,
+		Schema:          schema,
+	}
+	if err := s.achievementRepo.CreateAchievementTypeSchema(published); err != nil {
+		if errors.Is(err, repository.ErrSchemaVersionConflict) {
+			return helper.ErrorResponse(c, fiber.StatusConflict, err.Error())
+		}
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal menyimpan schema: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "schema berhasil diterbitkan",
+		Data:    published,
+	})
+}
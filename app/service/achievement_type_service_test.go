@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateAchievement_InvalidDetails tests details are validated against the type schema with field errors
+func TestCreateAchievement_InvalidDetails(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: uuid.New().String(), UserID: userID, StudentID: "123456"})
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		return service.CreateAchievement(c)
+	})
+	bodyBytes, _ := json.Marshal(model.CreateAchievementRequest{
+		AchievementType: "competition",
+		Title:           "Lomba Debat",
+		Details:         map[string]interface{}{"competition_name": "Debat Nasional", "level": "galaxy"},
+	})
+	// Act
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	var result struct {
+		Data []model.FieldError `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	// Assert
+	assert.Equal(t, 400, resp.StatusCode)
+	fields := make([]string, 0, len(result.Data))
+	for _, fieldErr := range result.Data {
+		fields = append(fields, fieldErr.Field)
+	}
+	assert.ElementsMatch(t, []string{"details.rank", "details.level"}, fields)
+}
+
+// TestPublishTypeSchema_Versioned tests a new schema version is used for validation while old versions stay readable
+func TestPublishTypeSchema_Versioned(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	service := NewAchievementTypeService(mockAchRepo)
+	setAdmin := func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New().String())
+		c.Locals("role", "Admin")
+		return c.Next()
+	}
+	app.Get("/achievement-types/:type/schema", setAdmin, service.GetTypeSchema)
+	app.Post("/achievement-types/:type/schema", setAdmin, service.PublishTypeSchema)
+	getSchema := func(query string) *model.AchievementTypeSchema {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievement-types/other/schema"+query, nil))
+		var result struct {
+			Data *model.AchievementTypeSchema `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return result.Data
+	}
+	bodyBytes := []byte(`{"type": "object", "required": ["category"], "properties": {"category": {"type": "string"}}}`)
+
+	// Act
+	req := httptest.NewRequest("POST", "/achievement-types/other/schema", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 201, resp.StatusCode)
+	if latest := getSchema(""); assert.NotNil(t, latest) {
+		assert.Equal(t, 2, latest.Version)
+		assert.Equal(t, []string{"category"}, latest.Schema.Required)
+	}
+	if original := getSchema("?version=1"); assert.NotNil(t, original) {
+		assert.Empty(t, original.Schema.Required)
+	}
+
+	// Act
+	achievement := &model.Achievement{AchievementType: "other", Details: map[string]interface{}{"category": "Pengabdian"}}
+	err := validateAchievementDetails(mockAchRepo, achievement)
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, achievement.SchemaVersion)
+}
+
+// conflictingSchemaRepository mensimulasikan penerbitan schema lain yang tersimpan lebih dulu dengan versi yang sama
+type conflictingSchemaRepository struct {
+	*repository.MockAchievementRepository
+}
+
+func (r *conflictingSchemaRepository) CreateAchievementTypeSchema(schema *model.AchievementTypeSchema) error {
+	return repository.ErrSchemaVersionConflict
+}
+
+// TestPublishTypeSchema_ConcurrentConflict tests a publish that loses to a concurrent publish returns 409 instead of 500
+func TestPublishTypeSchema_ConcurrentConflict(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	service := NewAchievementTypeService(&conflictingSchemaRepository{repository.NewMockAchievementRepository()})
+	app.Post("/achievement-types/:type/schema", service.PublishTypeSchema)
+	bodyBytes := []byte(`{"type": "object", "properties": {"category": {"type": "string"}}}`)
+
+	// Act
+	req := httptest.NewRequest("POST", "/achievement-types/other/schema", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	// Assert
+	assert.Equal(t, 409, resp.StatusCode)
+}
+
+// TestAchievementTypeCatalog_CreateAndRetire tests a new type can be used right away and is rejected after it is retired
+func TestAchievementTypeCatalog_CreateAndRetire(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	typeService := NewAchievementTypeService(mockAchRepo)
+	achievementService := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: uuid.New().String(), UserID: userID, StudentID: "123456"})
+	app.Post("/achievement-types", typeService.CreateAchievementType)
+	app.Delete("/achievement-types/:type", typeService.RetireAchievementType)
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		return achievementService.CreateAchievement(c)
+	})
+	createAchievement := func() int {
+		bodyBytes, _ := json.Marshal(model.CreateAchievementRequest{AchievementType: "community_service", Title: "Bakti Desa"})
+		req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	// Act & Assert: tipe belum ada di katalog
+	assert.Equal(t, 400, createAchievement())
+
+	bodyBytes, _ := json.Marshal(model.CreateAchievementTypeRequest{Code: "community_service", NameID: "Pengabdian Masyarakat", NameEN: "Community Service"})
+	req := httptest.NewRequest("POST", "/achievement-types", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, 201, createAchievement())
+
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/achievement-types/community_service", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 400, createAchievement())
+
+	retired, _ := mockAchRepo.GetAchievementType("community_service")
+	assert.False(t, retired.IsActive)
+}
+
+// TestSubmitAchievement_RequiredAttachment tests types that require an attachment cannot be submitted without one
+func TestSubmitAchievement_RequiredAttachment(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	mockAchRepo.CreateAchievementType(&model.AchievementTypeDefinition{Code: "entrepreneurship", NameID: "Kewirausahaan", NameEN: "Entrepreneurship", RequiresAttachment: true})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "entrepreneurship", Title: "Startup Kampus"}, studentID)
+	achievementID := achWithRef.StudentID
+	app.Post("/achievements/:id/submit", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return service.SubmitAchievement(c)
+	})
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/submit", nil))
+	// Assert
+	assert.Equal(t, 400, resp.StatusCode)
+
+	// Act
+	mockAchRepo.CreateAttachment(&model.AchievementAttachment{AchievementID: achievementID, FileName: "proposal.pdf"})
+	resp, _ = app.Test(httptest.NewRequest("POST", "/achievements/"+achievementID+"/submit", nil))
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"strconv"
+	"uas_be/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAchievementVersions godoc
+// @Summary Dapatkan versi isi prestasi
+// @Description Mengambil salinan isi prestasi yang disimpan setiap kali prestasi disubmit, dari versi 1
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} model.APIResponse{data=[]model.AchievementSnapshot} "Versi prestasi berhasil diambil"
+// @Failure 401 {object} model.APIResponse "Unauthorized"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/versions [get]
+func (s *achievementServiceImpl) GetAchievementVersions(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	if actionErr := s.authorizeVersionAccess(c, achievementID); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	snapshots, err := s.achievementRepo.GetAchievementSnapshots(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil versi prestasi",
+		})
+	}
+	if snapshots == nil {
+		snapshots = []*model.AchievementSnapshot{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "versi prestasi berhasil diambil",
+		Data:    snapshots,
+	})
+}
+
+// GetAchievementVersionDiff godoc
+// @Summary Bandingkan dua versi isi prestasi
+// @Description Menampilkan perubahan per field (termasuk details dan tags) antara dua versi. Tanpa parameter, versi terakhir dibandingkan dengan versi sebelumnya.
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param from query int false "Versi awal, default versi sebelum to"
+// @Param to query int false "Versi akhir, default versi terakhir"
+// @Success 200 {object} model.APIResponse{data=model.AchievementVersionDiff} "Perbandingan versi berhasil diambil"
+// @Failure 400 {object} model.APIResponse "Parameter versi tidak valid atau versi belum cukup"
+// @Failure 401 {object} model.APIResponse "Unauthorized"
+// @Failure 404 {object} model.APIResponse "Prestasi atau versi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/versions/diff [get]
+func (s *achievementServiceImpl) GetAchievementVersionDiff(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	if actionErr := s.authorizeVersionAccess(c, achievementID); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	snapshots, err := s.achievementRepo.GetAchievementSnapshots(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil versi prestasi",
+		})
+	}
+
+	to, err := strconv.Atoi(c.Query("to", strconv.Itoa(len(snapshots))))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "parameter to harus berupa angka",
+		})
+	}
+	from, err := strconv.Atoi(c.Query("from", strconv.Itoa(to-1)))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "parameter from harus berupa angka",
+		})
+	}
+	if from < 1 || to < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "perbandingan membutuhkan dua versi, prestasi ini baru memiliki " + strconv.Itoa(len(snapshots)) + " versi",
+		})
+	}
+
+	byVersion := make(map[int]*model.AchievementSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		byVersion[snapshot.Version] = snapshot
+	}
+	fromSnapshot, toSnapshot := byVersion[from], byVersion[to]
+	if fromSnapshot == nil || toSnapshot == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "versi prestasi tidak ditemukan",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "perbandingan versi berhasil diambil",
+		Data: model.AchievementVersionDiff{
+			From:    from,
+			To:      to,
+			Changes: model.DiffSnapshotContent(fromSnapshot.Content, toSnapshot.Content),
+		},
+	})
+}
+
+// authorizeVersionAccess memastikan prestasi ada dan boleh dilihat: mahasiswa hanya miliknya sendiri,
+// dosen wali hanya prestasi mahasiswa bimbingannya (langsung atau lewat pelimpahan)
+func (s *achievementServiceImpl) authorizeVersionAccess(c *fiber.Ctx, achievementID string) *actionError {
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil {
+		return loadActionError(err, fiber.StatusInternalServerError, "gagal mengambil achievement")
+	}
+	if achievement == nil || achievement.Status == model.AchievementStatusDeleted {
+		return newActionError(fiber.StatusNotFound, "prestasi tidak ditemukan")
+	}
+
+	switch role {
+	case "Mahasiswa":
+		student, err := s.studentRepo.GetStudentByUserID(userID)
+		if err != nil || student == nil || achievement.StudentID != student.ID {
+			return newActionError(fiber.StatusUnauthorized, "unauthorized")
+		}
+	case "Dosen Wali":
+		if _, status, message := s.authorizeAdvisor(userID, achievement); status != 0 {
+			return newActionError(status, message)
+		}
+	}
+	return nil
+}
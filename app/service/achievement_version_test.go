@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// versionFixture menyiapkan prestasi draft milik seorang mahasiswa beserta route submit, update, dan riwayat versinya
+type versionFixture struct {
+	app           *fiber.App
+	mockAchRepo   *repository.MockAchievementRepository
+	achievementID string
+}
+
+func newVersionFixture() *versionFixture {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	mockAchRepo.Create(&model.Achievement{
+		AchievementType: "other",
+		Title:           "Relawan Bencana",
+		Details:         map[string]interface{}{"location": "Malang", "hours": 20},
+		Tags:            []string{"sosial", "relawan"},
+	}, studentID)
+	setStudent := func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:submit"})
+		return c.Next()
+	}
+	app.Post("/achievements/:id/submit", setStudent, service.SubmitAchievement)
+	app.Put("/achievements/:id", setStudent, service.UpdateAchievement)
+	app.Get("/achievements/:id/versions", setStudent, service.GetAchievementVersions)
+	app.Get("/achievements/:id/versions/diff", setStudent, service.GetAchievementVersionDiff)
+	return &versionFixture{app: app, mockAchRepo: mockAchRepo, achievementID: studentID}
+}
+
+func (f *versionFixture) submit() int {
+	resp, _ := f.app.Test(httptest.NewRequest("POST", "/achievements/"+f.achievementID+"/submit", nil))
+	return resp.StatusCode
+}
+
+// resubmitAfterRevision mengembalikan prestasi ke mahasiswa, mengubah title, details, dan tags, lalu submit ulang
+func (f *versionFixture) resubmitAfterRevision() {
+	f.mockAchRepo.TransitionAchievementStatus(f.achievementID, model.AchievementStatusSubmitted, model.AchievementStatusRevisionRequested, 0, nil, nil)
+	updateBytes, _ := json.Marshal(map[string]interface{}{
+		"title":   "Relawan Bencana Banjir",
+		"details": map[string]interface{}{"location": "Malang", "hours": 24, "organizer": "PMI"},
+		"tags":    []string{"relawan", "kemanusiaan"},
+	})
+	req := httptest.NewRequest("PUT", "/achievements/"+f.achievementID, bytes.NewReader(updateBytes))
+	req.Header.Set("Content-Type", "application/json")
+	f.app.Test(req)
+	f.submit()
+}
+
+// TestSubmitAchievement_SnapshotsContent tests submitting stores a snapshot of the submitted content
+func TestSubmitAchievement_SnapshotsContent(t *testing.T) {
+	// Arrange
+	f := newVersionFixture()
+
+	// Act
+	status := f.submit()
+
+	// Assert
+	assert.Equal(t, 200, status)
+	snapshots, _ := f.mockAchRepo.GetAchievementSnapshots(f.achievementID)
+	if assert.Len(t, snapshots, 1) {
+		assert.Equal(t, "Relawan Bencana", snapshots[0].Content.Title)
+	}
+}
+
+// TestGetAchievementVersionDiff_SingleVersion tests a diff needs at least two submitted versions
+func TestGetAchievementVersionDiff_SingleVersion(t *testing.T) {
+	// Arrange
+	f := newVersionFixture()
+	f.submit()
+
+	// Act
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/achievements/"+f.achievementID+"/versions/diff", nil))
+
+	// Assert
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+// TestGetAchievementVersions_ListsSubmissions tests every submission is listed as a numbered version
+func TestGetAchievementVersions_ListsSubmissions(t *testing.T) {
+	// Arrange
+	f := newVersionFixture()
+	f.submit()
+	f.resubmitAfterRevision()
+
+	// Act
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/achievements/"+f.achievementID+"/versions", nil))
+	var versions struct {
+		Data []*model.AchievementSnapshot `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&versions)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.Len(t, versions.Data, 2) {
+		assert.Equal(t, "Relawan Bencana", versions.Data[0].Content.Title)
+		assert.Equal(t, 2, versions.Data[1].Version)
+	}
+}
+
+// TestGetAchievementVersionDiff_ChangedFields tests the diff shows what changed after a revision request
+func TestGetAchievementVersionDiff_ChangedFields(t *testing.T) {
+	// Arrange
+	f := newVersionFixture()
+	f.submit()
+	f.resubmitAfterRevision()
+
+	// Act
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/achievements/"+f.achievementID+"/versions/diff?from=1&to=2", nil))
+	var diff struct {
+		Data model.AchievementVersionDiff `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&diff)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	changes := make(map[string]*model.FieldChange)
+	for _, change := range diff.Data.Changes {
+		changes[change.Field] = change
+	}
+	assert.Len(t, changes, 4)
+	assert.Equal(t, "Relawan Bencana Banjir", changes["title"].New)
+	assert.Equal(t, []string{"kemanusiaan"}, changes["tags"].Added)
+	assert.Equal(t, []string{"sosial"}, changes["tags"].Removed)
+	assert.Equal(t, model.FieldChangeModified, changes["details.hours"].Change)
+	assert.Equal(t, model.FieldChangeAdded, changes["details.organizer"].Change)
+	assert.Nil(t, changes["details.location"])
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// achievementWorkflow adalah state machine status prestasi yang dipakai semua endpoint transisi
+var achievementWorkflow = model.DefaultAchievementWorkflow()
+
+// maxResubmissions adalah batas berapa kali prestasi yang ditolak boleh dibuka kembali (0 = tanpa batas)
+var maxResubmissions = 3
+
+// errInvalidTransitionInput menandai input transisi yang ditolak oleh hook (dikembalikan sebagai 400)
+var errInvalidTransitionInput = errors.New("input transisi tidak valid")
+
+// transitionContext membawa data satu kali eksekusi transisi ke setiap hook
+type transitionContext struct {
+	achievementID string
+	achievement   *model.AchievementWithReference
+	userID        string
+	note          string                             // Catatan yang disimpan ke history
+	points        *int                               // Diisi oleh endpoint verifikasi
+	justification string                             // Alasan jika poin menyimpang dari rubrik
+	rejectionNote string                             // Diisi oleh endpoint penolakan
+	comments      []model.AchievementRevisionComment // Diisi oleh endpoint permintaan revisi
+	approval      *model.AchievementApproval         // Diisi jika prestasi melewati approval chain
+	onBehalfOf    *model.Lecturer                    // Dosen wali asli jika aksi dilakukan lewat pelimpahan
+	duplicates    []*model.DuplicateMatch            // Diisi oleh hook flag_duplicates untuk ditampilkan di response
+	stampedAt     time.Time                          // Waktu tahap SLA, diisi oleh scheduler SLA
+	fields        map[string]interface{}
+	effects       model.AchievementTransitionEffects // Ditulis bersama perubahan status dalam satu transaksi
+}
+
+// transitionHook dijalankan sebelum status disimpan. Hook hanya memvalidasi dan mengisi tc.fields atau
+// tc.effects; tidak ada yang ditulis sebelum perubahan status berhasil.
+type transitionHook func(s *achievementServiceImpl, tc *transitionContext) error
+
+// transitionHooks adalah registry hook yang bisa dirujuk namanya dari definisi workflow
+var transitionHooks = map[string]transitionHook{
+	"validate_details": func(s *achievementServiceImpl, tc *transitionContext) error {
+		return validateAchievementDetails(s.achievementRepo, &tc.achievement.Achievement)
+	},
+	"check_evidence": checkEvidence,
+	// Nama lama, tetap didukung agar file workflow yang sudah ada tidak perlu diubah
+	"check_required_attachment": checkEvidence,
+	"flag_duplicates": func(s *achievementServiceImpl, tc *transitionContext) error {
+		duplicates, err := s.findDuplicates(tc.achievement)
+		if err != nil {
+			// Deteksi duplikat hanya membantu verifikator, kegagalannya tidak membatalkan submit
+			log.Println("warning: failed to check duplicate achievements:", err)
+			return nil
+		}
+		tc.duplicates = duplicates
+		tc.fields["possible_duplicate"] = len(duplicates) > 0
+		return nil
+	},
+	"snapshot_content": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.effects.Snapshot = &model.AchievementSnapshot{
+			AchievementID: tc.achievementID,
+			Content:       model.NewAchievementSnapshotContent(&tc.achievement.Achievement),
+			CreatedBy:     tc.userID,
+		}
+		return nil
+	},
+	"stamp_submitted": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.fields["submitted_at"] = time.Now()
+		// SLA verifikasi dihitung ulang untuk setiap submit
+		tc.fields["sla_reminded_at"] = nil
+		tc.fields["escalated_at"] = nil
+		return nil
+	},
+	"stamp_sla_reminded": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.fields["sla_reminded_at"] = tc.stampedAt
+		return nil
+	},
+	"stamp_escalated": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.fields["escalated_at"] = tc.stampedAt
+		return nil
+	},
+	"stamp_verifier": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.fields["verified_at"] = time.Now()
+		tc.fields["verified_by"] = tc.userID
+		return nil
+	},
+	"set_rejection_note": func(s *achievementServiceImpl, tc *transitionContext) error {
+		if tc.rejectionNote == "" {
+			return fmt.Errorf("%w: rejection_note tidak boleh kosong", errInvalidTransitionInput)
+		}
+		tc.fields["rejection_note"] = tc.rejectionNote
+		return nil
+	},
+	"stamp_deleted": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.fields["deleted_at"] = time.Now()
+		return nil
+	},
+	"clear_deleted": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.fields["deleted_at"] = nil
+		return nil
+	},
+	"record_revision_request": func(s *achievementServiceImpl, tc *transitionContext) error {
+		if err := validateRevisionComments(tc.comments); err != nil {
+			return err
+		}
+		previous, err := s.achievementRepo.GetRevisionRequests(tc.achievementID)
+		if err != nil {
+			return err
+		}
+		// Putaran baru tidak bisa dibuat bersamaan karena transisi lain dari status yang sama akan ditolak
+		tc.effects.RevisionRequest = &model.AchievementRevisionRequest{
+			AchievementID: tc.achievementID,
+			Round:         len(previous) + 1,
+			Comments:      tc.comments,
+			RequestedBy:   tc.userID,
+		}
+		tc.note = fmt.Sprintf("[round %d] %s", tc.effects.RevisionRequest.Round, tc.note)
+		return nil
+	},
+	"resolve_revision_requests": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.effects.ResolveRevisionRequests = true
+		return nil
+	},
+	"count_resubmission": func(s *achievementServiceImpl, tc *transitionContext) error {
+		if maxResubmissions > 0 && tc.achievement.ResubmissionCount >= maxResubmissions {
+			return fmt.Errorf("%w: batas pengajuan ulang (%d kali) sudah tercapai", errInvalidTransitionInput, maxResubmissions)
+		}
+		// Catatan penolakan lama tetap tersimpan di history, reference dikosongkan untuk putaran baru
+		tc.fields["resubmission_count"] = tc.achievement.ResubmissionCount + 1
+		tc.fields["rejection_note"] = nil
+		tc.fields["verified_at"] = nil
+		tc.fields["verified_by"] = nil
+		return nil
+	},
+	"record_stage_approval": func(s *achievementServiceImpl, tc *transitionContext) error {
+		// Prestasi tanpa approval chain cukup diverifikasi satu tahap
+		tc.effects.Approval = tc.approval
+		return nil
+	},
+	"reset_approvals": func(s *achievementServiceImpl, tc *transitionContext) error {
+		tc.effects.ResetApprovals = true
+		return nil
+	},
+	"check_points_rubric": func(s *achievementServiceImpl, tc *transitionContext) error {
+		// Validasi points kosong/negatif dilakukan oleh assign_points
+		if tc.points == nil {
+			return nil
+		}
+		suggestion, err := s.suggestPoints(&tc.achievement.Achievement)
+		if err != nil || suggestion == nil || !suggestion.Deviates(*tc.points) {
+			return err
+		}
+		justification := strings.TrimSpace(tc.justification)
+		if justification == "" {
+			return fmt.Errorf("%w: poin %d menyimpang dari rubrik (%d ± %d), justification wajib diisi",
+				errInvalidTransitionInput, *tc.points, suggestion.Points, suggestion.Tolerance)
+		}
+		tc.note = fmt.Sprintf("%s (rubric suggests %d points; justification: %s)", tc.note, suggestion.Points, justification)
+		return nil
+	},
+	"assign_points": func(s *achievementServiceImpl, tc *transitionContext) error {
+		if tc.points == nil {
+			return fmt.Errorf("%w: points wajib diisi", errInvalidTransitionInput)
+		}
+		if *tc.points < 0 {
+			return fmt.Errorf("%w: poin tidak boleh negatif", errInvalidTransitionInput)
+		}
+		// Poin disimpan di reference agar tiap anggota prestasi tim dinilai oleh dosen walinya sendiri.
+		// Dokumen bersama hanya diisi untuk prestasi individu.
+		tc.fields["points"] = *tc.points
+		if tc.achievement.TeamID == nil {
+			tc.effects.DocumentFields = map[string]interface{}{"points": *tc.points}
+		}
+		return nil
+	},
+}
+
+// InitAchievementWorkflow memuat definisi workflow dari file JSON. Jika path kosong,
+// workflow bawaan (draft -> submitted -> verified/rejected) tetap dipakai.
+func InitAchievementWorkflow(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("gagal membaca file workflow: %w", err)
+	}
+
+	var workflow model.AchievementWorkflow
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return fmt.Errorf("format file workflow tidak valid: %w", err)
+	}
+
+	if err := validateAchievementWorkflow(&workflow); err != nil {
+		return err
+	}
+
+	achievementWorkflow = &workflow
+	return nil
+}
+
+// SetAchievementResubmissionLimit mengatur batas pembukaan ulang prestasi yang ditolak (0 = tanpa batas)
+func SetAchievementResubmissionLimit(limit int) {
+	maxResubmissions = limit
+}
+
+// validateAchievementWorkflow memastikan setiap transisi lengkap dan hanya merujuk hook yang terdaftar
+func validateAchievementWorkflow(workflow *model.AchievementWorkflow) error {
+	if len(workflow.Transitions) == 0 {
+		return errors.New("workflow harus memiliki minimal satu transisi")
+	}
+
+	for _, t := range workflow.Transitions {
+		if t.Action == "" || t.To == "" || len(t.From) == 0 {
+			return fmt.Errorf("transisi %q harus memiliki action, from, dan to", t.Action)
+		}
+		if t.Permission == "" {
+			return fmt.Errorf("transisi %q harus memiliki permission", t.Action)
+		}
+		for _, hook := range t.Hooks {
+			if _, ok := transitionHooks[hook]; !ok {
+				return fmt.Errorf("transisi %q merujuk hook yang tidak dikenal: %s", t.Action, hook)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRevisionComments memastikan permintaan revisi berisi komentar untuk field yang dikenal
+func validateRevisionComments(comments []model.AchievementRevisionComment) error {
+	if len(comments) == 0 {
+		return fmt.Errorf("%w: comments tidak boleh kosong", errInvalidTransitionInput)
+	}
+
+	for _, comment := range comments {
+		if strings.TrimSpace(comment.Comment) == "" {
+			return fmt.Errorf("%w: komentar untuk field %s tidak boleh kosong", errInvalidTransitionInput, comment.Field)
+		}
+		if !isRevisionCommentField(comment.Field) {
+			return fmt.Errorf("%w: field %s tidak dikenal", errInvalidTransitionInput, comment.Field)
+		}
+	}
+
+	return nil
+}
+
+func isRevisionCommentField(field string) bool {
+	if strings.HasPrefix(field, "details.") && len(field) > len("details.") {
+		return true
+	}
+	for _, f := range model.RevisionCommentFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPermission mengecek permission user yang disisipkan middleware auth ke context
+func hasPermission(c *fiber.Ctx, permission string) bool {
+	permissions, _ := c.Locals("permissions").([]string)
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTransition menjalankan hook transisi, lalu menyimpan status baru secara kondisional bersama efek
+// hook dan catatan achievement_history dalam satu transaksi
+func (s *achievementServiceImpl) applyTransition(transition *model.AchievementTransition, tc *transitionContext) error {
+	tc.fields = make(map[string]interface{})
+	tc.effects = model.AchievementTransitionEffects{}
+	for _, name := range transition.Hooks {
+		if err := transitionHooks[name](s, tc); err != nil {
+			return err
+		}
+	}
+
+	fromStatus := tc.achievement.Status
+	note := tc.note
+	tc.effects.History = &model.AchievementHistory{
+		ID:            uuid.New().String(),
+		AchievementID: tc.achievementID,
+		Action:        transition.Action,
+		OldStatus:     fromStatus,
+		NewStatus:     transition.To,
+		ChangedBy:     tc.userID,
+		Note:          &note,
+	}
+	if tc.onBehalfOf != nil {
+		tc.effects.History.OnBehalfOf = &tc.onBehalfOf.UserID
+	}
+
+	return s.achievementRepo.TransitionAchievementStatus(tc.achievementID, fromStatus, transition.To, tc.achievement.Version, tc.fields, &tc.effects)
+}
+
+// actionError adalah kegagalan satu aksi prestasi beserta status HTTP-nya,
+// sehingga endpoint satuan dan batch menghasilkan pesan yang sama
+type actionError struct {
+	status  int
+	message string
+	data    interface{}
+}
+
+func newActionError(status int, message string) *actionError {
+	return &actionError{status: status, message: message}
+}
+
+// respond menulis actionError sebagai response error
+func (e *actionError) respond(c *fiber.Ctx) error {
+	return c.Status(e.status).JSON(model.APIResponse{
+		Status:  "error",
+		Message: e.message,
+		Data:    e.data,
+	})
+}
+
+// loadActionError memetakan error dari GetAchievementByID. Dokumen yang masih menunggu outbox menjadi 503
+// agar client mencoba lagi; error lain memakai status dan pesan fallback.
+func loadActionError(err error, status int, fallback string) *actionError {
+	if errors.Is(err, repository.ErrAchievementDocumentPending) {
+		return newActionError(fiber.StatusServiceUnavailable, repository.ErrAchievementDocumentPending.Error())
+	}
+	return newActionError(status, fallback)
+}
+
+// transitionActionError memetakan error dari applyTransition ke HTTP status yang sesuai
+func transitionActionError(err error, fallback string) *actionError {
+	var detailsErr *detailsValidationError
+	var evidenceErr *evidenceIncompleteError
+	switch {
+	case errors.As(err, &detailsErr):
+		return &actionError{status: fiber.StatusBadRequest, message: detailsErr.Error(), data: detailsErr.fields}
+	case errors.As(err, &evidenceErr):
+		return &actionError{status: fiber.StatusBadRequest, message: evidenceErr.Error(), data: evidenceErr.checklist}
+	case errors.Is(err, errInvalidTransitionInput):
+		return newActionError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrAchievementStatusConflict):
+		return newActionError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrAchievementVersionConflict):
+		return newActionError(fiber.StatusPreconditionFailed, err.Error())
+	default:
+		return newActionError(fiber.StatusInternalServerError, fallback)
+	}
+}
+
+// achievementETag mengubah versi prestasi menjadi nilai header ETag
+func achievementETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setAchievementETag mengisi header ETag dengan versi prestasi yang dikembalikan
+func setAchievementETag(c *fiber.Ctx, achievement *model.AchievementWithReference) {
+	if achievement != nil {
+		c.Set(fiber.HeaderETag, achievementETag(achievement.Version))
+	}
+}
+
+// checkIfMatch membandingkan nilai If-Match dengan versi prestasi; nilai kosong berarti tanpa pengecekan
+func checkIfMatch(ifMatch string, achievement *model.AchievementWithReference) *actionError {
+	if ifMatch == "" || ifMatch == "*" {
+		return nil
+	}
+	current := achievementETag(achievement.Version)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == current {
+			return nil
+		}
+	}
+	return newActionError(fiber.StatusPreconditionFailed, repository.ErrAchievementVersionConflict.Error())
+}
+
+// transitionErrorResponse menulis error dari applyTransition sebagai response
+func transitionErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	return transitionActionError(err, fallback).respond(c)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// staleTransitionFixture membuat prestasi berstatus status, lalu mengembalikan salinan yang dibaca sebelum
+// pengguna lain mengubahnya dan sudah tidak sama dengan data tersimpan
+func staleTransitionFixture(status string) (*achievementServiceImpl, *repository.MockAchievementRepository, *model.AchievementWithReference) {
+	mockAchRepo := repository.NewMockAchievementRepository()
+	service := &achievementServiceImpl{
+		achievementRepo: mockAchRepo,
+		studentRepo:     repository.NewMockStudentRepository(),
+		lecturerRepo:    repository.NewMockLecturerRepository(),
+	}
+	achievement, _ := mockAchRepo.Create(&model.Achievement{
+		AchievementType: model.AchievementTypeCompetition,
+		Title:           "Juara 1 Gemastik",
+	}, uuid.New().String())
+	achievement.Status = status
+	stale := *achievement
+	return service, mockAchRepo, &stale
+}
+
+// TestApplyTransition_LostSubmitWritesNothing tests a submit that loses the version check leaves no snapshot, history, or resolved revision request
+func TestApplyTransition_LostSubmitWritesNothing(t *testing.T) {
+	// Arrange
+	service, mockAchRepo, stale := staleTransitionFixture(model.AchievementStatusRevisionRequested)
+	mockAchRepo.CreateRevisionRequest(&model.AchievementRevisionRequest{AchievementID: stale.ReferenceID, Round: 1})
+	edited := stale.Achievement
+	edited.Title = "Juara 1 Gemastik 2024"
+	mockAchRepo.UpdateAchievement(stale.ReferenceID, &edited, 0)
+	transition := &model.AchievementTransition{
+		Action: model.AchievementActionSubmit,
+		To:     model.AchievementStatusSubmitted,
+		Hooks:  []string{"snapshot_content", "stamp_submitted", "resolve_revision_requests", "reset_approvals"},
+	}
+
+	// Act
+	err := service.applyTransition(transition, &transitionContext{achievementID: stale.ReferenceID, achievement: stale, userID: uuid.New().String()})
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrAchievementVersionConflict)
+	snapshots, _ := mockAchRepo.GetAchievementSnapshots(stale.ReferenceID)
+	assert.Empty(t, snapshots)
+	history, _ := mockAchRepo.GetAchievementHistory(stale.ReferenceID)
+	assert.Empty(t, history)
+	requests, _ := mockAchRepo.GetRevisionRequests(stale.ReferenceID)
+	assert.Nil(t, requests[0].ResolvedAt)
+}
+
+// TestApplyTransition_LostRevisionRequestWritesNothing tests a revision request that loses to a concurrent rejection records no revision round
+func TestApplyTransition_LostRevisionRequestWritesNothing(t *testing.T) {
+	// Arrange
+	service, mockAchRepo, stale := staleTransitionFixture(model.AchievementStatusSubmitted)
+	mockAchRepo.TransitionAchievementStatus(stale.ReferenceID, model.AchievementStatusSubmitted, model.AchievementStatusRejected, 0, nil, nil)
+	transition := &model.AchievementTransition{
+		Action: model.AchievementActionRequestRevision,
+		To:     model.AchievementStatusRevisionRequested,
+		Hooks:  []string{"record_revision_request"},
+	}
+
+	// Act
+	err := service.applyTransition(transition, &transitionContext{
+		achievementID: stale.ReferenceID,
+		achievement:   stale,
+		userID:        uuid.New().String(),
+		comments:      []model.AchievementRevisionComment{{Field: "title", Comment: "Sebutkan tahun lomba"}},
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrAchievementStatusConflict)
+	requests, _ := mockAchRepo.GetRevisionRequests(stale.ReferenceID)
+	assert.Empty(t, requests)
+}
+
+// TestApplyTransition_LostVerifyWritesNothing tests a verification that loses the version check records no stage approval and assigns no points
+func TestApplyTransition_LostVerifyWritesNothing(t *testing.T) {
+	// Arrange
+	service, mockAchRepo, stale := staleTransitionFixture(model.AchievementStatusSubmitted)
+	mockAchRepo.TransitionAchievementStatus(stale.ReferenceID, model.AchievementStatusSubmitted, model.AchievementStatusSubmitted, 0, nil, nil)
+	points := 50
+	transition := &model.AchievementTransition{
+		Action: model.AchievementActionVerify,
+		To:     model.AchievementStatusVerified,
+		Hooks:  []string{"record_stage_approval", "assign_points", "stamp_verifier"},
+	}
+
+	// Act
+	err := service.applyTransition(transition, &transitionContext{
+		achievementID: stale.ReferenceID,
+		achievement:   stale,
+		userID:        uuid.New().String(),
+		points:        &points,
+		approval:      &model.AchievementApproval{AchievementID: stale.ReferenceID, StageOrder: 1, ProposedPoints: points},
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrAchievementVersionConflict)
+	approvals, _ := mockAchRepo.GetAchievementApprovals(stale.ReferenceID)
+	assert.Empty(t, approvals)
+	current, _ := mockAchRepo.GetAchievementByID(stale.ReferenceID)
+	assert.Equal(t, 0, current.Points)
+	assert.Equal(t, model.AchievementStatusSubmitted, current.Status)
+}
+
+// TestApplyTransition_VerifyWritesEffects tests a successful verification stores the points, stage approval, and history together
+func TestApplyTransition_VerifyWritesEffects(t *testing.T) {
+	// Arrange
+	service, mockAchRepo, stale := staleTransitionFixture(model.AchievementStatusSubmitted)
+	current, _ := mockAchRepo.GetAchievementByID(stale.ReferenceID)
+	points := 50
+	transition := &model.AchievementTransition{
+		Action: model.AchievementActionVerify,
+		To:     model.AchievementStatusVerified,
+		Hooks:  []string{"record_stage_approval", "assign_points", "stamp_verifier"},
+	}
+
+	// Act
+	err := service.applyTransition(transition, &transitionContext{
+		achievementID: stale.ReferenceID,
+		achievement:   current,
+		userID:        uuid.New().String(),
+		points:        &points,
+		approval:      &model.AchievementApproval{AchievementID: stale.ReferenceID, StageOrder: 1, ProposedPoints: points},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 50, current.Points)
+	assert.Equal(t, model.AchievementStatusVerified, current.Status)
+	assert.Equal(t, 2, current.Version)
+	approvals, _ := mockAchRepo.GetAchievementApprovals(stale.ReferenceID)
+	assert.Len(t, approvals, 1)
+	history, _ := mockAchRepo.GetAchievementHistory(stale.ReferenceID)
+	assert.Len(t, history, 1)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"strings"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+	"uas_be/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ApprovalChainService interface {
+	GetApprovalChains(c *fiber.Ctx) error
+	CreateApprovalChain(c *fiber.Ctx) error
+	DeactivateApprovalChain(c *fiber.Ctx) error
+}
+
+type approvalChainServiceImpl struct {
+	achievementRepo repository.AchievementRepository
+}
+
+func NewApprovalChainService(achievementRepo repository.AchievementRepository) ApprovalChainService {
+	return &approvalChainServiceImpl{
+		achievementRepo: achievementRepo,
+	}
+}
+
+// GetApprovalChains godoc
+// @Summary Dapatkan semua approval chain
+// @Description Mengambil daftar approval chain beserta tahapannya (admin)
+// @Tags Approval Chains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Hanya tampilkan chain aktif"
+// @Success 200 {object} model.APIResponse{data=[]model.ApprovalChain} "Daftar approval chain berhasil diambil"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /approval-chains [get]
+func (s *approvalChainServiceImpl) GetApprovalChains(c *fiber.Ctx) error {
+	chains, err := s.achievementRepo.GetApprovalChains(c.QueryBool("active", false))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil approval chain: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "approval chain berhasil diambil",
+		Data:    chains,
+	})
+}
+
+// CreateApprovalChain godoc
+// @Summary Buat approval chain baru
+// @Description Membuat rangkaian tahap persetujuan untuk prestasi dengan tipe dan/atau poin tertentu (admin)
+// @Tags Approval Chains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body model.CreateApprovalChainRequest true "Data approval chain"
+// @Success 201 {object} model.APIResponse{data=model.ApprovalChain} "Approval chain berhasil dibuat"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid atau tahap tidak lengkap"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /approval-chains [post]
+func (s *approvalChainServiceImpl) CreateApprovalChain(c *fiber.Ctx) error {
+	req := new(model.CreateApprovalChainRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "name harus diisi")
+	}
+	if req.MinPoints < 0 {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "min_points tidak boleh negatif")
+	}
+	if len(req.Stages) == 0 {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "approval chain harus memiliki minimal satu tahap")
+	}
+
+	chain := &model.ApprovalChain{
+		Name:            req.Name,
+		AchievementType: req.AchievementType,
+		MinPoints:       req.MinPoints,
+	}
+	for _, stage := range req.Stages {
+		if strings.TrimSpace(stage.Name) == "" {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "nama tahap harus diisi")
+		}
+		if stage.ApproverRole == "" && len(stage.ApproverUserIDs) == 0 {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "tahap "+stage.Name+" harus memiliki approver_role atau approver_user_ids")
+		}
+		chain.Stages = append(chain.Stages, model.ApprovalStage{
+			Name:            stage.Name,
+			ApproverRole:    stage.ApproverRole,
+			ApproverUserIDs: stage.ApproverUserIDs,
+		})
+	}
+
+	if err := s.achievementRepo.CreateApprovalChain(chain); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal membuat approval chain: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "approval chain berhasil dibuat",
+		Data:    chain,
+	})
+}
+
+// DeactivateApprovalChain godoc
+// @Summary Nonaktifkan approval chain
+// @Description Menonaktifkan approval chain. Prestasi yang sudah berjalan di chain ini tetap menyelesaikan tahapnya.
+// @Tags Approval Chains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Approval Chain ID"
+// @Success 200 {object} model.APIResponse "Approval chain berhasil dinonaktifkan"
+// @Failure 404 {object} model.APIResponse "Approval chain tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /approval-chains/{id} [delete]
+func (s *approvalChainServiceImpl) DeactivateApprovalChain(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	chain, err := s.achievementRepo.GetApprovalChainByID(id)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil approval chain: "+err.Error())
+	}
+	if chain == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "approval chain tidak ditemukan")
+	}
+
+	if err := s.achievementRepo.DeactivateApprovalChain(id); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal menonaktifkan approval chain: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "approval chain berhasil dinonaktifkan",
+	})
+}
@@ -0,0 +1,591 @@
+package service
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// commentEditWindow adalah batas waktu penulis boleh mengedit atau menghapus komentarnya
+var commentEditWindow = 15 * time.Minute
+
+// maxCommentLength adalah panjang maksimal isi komentar
+const maxCommentLength = 2000
+
+// mentionPattern menangkap @username di dalam isi komentar
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9_.]+)`)
+
+// SetCommentEditWindow mengatur batas waktu edit/hapus komentar oleh penulisnya
+func SetCommentEditWindow(window time.Duration) {
+	commentEditWindow = window
+}
+
+type CommentService interface {
+	GetComments(c *fiber.Ctx) error
+	CreateComment(c *fiber.Ctx) error
+	UpdateComment(c *fiber.Ctx) error
+	DeleteComment(c *fiber.Ctx) error
+	GetTimeline(c *fiber.Ctx) error
+}
+
+type commentServiceImpl struct {
+	commentRepo      repository.CommentRepository
+	achievementRepo  repository.AchievementRepository
+	userRepo         repository.UserRepository
+	notificationRepo repository.NotificationRepository
+	// access dipakai ulang untuk aturan akses dosen wali (termasuk pelimpahan) milik layanan prestasi
+	access *achievementServiceImpl
+}
+
+func NewCommentService(
+	commentRepo repository.CommentRepository,
+	achievementRepo repository.AchievementRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	userRepo repository.UserRepository,
+	notificationRepo repository.NotificationRepository,
+) CommentService {
+	return &commentServiceImpl{
+		commentRepo:      commentRepo,
+		achievementRepo:  achievementRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		access: &achievementServiceImpl{
+			achievementRepo: achievementRepo,
+			studentRepo:     studentRepo,
+			lecturerRepo:    lecturerRepo,
+		},
+	}
+}
+
+// GetComments godoc
+// @Summary Dapatkan thread komentar prestasi
+// @Description Mengambil komentar prestasi dalam bentuk thread (komentar utama beserta balasannya)
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} model.APIResponse{data=[]model.AchievementComment} "Komentar berhasil diambil"
+// @Failure 401 {object} model.APIResponse "Tidak memiliki akses ke prestasi ini"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/comments [get]
+func (s *commentServiceImpl) GetComments(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	if _, status, message := s.authorizeAccess(c, achievementID); status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	comments, err := s.commentRepo.GetCommentsByAchievementID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil komentar",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "komentar berhasil diambil",
+		Data:    buildCommentThreads(comments),
+	})
+}
+
+// CreateComment godoc
+// @Summary Tulis komentar pada prestasi
+// @Description Menulis komentar atau balasan. Gunakan @username untuk mention; hanya peserta prestasi (pemilik, anggota tim, dosen wali, approver) yang bisa di-mention dan mendapat notifikasi.
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param body body model.CreateCommentRequest true "Isi komentar"
+// @Success 201 {object} model.APIResponse{data=model.AchievementComment} "Komentar berhasil dibuat"
+// @Failure 400 {object} model.APIResponse "Isi komentar, parent, atau lampiran tidak valid"
+// @Failure 401 {object} model.APIResponse "Tidak memiliki akses ke prestasi ini"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/comments [post]
+func (s *commentServiceImpl) CreateComment(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	userID := c.Locals("userID").(string)
+
+	achievement, status, message := s.authorizeAccess(c, achievementID)
+	if status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	var req model.CreateCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format request tidak valid",
+		})
+	}
+
+	body, message := validateCommentBody(req.Body)
+	if message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	var parent *model.AchievementComment
+	if req.ParentID != nil {
+		parent, _ = s.commentRepo.GetCommentByID(*req.ParentID)
+		if parent == nil || parent.AchievementID != achievementID || parent.DeletedAt != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "komentar yang dibalas tidak ditemukan",
+			})
+		}
+	}
+
+	if req.AttachmentID != nil {
+		ok, err := s.hasAttachment(achievementID, *req.AttachmentID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "gagal mengambil lampiran",
+			})
+		}
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "lampiran tidak ditemukan pada prestasi ini",
+			})
+		}
+	}
+
+	comment := &model.AchievementComment{
+		AchievementID: achievementID,
+		ParentID:      req.ParentID,
+		AuthorID:      userID,
+		Body:          body,
+		Mentions:      s.resolveMentions(body, achievement),
+		AttachmentID:  req.AttachmentID,
+	}
+	if author, _ := s.userRepo.GetUserByID(userID); author != nil {
+		comment.AuthorName = author.FullName
+	}
+
+	if err := s.commentRepo.CreateComment(comment); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal menyimpan komentar",
+		})
+	}
+
+	s.notifyComment(comment, parent, nil, achievement)
+
+	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "komentar berhasil dibuat",
+		Data:    comment,
+	})
+}
+
+// UpdateComment godoc
+// @Summary Edit komentar
+// @Description Mengedit isi komentar oleh penulisnya, hanya dalam batas waktu edit setelah komentar dibuat
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param commentId path string true "Comment ID"
+// @Param body body model.UpdateCommentRequest true "Isi komentar baru"
+// @Success 200 {object} model.APIResponse{data=model.AchievementComment} "Komentar berhasil diubah"
+// @Failure 400 {object} model.APIResponse "Isi komentar tidak valid"
+// @Failure 403 {object} model.APIResponse "Bukan penulis komentar atau batas waktu edit sudah lewat"
+// @Failure 404 {object} model.APIResponse "Komentar tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/comments/{commentId} [put]
+func (s *commentServiceImpl) UpdateComment(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+
+	achievement, status, message := s.authorizeAccess(c, achievementID)
+	if status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	comment, status, message := s.getOwnComment(c, achievementID, false)
+	if status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	var req model.UpdateCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format request tidak valid",
+		})
+	}
+
+	body, message := validateCommentBody(req.Body)
+	if message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	previousMentions := comment.Mentions
+	updated := *comment
+	updated.Body = body
+	updated.Mentions = s.resolveMentions(body, achievement)
+	if err := s.commentRepo.UpdateComment(&updated); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengubah komentar",
+		})
+	}
+
+	// Hanya user yang baru di-mention setelah edit yang mendapat notifikasi
+	s.notifyComment(&updated, nil, previousMentions, achievement)
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "komentar berhasil diubah",
+		Data:    &updated,
+	})
+}
+
+// DeleteComment godoc
+// @Summary Hapus komentar
+// @Description Menghapus komentar (soft delete). Penulis hanya bisa menghapus dalam batas waktu edit, admin kapan saja.
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} model.APIResponse "Komentar berhasil dihapus"
+// @Failure 403 {object} model.APIResponse "Bukan penulis komentar atau batas waktu hapus sudah lewat"
+// @Failure 404 {object} model.APIResponse "Komentar tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/comments/{commentId} [delete]
+func (s *commentServiceImpl) DeleteComment(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	role := c.Locals("role").(string)
+
+	if _, status, message := s.authorizeAccess(c, achievementID); status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	// Admin boleh menghapus komentar siapa pun untuk moderasi
+	comment, status, message := s.getOwnComment(c, achievementID, role == "Admin")
+	if status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	if err := s.commentRepo.DeleteComment(comment.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal menghapus komentar",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "komentar berhasil dihapus",
+	})
+}
+
+// GetTimeline godoc
+// @Summary Dapatkan timeline prestasi
+// @Description Menggabungkan riwayat perubahan status dan komentar prestasi dalam satu urutan waktu
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} model.APIResponse{data=[]model.TimelineEntry} "Timeline berhasil diambil"
+// @Failure 401 {object} model.APIResponse "Tidak memiliki akses ke prestasi ini"
+// @Failure 404 {object} model.APIResponse "Prestasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Failure 503 {object} model.APIResponse "Data prestasi sedang disinkronkan"
+// @Router /achievements/{id}/timeline [get]
+func (s *commentServiceImpl) GetTimeline(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	if _, status, message := s.authorizeAccess(c, achievementID); status != 0 {
+		return c.Status(status).JSON(model.APIResponse{
+			Status:  "error",
+			Message: message,
+		})
+	}
+
+	histories, err := s.achievementRepo.GetAchievementHistory(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil history",
+		})
+	}
+
+	comments, err := s.commentRepo.GetCommentsByAchievementID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil komentar",
+		})
+	}
+
+	timeline := make([]model.TimelineEntry, 0, len(histories)+len(comments))
+	for _, history := range histories {
+		timeline = append(timeline, model.TimelineEntry{
+			Type:      model.TimelineEntryHistory,
+			Timestamp: history.CreatedAt,
+			History:   history,
+		})
+	}
+	for _, comment := range comments {
+		timeline = append(timeline, model.TimelineEntry{
+			Type:      model.TimelineEntryComment,
+			Timestamp: comment.CreatedAt,
+			Comment:   comment,
+		})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Timestamp.Before(timeline[j].Timestamp)
+	})
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "timeline berhasil diambil",
+		Data:    timeline,
+	})
+}
+
+// authorizeAccess menerapkan aturan akses yang sama dengan detail prestasi: mahasiswa pemilik,
+// dosen wali (atau penerima pelimpahan), dan admin. Status 0 berarti diizinkan.
+func (s *commentServiceImpl) authorizeAccess(c *fiber.Ctx, achievementID string) (*model.AchievementWithReference, int, string) {
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	achievement, err := s.achievementRepo.GetAchievementByID(achievementID)
+	if err != nil {
+		loadErr := loadActionError(err, fiber.StatusInternalServerError, "gagal mengambil achievement")
+		return nil, loadErr.status, loadErr.message
+	}
+	if achievement == nil || achievement.Status == model.AchievementStatusDeleted {
+		return nil, fiber.StatusNotFound, "prestasi tidak ditemukan"
+	}
+
+	switch role {
+	case "Admin":
+		return achievement, 0, ""
+	case "Mahasiswa":
+		student, err := s.access.studentRepo.GetStudentByUserID(userID)
+		if err != nil || student == nil || achievement.StudentID != student.ID {
+			return nil, fiber.StatusUnauthorized, "unauthorized"
+		}
+		return achievement, 0, ""
+	case "Dosen Wali":
+		if _, status, message := s.access.authorizeAdvisor(userID, achievement); status != 0 {
+			return nil, status, message
+		}
+		return achievement, 0, ""
+	default:
+		return nil, fiber.StatusForbidden, "anda tidak memiliki akses ke prestasi ini"
+	}
+}
+
+// getOwnComment mengambil komentar dari path dan memastikan user yang login boleh mengubahnya
+func (s *commentServiceImpl) getOwnComment(c *fiber.Ctx, achievementID string, moderator bool) (*model.AchievementComment, int, string) {
+	userID := c.Locals("userID").(string)
+
+	comment, err := s.commentRepo.GetCommentByID(c.Params("commentId"))
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal mengambil komentar"
+	}
+	if comment == nil || comment.AchievementID != achievementID || comment.DeletedAt != nil {
+		return nil, fiber.StatusNotFound, "komentar tidak ditemukan"
+	}
+	if moderator {
+		return comment, 0, ""
+	}
+
+	if comment.AuthorID != userID {
+		return nil, fiber.StatusForbidden, "hanya penulis yang dapat mengubah komentar ini"
+	}
+	if commentEditWindow > 0 && time.Since(comment.CreatedAt) > commentEditWindow {
+		return nil, fiber.StatusForbidden, "batas waktu edit komentar (" + strconv.Itoa(int(commentEditWindow.Minutes())) + " menit) sudah lewat"
+	}
+
+	return comment, 0, ""
+}
+
+// hasAttachment mengecek apakah lampiran milik prestasi yang dikomentari
+func (s *commentServiceImpl) hasAttachment(achievementID, attachmentID string) (bool, error) {
+	attachments, err := s.achievementRepo.GetAttachmentsByAchievementID(achievementID)
+	if err != nil {
+		return false, err
+	}
+	for _, attachment := range attachments {
+		if attachment.ID == attachmentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolveMentions mengubah @username di isi komentar menjadi user ID. Username tidak dikenal dan user yang
+// bukan peserta prestasi diabaikan, sehingga judul prestasi tidak bocor lewat notifikasi ke sembarang user.
+func (s *commentServiceImpl) resolveMentions(body string, achievement *model.AchievementWithReference) []string {
+	mentions := []string{}
+	seen := make(map[string]bool)
+	var participants map[string]bool
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := strings.TrimRight(match[1], ".")
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		user, err := s.userRepo.GetUserByUsername(username)
+		if err != nil || user == nil {
+			continue
+		}
+		if participants == nil {
+			participants = s.participantUserIDs(achievement)
+		}
+		if participants[user.ID] {
+			mentions = append(mentions, user.ID)
+		}
+	}
+	return mentions
+}
+
+// participantUserIDs mengumpulkan user yang terlibat pada prestasi: mahasiswa pemilik dan anggota tim beserta
+// dosen walinya, serta approver yang sudah memverifikasi atau menyetujui salah satu tahap
+func (s *commentServiceImpl) participantUserIDs(achievement *model.AchievementWithReference) map[string]bool {
+	participants := make(map[string]bool)
+	studentIDs := []string{achievement.StudentID}
+	if achievement.TeamID != nil {
+		if members, err := s.achievementRepo.GetTeamMembers(*achievement.TeamID); err == nil {
+			for _, member := range members {
+				studentIDs = append(studentIDs, member.StudentID)
+			}
+		}
+	}
+	for _, studentID := range studentIDs {
+		student, err := s.access.studentRepo.GetStudentByID(studentID)
+		if err != nil || student == nil {
+			continue
+		}
+		participants[student.UserID] = true
+		if advisor, err := s.access.lecturerRepo.GetLecturerByID(student.AdvisorID); err == nil && advisor != nil {
+			participants[advisor.UserID] = true
+		}
+	}
+
+	if achievement.VerifiedBy != nil {
+		participants[*achievement.VerifiedBy] = true
+	}
+	if approvals, err := s.achievementRepo.GetAchievementApprovals(achievement.ReferenceID); err == nil {
+		for _, approval := range approvals {
+			participants[approval.VerifiedBy] = true
+			if approval.OnBehalfOf != nil {
+				participants[*approval.OnBehalfOf] = true
+			}
+		}
+	}
+	return participants
+}
+
+// notifyComment mengirim notifikasi ke user yang di-mention dan ke penulis komentar yang dibalas.
+// previousMentions berisi mention sebelum edit agar user yang sama tidak diberi notifikasi dua kali.
+func (s *commentServiceImpl) notifyComment(comment *model.AchievementComment, parent *model.AchievementComment, previousMentions []string, achievement *model.AchievementWithReference) {
+	notified := map[string]bool{comment.AuthorID: true}
+	for _, id := range previousMentions {
+		notified[id] = true
+	}
+
+	send := func(userID, notificationType, message string) {
+		if notified[userID] {
+			return
+		}
+		notified[userID] = true
+		err := s.notificationRepo.CreateNotification(&model.Notification{
+			UserID:        userID,
+			Type:          notificationType,
+			Message:       message,
+			AchievementID: &comment.AchievementID,
+		})
+		if err != nil {
+			// Komentar sudah tersimpan; kegagalan notifikasi tidak membatalkannya
+			log.Println("warning: failed to create notification:", err)
+		}
+	}
+
+	for _, userID := range comment.Mentions {
+		send(userID, model.NotificationTypeMention, comment.AuthorName+" menyebut anda di komentar prestasi \""+achievement.Title+"\"")
+	}
+	if parent != nil {
+		send(parent.AuthorID, model.NotificationTypeCommentReply, comment.AuthorName+" membalas komentar anda di prestasi \""+achievement.Title+"\"")
+	}
+}
+
+// validateCommentBody merapikan isi komentar dan mengembalikan pesan error jika tidak valid
+func validateCommentBody(body string) (string, string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", "isi komentar tidak boleh kosong"
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return "", "isi komentar maksimal " + strconv.Itoa(maxCommentLength) + " karakter"
+	}
+	return body, ""
+}
+
+// buildCommentThreads menyusun daftar komentar datar menjadi thread berdasarkan parent_id
+func buildCommentThreads(comments []*model.AchievementComment) []*model.AchievementComment {
+	byID := make(map[string]*model.AchievementComment, len(comments))
+	for _, comment := range comments {
+		byID[comment.ID] = comment
+	}
+
+	threads := []*model.AchievementComment{}
+	for _, comment := range comments {
+		if comment.ParentID != nil {
+			if parent, ok := byID[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, comment)
+				continue
+			}
+		}
+		threads = append(threads, comment)
+	}
+	return threads
+}
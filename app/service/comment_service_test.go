@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateComment_MentionAndReply tests mentions and replies notify the right users and form a thread
+func TestCreateComment_MentionAndReply(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockCommentRepo := repository.NewMockCommentRepository()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	mockUserRepo := NewMockUserRepository()
+	mockNotificationRepo := repository.NewMockNotificationRepository()
+	service := NewCommentService(mockCommentRepo, mockAchRepo, mockStudentRepo, mockLecturerRepo, mockUserRepo, mockNotificationRepo)
+	studentID := uuid.New().String()
+	studentUserID := uuid.New().String()
+	advisorID := uuid.New().String()
+	advisorUserID := uuid.New().String()
+	mockUserRepo.CreateUser(&model.User{ID: studentUserID, Username: "mahasiswa1", FullName: "Mahasiswa Satu"})
+	mockUserRepo.CreateUser(&model.User{ID: advisorUserID, Username: "dosen1", FullName: "Dosen Satu"})
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: studentUserID, StudentID: "123456", AdvisorID: advisorID})
+	mockLecturerRepo.CreateLecturer(&model.Lecturer{ID: advisorID, UserID: advisorUserID, LecturerID: "111111"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "competition", Title: "Juara Lomba"}, studentID)
+	achievementID := achWithRef.StudentID
+	attachment := &model.AchievementAttachment{AchievementID: achievementID, FileName: "sertifikat.pdf"}
+	mockAchRepo.CreateAttachment(attachment)
+	currentUser, currentRole := studentUserID, "Mahasiswa"
+	setUser := func(c *fiber.Ctx) error {
+		c.Locals("userID", currentUser)
+		c.Locals("role", currentRole)
+		return c.Next()
+	}
+	app.Post("/achievements/:id/comments", setUser, service.CreateComment)
+	app.Get("/achievements/:id/comments", setUser, service.GetComments)
+	post := func(req model.CreateCommentRequest) (int, *model.AchievementComment) {
+		bodyBytes, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/achievements/"+achievementID+"/comments", bytes.NewReader(bodyBytes))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(httpReq)
+		var result struct {
+			Data *model.AchievementComment `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result.Data
+	}
+
+	// Act: mahasiswa mention dosen wali dan menautkan lampiran
+	status, comment := post(model.CreateCommentRequest{Body: "@dosen1 apakah sertifikat ini cukup?", AttachmentID: &attachment.ID})
+	// Assert
+	assert.Equal(t, 201, status)
+	if assert.NotNil(t, comment) {
+		assert.Equal(t, []string{advisorUserID}, comment.Mentions)
+	}
+	notifications, _ := mockNotificationRepo.GetNotificationsByUserID(advisorUserID, true)
+	if assert.Len(t, notifications, 1) {
+		assert.Equal(t, model.NotificationTypeMention, notifications[0].Type)
+	}
+
+	// Act: dosen wali membalas
+	currentUser, currentRole = advisorUserID, "Dosen Wali"
+	status, _ = post(model.CreateCommentRequest{Body: "Cukup, tambahkan foto kegiatan", ParentID: &comment.ID})
+	// Assert
+	assert.Equal(t, 201, status)
+	notifications, _ = mockNotificationRepo.GetNotificationsByUserID(studentUserID, true)
+	if assert.Len(t, notifications, 1) {
+		assert.Equal(t, model.NotificationTypeCommentReply, notifications[0].Type)
+	}
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+achievementID+"/comments", nil))
+	var threads struct {
+		Data []*model.AchievementComment `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&threads)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.Len(t, threads.Data, 1) {
+		assert.Len(t, threads.Data[0].Replies, 1)
+	}
+}
+
+// TestCreateComment_OtherStudentUnauthorized tests comments follow achievement ownership rules
+func TestCreateComment_OtherStudentUnauthorized(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewCommentService(repository.NewMockCommentRepository(), mockAchRepo, mockStudentRepo,
+		repository.NewMockLecturerRepository(), NewMockUserRepository(), repository.NewMockNotificationRepository())
+	ownerID := uuid.New().String()
+	otherUserID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: ownerID, UserID: uuid.New().String(), StudentID: "111111"})
+	mockStudentRepo.CreateStudent(&model.Student{ID: uuid.New().String(), UserID: otherUserID, StudentID: "222222"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, ownerID)
+	app.Post("/achievements/:id/comments", func(c *fiber.Ctx) error {
+		c.Locals("userID", otherUserID)
+		c.Locals("role", "Mahasiswa")
+		return service.CreateComment(c)
+	})
+	bodyBytes, _ := json.Marshal(model.CreateCommentRequest{Body: "Halo"})
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achWithRef.StudentID+"/comments", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+// TestCreateComment_MentionOutsiderIgnored tests mentioning a user who is not a participant of the achievement sends no notification
+func TestCreateComment_MentionOutsiderIgnored(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	mockUserRepo := NewMockUserRepository()
+	mockNotificationRepo := repository.NewMockNotificationRepository()
+	service := NewCommentService(repository.NewMockCommentRepository(), mockAchRepo, mockStudentRepo,
+		repository.NewMockLecturerRepository(), mockUserRepo, mockNotificationRepo)
+	studentID := uuid.New().String()
+	studentUserID := uuid.New().String()
+	outsiderUserID := uuid.New().String()
+	mockUserRepo.CreateUser(&model.User{ID: outsiderUserID, Username: "mahasiswa2", FullName: "Mahasiswa Dua"})
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: studentUserID, StudentID: "111111"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Rahasia"}, studentID)
+	app.Post("/achievements/:id/comments", func(c *fiber.Ctx) error {
+		c.Locals("userID", studentUserID)
+		c.Locals("role", "Mahasiswa")
+		return service.CreateComment(c)
+	})
+	bodyBytes, _ := json.Marshal(model.CreateCommentRequest{Body: "@mahasiswa2 lihat ini"})
+
+	// Act
+	req := httptest.NewRequest("POST", "/achievements/"+achWithRef.StudentID+"/comments", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	// Assert
+	assert.Equal(t, 201, resp.StatusCode)
+	notifications, _ := mockNotificationRepo.GetNotificationsByUserID(outsiderUserID, true)
+	assert.Empty(t, notifications)
+}
+
+// TestUpdateComment_EditWindowExpired tests authors cannot edit comments after the edit window
+func TestUpdateComment_EditWindowExpired(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockCommentRepo := repository.NewMockCommentRepository()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewCommentService(mockCommentRepo, mockAchRepo, mockStudentRepo,
+		repository.NewMockLecturerRepository(), NewMockUserRepository(), repository.NewMockNotificationRepository())
+	studentID := uuid.New().String()
+	userID := uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	comment := &model.AchievementComment{AchievementID: achievementID, AuthorID: userID, Body: "Komentar lama"}
+	mockCommentRepo.CreateComment(comment)
+	comment.CreatedAt = time.Now().Add(-time.Hour)
+	app.Put("/achievements/:id/comments/:commentId", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Mahasiswa")
+		return service.UpdateComment(c)
+	})
+	bodyBytes, _ := json.Marshal(model.UpdateCommentRequest{Body: "Komentar baru"})
+	// Act
+	req := httptest.NewRequest("PUT", "/achievements/"+achievementID+"/comments/"+comment.ID, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	// Assert
+	assert.Equal(t, 403, resp.StatusCode)
+	assert.Equal(t, "Komentar lama", comment.Body)
+}
+
+// TestGetTimeline_MergesHistoryAndComments tests history and comments are returned in time order
+func TestGetTimeline_MergesHistoryAndComments(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockCommentRepo := repository.NewMockCommentRepository()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	service := NewCommentService(mockCommentRepo, mockAchRepo, repository.NewMockStudentRepository(),
+		repository.NewMockLecturerRepository(), NewMockUserRepository(), repository.NewMockNotificationRepository())
+	studentID := uuid.New().String()
+	achWithRef, _ := mockAchRepo.Create(&model.Achievement{AchievementType: "academic", Title: "Test Achievement"}, studentID)
+	achievementID := achWithRef.StudentID
+	history := &model.AchievementHistory{AchievementID: achievementID, Action: model.AchievementActionSubmit}
+	mockAchRepo.CreateAchievementHistory(history)
+	history.CreatedAt = time.Now().Add(-2 * time.Hour)
+	comment := &model.AchievementComment{AchievementID: achievementID, AuthorID: uuid.New().String(), Body: "Mohon dicek"}
+	mockCommentRepo.CreateComment(comment)
+	comment.CreatedAt = time.Now().Add(-3 * time.Hour)
+	app.Get("/achievements/:id/timeline", func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New().String())
+		c.Locals("role", "Admin")
+		return service.GetTimeline(c)
+	})
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+achievementID+"/timeline", nil))
+	var result struct {
+		Data []model.TimelineEntry `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.Len(t, result.Data, 2) {
+		assert.Equal(t, model.TimelineEntryComment, result.Data[0].Type)
+		assert.Equal(t, model.TimelineEntryHistory, result.Data[1].Type)
+	}
+}
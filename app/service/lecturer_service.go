@@ -2,6 +2,7 @@ package service
 
 import (
 	"strconv"
+	"time"
 	"uas_be/app/model"
 	"uas_be/app/repository"
 
@@ -17,6 +18,10 @@ type LecturerService interface {
 	GetAdvisees(c *fiber.Ctx) error
 	UpdateLecturer(c *fiber.Ctx) error
 	DeleteLecturer(c *fiber.Ctx) error
+	CreateDelegation(c *fiber.Ctx) error
+	GetDelegations(c *fiber.Ctx) error
+	RevokeDelegation(c *fiber.Ctx) error
+	SetDepartmentReviewer(c *fiber.Ctx) error
 }
 
 type lecturerServiceImpl struct {
@@ -384,3 +389,294 @@ func (s *lecturerServiceImpl) DeleteLecturer(c *fiber.Ctx) error {
 		Message: "lecturer berhasil dihapus",
 	})
 }
+
+// CreateDelegation godoc
+// @Summary Limpahkan wewenang verifikasi
+// @Description Melimpahkan wewenang verifikasi prestasi anak bimbingan ke dosen lain untuk rentang tanggal tertentu (dosen wali bersangkutan/admin)
+// @Tags Lecturers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Lecturer ID dosen wali yang melimpahkan"
+// @Param body body model.CreateDelegationRequest true "Data pelimpahan"
+// @Success 201 {object} model.APIResponse{data=model.VerificationDelegation} "Pelimpahan berhasil dibuat"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid"
+// @Failure 403 {object} model.APIResponse "Anda hanya dapat melimpahkan wewenang anda sendiri"
+// @Failure 404 {object} model.APIResponse "Dosen tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /lecturers/{id}/delegations [post]
+func (s *lecturerServiceImpl) CreateDelegation(c *fiber.Ctx) error {
+	delegatorID := c.Params("id")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	var req model.CreateDelegationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format request tidak valid: " + err.Error(),
+		})
+	}
+
+	if req.DelegateID == "" || req.StartDate == "" || req.EndDate == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "delegate_id, start_date, dan end_date tidak boleh kosong",
+		})
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format start_date tidak valid (gunakan YYYY-MM-DD)",
+		})
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "format end_date tidak valid (gunakan YYYY-MM-DD)",
+		})
+	}
+	if endDate.Before(startDate) {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "end_date tidak boleh sebelum start_date",
+		})
+	}
+
+	delegator, err := s.lecturerRepo.GetLecturerByID(delegatorID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil lecturer: " + err.Error(),
+		})
+	}
+	if delegator == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "lecturer tidak ditemukan",
+		})
+	}
+
+	if role != "Admin" && delegator.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "anda hanya dapat melimpahkan wewenang verifikasi anda sendiri",
+		})
+	}
+
+	if req.DelegateID == delegator.ID {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "wewenang tidak dapat dilimpahkan ke diri sendiri",
+		})
+	}
+
+	delegate, err := s.lecturerRepo.GetLecturerByID(req.DelegateID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil lecturer: " + err.Error(),
+		})
+	}
+	if delegate == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "lecturer penerima pelimpahan tidak ditemukan",
+		})
+	}
+
+	delegation := &model.VerificationDelegation{
+		ID:          uuid.New().String(),
+		DelegatorID: delegator.ID,
+		DelegateID:  delegate.ID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Reason:      req.Reason,
+		CreatedBy:   userID,
+	}
+
+	if err := s.lecturerRepo.CreateDelegation(delegation); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal membuat delegation: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "delegation berhasil dibuat",
+		Data:    delegation,
+	})
+}
+
+// GetDelegations godoc
+// @Summary Dapatkan daftar pelimpahan wewenang
+// @Description Mengambil pelimpahan wewenang verifikasi yang diberikan maupun diterima dosen
+// @Tags Lecturers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Lecturer ID"
+// @Success 200 {object} model.APIResponse{data=[]model.VerificationDelegation} "Daftar pelimpahan berhasil diambil"
+// @Failure 403 {object} model.APIResponse "Anda tidak memiliki akses ke data ini"
+// @Failure 404 {object} model.APIResponse "Dosen tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /lecturers/{id}/delegations [get]
+func (s *lecturerServiceImpl) GetDelegations(c *fiber.Ctx) error {
+	lecturerID := c.Params("id")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	lecturer, err := s.lecturerRepo.GetLecturerByID(lecturerID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil lecturer: " + err.Error(),
+		})
+	}
+	if lecturer == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "lecturer tidak ditemukan",
+		})
+	}
+
+	if role != "Admin" && lecturer.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "anda tidak memiliki akses ke data ini",
+		})
+	}
+
+	delegations, err := s.lecturerRepo.GetDelegationsByLecturerID(lecturer.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil delegations: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "delegations berhasil diambil",
+		Data:    delegations,
+	})
+}
+
+// RevokeDelegation godoc
+// @Summary Batalkan pelimpahan wewenang
+// @Description Membatalkan pelimpahan wewenang verifikasi (dosen wali pemberi/admin)
+// @Tags Lecturers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Lecturer ID"
+// @Param delegationId path string true "Delegation ID"
+// @Success 200 {object} model.APIResponse "Pelimpahan berhasil dibatalkan"
+// @Failure 403 {object} model.APIResponse "Anda tidak memiliki akses ke pelimpahan ini"
+// @Failure 404 {object} model.APIResponse "Pelimpahan tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /lecturers/{id}/delegations/{delegationId} [delete]
+func (s *lecturerServiceImpl) RevokeDelegation(c *fiber.Ctx) error {
+	lecturerID := c.Params("id")
+	delegationID := c.Params("delegationId")
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	delegation, err := s.lecturerRepo.GetDelegationByID(delegationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil delegation: " + err.Error(),
+		})
+	}
+	if delegation == nil || delegation.DelegatorID != lecturerID {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "delegation tidak ditemukan",
+		})
+	}
+
+	if role != "Admin" {
+		lecturer, err := s.lecturerRepo.GetLecturerByUserID(userID)
+		if err != nil || lecturer == nil || lecturer.ID != delegation.DelegatorID {
+			return c.Status(fiber.StatusForbidden).JSON(model.APIResponse{
+				Status:  "error",
+				Message: "anda tidak memiliki akses ke delegation ini",
+			})
+		}
+	}
+
+	if err := s.lecturerRepo.RevokeDelegation(delegationID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal membatalkan delegation: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "delegation berhasil dibatalkan",
+	})
+}
+
+// SetDepartmentReviewer godoc
+// @Summary Atur reviewer departemen
+// @Description Menandai dosen sebagai reviewer departemen yang menerima eskalasi prestasi yang melewati SLA verifikasi
+// @Tags Lecturers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Lecturer ID"
+// @Param body body object{department_reviewer=bool} true "Status reviewer departemen"
+// @Success 200 {object} model.APIResponse "Status reviewer departemen berhasil diubah"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid"
+// @Failure 404 {object} model.APIResponse "Dosen tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /lecturers/{id}/department-reviewer [put]
+func (s *lecturerServiceImpl) SetDepartmentReviewer(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req struct {
+		DepartmentReviewer *bool `json:"department_reviewer"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.DepartmentReviewer == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "department_reviewer wajib diisi",
+		})
+	}
+
+	lecturer, err := s.lecturerRepo.GetLecturerByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengambil lecturer: " + err.Error(),
+		})
+	}
+	if lecturer == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "lecturer tidak ditemukan",
+		})
+	}
+
+	if err := s.lecturerRepo.SetDepartmentReviewer(id, *req.DepartmentReviewer); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.APIResponse{
+			Status:  "error",
+			Message: "gagal mengubah reviewer departemen: " + err.Error(),
+		})
+	}
+
+	message := "dosen ditetapkan sebagai reviewer departemen " + lecturer.Department
+	if !*req.DepartmentReviewer {
+		message = "dosen tidak lagi menjadi reviewer departemen " + lecturer.Department
+	}
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: message,
+	})
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"uas_be/app/model"
 	"uas_be/app/repository"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
@@ -212,3 +215,52 @@ func TestDeleteLecturer(t *testing.T) {
 		})
 	}
 }
+
+// TestCreateDelegation menguji pembuatan pelimpahan wewenang verifikasi
+func TestCreateDelegation(t *testing.T) {
+	// ARRANGE
+	mockLecturerRepo := repository.NewMockLecturerRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	service := NewLecturerService(mockLecturerRepo, mockStudentRepo)
+
+	delegatorUserID := uuid.New().String()
+	delegator := &model.Lecturer{ID: uuid.New().String(), UserID: delegatorUserID, LecturerID: "111111"}
+	delegate := &model.Lecturer{ID: uuid.New().String(), UserID: uuid.New().String(), LecturerID: "222222"}
+	mockLecturerRepo.CreateLecturer(delegator)
+	mockLecturerRepo.CreateLecturer(delegate)
+
+	tests := []struct {
+		name       string
+		userID     string
+		role       string
+		body       string
+		wantStatus int
+	}{
+		{"Valid Delegation", delegatorUserID, "Dosen Wali", `{"delegate_id":"` + delegate.ID + `","start_date":"2026-01-01","end_date":"2026-01-31"}`, 201},
+		{"Admin On Behalf", uuid.New().String(), "Admin", `{"delegate_id":"` + delegate.ID + `","start_date":"2026-02-01","end_date":"2026-02-28"}`, 201},
+		{"Other Lecturer", uuid.New().String(), "Dosen Wali", `{"delegate_id":"` + delegate.ID + `","start_date":"2026-01-01","end_date":"2026-01-31"}`, 403},
+		{"Self Delegation", delegatorUserID, "Dosen Wali", `{"delegate_id":"` + delegator.ID + `","start_date":"2026-01-01","end_date":"2026-01-31"}`, 400},
+		{"Invalid Range", delegatorUserID, "Dosen Wali", `{"delegate_id":"` + delegate.ID + `","start_date":"2026-01-31","end_date":"2026-01-01"}`, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/lecturers/:id/delegations", func(c *fiber.Ctx) error {
+				c.Locals("userID", tt.userID)
+				c.Locals("role", tt.role)
+				return service.CreateDelegation(c)
+			})
+
+			// ACT
+			req := httptest.NewRequest("POST", "/lecturers/"+delegator.ID+"/delegations", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			// ASSERT
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("CreateDelegation() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
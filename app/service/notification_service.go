@@ -0,0 +1,80 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+	"uas_be/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationService interface {
+	GetNotifications(c *fiber.Ctx) error
+	MarkAsRead(c *fiber.Ctx) error
+}
+
+type notificationServiceImpl struct {
+	notificationRepo repository.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return &notificationServiceImpl{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// GetNotifications godoc
+// @Summary Dapatkan notifikasi user
+// @Description Mengambil notifikasi milik user yang login, dari yang terbaru
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Hanya tampilkan notifikasi yang belum dibaca"
+// @Success 200 {object} model.APIResponse{data=[]model.Notification} "Notifikasi berhasil diambil"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /notifications [get]
+func (s *notificationServiceImpl) GetNotifications(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	notifications, err := s.notificationRepo.GetNotificationsByUserID(userID, c.QueryBool("unread", false))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil notifikasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "notifikasi berhasil diambil",
+		Data:    notifications,
+	})
+}
+
+// MarkAsRead godoc
+// @Summary Tandai notifikasi sudah dibaca
+// @Description Menandai satu notifikasi milik user yang login sebagai sudah dibaca
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} model.APIResponse "Notifikasi ditandai sudah dibaca"
+// @Failure 404 {object} model.APIResponse "Notifikasi tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /notifications/{id}/read [put]
+func (s *notificationServiceImpl) MarkAsRead(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	if err := s.notificationRepo.MarkNotificationRead(c.Params("id"), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.ErrorResponse(c, fiber.StatusNotFound, "notifikasi tidak ditemukan")
+		}
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengubah notifikasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "notifikasi ditandai sudah dibaca",
+	})
+}
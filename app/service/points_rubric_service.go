@@ -0,0 +1,131 @@
+package service
+
+import (
+	"strings"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+	"uas_be/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// pointsTolerancePercent adalah selisih maksimal (persen dari poin rubrik) yang boleh diberikan tanpa justifikasi
+var pointsTolerancePercent = 20
+
+// SetPointsTolerance mengatur toleransi penyimpangan poin dari rubrik dalam persen
+func SetPointsTolerance(percent int) {
+	pointsTolerancePercent = percent
+}
+
+type PointsRubricService interface {
+	GetRubricRules(c *fiber.Ctx) error
+	CreateRubricRule(c *fiber.Ctx) error
+	DeactivateRubricRule(c *fiber.Ctx) error
+}
+
+type pointsRubricServiceImpl struct {
+	achievementRepo repository.AchievementRepository
+}
+
+func NewPointsRubricService(achievementRepo repository.AchievementRepository) PointsRubricService {
+	return &pointsRubricServiceImpl{
+		achievementRepo: achievementRepo,
+	}
+}
+
+// GetRubricRules godoc
+// @Summary Dapatkan rubrik poin
+// @Description Mengambil daftar aturan rubrik poin prestasi (admin)
+// @Tags Points Rubric
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Hanya tampilkan aturan aktif"
+// @Success 200 {object} model.APIResponse{data=[]model.PointsRubricRule} "Rubrik poin berhasil diambil"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /points-rubric [get]
+func (s *pointsRubricServiceImpl) GetRubricRules(c *fiber.Ctx) error {
+	rules, err := s.achievementRepo.GetPointsRubricRules(c.QueryBool("active", false))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil rubrik poin: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "rubrik poin berhasil diambil",
+		Data:    rules,
+	})
+}
+
+// CreateRubricRule godoc
+// @Summary Tambah aturan rubrik poin
+// @Description Menambah aturan yang memetakan tipe prestasi dan atribut details (level, rank, role, indexing, ...) ke poin rekomendasi (admin)
+// @Tags Points Rubric
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body model.CreatePointsRubricRuleRequest true "Data aturan rubrik"
+// @Success 201 {object} model.APIResponse{data=model.PointsRubricRule} "Aturan rubrik berhasil dibuat"
+// @Failure 400 {object} model.APIResponse "Format request tidak valid atau field wajib tidak diisi"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /points-rubric [post]
+func (s *pointsRubricServiceImpl) CreateRubricRule(c *fiber.Ctx) error {
+	req := new(model.CreatePointsRubricRuleRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+
+	if _, status, message := checkAchievementType(s.achievementRepo, req.AchievementType); status != 0 {
+		return helper.ErrorResponse(c, status, message)
+	}
+	if req.Points < 0 {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "points tidak boleh negatif")
+	}
+
+	conditions := make(map[string]string, len(req.Conditions))
+	for key, value := range req.Conditions {
+		key = strings.TrimSpace(key)
+		if key == "" || strings.TrimSpace(value) == "" {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "nama dan nilai kondisi tidak boleh kosong")
+		}
+		conditions[key] = strings.TrimSpace(value)
+	}
+
+	rule := &model.PointsRubricRule{
+		AchievementType: req.AchievementType,
+		Conditions:      conditions,
+		Points:          req.Points,
+		Description:     req.Description,
+	}
+	if err := s.achievementRepo.CreatePointsRubricRule(rule); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal membuat aturan rubrik: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "aturan rubrik berhasil dibuat",
+		Data:    rule,
+	})
+}
+
+// DeactivateRubricRule godoc
+// @Summary Nonaktifkan aturan rubrik poin
+// @Description Menonaktifkan aturan rubrik sehingga tidak lagi dipakai untuk rekomendasi poin (admin)
+// @Tags Points Rubric
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rubric Rule ID"
+// @Success 200 {object} model.APIResponse "Aturan rubrik berhasil dinonaktifkan"
+// @Failure 404 {object} model.APIResponse "Aturan rubrik tidak ditemukan"
+// @Router /points-rubric/{id} [delete]
+func (s *pointsRubricServiceImpl) DeactivateRubricRule(c *fiber.Ctx) error {
+	if err := s.achievementRepo.DeactivatePointsRubricRule(c.Params("id")); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "aturan rubrik berhasil dinonaktifkan",
+	})
+}
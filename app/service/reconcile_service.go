@@ -0,0 +1,231 @@
+package service
+
+import (
+	"log"
+	"os"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+	"uas_be/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReconcileService interface {
+	GetReconcileReport(c *fiber.Ctx) error
+	RepairMismatches(c *fiber.Ctx) error
+}
+
+type reconcileServiceImpl struct {
+	achievementRepo repository.AchievementRepository
+}
+
+func NewReconcileService(achievementRepo repository.AchievementRepository) ReconcileService {
+	return &reconcileServiceImpl{
+		achievementRepo: achievementRepo,
+	}
+}
+
+// GetReconcileReport godoc
+// @Summary Periksa kesesuaian PostgreSQL dan MongoDB
+// @Description Memindai achievement_references dan dokumen prestasi di MongoDB lalu melaporkan dokumen yatim (tanpa reference), reference yang dokumennya hilang, achievement_title yang berbeda dengan title di MongoDB, serta student_id dokumen yang berbeda dengan pemilik reference. Tidak ada data yang diubah (admin).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.APIResponse{data=model.ReconcileReport} "Laporan rekonsiliasi"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /admin/reconcile [get]
+func (s *reconcileServiceImpl) GetReconcileReport(c *fiber.Ctx) error {
+	report, err := ReconcileAchievementStores(s.achievementRepo, false, false)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal memindai data prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "rekonsiliasi selesai dipindai",
+		Data:    report,
+	})
+}
+
+// RepairMismatches godoc
+// @Summary Perbaiki ketidaksesuaian PostgreSQL dan MongoDB
+// @Description Memindai kedua penyimpanan lalu memperbaiki setiap ketidaksesuaian: dokumen yatim dihapus, reference yang dokumennya hilang dihapus beserta history dan lampirannya, achievement_title disamakan dengan title di MongoDB, dan student_id dokumen disamakan dengan pemilik reference. Dengan dry_run=true hanya tindakan yang akan dilakukan yang dilaporkan (admin).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param dry_run query bool false "Laporkan tindakan perbaikan tanpa mengubah data"
+// @Success 200 {object} model.APIResponse{data=model.ReconcileReport} "Hasil perbaikan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /admin/reconcile [post]
+func (s *reconcileServiceImpl) RepairMismatches(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", false)
+	report, err := ReconcileAchievementStores(s.achievementRepo, true, dryRun)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal memindai data prestasi: "+err.Error())
+	}
+
+	message := "ketidaksesuaian data prestasi berhasil diperbaiki"
+	if dryRun {
+		message = "dry run: tidak ada data yang diubah"
+	} else if report.Failed > 0 {
+		message = "sebagian ketidaksesuaian gagal diperbaiki"
+	}
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: message,
+		Data:    report,
+	})
+}
+
+// ReconcileAchievementStores membandingkan achievement_references dengan dokumen MongoDB. Dokumen yang masih
+// punya entri outbox dilewati karena sedang ditulis. Jika repair aktif dan dryRun tidak, setiap
+// ketidaksesuaian langsung diperbaiki; kegagalan dicatat di mismatch tanpa menghentikan perbaikan lain.
+func ReconcileAchievementStores(achievementRepo repository.AchievementRepository, repair, dryRun bool) (*model.ReconcileReport, error) {
+	references, err := achievementRepo.GetReconcileReferences()
+	if err != nil {
+		return nil, err
+	}
+	documents, err := achievementRepo.GetReconcileDocuments()
+	if err != nil {
+		return nil, err
+	}
+	pendingIDs, err := achievementRepo.GetPendingOutboxDocuments()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]bool)
+	for _, mongoID := range pendingIDs {
+		pending[mongoID] = true
+	}
+	documentsByID := make(map[string]*model.ReconcileDocument)
+	for _, document := range documents {
+		documentsByID[document.MongoAchievementID] = document
+	}
+	referencesByDocument := make(map[string][]*model.ReconcileReference)
+	for _, ref := range references {
+		referencesByDocument[ref.MongoAchievementID] = append(referencesByDocument[ref.MongoAchievementID], ref)
+	}
+
+	report := &model.ReconcileReport{
+		Repair:            repair,
+		DryRun:            dryRun,
+		ScannedReferences: len(references),
+		ScannedDocuments:  len(documents),
+		PendingSync:       len(pending),
+		Mismatches:        []*model.ReconcileMismatch{},
+		Summary:           map[string]int{},
+	}
+
+	for _, ref := range references {
+		if pending[ref.MongoAchievementID] {
+			continue
+		}
+		document, ok := documentsByID[ref.MongoAchievementID]
+		if !ok {
+			report.Mismatches = append(report.Mismatches, &model.ReconcileMismatch{
+				Kind:               model.ReconcileDanglingReference,
+				ReferenceID:        ref.ReferenceID,
+				MongoAchievementID: ref.MongoAchievementID,
+				Actual:             ref.Title,
+				Action:             model.ReconcileActionDeleteRef,
+			})
+			continue
+		}
+		if ref.Title != document.Title {
+			report.Mismatches = append(report.Mismatches, &model.ReconcileMismatch{
+				Kind:               model.ReconcileTitleDrift,
+				ReferenceID:        ref.ReferenceID,
+				MongoAchievementID: ref.MongoAchievementID,
+				Expected:           document.Title,
+				Actual:             ref.Title,
+				Action:             model.ReconcileActionUpdateTitle,
+			})
+		}
+	}
+
+	for _, document := range documents {
+		if pending[document.MongoAchievementID] {
+			continue
+		}
+		refs := referencesByDocument[document.MongoAchievementID]
+		if len(refs) == 0 {
+			report.Mismatches = append(report.Mismatches, &model.ReconcileMismatch{
+				Kind:               model.ReconcileOrphanDocument,
+				MongoAchievementID: document.MongoAchievementID,
+				Actual:             document.Title,
+				Action:             model.ReconcileActionDeleteDocument,
+			})
+			continue
+		}
+		owner := documentOwner(refs)
+		if owner != nil && owner.StudentID != document.StudentID {
+			report.Mismatches = append(report.Mismatches, &model.ReconcileMismatch{
+				Kind:               model.ReconcileStudentMismatch,
+				ReferenceID:        owner.ReferenceID,
+				MongoAchievementID: document.MongoAchievementID,
+				Expected:           owner.StudentID,
+				Actual:             document.StudentID,
+				Action:             model.ReconcileActionUpdateStudent,
+			})
+		}
+	}
+
+	for _, mismatch := range report.Mismatches {
+		report.Summary[mismatch.Kind]++
+		if !repair || dryRun {
+			continue
+		}
+		if err := repairMismatch(achievementRepo, mismatch); err != nil {
+			mismatch.Error = err.Error()
+			report.Failed++
+			continue
+		}
+		mismatch.Repaired = true
+		report.Repaired++
+	}
+
+	return report, nil
+}
+
+// documentOwner menentukan reference pemilik dokumen: ketua untuk prestasi tim, atau satu-satunya reference.
+// Nil jika pemilik tidak bisa ditentukan sehingga student_id dokumen tidak diperiksa.
+func documentOwner(refs []*model.ReconcileReference) *model.ReconcileReference {
+	for _, ref := range refs {
+		if ref.TeamRole != nil && *ref.TeamRole == model.TeamRoleLeader {
+			return ref
+		}
+	}
+	if len(refs) == 1 {
+		return refs[0]
+	}
+	return nil
+}
+
+func repairMismatch(achievementRepo repository.AchievementRepository, mismatch *model.ReconcileMismatch) error {
+	switch mismatch.Action {
+	case model.ReconcileActionDeleteDocument:
+		return achievementRepo.DeleteOrphanDocument(mismatch.MongoAchievementID)
+	case model.ReconcileActionUpdateTitle:
+		return achievementRepo.UpdateReferenceTitle(mismatch.ReferenceID, mismatch.Expected)
+	case model.ReconcileActionUpdateStudent:
+		return achievementRepo.SetAchievementDocumentStudent(mismatch.MongoAchievementID, mismatch.Expected)
+	case model.ReconcileActionDeleteRef:
+		attachments, err := achievementRepo.GetAttachmentsByAchievementID(mismatch.ReferenceID)
+		if err != nil {
+			return err
+		}
+		if err := achievementRepo.DeleteDanglingReference(mismatch.ReferenceID); err != nil {
+			return err
+		}
+		for _, attachment := range attachments {
+			if err := os.Remove("." + attachment.FilePath); err != nil && !os.IsNotExist(err) {
+				log.Println("warning: failed to remove attachment file:", err)
+			}
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// reconcileFixture membuat satu prestasi yang sesuai, satu dengan title berbeda, satu dengan student_id berbeda,
+// satu reference tanpa dokumen, dan satu dokumen tanpa reference
+func reconcileFixture() (*repository.MockAchievementRepository, map[string]string) {
+	mockAchRepo := repository.NewMockAchievementRepository()
+	ids := map[string]string{
+		"ok":       uuid.New().String(),
+		"drift":    uuid.New().String(),
+		"student":  uuid.New().String(),
+		"dangling": uuid.New().String(),
+		"orphan":   uuid.New().String(),
+	}
+	mockAchRepo.Create(&model.Achievement{Title: "Juara 1"}, ids["ok"])
+	mockAchRepo.Create(&model.Achievement{Title: "Judul lama"}, ids["drift"])
+	mockAchRepo.Create(&model.Achievement{Title: "Sertifikasi"}, ids["student"])
+	mockAchRepo.Create(&model.Achievement{Title: "Hilang"}, ids["dangling"])
+	mockAchRepo.Documents = map[string]*model.ReconcileDocument{
+		ids["ok"]:      {MongoAchievementID: ids["ok"], StudentID: ids["ok"], Title: "Juara 1"},
+		ids["drift"]:   {MongoAchievementID: ids["drift"], StudentID: ids["drift"], Title: "Judul baru"},
+		ids["student"]: {MongoAchievementID: ids["student"], StudentID: "other-student", Title: "Sertifikasi"},
+		ids["orphan"]:  {MongoAchievementID: ids["orphan"], StudentID: ids["orphan"], Title: "Yatim"},
+	}
+	return mockAchRepo, ids
+}
+
+// TestReconcileAchievementStores_Report tests every kind of mismatch is reported without changing data
+func TestReconcileAchievementStores_Report(t *testing.T) {
+	// Arrange
+	mockAchRepo, ids := reconcileFixture()
+
+	// Act
+	report, err := ReconcileAchievementStores(mockAchRepo, false, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.ScannedReferences)
+	assert.Equal(t, 4, report.ScannedDocuments)
+	assert.Len(t, report.Mismatches, 4)
+	assert.Equal(t, map[string]int{
+		model.ReconcileDanglingReference: 1,
+		model.ReconcileTitleDrift:        1,
+		model.ReconcileStudentMismatch:   1,
+		model.ReconcileOrphanDocument:    1,
+	}, report.Summary)
+	byKind := make(map[string]*model.ReconcileMismatch)
+	for _, mismatch := range report.Mismatches {
+		byKind[mismatch.Kind] = mismatch
+		assert.False(t, mismatch.Repaired)
+	}
+	assert.Equal(t, ids["dangling"], byKind[model.ReconcileDanglingReference].ReferenceID)
+	assert.Equal(t, "Judul baru", byKind[model.ReconcileTitleDrift].Expected)
+	assert.Equal(t, "Judul lama", byKind[model.ReconcileTitleDrift].Actual)
+	assert.Equal(t, ids["student"], byKind[model.ReconcileStudentMismatch].Expected)
+	assert.Equal(t, "other-student", byKind[model.ReconcileStudentMismatch].Actual)
+	assert.Equal(t, ids["orphan"], byKind[model.ReconcileOrphanDocument].MongoAchievementID)
+	assert.Equal(t, 0, report.Repaired)
+	_, err = mockAchRepo.GetAchievementByID(ids["dangling"])
+	assert.NoError(t, err)
+}
+
+// TestReconcileAchievementStores_DryRun tests dry run reports the planned repairs but leaves both stores untouched
+func TestReconcileAchievementStores_DryRun(t *testing.T) {
+	// Arrange
+	mockAchRepo, ids := reconcileFixture()
+
+	// Act
+	report, err := ReconcileAchievementStores(mockAchRepo, true, true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Mismatches, 4)
+	assert.Equal(t, 0, report.Repaired)
+	assert.Contains(t, mockAchRepo.Documents, ids["orphan"])
+	drift, _ := mockAchRepo.GetAchievementByID(ids["drift"])
+	assert.Equal(t, "Judul lama", drift.Title)
+}
+
+// TestReconcileAchievementStores_Repair tests repairs converge both stores so a second scan finds nothing
+func TestReconcileAchievementStores_Repair(t *testing.T) {
+	// Arrange
+	mockAchRepo, ids := reconcileFixture()
+
+	// Act
+	report, err := ReconcileAchievementStores(mockAchRepo, true, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Repaired)
+	assert.Equal(t, 0, report.Failed)
+	assert.NotContains(t, mockAchRepo.Documents, ids["orphan"])
+	assert.Equal(t, ids["student"], mockAchRepo.Documents[ids["student"]].StudentID)
+	drift, _ := mockAchRepo.GetAchievementByID(ids["drift"])
+	assert.Equal(t, "Judul baru", drift.Title)
+	dangling, _ := mockAchRepo.GetAchievementByID(ids["dangling"])
+	assert.Nil(t, dangling)
+
+	// Act
+	rescan, err := ReconcileAchievementStores(mockAchRepo, false, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, rescan.Mismatches)
+}
+
+// TestReconcileAchievementStores_SkipsPendingOutbox tests documents still being written through the outbox are not reported
+func TestReconcileAchievementStores_SkipsPendingOutbox(t *testing.T) {
+	// Arrange
+	mockAchRepo, ids := reconcileFixture()
+	mockAchRepo.PendingOutbox = []string{ids["dangling"], ids["orphan"]}
+
+	// Act
+	report, err := ReconcileAchievementStores(mockAchRepo, true, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.PendingSync)
+	assert.Len(t, report.Mismatches, 2)
+	assert.Zero(t, report.Summary[model.ReconcileDanglingReference])
+	assert.Zero(t, report.Summary[model.ReconcileOrphanDocument])
+	assert.Contains(t, mockAchRepo.Documents, ids["orphan"])
+}
+
+// TestReconcileAchievementStores_TeamMembersShareDocument tests team member references are not reported as student mismatches
+func TestReconcileAchievementStores_TeamMembersShareDocument(t *testing.T) {
+	// Arrange
+	mockAchRepo := repository.NewMockAchievementRepository()
+	leaderID := uuid.New().String()
+	memberID := uuid.New().String()
+	team, _ := mockAchRepo.CreateTeamAchievement(&model.Achievement{Title: "Lomba Tim"}, []*model.TeamMember{
+		{StudentID: leaderID, Role: model.TeamRoleLeader},
+		{StudentID: memberID, Role: model.TeamRoleMember},
+	})
+	references, _ := mockAchRepo.GetReconcileReferences()
+	mongoID := references[0].MongoAchievementID
+	mockAchRepo.Documents = map[string]*model.ReconcileDocument{
+		mongoID: {MongoAchievementID: mongoID, StudentID: leaderID, Title: "Lomba Tim"},
+	}
+
+	// Act
+	report, err := ReconcileAchievementStores(mockAchRepo, false, false)
+
+	// Assert
+	assert.NotNil(t, team)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Mismatches)
+}
+
+// TestRepairMismatches_Endpoint tests the admin endpoint honours dry_run
+func TestRepairMismatches_Endpoint(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockAchRepo, ids := reconcileFixture()
+	reconcileService := NewReconcileService(mockAchRepo)
+	app.Get("/admin/reconcile", reconcileService.GetReconcileReport)
+	app.Post("/admin/reconcile", reconcileService.RepairMismatches)
+
+	// Act
+	resp, _ := app.Test(httptest.NewRequest("POST", "/admin/reconcile?dry_run=true", nil))
+	var result struct {
+		Data model.ReconcileReport `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, result.Data.DryRun)
+	assert.Len(t, result.Data.Mismatches, 4)
+	assert.Contains(t, mockAchRepo.Documents, ids["orphan"])
+
+	// Act
+	resp, _ = app.Test(httptest.NewRequest("POST", "/admin/reconcile", nil))
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 4, result.Data.Repaired)
+	assert.NotContains(t, mockAchRepo.Documents, ids["orphan"])
+}
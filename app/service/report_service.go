@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 	"uas_be/app/model"
@@ -16,6 +17,7 @@ type ReportService interface {
 	GetStudentReport(c *fiber.Ctx) error
 	GetStatisticsByPeriod(c *fiber.Ctx) error
 	GetStatisticsByType(c *fiber.Ctx) error
+	GetStatisticsByTag(c *fiber.Ctx) error
 	GetTopStudents(c *fiber.Ctx) error
 }
 
@@ -100,6 +102,8 @@ func (s *reportServiceImpl) GetStatistics(c *fiber.Ctx) error {
 	periodCount := make(map[string]int)
 	totalPoints := 0
 	verifiedCount := 0
+	expiredCount := 0
+	now := time.Now()
 
 	// Initialize competition levels with default values
 	competitionLevels := []string{"school", "city", "provincial", "national", "international"}
@@ -118,9 +122,14 @@ func (s *reportServiceImpl) GetStatistics(c *fiber.Ctx) error {
 		typeCount[ach.AchievementType]++
 
 		if ach.Status == "verified" {
-			totalPoints += ach.Points
 			verifiedCount++
 		}
+		if countsTowardPoints(ach, now) {
+			totalPoints += ach.Points
+		}
+		if ach.IsExpired(now) {
+			expiredCount++
+		}
 
 		// Count by competition level (from details)
 		if ach.Details != nil {
@@ -144,7 +153,7 @@ func (s *reportServiceImpl) GetStatistics(c *fiber.Ctx) error {
 	// Get top students (only for admin view)
 	var topStudents []map[string]interface{}
 	if role == "Admin" {
-		studentStats, err := s.achievementRepo.GetTopStudents(10) // Get top 10 students
+		studentStats, err := s.achievementRepo.GetTopStudents(10, countExpiredPoints) // Get top 10 students
 		if err != nil {
 			// Log error but don't fail the request
 			fmt.Printf("Error getting top students: %v\n", err)
@@ -167,6 +176,8 @@ func (s *reportServiceImpl) GetStatistics(c *fiber.Ctx) error {
 	stats["summary"] = map[string]interface{}{
 		"total":             totalAchievements,
 		"verification_rate": verificationRate,
+		"total_points":      totalPoints,
+		"expired":           expiredCount,
 	}
 	stats["top_students"] = topStudents
 
@@ -179,7 +190,7 @@ func (s *reportServiceImpl) GetStatistics(c *fiber.Ctx) error {
 
 // GetStudentReport godoc
 // @Summary Dapatkan laporan mahasiswa
-// @Description Mengambil laporan lengkap prestasi mahasiswa berdasarkan ID
+// @Description Mengambil laporan lengkap prestasi mahasiswa berdasarkan ID, termasuk anggota tim untuk prestasi tim
 // @Tags Reports
 // @Accept json
 // @Produce json
@@ -237,13 +248,27 @@ func (s *reportServiceImpl) GetStudentReport(c *fiber.Ctx) error {
 	totalPoints := 0
 	statusCount := make(map[string]int)
 	typeCount := make(map[string]int)
+	teamAchievements := 0
+	expiredAchievements := 0
+	now := time.Now()
 
 	for _, ach := range achievements {
 		statusCount[ach.Status]++
 		typeCount[ach.AchievementType]++
-		if ach.Status == "verified" {
+		if countsTowardPoints(ach, now) {
 			totalPoints += ach.Points
 		}
+		if ach.IsExpired(now) {
+			expiredAchievements++
+		}
+		if ach.TeamID != nil {
+			teamAchievements++
+			members, err := s.achievementRepo.GetTeamMembers(*ach.TeamID)
+			if err != nil {
+				return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil anggota tim")
+			}
+			ach.TeamMembers = members
+		}
 	}
 
 	report["student"] = student
@@ -251,6 +276,9 @@ func (s *reportServiceImpl) GetStudentReport(c *fiber.Ctx) error {
 	report["total_points"] = totalPoints
 	report["by_status"] = statusCount
 	report["by_type"] = typeCount
+	report["team_achievements"] = teamAchievements
+	report["expired_achievements"] = expiredAchievements
+	report["expired_points_counted"] = countExpiredPoints
 	report["achievements"] = achievements
 
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
@@ -294,7 +322,7 @@ func (s *reportServiceImpl) GetStatisticsByPeriod(c *fiber.Ctx) error {
 		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format end_date tidak valid (gunakan YYYY-MM-DD)")
 	}
 
-	stats, err := s.achievementRepo.GetAchievementStatsByPeriod(startDate, endDate, role, userID)
+	stats, err := s.achievementRepo.GetAchievementStatsByPeriod(startDate, endDate, role, userID, countExpiredPoints)
 	if err != nil {
 		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil statistik: "+err.Error())
 	}
@@ -308,7 +336,7 @@ func (s *reportServiceImpl) GetStatisticsByPeriod(c *fiber.Ctx) error {
 
 // GetStatisticsByType godoc
 // @Summary Dapatkan statistik berdasarkan tipe
-// @Description Mengambil statistik prestasi berdasarkan tipe prestasi
+// @Description Mengambil statistik prestasi berdasarkan tipe prestasi beserta nama tipe dari katalog
 // @Tags Reports
 // @Accept json
 // @Produce json
@@ -325,6 +353,20 @@ func (s *reportServiceImpl) GetStatisticsByType(c *fiber.Ctx) error {
 		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil statistik: "+err.Error())
 	}
 
+	// Label dan daftar tipe diambil dari katalog; tipe aktif tanpa prestasi tetap ditampilkan dengan nilai kosong
+	types, err := s.achievementRepo.GetAchievementTypes(false)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tipe prestasi: "+err.Error())
+	}
+	if typeStats, ok := stats["stats_by_type"].(map[string]map[string]int); ok {
+		for _, achievementType := range types {
+			if _, exists := typeStats[achievementType.Code]; !exists && achievementType.IsActive {
+				typeStats[achievementType.Code] = map[string]int{}
+			}
+		}
+	}
+	stats["types"] = types
+
 	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
 		Status:  "success",
 		Message: "statistik berdasarkan tipe berhasil diambil",
@@ -332,6 +374,71 @@ func (s *reportServiceImpl) GetStatisticsByType(c *fiber.Ctx) error {
 	})
 }
 
+// GetStatisticsByTag godoc
+// @Summary Dapatkan statistik berdasarkan tag
+// @Description Mengambil jumlah prestasi per status dan poin terverifikasi untuk setiap tag. Variasi penulisan dan alias digabung ke tag kanonik di katalog; tag katalog tanpa prestasi tetap ditampilkan. Poin prestasi kedaluwarsa mengikuti aturan laporan.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.APIResponse{data=object{tags=[]model.TagStat,unused_catalog_tags=int}} "Statistik berdasarkan tag berhasil diambil"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /reports/statistics/tag [get]
+func (s *reportServiceImpl) GetStatisticsByTag(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	role := c.Locals("role").(string)
+
+	stored, err := s.achievementRepo.GetAchievementStatsByTag(role, userID, countExpiredPoints)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil statistik: "+err.Error())
+	}
+	catalog, err := s.achievementRepo.GetTags()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+
+	// Tag katalog ditampilkan dengan nama kanonik; data lama yang belum dinormalisasi ikut digabung
+	index := model.NewTagIndex(catalog)
+	byKey := make(map[string]*model.TagStat)
+	unused := 0
+	for _, tag := range catalog {
+		byKey[model.TagKey(tag.Name)] = &model.TagStat{Tag: tag.Name, Slug: tag.Slug, ByStatus: map[string]int{}}
+	}
+	for tag, stat := range stored {
+		key := model.TagKey(tag)
+		if canonical := index.Resolve(tag); canonical != nil {
+			key = model.TagKey(canonical.Name)
+		}
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = &model.TagStat{Tag: model.CleanTag(tag), ByStatus: map[string]int{}}
+		}
+		byKey[key].Add(stat)
+	}
+
+	tagStats := make([]*model.TagStat, 0, len(byKey))
+	for _, stat := range byKey {
+		if stat.Total == 0 {
+			unused++
+		}
+		tagStats = append(tagStats, stat)
+	}
+	sort.Slice(tagStats, func(i, j int) bool {
+		if tagStats[i].Total != tagStats[j].Total {
+			return tagStats[i].Total > tagStats[j].Total
+		}
+		return tagStats[i].Tag < tagStats[j].Tag
+	})
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "statistik berdasarkan tag berhasil diambil",
+		Data: fiber.Map{
+			"tags":                tagStats,
+			"unused_catalog_tags": unused,
+		},
+	})
+}
+
 // GetTopStudents godoc
 // @Summary Dapatkan mahasiswa dengan prestasi terbaik
 // @Description Mengambil daftar mahasiswa dengan poin prestasi tertinggi (hanya admin)
@@ -357,7 +464,7 @@ func (s *reportServiceImpl) GetTopStudents(c *fiber.Ctx) error {
 		limit = 10
 	}
 
-	topStudents, err := s.achievementRepo.GetTopStudents(limit)
+	topStudents, err := s.achievementRepo.GetTopStudents(limit, countExpiredPoints)
 	if err != nil {
 		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil top students: "+err.Error())
 	}
@@ -0,0 +1,447 @@
+package service
+
+import (
+	"strings"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+	"uas_be/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxTagLength sama dengan panjang kolom name dan alias di tabel tags
+const maxTagLength = 100
+
+// normalizeAchievementTags mengganti tag prestasi dengan nama kanonik dari katalog dan menghapus duplikat
+func normalizeAchievementTags(repo repository.AchievementRepository, tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	catalog, err := repo.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	return model.NewTagIndex(catalog).Normalize(tags), nil
+}
+
+type TagService interface {
+	GetTags(c *fiber.Ctx) error
+	SuggestTags(c *fiber.Ctx) error
+	GetTag(c *fiber.Ctx) error
+	CreateTag(c *fiber.Ctx) error
+	UpdateTag(c *fiber.Ctx) error
+	DeleteTag(c *fiber.Ctx) error
+	MergeTags(c *fiber.Ctx) error
+}
+
+type tagServiceImpl struct {
+	achievementRepo repository.AchievementRepository
+}
+
+func NewTagService(achievementRepo repository.AchievementRepository) TagService {
+	return &tagServiceImpl{
+		achievementRepo: achievementRepo,
+	}
+}
+
+// catalogWithUsage mengambil katalog tag dan mengisi jumlah pemakaian, termasuk variasi penulisan lama
+func (s *tagServiceImpl) catalogWithUsage() ([]*model.Tag, error) {
+	tags, err := s.achievementRepo.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.achievementRepo.GetTagUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	index := model.NewTagIndex(tags)
+	for stored, count := range usage {
+		if tag := index.Resolve(stored); tag != nil {
+			tag.Usage += count
+		}
+	}
+	if tags == nil {
+		tags = []*model.Tag{}
+	}
+	return tags, nil
+}
+
+// GetTags godoc
+// @Summary Dapatkan katalog tag
+// @Description Mengambil semua tag kanonik beserta alias dan jumlah prestasi yang memakainya
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.APIResponse{data=[]model.Tag} "Tag berhasil diambil"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /tags [get]
+func (s *tagServiceImpl) GetTags(c *fiber.Ctx) error {
+	tags, err := s.catalogWithUsage()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tag berhasil diambil",
+		Data:    tags,
+	})
+}
+
+// SuggestTags godoc
+// @Summary Autocomplete tag
+// @Description Mencari tag yang nama atau aliasnya cocok dengan q. Hasil yang cocok persis dan berupa awalan didahulukan, lalu tag yang paling sering dipakai.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Teks yang sedang diketik"
+// @Param limit query int false "Jumlah saran maksimal (default 10, maksimal 50)"
+// @Success 200 {object} model.APIResponse{data=[]model.TagSuggestion} "Saran tag berhasil diambil"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /tags/suggest [get]
+func (s *tagServiceImpl) SuggestTags(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 10)
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	tags, err := s.catalogWithUsage()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "saran tag berhasil diambil",
+		Data:    model.NewTagIndex(tags).Suggest(c.Query("q"), limit),
+	})
+}
+
+// GetTag godoc
+// @Summary Dapatkan tag
+// @Description Mengambil satu tag berdasarkan slug
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Slug tag"
+// @Success 200 {object} model.APIResponse{data=model.Tag} "Tag berhasil diambil"
+// @Failure 404 {object} model.APIResponse "Tag tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /tags/{slug} [get]
+func (s *tagServiceImpl) GetTag(c *fiber.Ctx) error {
+	tag, err := s.achievementRepo.GetTag(c.Params("slug"))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+	if tag == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "tag tidak ditemukan")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tag berhasil diambil",
+		Data:    tag,
+	})
+}
+
+// CreateTag godoc
+// @Summary Tambah tag ke katalog
+// @Description Menambah tag kanonik beserta alias. Prestasi yang sudah memakai nama atau alias tag ini ditulis ulang ke nama kanonik (admin).
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body model.CreateTagRequest true "Nama dan alias tag"
+// @Success 201 {object} model.APIResponse{data=model.Tag} "Tag berhasil dibuat"
+// @Failure 400 {object} model.APIResponse "Nama kosong atau terlalu panjang"
+// @Failure 409 {object} model.APIResponse "Nama atau alias sudah dipakai tag lain"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /tags [post]
+func (s *tagServiceImpl) CreateTag(c *fiber.Ctx) error {
+	req := new(model.CreateTagRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+
+	tag := &model.Tag{Name: model.CleanTag(req.Name), Slug: model.TagSlug(req.Name)}
+	if tag.Name == "" || tag.Slug == "" {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "name harus diisi dan mengandung huruf atau angka")
+	}
+
+	catalog, err := s.achievementRepo.GetTags()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+	index := model.NewTagIndex(catalog)
+	for _, key := range []string{tag.Name, tag.Slug} {
+		if existing := index.Resolve(key); existing != nil {
+			return helper.ErrorResponse(c, fiber.StatusConflict, key+" sudah dipakai tag "+existing.Name)
+		}
+	}
+
+	var actionErr *actionError
+	if tag.Aliases, actionErr = cleanTagAliases(index, tag, req.Aliases); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	if err := s.achievementRepo.CreateTag(tag); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal membuat tag: "+err.Error())
+	}
+	if _, err := s.achievementRepo.RenameAchievementTags(append([]string{tag.Name}, tag.Aliases...), tag.Name); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "tag dibuat tetapi gagal menormalisasi tag prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tag berhasil dibuat",
+		Data:    tag,
+	})
+}
+
+// UpdateTag godoc
+// @Summary Ubah tag
+// @Description Mengganti nama kanonik dan/atau seluruh alias tag. Slug tidak berubah. Prestasi yang memakai nama lama atau alias ditulis ulang ke nama baru (admin).
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Slug tag"
+// @Param body body model.UpdateTagRequest true "Data yang diubah"
+// @Success 200 {object} model.APIResponse{data=model.Tag} "Tag berhasil diubah"
+// @Failure 400 {object} model.APIResponse "Nama kosong atau terlalu panjang"
+// @Failure 404 {object} model.APIResponse "Tag tidak ditemukan"
+// @Failure 409 {object} model.APIResponse "Nama atau alias sudah dipakai tag lain"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /tags/{slug} [put]
+func (s *tagServiceImpl) UpdateTag(c *fiber.Ctx) error {
+	req := new(model.UpdateTagRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+
+	existing, err := s.achievementRepo.GetTag(c.Params("slug"))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+	if existing == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "tag tidak ditemukan")
+	}
+
+	catalog, err := s.achievementRepo.GetTags()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+	index := model.NewTagIndex(catalog)
+
+	updated := *existing
+	if req.Name != nil {
+		updated.Name = model.CleanTag(*req.Name)
+		if updated.Name == "" {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "name tidak boleh kosong")
+		}
+		if owner := index.Resolve(updated.Name); owner != nil && owner.Slug != existing.Slug {
+			return helper.ErrorResponse(c, fiber.StatusConflict, updated.Name+" sudah dipakai tag "+owner.Name)
+		}
+	}
+	aliases := existing.Aliases
+	if req.Aliases != nil {
+		aliases = *req.Aliases
+	}
+	var actionErr *actionError
+	if updated.Aliases, actionErr = cleanTagAliases(index, &updated, aliases); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	if err := s.achievementRepo.UpdateTag(&updated); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengubah tag: "+err.Error())
+	}
+	variants := append([]string{existing.Name, updated.Name}, updated.Aliases...)
+	if _, err := s.achievementRepo.RenameAchievementTags(variants, updated.Name); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "tag diubah tetapi gagal menormalisasi tag prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tag berhasil diubah",
+		Data:    &updated,
+	})
+}
+
+// DeleteTag godoc
+// @Summary Hapus tag dari katalog
+// @Description Menghapus tag dan aliasnya dari katalog. Prestasi tetap menyimpan nama tag sebagai tag bebas (admin).
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Slug tag"
+// @Success 200 {object} model.APIResponse "Tag berhasil dihapus"
+// @Failure 404 {object} model.APIResponse "Tag tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /tags/{slug} [delete]
+func (s *tagServiceImpl) DeleteTag(c *fiber.Ctx) error {
+	existing, err := s.achievementRepo.GetTag(c.Params("slug"))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+	if existing == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "tag tidak ditemukan")
+	}
+
+	if err := s.achievementRepo.DeleteTag(existing.Slug); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal menghapus tag: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tag berhasil dihapus",
+	})
+}
+
+// MergeTags godoc
+// @Summary Gabungkan tag
+// @Description Menggabungkan tag lain ke tag ini. Sources boleh berupa slug tag di katalog (tag tersebut dihapus, nama dan aliasnya menjadi alias tag ini) maupun tag bebas yang belum ada di katalog. Semua prestasi yang memakai tag sumber ditulis ulang ke nama tag ini (admin).
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Slug tag tujuan"
+// @Param body body model.MergeTagsRequest true "Tag yang digabungkan"
+// @Success 200 {object} model.APIResponse{data=model.TagMergeResult} "Tag berhasil digabungkan"
+// @Failure 400 {object} model.APIResponse "Sources kosong atau berisi tag tujuan"
+// @Failure 404 {object} model.APIResponse "Tag tujuan tidak ditemukan"
+// @Failure 500 {object} model.APIResponse "Internal server error"
+// @Router /tags/{slug}/merge [post]
+func (s *tagServiceImpl) MergeTags(c *fiber.Ctx) error {
+	req := new(model.MergeTagsRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "format request tidak valid: "+err.Error())
+	}
+	if len(req.Sources) == 0 {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "sources tidak boleh kosong")
+	}
+
+	target, err := s.achievementRepo.GetTag(c.Params("slug"))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+	if target == nil {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "tag tidak ditemukan")
+	}
+
+	catalog, err := s.achievementRepo.GetTags()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+	}
+	index := model.NewTagIndex(catalog)
+
+	// Slug dicek lebih dulu agar "ai" berarti tag katalog ai, bukan alias "ai" milik tag lain. Tag yang
+	// bukan slug maupun alias di katalog dianggap tag bebas dan langsung menjadi alias.
+	merged := *target
+	aliases := append([]string{}, target.Aliases...)
+	variants := []string{target.Name}
+	mergedSlugs := []string{}
+	for _, source := range req.Sources {
+		source = model.CleanTag(source)
+		if source == "" {
+			continue
+		}
+		if model.TagSlug(source) == target.Slug || model.TagKey(source) == model.TagKey(target.Name) {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "tag tidak bisa digabungkan ke dirinya sendiri")
+		}
+
+		tag, err := s.achievementRepo.GetTag(model.TagSlug(source))
+		if err != nil {
+			return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal mengambil tag: "+err.Error())
+		}
+		if tag == nil {
+			tag = index.Resolve(source)
+		}
+		switch {
+		case tag != nil && tag.Slug == target.Slug:
+			// Sudah menjadi alias tag tujuan
+			continue
+		case tag != nil && containsString(mergedSlugs, tag.Slug):
+			continue
+		case tag != nil:
+			mergedSlugs = append(mergedSlugs, tag.Slug)
+			aliases = append(aliases, tag.Name)
+			aliases = append(aliases, tag.Aliases...)
+			variants = append(variants, tag.Name)
+			variants = append(variants, tag.Aliases...)
+		default:
+			aliases = append(aliases, source)
+			variants = append(variants, source)
+		}
+	}
+
+	// Tag sumber akan dihapus, sehingga nama dan aliasnya tidak lagi dianggap bentrok
+	remaining := []*model.Tag{}
+	for _, tag := range catalog {
+		if !containsString(mergedSlugs, tag.Slug) {
+			remaining = append(remaining, tag)
+		}
+	}
+	var actionErr *actionError
+	if merged.Aliases, actionErr = cleanTagAliases(model.NewTagIndex(remaining), &merged, aliases); actionErr != nil {
+		return actionErr.respond(c)
+	}
+
+	if err := s.achievementRepo.MergeTags(&merged, mergedSlugs); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "gagal menggabungkan tag: "+err.Error())
+	}
+	updated, err := s.achievementRepo.RenameAchievementTags(append(variants, merged.Aliases...), merged.Name)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "tag digabungkan tetapi gagal menulis ulang tag prestasi: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.APIResponse{
+		Status:  "success",
+		Message: "tag berhasil digabungkan",
+		Data: &model.TagMergeResult{
+			Tag:                 &merged,
+			MergedTags:          mergedSlugs,
+			UpdatedAchievements: updated,
+		},
+	})
+}
+
+// cleanTagAliases merapikan alias, membuang alias yang sama dengan nama/slug tag sendiri atau duplikat,
+// dan menolak alias yang sudah dipakai tag lain
+func cleanTagAliases(index *model.TagIndex, tag *model.Tag, aliases []string) ([]string, *actionError) {
+	if len(tag.Name) > maxTagLength {
+		return nil, newActionError(fiber.StatusBadRequest, "name maksimal 100 karakter")
+	}
+
+	cleaned := []string{}
+	seen := map[string]bool{model.TagKey(tag.Name): true, model.TagKey(tag.Slug): true}
+	for _, alias := range aliases {
+		alias = model.CleanTag(alias)
+		key := model.TagKey(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+		if len(alias) > maxTagLength {
+			return nil, newActionError(fiber.StatusBadRequest, "alias maksimal 100 karakter: "+alias)
+		}
+		if owner := index.Resolve(alias); owner != nil && owner.Slug != tag.Slug {
+			return nil, newActionError(fiber.StatusConflict, "alias "+alias+" sudah dipakai tag "+owner.Name)
+		}
+		seen[key] = true
+		cleaned = append(cleaned, alias)
+	}
+	return cleaned, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
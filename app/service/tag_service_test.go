@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"uas_be/app/model"
+	"uas_be/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// tagFixture menyiapkan route katalog tag, pembuatan prestasi, dan statistik tag, beserta satu prestasi lama
+// terverifikasi bertag bebas "ai" dan "Robotik"
+type tagFixture struct {
+	app         *fiber.App
+	mockAchRepo *repository.MockAchievementRepository
+	legacy      *model.AchievementWithReference
+	studentID   string
+}
+
+func newTagFixture() *tagFixture {
+	app := fiber.New()
+	mockAchRepo := repository.NewMockAchievementRepository()
+	mockStudentRepo := repository.NewMockStudentRepository()
+	achievementService := NewAchievementService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	reportService := NewReportService(mockAchRepo, mockStudentRepo, repository.NewMockLecturerRepository())
+	tagService := NewTagService(mockAchRepo)
+	studentID, userID := uuid.New().String(), uuid.New().String()
+	mockStudentRepo.CreateStudent(&model.Student{ID: studentID, UserID: userID, StudentID: "123456"})
+	legacy, _ := mockAchRepo.Create(&model.Achievement{AchievementType: model.AchievementTypeOther, Title: "Hackathon", Tags: []string{"ai", "Robotik"}}, uuid.New().String())
+	legacy.Status = model.AchievementStatusVerified
+	legacy.Points = 25
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("role", "Admin")
+		return c.Next()
+	})
+	app.Post("/tags", tagService.CreateTag)
+	app.Get("/tags/suggest", tagService.SuggestTags)
+	app.Post("/tags/:slug/merge", tagService.MergeTags)
+	app.Get("/reports/statistics/tag", reportService.GetStatisticsByTag)
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		return achievementService.CreateAchievement(c)
+	})
+	return &tagFixture{app: app, mockAchRepo: mockAchRepo, legacy: legacy, studentID: studentID}
+}
+
+func (f *tagFixture) post(path string, body interface{}) int {
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := f.app.Test(req)
+	return resp.StatusCode
+}
+
+// createAITag menambahkan tag katalog "Artificial Intelligence" beserta alias-aliasnya
+func (f *tagFixture) createAITag() int {
+	return f.post("/tags", model.CreateTagRequest{Name: "Artificial Intelligence", Aliases: []string{"AI", "kecerdasan buatan", "ai"}})
+}
+
+// createChatbotAchievement membuat prestasi baru yang tagnya ditulis dengan variasi alias dan spasi
+func (f *tagFixture) createChatbotAchievement() int {
+	return f.post("/achievements", model.CreateAchievementRequest{
+		AchievementType: model.AchievementTypeOther,
+		Title:           "Riset Chatbot",
+		Tags:            []string{"Kecerdasan  Buatan", "#AI", " robotik "},
+	})
+}
+
+// TestCreateTag_NormalizesExistingAchievements tests a new catalog tag replaces its aliases on existing achievements
+func TestCreateTag_NormalizesExistingAchievements(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+
+	// Act
+	status := f.createAITag()
+
+	// Assert
+	assert.Equal(t, 201, status)
+	assert.Equal(t, []string{"Artificial Intelligence", "Robotik"}, f.legacy.Tags)
+}
+
+// TestCreateTag_BumpsRenamedAchievementVersion tests a renamed achievement gets a new version so an edit still holding the old tags is rejected
+func TestCreateTag_BumpsRenamedAchievementVersion(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+	stale := f.legacy.Achievement
+
+	// Act
+	f.createAITag()
+
+	// Assert
+	assert.Equal(t, 2, f.legacy.Version)
+	err := f.mockAchRepo.UpdateAchievement(f.legacy.ReferenceID, &stale, 1)
+	assert.ErrorIs(t, err, repository.ErrAchievementVersionConflict)
+	assert.Equal(t, []string{"Artificial Intelligence", "Robotik"}, f.legacy.Tags)
+}
+
+// TestCreateTag_AliasConflict tests an alias already owned by another catalog tag is rejected
+func TestCreateTag_AliasConflict(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+	f.createAITag()
+
+	// Act
+	status := f.post("/tags", model.CreateTagRequest{Name: "Machine Learning", Aliases: []string{"AI"}})
+
+	// Assert
+	assert.Equal(t, 409, status)
+}
+
+// TestCreateAchievement_NormalizesTags tests aliases on a new achievement become the catalog name and free tags are cleaned up
+func TestCreateAchievement_NormalizesTags(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+	f.createAITag()
+
+	// Act
+	status := f.createChatbotAchievement()
+
+	// Assert
+	assert.Equal(t, 201, status)
+	created, _ := f.mockAchRepo.GetAchievementByID(f.studentID)
+	assert.Equal(t, []string{"Artificial Intelligence", "robotik"}, created.Tags)
+}
+
+// TestSuggestTags_MatchesAlias tests autocomplete finds a catalog tag through its alias and reports its usage
+func TestSuggestTags_MatchesAlias(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+	f.createAITag()
+	f.createChatbotAchievement()
+
+	// Act
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/tags/suggest?q=kecer", nil))
+	var suggestions struct {
+		Data []*model.TagSuggestion `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&suggestions)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.Len(t, suggestions.Data, 1) {
+		assert.Equal(t, "artificial-intelligence", suggestions.Data[0].Slug)
+		assert.Equal(t, "kecerdasan buatan", suggestions.Data[0].MatchedBy)
+		assert.Equal(t, 2, suggestions.Data[0].Usage)
+	}
+}
+
+// TestMergeTags_Success tests merging a free tag renames it on every achievement and keeps it as an alias
+func TestMergeTags_Success(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+	f.createAITag()
+	f.createChatbotAchievement()
+	f.post("/tags", model.CreateTagRequest{Name: "Robotika"})
+
+	// Act
+	status := f.post("/tags/robotika/merge", model.MergeTagsRequest{Sources: []string{"robotik"}})
+
+	// Assert
+	assert.Equal(t, 200, status)
+	created, _ := f.mockAchRepo.GetAchievementByID(f.studentID)
+	assert.Equal(t, []string{"Artificial Intelligence", "Robotika"}, f.legacy.Tags)
+	assert.Equal(t, []string{"Artificial Intelligence", "Robotika"}, created.Tags)
+	robotika, _ := f.mockAchRepo.GetTag("robotika")
+	assert.Equal(t, []string{"robotik"}, robotika.Aliases)
+}
+
+// TestMergeTags_IntoItself tests a tag cannot be merged into itself
+func TestMergeTags_IntoItself(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+	f.post("/tags", model.CreateTagRequest{Name: "Robotika"})
+
+	// Act
+	status := f.post("/tags/robotika/merge", model.MergeTagsRequest{Sources: []string{"Robotika"}})
+
+	// Assert
+	assert.Equal(t, 400, status)
+}
+
+// TestGetStatisticsByTag_CatalogTags tests tag statistics count achievements per normalized tag with verified points
+func TestGetStatisticsByTag_CatalogTags(t *testing.T) {
+	// Arrange
+	f := newTagFixture()
+	f.createAITag()
+	f.createChatbotAchievement()
+	f.post("/tags", model.CreateTagRequest{Name: "Robotika"})
+	f.post("/tags/robotika/merge", model.MergeTagsRequest{Sources: []string{"robotik"}})
+
+	// Act
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/reports/statistics/tag", nil))
+	var report struct {
+		Data struct {
+			Tags []*model.TagStat `json:"tags"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&report)
+
+	// Assert
+	assert.Equal(t, 200, resp.StatusCode)
+	if assert.Len(t, report.Data.Tags, 2) {
+		assert.Equal(t, "Artificial Intelligence", report.Data.Tags[0].Tag)
+		assert.Equal(t, 2, report.Data.Tags[0].Total)
+		assert.Equal(t, 1, report.Data.Tags[0].ByStatus[model.AchievementStatusVerified])
+		assert.Equal(t, 25, report.Data.Tags[0].VerifiedPoints)
+	}
+}
@@ -8,10 +8,11 @@ import (
 
 // Config menyimpan semua konfigurasi aplikasi dari environment variables
 type Config struct {
-	Database DatabaseConfig
-	MongoDB  MongoDBConfig // Add MongoDB configuration
-	Server   ServerConfig
-	JWT      JWTConfig
+	Database    DatabaseConfig
+	MongoDB     MongoDBConfig // Add MongoDB configuration
+	Server      ServerConfig
+	JWT         JWTConfig
+	Achievement AchievementConfig
 }
 
 // DatabaseConfig menyimpan konfigurasi PostgreSQL database
@@ -42,6 +43,20 @@ type JWTConfig struct {
 	Secret string // JWT_SECRET - secret key untuk JWT (default: mysecretkey)
 }
 
+// AchievementConfig menyimpan konfigurasi alur status prestasi
+type AchievementConfig struct {
+	WorkflowFile     string // ACHIEVEMENT_WORKFLOW_FILE - path file JSON definisi workflow (default: kosong, pakai workflow bawaan)
+	MaxResubmissions int    // ACHIEVEMENT_MAX_RESUBMISSIONS - batas pembukaan ulang prestasi yang ditolak, 0 = tanpa batas (default: 3)
+	CommentEditMins  int    // ACHIEVEMENT_COMMENT_EDIT_MINUTES - batas waktu edit/hapus komentar oleh penulisnya (default: 15)
+	PointsTolerance  int    // ACHIEVEMENT_POINTS_TOLERANCE_PERCENT - toleransi selisih poin dari rubrik tanpa justifikasi (default: 20)
+	TrashRetention   int    // ACHIEVEMENT_TRASH_RETENTION_DAYS - lama prestasi di trash sebelum dihapus permanen, 0 = tidak pernah (default: 30)
+	DuplicateGlobal  bool   // ACHIEVEMENT_DUPLICATE_ACROSS_STUDENTS - deteksi duplikat juga membandingkan prestasi mahasiswa lain (default: false)
+	SLARemindDays    int    // ACHIEVEMENT_SLA_REMIND_DAYS - hari menunggu verifikasi sebelum dosen wali diingatkan, 0 = SLA tidak dipantau (default: 7)
+	SLAEscalateDays  int    // ACHIEVEMENT_SLA_ESCALATE_DAYS - hari menunggu verifikasi sebelum dieskalasi ke reviewer departemen, 0 = tanpa eskalasi (default: 14)
+	ExpiryWarnDays   int    // ACHIEVEMENT_EXPIRY_WARNING_DAYS - hari sebelum masa berlaku habis mahasiswa diingatkan, 0 = tanpa pengingat (default: 30)
+	CountExpired     bool   // ACHIEVEMENT_COUNT_EXPIRED_POINTS - poin prestasi yang masa berlakunya habis tetap dihitung di laporan (default: true)
+}
+
 // LoadConfig memuat konfigurasi dari environment variables dengan default values
 func LoadConfig() *Config {
 	return &Config{
@@ -65,6 +80,18 @@ func LoadConfig() *Config {
 		JWT: JWTConfig{
 			Secret: GetEnv("JWT_SECRET", "mysecretkey"),
 		},
+		Achievement: AchievementConfig{
+			WorkflowFile:     GetEnv("ACHIEVEMENT_WORKFLOW_FILE", ""),
+			MaxResubmissions: getEnvAsInt("ACHIEVEMENT_MAX_RESUBMISSIONS", 3),
+			CommentEditMins:  getEnvAsInt("ACHIEVEMENT_COMMENT_EDIT_MINUTES", 15),
+			PointsTolerance:  getEnvAsInt("ACHIEVEMENT_POINTS_TOLERANCE_PERCENT", 20),
+			TrashRetention:   getEnvAsInt("ACHIEVEMENT_TRASH_RETENTION_DAYS", 30),
+			DuplicateGlobal:  getEnvAsBool("ACHIEVEMENT_DUPLICATE_ACROSS_STUDENTS", false),
+			SLARemindDays:    getEnvAsInt("ACHIEVEMENT_SLA_REMIND_DAYS", 7),
+			SLAEscalateDays:  getEnvAsInt("ACHIEVEMENT_SLA_ESCALATE_DAYS", 14),
+			ExpiryWarnDays:   getEnvAsInt("ACHIEVEMENT_EXPIRY_WARNING_DAYS", 30),
+			CountExpired:     getEnvAsBool("ACHIEVEMENT_COUNT_EXPIRED_POINTS", true),
+		},
 	}
 }
 
@@ -96,3 +123,12 @@ func getEnvAsInt(name string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+// getEnvAsBool mengambil environment variable sebagai boolean dengan default value
+func getEnvAsBool(name string, defaultVal bool) bool {
+	valStr := GetEnv(name, "")
+	if val, err := strconv.ParseBool(valStr); err == nil {
+		return val
+	}
+	return defaultVal
+}
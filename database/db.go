@@ -9,6 +9,7 @@ import (
 	"uas_be/config"
 
 	_ "github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -122,6 +123,33 @@ func InitMongoCollections(db *mongo.Database, collectionName string) error {
 				"tags": 1,
 			},
 		},
+		{
+			// Text index pada field yang dicari saja; isi Details dan kata dasar bahasa Indonesia masuk lewat
+			// search_terms sehingga stemming MongoDB dimatikan (default_language none). language_override
+			// diarahkan ke field yang tidak dipakai karena Details boleh berisi key "language" (mis. sertifikat bahasa).
+			Keys: bson.D{
+				{Key: "title", Value: "text"},
+				{Key: "description", Value: "text"},
+				{Key: "tags", Value: "text"},
+				{Key: "details", Value: "text"},
+				{Key: "search_terms", Value: "text"},
+			},
+			Options: options.Index().
+				SetName(achievementTextIndex).
+				SetDefaultLanguage("none").
+				SetLanguageOverride("search_language").
+				SetWeights(map[string]interface{}{
+					"title":        10,
+					"description":  5,
+					"tags":         5,
+					"search_terms": 1,
+				}),
+		},
+	}
+
+	// Collection hanya boleh punya satu text index, sehingga index wildcard versi lama dihapus lebih dulu
+	if err := dropLegacyTextIndex(ctx, collection); err != nil {
+		return err
 	}
 
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
@@ -133,6 +161,36 @@ func InitMongoCollections(db *mongo.Database, collectionName string) error {
 	return nil
 }
 
+// achievementTextIndex adalah nama text index pencarian prestasi
+const achievementTextIndex = "achievement_text_search"
+
+// dropLegacyTextIndex menghapus text index pencarian versi lama yang memakai wildcard "$**"
+func dropLegacyTextIndex(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return err
+	}
+	var indexes []bson.M
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if index["name"] != achievementTextIndex {
+			continue
+		}
+		if weights, ok := index["weights"].(bson.M); ok {
+			if _, wildcard := weights["$**"]; !wildcard {
+				return nil
+			}
+		}
+		if _, err := collection.Indexes().DropOne(ctx, achievementTextIndex); err != nil {
+			return err
+		}
+		log.Printf("🔄 Text index wildcard '%s' dihapus, dibuat ulang dengan field eksplisit", achievementTextIndex)
+	}
+	return nil
+}
+
 // InitSchema membuat schema dan tabel awal di database
 func InitSchema(db *sql.DB) error {
 	schema := `
@@ -205,6 +263,9 @@ func InitSchema(db *sql.DB) error {
 		verified_at TIMESTAMP,
 		verified_by UUID REFERENCES users(id),
 		rejection_note TEXT,
+		resubmission_count INT NOT NULL DEFAULT 0,
+		version INT NOT NULL DEFAULT 1,
+		possible_duplicate BOOLEAN NOT NULL DEFAULT FALSE,
 		deleted_at TIMESTAMP,
 		created_at TIMESTAMP DEFAULT NOW(),
 		updated_at TIMESTAMP DEFAULT NOW()
@@ -230,10 +291,169 @@ func InitSchema(db *sql.DB) error {
 		file_path VARCHAR(500) NOT NULL,
 		file_type VARCHAR(50),
 		file_size BIGINT,
+		content_hash VARCHAR(64),
 		uploaded_by UUID NOT NULL REFERENCES users(id),
 		uploaded_at TIMESTAMP DEFAULT NOW()
 	);
 
+	-- Tabel achievement_revision_requests: komentar per field saat dosen meminta revisi prestasi
+	CREATE TABLE IF NOT EXISTS achievement_revision_requests (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+		round INT NOT NULL,
+		comments JSONB NOT NULL,
+		requested_by UUID NOT NULL REFERENCES users(id),
+		created_at TIMESTAMP DEFAULT NOW(),
+		resolved_at TIMESTAMP,
+		UNIQUE (achievement_id, round)
+	);
+
+	-- Tabel approval_chains: tahapan persetujuan untuk prestasi bernilai tinggi
+	CREATE TABLE IF NOT EXISTS approval_chains (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		name VARCHAR(100) NOT NULL,
+		achievement_type VARCHAR(50),
+		min_points INT NOT NULL DEFAULT 0,
+		is_active BOOLEAN DEFAULT TRUE,
+		created_at TIMESTAMP DEFAULT NOW()
+	);
+
+	-- Tabel approval_chain_stages: urutan tahap dan pihak yang berwenang menyetujui
+	CREATE TABLE IF NOT EXISTS approval_chain_stages (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		chain_id UUID NOT NULL REFERENCES approval_chains(id) ON DELETE CASCADE,
+		stage_order INT NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		approver_role VARCHAR(50),
+		approver_user_ids JSONB NOT NULL DEFAULT '[]',
+		UNIQUE (chain_id, stage_order)
+	);
+
+	-- Tabel achievement_approvals: verified_at/verified_by untuk setiap tahap persetujuan
+	CREATE TABLE IF NOT EXISTS achievement_approvals (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+		chain_id UUID NOT NULL REFERENCES approval_chains(id),
+		stage_order INT NOT NULL,
+		stage_name VARCHAR(100) NOT NULL,
+		verified_by UUID NOT NULL REFERENCES users(id),
+		on_behalf_of UUID REFERENCES users(id),
+		proposed_points INT NOT NULL DEFAULT 0,
+		verified_at TIMESTAMP DEFAULT NOW(),
+		UNIQUE (achievement_id, stage_order)
+	);
+
+	-- Tabel points_rubric_rules: rekomendasi poin berdasarkan tipe dan atribut detail prestasi
+	CREATE TABLE IF NOT EXISTS points_rubric_rules (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_type VARCHAR(50) NOT NULL,
+		conditions JSONB NOT NULL DEFAULT '{}',
+		points INT NOT NULL CHECK (points >= 0),
+		description TEXT,
+		is_active BOOLEAN DEFAULT TRUE,
+		created_at TIMESTAMP DEFAULT NOW()
+	);
+
+	-- Tabel achievement_types: katalog tipe prestasi yang dikelola admin
+	CREATE TABLE IF NOT EXISTS achievement_types (
+		code VARCHAR(50) PRIMARY KEY,
+		name_id VARCHAR(100) NOT NULL,
+		name_en VARCHAR(100) NOT NULL,
+		description TEXT,
+		is_active BOOLEAN DEFAULT TRUE,
+		requires_attachment BOOLEAN DEFAULT FALSE,
+		created_at TIMESTAMP DEFAULT NOW(),
+		updated_at TIMESTAMP DEFAULT NOW()
+	);
+
+	-- Tabel achievement_type_schemas: versi schema Details per tipe prestasi (versi 1 tipe bawaan ada di aplikasi)
+	CREATE TABLE IF NOT EXISTS achievement_type_schemas (
+		achievement_type VARCHAR(50) NOT NULL,
+		version INT NOT NULL CHECK (version >= 1),
+		schema JSONB NOT NULL,
+		created_at TIMESTAMP DEFAULT NOW(),
+		PRIMARY KEY (achievement_type, version)
+	);
+
+	-- Tabel achievement_comments: diskusi bertingkat antara mahasiswa dan dosen pada prestasi
+	CREATE TABLE IF NOT EXISTS achievement_comments (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+		parent_id UUID REFERENCES achievement_comments(id),
+		author_id UUID NOT NULL REFERENCES users(id),
+		body TEXT NOT NULL,
+		mentions JSONB NOT NULL DEFAULT '[]',
+		attachment_id UUID REFERENCES achievement_attachments(id) ON DELETE SET NULL,
+		created_at TIMESTAMP DEFAULT NOW(),
+		edited_at TIMESTAMP,
+		deleted_at TIMESTAMP
+	);
+
+	-- Tabel notifications: pemberitahuan untuk user, misalnya mention pada komentar
+	CREATE TABLE IF NOT EXISTS notifications (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		type VARCHAR(50) NOT NULL,
+		message TEXT NOT NULL,
+		achievement_id UUID REFERENCES achievement_references(id) ON DELETE CASCADE,
+		is_read BOOLEAN DEFAULT FALSE,
+		created_at TIMESTAMP DEFAULT NOW()
+	);
+
+	-- Tabel verification_delegations: pelimpahan wewenang verifikasi antar dosen wali
+	CREATE TABLE IF NOT EXISTS verification_delegations (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		delegator_id UUID NOT NULL REFERENCES lecturers(id) ON DELETE CASCADE,
+		delegate_id UUID NOT NULL REFERENCES lecturers(id) ON DELETE CASCADE,
+		start_date DATE NOT NULL,
+		end_date DATE NOT NULL,
+		reason TEXT,
+		created_by UUID NOT NULL REFERENCES users(id),
+		revoked_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT NOW(),
+		CHECK (end_date >= start_date)
+	);
+
+	-- Tabel achievement_snapshots: salinan isi prestasi (dari MongoDB) setiap kali disubmit
+	CREATE TABLE IF NOT EXISTS achievement_snapshots (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+		version INT NOT NULL CHECK (version >= 1),
+		content JSONB NOT NULL,
+		created_by UUID NOT NULL REFERENCES users(id),
+		created_at TIMESTAMP DEFAULT NOW(),
+		UNIQUE (achievement_id, version)
+	);
+
+	-- Tabel tags: katalog tag kanonik; prestasi di MongoDB menyimpan name
+	CREATE TABLE IF NOT EXISTS tags (
+		slug VARCHAR(100) PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		name_key VARCHAR(100) NOT NULL UNIQUE,
+		created_at TIMESTAMP DEFAULT NOW(),
+		updated_at TIMESTAMP DEFAULT NOW()
+	);
+
+	-- Tabel tag_aliases: sinonim, singkatan, dan terjemahan yang dinormalisasi ke tag kanonik
+	CREATE TABLE IF NOT EXISTS tag_aliases (
+		alias_key VARCHAR(100) PRIMARY KEY,
+		alias VARCHAR(100) NOT NULL,
+		tag_slug VARCHAR(100) NOT NULL REFERENCES tags(slug) ON DELETE CASCADE
+	);
+
+	-- Tabel achievement_outbox: perubahan dokumen prestasi di MongoDB yang belum diterapkan, dicatat dalam
+	-- transaksi yang sama dengan perubahan achievement_references dan dihapus setelah berhasil diterapkan
+	CREATE TABLE IF NOT EXISTS achievement_outbox (
+		id BIGSERIAL PRIMARY KEY,
+		mongo_achievement_id VARCHAR(24) NOT NULL,
+		operation VARCHAR(20) NOT NULL CHECK (operation IN ('upsert', 'update', 'delete')),
+		payload BYTEA,
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT,
+		next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		created_at TIMESTAMP DEFAULT NOW()
+	);
+
 	-- Masukkan data awal untuk roles
 	INSERT INTO roles (name, description) VALUES 
 		('Admin', 'Administrator sistem dengan akses penuh'),
@@ -241,6 +461,16 @@ func InitSchema(db *sql.DB) error {
 		('Dosen Wali', 'Dosen pembimbing akademik')
 	ON CONFLICT (name) DO NOTHING;
 
+	-- Masukkan tipe prestasi bawaan ke katalog
+	INSERT INTO achievement_types (code, name_id, name_en) VALUES
+		('academic', 'Akademik', 'Academic'),
+		('competition', 'Kompetisi', 'Competition'),
+		('organization', 'Organisasi', 'Organization'),
+		('publication', 'Publikasi', 'Publication'),
+		('certification', 'Sertifikasi', 'Certification'),
+		('other', 'Lainnya', 'Other')
+	ON CONFLICT (code) DO NOTHING;
+
 	-- Masukkan data awal untuk permissions
 	INSERT INTO permissions (name, resource, action, description) VALUES
 		('achievement:create', 'achievement', 'create', 'Membuat prestasi baru'),
@@ -266,7 +496,14 @@ func InitSchema(db *sql.DB) error {
 		('role:update', 'role', 'update', 'Mengubah data role'),
 		('role:assign-permission', 'role', 'assign-permission', 'Menetapkan permission ke role'),
 		('role:remove-permission', 'role', 'remove-permission', 'Menghapus permission dari role'),
-		('report:read', 'report', 'read', 'Membaca laporan dan statistik')
+		('report:read', 'report', 'read', 'Membaca laporan dan statistik'),
+		('approval-chain:manage', 'approval-chain', 'manage', 'Mengelola tahapan persetujuan prestasi'),
+		('points-rubric:manage', 'points-rubric', 'manage', 'Mengelola rubrik poin prestasi'),
+		('achievement-type:manage', 'achievement-type', 'manage', 'Mengelola tipe dan schema prestasi'),
+		('achievement:monitor', 'achievement', 'monitor', 'Memantau SLA verifikasi prestasi'),
+		('tag:manage', 'tag', 'manage', 'Mengelola katalog tag prestasi'),
+		('achievement:reconcile', 'achievement', 'reconcile', 'Merekonsiliasi data prestasi PostgreSQL dan MongoDB'),
+		('achievement:comment', 'achievement', 'comment', 'Menulis, mengubah, dan menghapus komentar prestasi')
 	ON CONFLICT (name) DO NOTHING;
 
 	-- Assign permissions ke role Admin (semua permission)
@@ -277,13 +514,13 @@ func InitSchema(db *sql.DB) error {
 	-- Assign permissions ke role Mahasiswa
 	INSERT INTO role_permissions (role_id, permission_id)
 	SELECT r.id, p.id FROM roles r, permissions p 
-	WHERE r.name = 'Mahasiswa' AND p.name IN ('achievement:create', 'achievement:read', 'achievement:update', 'achievement:delete', 'achievement:submit', 'report:read')
+	WHERE r.name = 'Mahasiswa' AND p.name IN ('achievement:create', 'achievement:read', 'achievement:update', 'achievement:delete', 'achievement:submit', 'achievement:comment', 'report:read')
 	ON CONFLICT DO NOTHING;
 
 	-- Assign permissions ke role Dosen Wali
 	INSERT INTO role_permissions (role_id, permission_id)
 	SELECT r.id, p.id FROM roles r, permissions p 
-	WHERE r.name = 'Dosen Wali' AND p.name IN ('achievement:read', 'achievement:verify', 'report:read')
+	WHERE r.name = 'Dosen Wali' AND p.name IN ('achievement:read', 'achievement:verify', 'achievement:comment', 'report:read')
 	ON CONFLICT DO NOTHING;
 	`
 
@@ -362,6 +599,112 @@ func syncSchemaUpdates(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_achievement_references_student_status 
 			ON achievement_references(student_id, status) WHERE status != 'deleted';`,
 
+		// Update 3.1: Tambahkan kolom on_behalf_of di achievement_history untuk verifikasi lewat pelimpahan
+		`ALTER TABLE achievement_history ADD COLUMN IF NOT EXISTS on_behalf_of UUID REFERENCES users(id);`,
+
+		`CREATE INDEX IF NOT EXISTS idx_verification_delegations_delegate 
+			ON verification_delegations(delegate_id, start_date, end_date) WHERE revoked_at IS NULL;`,
+
+		// Update 3.2: Tambahkan kolom resubmission_count untuk membatasi pembukaan ulang prestasi yang ditolak
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS resubmission_count INT NOT NULL DEFAULT 0;`,
+
+		// Update 3.3: Index untuk thread komentar dan notifikasi yang belum dibaca
+		`CREATE INDEX IF NOT EXISTS idx_achievement_comments_achievement 
+			ON achievement_comments(achievement_id, created_at);`,
+
+		`CREATE INDEX IF NOT EXISTS idx_notifications_user_unread 
+			ON notifications(user_id, created_at) WHERE is_read = FALSE;`,
+
+		// Update 3.4: Tipe prestasi baru dari katalog boleh menerbitkan schema versi 1
+		`ALTER TABLE achievement_type_schemas DROP CONSTRAINT IF EXISTS achievement_type_schemas_version_check;`,
+
+		`ALTER TABLE achievement_type_schemas ADD CONSTRAINT achievement_type_schemas_version_check CHECK (version >= 1);`,
+
+		// Update 3.5: Index untuk trash dan job penghapusan permanen prestasi
+		`CREATE INDEX IF NOT EXISTS idx_achievement_references_deleted 
+			ON achievement_references(deleted_at) WHERE status = 'deleted';`,
+
+		// Update 3.6: Tambahkan kolom version untuk optimistic concurrency (ETag/If-Match)
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;`,
+
+		// Update 3.7: Deteksi duplikat prestasi lewat hash lampiran dan penanda di reference
+		`ALTER TABLE achievement_attachments ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);`,
+
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS possible_duplicate BOOLEAN NOT NULL DEFAULT FALSE;`,
+
+		`CREATE INDEX IF NOT EXISTS idx_achievement_attachments_content_hash 
+			ON achievement_attachments(content_hash);`,
+
+		// Update 3.8: Prestasi tim - satu dokumen MongoDB dipakai bersama oleh reference tiap anggota
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS team_id UUID;`,
+
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS team_role VARCHAR(20)
+			CHECK (team_role IN ('leader', 'member'));`,
+
+		`CREATE INDEX IF NOT EXISTS idx_achievement_references_team_id 
+			ON achievement_references(team_id);`,
+
+		`CREATE INDEX IF NOT EXISTS idx_achievement_references_mongo_id 
+			ON achievement_references(mongo_achievement_id);`,
+
+		// Update 3.9: SLA verifikasi - pengingat ke dosen wali dan eskalasi ke reviewer departemen
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS sla_reminded_at TIMESTAMP;`,
+
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMP;`,
+
+		`ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS department_reviewer BOOLEAN NOT NULL DEFAULT FALSE;`,
+
+		// Eskalasi dicatat oleh scheduler sehingga tidak ada user yang melakukan perubahan
+		`ALTER TABLE achievement_history ALTER COLUMN changed_by DROP NOT NULL;`,
+
+		`CREATE INDEX IF NOT EXISTS idx_achievement_references_submitted 
+			ON achievement_references(submitted_at) WHERE status = 'submitted';`,
+
+		`INSERT INTO permissions (name, resource, action, description) VALUES
+			('achievement:monitor', 'achievement', 'monitor', 'Memantau SLA verifikasi prestasi')
+		ON CONFLICT (name) DO NOTHING;`,
+
+		`INSERT INTO role_permissions (role_id, permission_id)
+		SELECT r.id, p.id FROM roles r, permissions p 
+		WHERE r.name = 'Admin' AND p.name = 'achievement:monitor'
+		ON CONFLICT DO NOTHING;`,
+
+		// Update 3.10: Masa berlaku prestasi (misalnya sertifikasi) dan pengingat sebelum kedaluwarsa
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS valid_until DATE;`,
+
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS expiry_reminded_at TIMESTAMP;`,
+
+		`CREATE INDEX IF NOT EXISTS idx_achievement_references_valid_until 
+			ON achievement_references(valid_until) WHERE valid_until IS NOT NULL;`,
+
+		// Update 3.11: Koreksi poin setelah verifikasi dicatat nilai lama dan barunya di history
+		`ALTER TABLE achievement_history ADD COLUMN IF NOT EXISTS old_points INT;`,
+
+		`ALTER TABLE achievement_history ADD COLUMN IF NOT EXISTS new_points INT;`,
+
+		// Update 3.12: Syarat bukti per tipe prestasi (jumlah lampiran, tipe file, field Details wajib)
+		`ALTER TABLE achievement_types ADD COLUMN IF NOT EXISTS evidence_rules JSONB NOT NULL DEFAULT '{}';`,
+
+		// Update 3.13: Index alias per tag untuk katalog tag
+		`CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag_slug ON tag_aliases(tag_slug);`,
+
+		// Update 3.14: Index outbox per dokumen MongoDB untuk menerapkan entri secara berurutan
+		`CREATE INDEX IF NOT EXISTS idx_achievement_outbox_mongo_id ON achievement_outbox(mongo_achievement_id, id);`,
+
+		// Update 3.15: Permission terpisah untuk menulis komentar prestasi
+		`INSERT INTO permissions (name, resource, action, description) VALUES
+			('achievement:comment', 'achievement', 'comment', 'Menulis, mengubah, dan menghapus komentar prestasi')
+		ON CONFLICT (name) DO NOTHING;`,
+
+		`INSERT INTO role_permissions (role_id, permission_id)
+		SELECT r.id, p.id FROM roles r, permissions p 
+		WHERE r.name IN ('Admin', 'Mahasiswa', 'Dosen Wali') AND p.name = 'achievement:comment'
+		ON CONFLICT DO NOTHING;`,
+
+		// Update 3.16: Poin disimpan per reference agar tiap anggota prestasi tim bisa dinilai sendiri.
+		// NULL berarti reference lama, poinnya masih dibaca dari dokumen MongoDB.
+		`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS points INT;`,
+
 		// Update 4: Pastikan permission report:read ada
 		`INSERT INTO permissions (name, resource, action, description) VALUES
 			('report:read', 'report', 'read', 'Membaca laporan dan statistik')
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"os"
+	"time"
 
 	"uas_be/app/model"
 	"uas_be/app/repository"
+	"uas_be/app/service"
 	"uas_be/config"
 	"uas_be/database"
 	_ "uas_be/docs"
@@ -43,6 +46,21 @@ func main() {
 	// Initialize JWT secret
 	utils.InitJWT(cfg.JWT.Secret)
 
+	// Load achievement status workflow (falls back to the built-in workflow)
+	if err := service.InitAchievementWorkflow(cfg.Achievement.WorkflowFile); err != nil {
+		log.Fatal("❌ Failed to load achievement workflow:", err)
+	}
+	service.SetAchievementResubmissionLimit(cfg.Achievement.MaxResubmissions)
+	service.SetCommentEditWindow(time.Duration(cfg.Achievement.CommentEditMins) * time.Minute)
+	service.SetPointsTolerance(cfg.Achievement.PointsTolerance)
+	service.SetTrashRetention(time.Duration(cfg.Achievement.TrashRetention) * 24 * time.Hour)
+	service.SetDuplicateCheckAcrossStudents(cfg.Achievement.DuplicateGlobal)
+	service.SetVerificationSLA(
+		time.Duration(cfg.Achievement.SLARemindDays)*24*time.Hour,
+		time.Duration(cfg.Achievement.SLAEscalateDays)*24*time.Hour,
+	)
+	service.SetAchievementExpiry(time.Duration(cfg.Achievement.ExpiryWarnDays)*24*time.Hour, cfg.Achievement.CountExpired)
+
 	db := database.InitPostgres(cfg)
 	if err := database.InitSchema(db); err != nil {
 		log.Println("warning: failed to init schema:", err)
@@ -63,9 +81,35 @@ func main() {
 
 	database.SetDB(db)
 
+	// Subcommand CLI: go run . reconcile [-repair] [-dry-run] [-backfill-search-terms]
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(db, os.Args[2:]))
+	}
+
 	// Seed default admin user
 	seedDefaultAdmin(db)
 
+	// Terapkan ulang perubahan dokumen prestasi di MongoDB yang belum berhasil
+	service.StartAchievementOutboxJob(repository.NewAchievementRepository(db), 30*time.Second)
+
+	// Hapus permanen prestasi yang sudah melewati masa simpan trash
+	service.StartTrashPurgeJob(repository.NewAchievementRepository(db), time.Hour)
+
+	// Ingatkan dosen wali dan eskalasi prestasi yang melewati SLA verifikasi
+	service.StartSLAEscalationJob(service.NewSLAService(
+		repository.NewAchievementRepository(db),
+		repository.NewLecturerRepository(db),
+		repository.NewNotificationRepository(db),
+	), time.Hour)
+
+	// Ingatkan mahasiswa yang masa berlaku prestasinya (misalnya sertifikasi) segera habis
+	service.StartExpiryWarningJob(
+		repository.NewAchievementRepository(db),
+		repository.NewStudentRepository(db),
+		repository.NewNotificationRepository(db),
+		24*time.Hour,
+	)
+
 	// ===== FIBER APP =====
 	app := fiber.New()
 
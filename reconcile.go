@@ -0,0 +1,54 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"uas_be/app/repository"
+	"uas_be/app/service"
+)
+
+// runReconcile menjalankan rekonsiliasi PostgreSQL dan MongoDB dari command line dan mencetak laporannya
+// sebagai JSON. Exit code 1 jika masih ada ketidaksesuaian yang belum diperbaiki.
+func runReconcile(db *sql.DB, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	repair := flags.Bool("repair", false, "perbaiki ketidaksesuaian yang ditemukan")
+	dryRun := flags.Bool("dry-run", false, "bersama -repair atau -backfill-search-terms: laporkan tindakan tanpa mengubah data")
+	backfill := flags.Bool("backfill-search-terms", false, "isi search_terms dokumen lama yang belum memilikinya")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	repo := repository.NewAchievementRepository(db)
+	if *backfill {
+		count, err := repo.BackfillSearchTerms(*dryRun)
+		if err != nil {
+			log.Println("❌ Search terms backfill failed:", err)
+			return 1
+		}
+		log.Printf("🔤 Backfilled search terms for %d documents", count)
+	}
+
+	report, err := service.ReconcileAchievementStores(repo, *repair, *dryRun)
+	if err != nil {
+		log.Println("❌ Reconciliation failed:", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Println("❌ Failed to write reconciliation report:", err)
+		return 1
+	}
+
+	log.Printf("🔍 Scanned %d references and %d documents: %d mismatches, %d repaired, %d failed",
+		report.ScannedReferences, report.ScannedDocuments, len(report.Mismatches), report.Repaired, report.Failed)
+	if len(report.Mismatches) > report.Repaired {
+		return 1
+	}
+	return 0
+}
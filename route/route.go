@@ -18,6 +18,8 @@ func RegisterRoutes(app *fiber.App) {
 	roleRepo := repository.NewRoleRepository(db)
 	permissionRepo := repository.NewPermissionRepository(db)
 	userRepo := repository.NewUserRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
 
 	authService := service.NewAuthService(userRepo, permissionRepo, roleRepo)
 	achievementService := service.NewAchievementService(achievementRepo, studentRepo, lecturerRepo)
@@ -27,15 +29,29 @@ func RegisterRoutes(app *fiber.App) {
 	userService := service.NewUserService(userRepo)
 	permissionService := service.NewPermissionService(permissionRepo)
 	reportService := service.NewReportService(achievementRepo, studentRepo, lecturerRepo)
+	slaService := service.NewSLAService(achievementRepo, lecturerRepo, notificationRepo)
+	approvalChainService := service.NewApprovalChainService(achievementRepo)
+	pointsRubricService := service.NewPointsRubricService(achievementRepo)
+	achievementTypeService := service.NewAchievementTypeService(achievementRepo)
+	tagService := service.NewTagService(achievementRepo)
+	commentService := service.NewCommentService(commentRepo, achievementRepo, studentRepo, lecturerRepo, userRepo, notificationRepo)
+	notificationService := service.NewNotificationService(notificationRepo)
+	reconcileService := service.NewReconcileService(achievementRepo)
 
 	SetupAuthRoutes(app, authService)
-	SetupAchievementRoutes(app, achievementService)
+	SetupAchievementRoutes(app, achievementService, commentService)
 	SetupLecturerRoutes(app, lecturerService)
 	SetupStudentRoutes(app, studentService)
 	SetupRoleRoutes(app, roleService)
 	SetupUserRoutes(app, userService)
 	SetupPermissionRoutes(app, permissionService)
-	SetupReportRoutes(app, reportService)
+	SetupReportRoutes(app, reportService, slaService)
+	SetupApprovalChainRoutes(app, approvalChainService)
+	SetupPointsRubricRoutes(app, pointsRubricService)
+	SetupAchievementTypeRoutes(app, achievementTypeService)
+	SetupTagRoutes(app, tagService)
+	SetupNotificationRoutes(app, notificationService)
+	SetupReconcileRoutes(app, reconcileService)
 
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -46,20 +62,77 @@ func RegisterRoutes(app *fiber.App) {
 	})
 }
 
-func SetupAchievementRoutes(app *fiber.App, achievementService service.AchievementService) {
+func SetupAchievementRoutes(app *fiber.App, achievementService service.AchievementService, commentService service.CommentService) {
 	group := app.Group("/api/v1/achievements", middleware.AuthMiddleware())
 
 	group.Get("/", middleware.RBACMiddleware("achievement:read"), achievementService.GetAllAchievements)
+	group.Get("/search", middleware.RBACMiddleware("achievement:read"), achievementService.SearchAchievements)
+	group.Get("/trash", middleware.RBACMiddleware("achievement:delete"), achievementService.GetTrash)
 	group.Get("/:id", middleware.RBACMiddleware("achievement:read"), achievementService.GetAchievementDetail)
 	group.Get("/:id/history", achievementService.GetAchievementHistory)
+	group.Get("/:id/versions", middleware.RBACMiddleware("achievement:read"), achievementService.GetAchievementVersions)
+	group.Get("/:id/versions/diff", middleware.RBACMiddleware("achievement:read"), achievementService.GetAchievementVersionDiff)
 	group.Post("/", middleware.RBACMiddleware("achievement:create"), achievementService.CreateAchievement)
 	group.Post("/:id/attachments", middleware.RBACMiddleware("achievement:update"), achievementService.UploadAttachment)
 	group.Put("/:id", middleware.RBACMiddleware("achievement:update"), achievementService.UpdateAchievement)
+	group.Patch("/:id", middleware.RBACMiddleware("achievement:update"), achievementService.PatchAchievement)
 	group.Delete("/:id", middleware.RBACMiddleware("achievement:delete"), achievementService.DeleteAchievement)
 	group.Post("/:id/submit", middleware.RBACMiddleware("achievement:submit"), achievementService.SubmitAchievement)
+	group.Post("/:id/restore", middleware.RBACMiddleware("achievement:delete"), achievementService.RestoreAchievement)
+	group.Post("/:id/reopen", middleware.RBACMiddleware("achievement:update"), achievementService.ReopenAchievement)
+	group.Post("/bulk-review", middleware.RBACMiddleware("achievement:verify"), achievementService.BulkReviewAchievements)
 	group.Post("/:id/verify", middleware.RBACMiddleware("achievement:verify"), achievementService.VerifyAchievement)
 	group.Post("/:id/reject", middleware.RBACMiddleware("achievement:verify"), achievementService.RejectAchievement)
+	group.Post("/:id/request-revision", middleware.RBACMiddleware("achievement:verify"), achievementService.RequestRevision)
+	group.Put("/:id/points", middleware.RBACMiddleware("achievement:verify"), achievementService.AdjustAchievementPoints)
+	// Permission aksi generik dicek per transisi oleh workflow, bukan oleh middleware
+	group.Post("/:id/transitions/:action", achievementService.TransitionAchievement)
 	group.Get("/advisee/list", middleware.RBACMiddleware("achievement:read"), achievementService.GetAdviseeAchievements)
+
+	group.Get("/:id/comments", middleware.RBACMiddleware("achievement:read"), commentService.GetComments)
+	group.Post("/:id/comments", middleware.RBACMiddleware("achievement:comment"), commentService.CreateComment)
+	group.Put("/:id/comments/:commentId", middleware.RBACMiddleware("achievement:comment"), commentService.UpdateComment)
+	group.Delete("/:id/comments/:commentId", middleware.RBACMiddleware("achievement:comment"), commentService.DeleteComment)
+	group.Get("/:id/timeline", middleware.RBACMiddleware("achievement:read"), commentService.GetTimeline)
+}
+
+func SetupPointsRubricRoutes(app *fiber.App, pointsRubricService service.PointsRubricService) {
+	group := app.Group("/api/v1/points-rubric", middleware.AuthMiddleware())
+
+	group.Get("/", middleware.RBACMiddleware("points-rubric:manage"), pointsRubricService.GetRubricRules)
+	group.Post("/", middleware.RBACMiddleware("points-rubric:manage"), pointsRubricService.CreateRubricRule)
+	group.Delete("/:id", middleware.RBACMiddleware("points-rubric:manage"), pointsRubricService.DeactivateRubricRule)
+}
+
+func SetupAchievementTypeRoutes(app *fiber.App, achievementTypeService service.AchievementTypeService) {
+	group := app.Group("/api/v1/achievement-types", middleware.AuthMiddleware())
+
+	group.Get("/", achievementTypeService.GetAchievementTypes)
+	group.Post("/", middleware.RBACMiddleware("achievement-type:manage"), achievementTypeService.CreateAchievementType)
+	group.Get("/:type", achievementTypeService.GetAchievementType)
+	group.Put("/:type", middleware.RBACMiddleware("achievement-type:manage"), achievementTypeService.UpdateAchievementType)
+	group.Delete("/:type", middleware.RBACMiddleware("achievement-type:manage"), achievementTypeService.RetireAchievementType)
+	group.Get("/:type/schema", achievementTypeService.GetTypeSchema)
+	group.Post("/:type/schema", middleware.RBACMiddleware("achievement-type:manage"), achievementTypeService.PublishTypeSchema)
+}
+
+func SetupTagRoutes(app *fiber.App, tagService service.TagService) {
+	group := app.Group("/api/v1/tags", middleware.AuthMiddleware())
+
+	group.Get("/", tagService.GetTags)
+	group.Get("/suggest", tagService.SuggestTags)
+	group.Post("/", middleware.RBACMiddleware("tag:manage"), tagService.CreateTag)
+	group.Get("/:slug", tagService.GetTag)
+	group.Put("/:slug", middleware.RBACMiddleware("tag:manage"), tagService.UpdateTag)
+	group.Delete("/:slug", middleware.RBACMiddleware("tag:manage"), tagService.DeleteTag)
+	group.Post("/:slug/merge", middleware.RBACMiddleware("tag:manage"), tagService.MergeTags)
+}
+
+func SetupNotificationRoutes(app *fiber.App, notificationService service.NotificationService) {
+	group := app.Group("/api/v1/notifications", middleware.AuthMiddleware())
+
+	group.Get("/", notificationService.GetNotifications)
+	group.Put("/:id/read", notificationService.MarkAsRead)
 }
 
 func SetupLecturerRoutes(app *fiber.App, lecturerService service.LecturerService) {
@@ -71,6 +144,10 @@ func SetupLecturerRoutes(app *fiber.App, lecturerService service.LecturerService
 	group.Post("/", middleware.RBACMiddleware("lecturer:create"), lecturerService.CreateLecturer)
 	group.Put("/:id", middleware.RBACMiddleware("lecturer:update"), lecturerService.UpdateLecturer)
 	group.Delete("/:id", middleware.RBACMiddleware("lecturer:delete"), lecturerService.DeleteLecturer)
+	group.Get("/:id/delegations", middleware.RBACMiddleware("achievement:verify"), lecturerService.GetDelegations)
+	group.Post("/:id/delegations", middleware.RBACMiddleware("achievement:verify"), lecturerService.CreateDelegation)
+	group.Delete("/:id/delegations/:delegationId", middleware.RBACMiddleware("achievement:verify"), lecturerService.RevokeDelegation)
+	group.Put("/:id/department-reviewer", middleware.RBACMiddleware("lecturer:update"), lecturerService.SetDepartmentReviewer)
 }
 
 func SetupRoleRoutes(app *fiber.App, roleService service.RoleService) {
@@ -110,14 +187,16 @@ func SetupUserRoutes(app *fiber.App, userService service.UserService) {
 	group.Delete("/:id", middleware.RBACMiddleware("user:delete"), userService.DeleteUser)
 }
 
-func SetupReportRoutes(app *fiber.App, reportService service.ReportService) {
+func SetupReportRoutes(app *fiber.App, reportService service.ReportService, slaService service.SLAService) {
 	group := app.Group("/api/v1/reports", middleware.AuthMiddleware())
 
 	group.Get("/statistics", reportService.GetStatistics)
 	group.Get("/statistics/period", reportService.GetStatisticsByPeriod)
 	group.Get("/statistics/type", reportService.GetStatisticsByType)
+	group.Get("/statistics/tag", reportService.GetStatisticsByTag)
 	group.Get("/top-students", reportService.GetTopStudents)
 	group.Get("/student/:id", reportService.GetStudentReport)
+	group.Get("/overdue-submissions", middleware.RBACMiddleware("achievement:monitor"), slaService.GetOverdueSubmissions)
 }
 
 func SetupPermissionRoutes(app *fiber.App, permissionService service.PermissionService) {
@@ -128,6 +207,21 @@ func SetupPermissionRoutes(app *fiber.App, permissionService service.PermissionS
 	group.Get("/role/:roleId", middleware.RBACMiddleware("permission:read"), permissionService.GetPermissionsByRoleID)
 }
 
+func SetupApprovalChainRoutes(app *fiber.App, approvalChainService service.ApprovalChainService) {
+	group := app.Group("/api/v1/approval-chains", middleware.AuthMiddleware())
+
+	group.Get("/", middleware.RBACMiddleware("approval-chain:manage"), approvalChainService.GetApprovalChains)
+	group.Post("/", middleware.RBACMiddleware("approval-chain:manage"), approvalChainService.CreateApprovalChain)
+	group.Delete("/:id", middleware.RBACMiddleware("approval-chain:manage"), approvalChainService.DeactivateApprovalChain)
+}
+
+func SetupReconcileRoutes(app *fiber.App, reconcileService service.ReconcileService) {
+	group := app.Group("/api/v1/admin/reconcile", middleware.AuthMiddleware())
+
+	group.Get("/", middleware.RBACMiddleware("achievement:reconcile"), reconcileService.GetReconcileReport)
+	group.Post("/", middleware.RBACMiddleware("achievement:reconcile"), reconcileService.RepairMismatches)
+}
+
 func SetupAuthRoutes(app *fiber.App, authService service.AuthService) {
 	auth := app.Group("/api/v1/auth")
 